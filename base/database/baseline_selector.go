@@ -0,0 +1,145 @@
+package database
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Selector of systems dynamically associated with a baseline, all the set criteria have to match
+type BaselineSelector struct {
+	// Tags the system has to have, namespace and value are optional
+	Tags []ScopeTag `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Major version of the system operating system
+	OSMajor *int `json:"os_major,omitempty" yaml:"os_major,omitempty" example:"8"`
+	// Minor version of the system operating system
+	OSMinor *int `json:"os_minor,omitempty" yaml:"os_minor,omitempty" example:"6"`
+	// Pattern of the system display name, `*` matches any characters
+	DisplayName string `json:"display_name,omitempty" yaml:"display_name,omitempty" example:"web-*.example.com"`
+	// Repositories the system has to have at least one of
+	Repos []string `json:"repos,omitempty" yaml:"repos,omitempty" example:"rhel-8-for-x86_64-baseos-rpms"`
+}
+
+// System facts matched by baseline selectors
+type SelectorFacts struct {
+	DisplayName string
+	Tags        []ScopeTag
+	OSMajor     int
+	OSMinor     int
+	Repos       []string
+}
+
+func (s *BaselineSelector) IsEmpty() bool {
+	return len(s.Tags) == 0 && s.OSMajor == nil && s.OSMinor == nil && s.DisplayName == "" && len(s.Repos) == 0
+}
+
+// Display name pattern as regular expression usable both in go and postgres
+func (s *BaselineSelector) displayNameRegexp() string {
+	parts := strings.Split(s.DisplayName, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return "^" + strings.Join(parts, ".*") + "$"
+}
+
+func stringPtrMatches(selector *string, value *string) bool {
+	return selector == nil || value != nil && *selector == *value
+}
+
+func (s *BaselineSelector) tagsMatch(tags []ScopeTag) bool {
+	for _, selectorTag := range s.Tags {
+		found := false
+		for _, tag := range tags {
+			if tag.Key == selectorTag.Key && stringPtrMatches(selectorTag.Namespace, tag.Namespace) &&
+				stringPtrMatches(selectorTag.Value, tag.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *BaselineSelector) reposMatch(repos []string) bool {
+	if len(s.Repos) == 0 {
+		return true
+	}
+	for _, selectorRepo := range s.Repos {
+		for _, repo := range repos {
+			if repo == selectorRepo {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Matches reports whether the system facts match the selector, it has to be consistent with Condition
+func (s *BaselineSelector) Matches(facts *SelectorFacts) bool {
+	if s.IsEmpty() {
+		return false
+	}
+	if s.OSMajor != nil && *s.OSMajor != facts.OSMajor || s.OSMinor != nil && *s.OSMinor != facts.OSMinor {
+		return false
+	}
+	if s.DisplayName != "" && !regexp.MustCompile(s.displayNameRegexp()).MatchString(facts.DisplayName) {
+		return false
+	}
+	return s.tagsMatch(facts.Tags) && s.reposMatch(facts.Repos)
+}
+
+// Condition returns SQL condition on `system_platform sp` matching systems of the selector,
+// the condition is never NULL so it can be negated
+func (s *BaselineSelector) Condition() (string, []interface{}) {
+	if s.IsEmpty() {
+		return "false", nil
+	}
+
+	conds := make([]string, 0, 3)
+	args := make([]interface{}, 0, 4)
+	if s.DisplayName != "" {
+		conds = append(conds, "sp.display_name ~ ?")
+		args = append(args, s.displayNameRegexp())
+	}
+	if len(s.Repos) > 0 {
+		conds = append(conds, "EXISTS (SELECT 1 FROM system_repo sr JOIN repo r ON r.id = sr.repo_id"+
+			" WHERE sr.rh_account_id = sp.rh_account_id AND sr.system_id = sp.id AND r.name IN (?))")
+		args = append(args, s.Repos)
+	}
+
+	hostConds := make([]string, 0, 3)
+	if len(s.Tags) > 0 {
+		tagsJSON, _ := json.Marshal(s.Tags)
+		hostConds = append(hostConds, "ihs.tags @> ?::jsonb")
+		args = append(args, string(tagsJSON))
+	}
+	if s.OSMajor != nil {
+		hostConds = append(hostConds, "ihs.system_profile->'operating_system'->>'major' = ?")
+		args = append(args, strconv.Itoa(*s.OSMajor))
+	}
+	if s.OSMinor != nil {
+		hostConds = append(hostConds, "ihs.system_profile->'operating_system'->>'minor' = ?")
+		args = append(args, strconv.Itoa(*s.OSMinor))
+	}
+	if len(hostConds) > 0 {
+		conds = append(conds, "EXISTS (SELECT 1 FROM inventory.hosts ihs WHERE ihs.id = sp.inventory_id AND "+
+			strings.Join(hostConds, " AND ")+")")
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// ParseBaselineSelector returns nil for baselines without selector
+func ParseBaselineSelector(selectorJSON []byte) (*BaselineSelector, error) {
+	if len(selectorJSON) == 0 {
+		return nil, nil
+	}
+	var selector BaselineSelector
+	if err := json.Unmarshal(selectorJSON, &selector); err != nil {
+		return nil, err
+	}
+	return &selector, nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"app/base/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaselineSelectorMatches(t *testing.T) {
+	facts := SelectorFacts{
+		DisplayName: "web-1.example.com",
+		Tags:        []ScopeTag{{Namespace: utils.PtrString("ns1"), Key: "k1", Value: utils.PtrString("val1")}},
+		OSMajor:     8,
+		OSMinor:     6,
+		Repos:       []string{"repo1", "repo2"},
+	}
+	major, minor := 8, 4
+	assert.False(t, (&BaselineSelector{}).Matches(&facts))
+	assert.True(t, (&BaselineSelector{DisplayName: "web-*.example.com"}).Matches(&facts))
+	assert.False(t, (&BaselineSelector{DisplayName: "web-?.example.com"}).Matches(&facts))
+	assert.False(t, (&BaselineSelector{DisplayName: "web-1"}).Matches(&facts))
+	assert.True(t, (&BaselineSelector{OSMajor: &major, Repos: []string{"repo3", "repo2"}}).Matches(&facts))
+	assert.False(t, (&BaselineSelector{OSMajor: &major, OSMinor: &minor}).Matches(&facts))
+	assert.False(t, (&BaselineSelector{Repos: []string{"repo3"}}).Matches(&facts))
+	assert.True(t, (&BaselineSelector{Tags: []ScopeTag{{Key: "k1"}}}).Matches(&facts))
+	assert.False(t, (&BaselineSelector{Tags: []ScopeTag{{Key: "k1", Value: utils.PtrString("val2")}}}).Matches(&facts))
+	assert.False(t, (&BaselineSelector{Tags: []ScopeTag{{Key: "k1"}, {Key: "k2"}}}).Matches(&facts))
+}
+
+func TestBaselineSelectorCondition(t *testing.T) {
+	major := 8
+	cond, args := (&BaselineSelector{DisplayName: "web.*", OSMajor: &major}).Condition()
+	assert.Equal(t, "sp.display_name ~ ? AND EXISTS (SELECT 1 FROM inventory.hosts ihs WHERE ihs.id = sp.inventory_id"+
+		" AND ihs.system_profile->'operating_system'->>'major' = ?)", cond)
+	assert.Equal(t, []interface{}{`^web\..*$`, "8"}, args)
+
+	cond, args = (&BaselineSelector{}).Condition()
+	assert.Equal(t, "false", cond)
+	assert.Nil(t, args)
+}
+
+func TestBaselineSelectorSystems(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	Configure()
+
+	var inventoryIDs []string
+	selector := BaselineSelector{Tags: []ScopeTag{{Key: "k3"}}, Repos: []string{"repo1"}}
+	cond, args := selector.Condition()
+	assert.Nil(t, Db.Table("system_platform sp").Where(cond, args...).Order("sp.inventory_id").
+		Pluck("sp.inventory_id", &inventoryIDs).Error)
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000003"},
+		inventoryIDs)
+}
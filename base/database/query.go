@@ -27,6 +27,11 @@ type AttrMap = map[AttrName]AttrInfo
 var ColumnNameRe = regexp.MustCompile(`column:([\w_]+)`)
 
 func MustGetSelect(t interface{}) string {
+	return MustGetSelectColumns(t, nil)
+}
+
+// Same as MustGetSelect, but only listed columns are selected. All columns are selected for nil map.
+func MustGetSelectColumns(t interface{}, columns map[string]bool) string {
 	// We must get fields ordered, so we assemble them in proper order for gorm select
 	attrs, names, err := GetQueryAttrs(t)
 	if err != nil {
@@ -34,6 +39,9 @@ func MustGetSelect(t interface{}) string {
 	}
 	fields := make([]string, 0, len(names))
 	for _, n := range names {
+		if columns != nil && !columns[n] {
+			continue
+		}
 		fields = append(fields, fmt.Sprintf("%v as %v", attrs[n].DataQuery, n))
 	}
 	return strings.Join(fields, ", ")
@@ -141,6 +149,45 @@ func GetQueryAttrs(s interface{}) (AttrMap, []string, error) {
 	return nil, nil, errors.New("Invalid type")
 }
 
+// Map json attribute names to database column names, attributes not loaded from a column (`gorm:"-"`) are mapped to ""
+func getJSONColumns(v reflect.Type, res map[string]string) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Name == field.Type.Name() {
+			getJSONColumns(field.Type, res)
+			continue
+		}
+
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "-" || jsonName == "" {
+			continue
+		}
+
+		columnName := field.Name
+		if expr, has := field.Tag.Lookup("gorm"); has {
+			if expr == "-" {
+				columnName = ""
+			} else if match := ColumnNameRe.FindStringSubmatch(expr); len(match) > 0 {
+				columnName = match[1]
+			}
+		}
+		res[jsonName] = columnName
+	}
+}
+
+func MustGetJSONColumns(s interface{}) map[string]string {
+	v := reflect.TypeOf(s)
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(errors.New("Only struct kind is supported"))
+	}
+	res := map[string]string{}
+	getJSONColumns(v, res)
+	return res
+}
+
 func MustGetQueryAttrs(s interface{}) AttrMap {
 	res, _, err := GetQueryAttrs(s)
 	if err != nil {
@@ -82,3 +82,29 @@ func TestOrderQuery(t *testing.T) {
 	assert.Equal(t, info["note"].OrderQuery, info["note"].DataQuery)
 	assert.Equal(t, info["note2"].OrderQuery, "REVERSE(am.text_note)")
 }
+
+func TestSelectColumns(t *testing.T) {
+	sel := MustGetSelectColumns(queryStruct{}, map[string]bool{"id": true, "bare": true})
+	assert.Equal(t, "am.id as id, bare as bare", sel)
+	assert.Equal(t, MustGetSelect(queryStruct{}), MustGetSelectColumns(queryStruct{}, nil))
+}
+
+type jsonInherited struct {
+	Bare string `json:"bare" gorm:"column:bare_col"`
+}
+
+type jsonStruct struct {
+	ID      int      `json:"id" gorm:"column:id"`
+	Name    string   `json:"name,omitempty" gorm:"column:name_col"`
+	Hidden  string   `json:"-" gorm:"column:hidden"`
+	Virtual []string `json:"virtual" gorm:"-"`
+	jsonInherited
+}
+
+func TestJSONColumns(t *testing.T) {
+	columns := MustGetJSONColumns(&jsonStruct{})
+	assert.Equal(t, map[string]string{"id": "id", "name": "name_col", "virtual": "", "bare": "bare_col"}, columns)
+	assert.Panics(t, func() {
+		MustGetJSONColumns([]string{})
+	})
+}
@@ -393,6 +393,27 @@ func DeleteBaseline(t *testing.T, baselineID int) {
 	assert.Nil(t, err)
 }
 
+func DeleteTemplate(t *testing.T, templateID int) {
+	tx := Db.WithContext(base.Context).Begin()
+	defer tx.Rollback()
+
+	err := tx.Model(models.SystemPlatform{}).
+		Where("template_id = ?", templateID).
+		Updates(map[string]interface{}{"template_id": nil, "template_installable": 0, "template_applicable": 0}).Error
+	assert.Nil(t, err)
+
+	err = tx.Where("id = ?", templateID).Delete(&models.Template{}).Error
+	assert.Nil(t, err)
+
+	err = tx.Commit().Error
+	assert.Nil(t, err)
+}
+
+func DeleteAPIToken(t *testing.T, tokenID int64) {
+	err := Db.Where("id = ?", tokenID).Delete(&models.APIToken{}).Error
+	assert.Nil(t, err)
+}
+
 func CheckBaseline(t *testing.T, baselineID int, inventoryIDs []string, config, name string, description *string) {
 	type Baseline struct {
 		ID          int     `query:"bl.id" gorm:"column:id"`
@@ -4,6 +4,7 @@ import (
 	"app/base/models"
 	"app/base/types"
 	"app/base/utils"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -21,12 +22,56 @@ const (
 	PgErrorDuplicateKey = "23505"
 )
 
-func Systems(tx *gorm.DB, accountID int) *gorm.DB {
-	return tx.Table("system_platform sp").Where("sp.rh_account_id = ?", accountID)
+// SystemScope restricts systems accessible by the user to inventory groups or tags,
+// system is accessible when it matches any of them. Nil scope doesn't restrict systems.
+type SystemScope struct {
+	Groups    []string   // inventory group ids
+	Ungrouped bool       // systems without inventory group
+	Tags      []ScopeTag // tags, missing namespace or value match any
 }
 
-func SystemAdvisories(tx *gorm.DB, accountID int) *gorm.DB {
-	return Systems(tx, accountID).
+type ScopeTag struct {
+	Namespace *string `json:"namespace,omitempty"`
+	Key       string  `json:"key"`
+	Value     *string `json:"value,omitempty"`
+}
+
+func Systems(tx *gorm.DB, accountID int, scope *SystemScope) *gorm.DB {
+	tx = tx.Table("system_platform sp").Where("sp.rh_account_id = ?", accountID)
+	return ApplySystemScope(tx, scope)
+}
+
+// ApplySystemScope limits query on system_platform sp to systems accessible in the scope
+func ApplySystemScope(tx *gorm.DB, scope *SystemScope) *gorm.DB {
+	if scope == nil {
+		return tx
+	}
+
+	conds := make([]string, 0, len(scope.Groups)+len(scope.Tags)+1)
+	args := make([]interface{}, 0, len(scope.Groups)+len(scope.Tags))
+	for _, group := range scope.Groups {
+		groupJSON, _ := json.Marshal([]map[string]string{{"id": group}})
+		conds = append(conds, "ihs.groups @> ?::jsonb")
+		args = append(args, string(groupJSON))
+	}
+	if scope.Ungrouped {
+		conds = append(conds, "ihs.groups = '[]'::jsonb")
+	}
+	for _, tag := range scope.Tags {
+		tagJSON, _ := json.Marshal([]ScopeTag{tag})
+		conds = append(conds, "ihs.tags @> ?::jsonb")
+		args = append(args, string(tagJSON))
+	}
+	if len(conds) == 0 {
+		// scope without any group or tag allows no system
+		return tx.Where("false")
+	}
+	return tx.Where(fmt.Sprintf("EXISTS (SELECT 1 FROM inventory.hosts ihs WHERE ihs.id = sp.inventory_id AND (%s))",
+		strings.Join(conds, " OR ")), args...)
+}
+
+func SystemAdvisories(tx *gorm.DB, accountID int, scope *SystemScope) *gorm.DB {
+	return Systems(tx, accountID, scope).
 		Joins("JOIN system_advisories sa on sa.system_id = sp.id AND sa.rh_account_id = ?", accountID).
 		Where("when_patched IS NULL")
 }
@@ -36,8 +81,8 @@ func SystemPackagesShort(tx *gorm.DB, accountID int) *gorm.DB {
 		Where("spkg.rh_account_id = ?", accountID)
 }
 
-func SystemPackages(tx *gorm.DB, accountID int) *gorm.DB {
-	return Systems(tx, accountID).
+func SystemPackages(tx *gorm.DB, accountID int, scope *SystemScope) *gorm.DB {
+	return Systems(tx, accountID, scope).
 		Joins("JOIN system_package spkg on spkg.system_id = sp.id AND spkg.rh_account_id = ?", accountID).
 		Joins("JOIN package p on p.id = spkg.package_id").
 		Joins("JOIN package_name pn on pn.id = spkg.name_id")
@@ -55,8 +100,8 @@ func PackageByName(tx *gorm.DB, pkgName string) *gorm.DB {
 	return Packages(tx).Where("pn.name = ?", pkgName)
 }
 
-func SystemAdvisoriesByInventoryID(tx *gorm.DB, accountID int, inventoryID string) *gorm.DB {
-	return SystemAdvisories(tx, accountID).Where("sp.inventory_id = ?::uuid", inventoryID)
+func SystemAdvisoriesByInventoryID(tx *gorm.DB, accountID int, scope *SystemScope, inventoryID string) *gorm.DB {
+	return SystemAdvisories(tx, accountID, scope).Where("sp.inventory_id = ?::uuid", inventoryID)
 }
 
 func SystemAdvisoriesBySystemID(tx *gorm.DB, accountID, systemID int) *gorm.DB {
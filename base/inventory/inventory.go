@@ -1,13 +1,19 @@
 package inventory
 
 type SystemProfile struct {
-	Arch              *string         `json:"arch,omitempty"`
-	HostType          string          `json:"host_type,omitempty"`
-	InstalledPackages *[]string       `json:"installed_packages,omitempty"`
-	YumRepos          *[]YumRepo      `json:"yum_repos,omitempty"`
-	DnfModules        *[]DnfModule    `json:"dnf_modules,omitempty"`
-	OperatingSystem   OperatingSystem `json:"operating_system,omitempty"`
-	Rhsm              Rhsm            `json:"rhsm,omitempty"`
+	Arch               *string         `json:"arch,omitempty"`
+	HostType           string          `json:"host_type,omitempty"`
+	InstalledPackages  *[]string       `json:"installed_packages,omitempty"`
+	YumRepos           *[]YumRepo      `json:"yum_repos,omitempty"`
+	DnfModules         *[]DnfModule    `json:"dnf_modules,omitempty"`
+	OperatingSystem    OperatingSystem `json:"operating_system,omitempty"`
+	Rhsm               Rhsm            `json:"rhsm,omitempty"`
+	OSKernelVersion    *string         `json:"os_kernel_version,omitempty"`
+	LastBootTime       *string         `json:"last_boot_time,omitempty"`
+	SapSystem          bool            `json:"sap_system,omitempty"`
+	Ansible            *Ansible        `json:"ansible,omitempty"`
+	InfrastructureType *string         `json:"infrastructure_type,omitempty"`
+	CloudProvider      *string         `json:"cloud_provider,omitempty"`
 }
 
 func (t *SystemProfile) GetInstalledPackages() []string {
@@ -51,3 +57,10 @@ type DnfModule struct {
 type Rhsm struct {
 	Version string `json:"version,omitempty"`
 }
+
+type Ansible struct {
+	ControllerVersion    string `json:"controller_version,omitempty"`
+	HubVersion           string `json:"hub_version,omitempty"`
+	CatalogWorkerVersion string `json:"catalog_worker_version,omitempty"`
+	SsoVersion           string `json:"sso_version,omitempty"`
+}
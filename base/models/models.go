@@ -29,12 +29,48 @@ type Baseline struct {
 	Name        string
 	Config      []byte
 	Description *string
+	Selector    []byte
 }
 
 func (Baseline) TableName() string {
 	return "baseline"
 }
 
+type Template struct {
+	ID          int
+	RhAccountID int
+	Name        string
+	Description *string
+	Version     int
+	Created     time.Time
+	Updated     time.Time
+}
+
+func (Template) TableName() string {
+	return "template"
+}
+
+type TemplateAdvisory struct {
+	RhAccountID int `gorm:"primary_key"`
+	TemplateID  int `gorm:"primary_key"`
+	AdvisoryID  int `gorm:"primary_key"`
+}
+
+func (TemplateAdvisory) TableName() string {
+	return "template_advisory"
+}
+
+type TemplatePackage struct {
+	RhAccountID int    `gorm:"primary_key"`
+	TemplateID  int    `gorm:"primary_key"`
+	Name        string `gorm:"primary_key"`
+	EVRA        string `gorm:"primary_key;column:evra"`
+}
+
+func (TemplatePackage) TableName() string {
+	return "template_package"
+}
+
 // nolint: maligned
 type SystemPlatform struct {
 	ID                    int    `gorm:"primary_key"`
@@ -60,8 +96,22 @@ type SystemPlatform struct {
 	ThirdParty            bool
 	ReporterID            *int
 	BaselineID            *int
-	BaselineUpToDate      *bool  `gorm:"column:baseline_uptodate"`
+	BaselineUpToDate      *bool `gorm:"column:baseline_uptodate"`
+	BaselineOutdatedSince *time.Time
 	YumUpdates            []byte `gorm:"column:yum_updates"`
+	RunningKernel         *string
+	InstalledKernel       *string
+	LastBoot              *time.Time
+	Releasever            *string
+	InfrastructureType    *string
+	CloudProvider         *string
+	SapSystem             bool
+	Ansible               bool
+	RebootPending         bool
+	RebootPatched         *time.Time
+	TemplateID            *int
+	TemplateInstallable   int
+	TemplateApplicable    int
 }
 
 func (SystemPlatform) TableName() string {
@@ -180,6 +230,7 @@ type SystemAdvisories struct {
 	FirstReported *time.Time
 	WhenPatched   *time.Time
 	StatusID      *int
+	InTemplate    *bool
 }
 
 func (SystemAdvisories) TableName() string {
@@ -226,6 +277,43 @@ func (SystemRepo) TableName() string {
 
 type SystemRepoSlice []SystemRepo
 
+type Module struct {
+	ID     int64
+	Name   string
+	Stream string
+}
+
+func (Module) TableName() string {
+	return "module"
+}
+
+type ModuleSlice []Module
+
+type SystemModule struct {
+	RhAccountID int64
+	SystemID    int64
+	ModuleID    int64
+}
+
+func (SystemModule) TableName() string {
+	return "system_module"
+}
+
+type SystemModuleSlice []SystemModule
+
+// Modular package build of a module stream
+type ModulePackage struct {
+	ModuleID int64
+	Name     string
+	EVRA     string `gorm:"column:evra"`
+}
+
+func (ModulePackage) TableName() string {
+	return "module_package"
+}
+
+type ModulePackageSlice []ModulePackage
+
 type TimestampKV struct {
 	Name  string
 	Value time.Time
@@ -234,3 +322,79 @@ type TimestampKV struct {
 func (TimestampKV) TableName() string {
 	return "timestamp_kv"
 }
+
+type RecalcJob struct {
+	ID               int64 `gorm:"primary_key"`
+	Scope            []byte
+	Created          time.Time
+	Finished         *time.Time
+	SystemsTotal     int
+	MessagesQueued   int
+	SystemsEvaluated int
+}
+
+func (RecalcJob) TableName() string {
+	return "recalc_job"
+}
+
+type SyncRun struct {
+	ID                 int64 `gorm:"primary_key"`
+	Started            time.Time
+	Finished           *time.Time
+	ModifiedSince      *time.Time
+	AdvisoriesInserted int
+	AdvisoriesUpdated  int
+	PackagesInserted   int
+	PackagesUpdated    int
+	ReposUpdated       int
+	DBChange           []byte `gorm:"column:dbchange"`
+	Error              *string
+}
+
+func (SyncRun) TableName() string {
+	return "sync_run"
+}
+
+type ShadowEvalDiff struct {
+	ID                    int64 `gorm:"primary_key"`
+	RhAccountID           int
+	InventoryID           string
+	Created               time.Time
+	Source                string
+	AdvisoriesOnlyPrimary []byte
+	AdvisoriesOnlyShadow  []byte
+	PackagesOnlyPrimary   []byte
+	PackagesOnlyShadow    []byte
+}
+
+func (ShadowEvalDiff) TableName() string {
+	return "shadow_eval_diff"
+}
+
+type APIToken struct {
+	ID          int64 `gorm:"primary_key"`
+	RhAccountID int
+	Name        string
+	TokenHash   string
+	Scopes      []byte
+	Created     time.Time
+	CreatedBy   *string
+	Expires     *time.Time
+	LastUsed    *time.Time
+	Revoked     *time.Time
+}
+
+func (APIToken) TableName() string {
+	return "api_token"
+}
+
+type RateLimit struct {
+	RhAccountID       int    `gorm:"primary_key"`
+	RouteClass        string `gorm:"primary_key"`
+	RequestsPerMinute int
+	Burst             int
+}
+
+func (RateLimit) TableName() string {
+	return "rate_limit"
+}
@@ -19,6 +19,7 @@ type PlatformEvent struct {
 	URL         *string                 `json:"url"`
 	SystemIDs   []string                `json:"system_ids,omitempty"`
 	RequestIDs  []string                `json:"request_ids,omitempty"`
+	RecalcJobID *int64                  `json:"recalc_job_id,omitempty"`
 }
 
 type EvalData struct {
@@ -26,6 +27,7 @@ type EvalData struct {
 	RhAccountID int
 	RequestID   string
 	OrgID       *string
+	RecalcJobID *int64
 }
 
 type PlatformEvents []PlatformEvent
@@ -34,6 +36,7 @@ type EvalDataSlice []EvalData
 type accountInventories map[int][]string
 type accountRequests map[int][]string
 type orgIDs map[int]*string
+type recalcJobIDs map[int]*int64
 
 func (event *PlatformEvent) createKafkaMessage() (KafkaMessage, error) {
 	data, err := json.Marshal(event) //nolint:gosec
@@ -73,28 +76,36 @@ func batchSize(grouped map[int][]string) int {
 	// compute how many batches we will create
 	var batches = 0
 	for _, ev := range grouped {
-		batches += len(ev)/BatchSize + 1
+		batches += (len(ev) + BatchSize - 1) / BatchSize
 	}
 	return batches
 }
 
-func (evals *EvalDataSlice) getAccountEvalData() (int, accountInventories, accountRequests, orgIDs) {
+func (evals *EvalDataSlice) getAccountEvalData() (int, accountInventories, accountRequests, orgIDs, recalcJobIDs) {
 	// group systems by account
 	invs := accountInventories{}
 	reqs := accountRequests{}
 	orgs := orgIDs{}
+	jobs := recalcJobIDs{}
 	for _, e := range *evals {
 		invs[e.RhAccountID] = append(invs[e.RhAccountID], e.InventoryID)
 		reqs[e.RhAccountID] = append(reqs[e.RhAccountID], e.RequestID)
 		if _, has := orgs[e.RhAccountID]; !has {
 			orgs[e.RhAccountID] = e.OrgID
+			jobs[e.RhAccountID] = e.RecalcJobID
 		}
 	}
-	return batchSize(invs), invs, reqs, orgs
+	return batchSize(invs), invs, reqs, orgs, jobs
+}
+
+// CountMessages returns number of messages the slice is split into by WriteEvents
+func (evals *EvalDataSlice) CountMessages() int {
+	batches, _, _, _, _ := evals.getAccountEvalData()
+	return batches
 }
 
 func (evals *EvalDataSlice) WriteEvents(ctx context.Context, w Writer) error {
-	batches, accInvs, reqs, orgs := evals.getAccountEvalData()
+	batches, accInvs, reqs, orgs, jobs := evals.getAccountEvalData()
 	// create events, per BatchSize of systems from one account
 	now := types.Rfc3339Timestamp(time.Now())
 	events := make(PlatformEvents, 0, batches)
@@ -105,11 +116,12 @@ func (evals *EvalDataSlice) WriteEvents(ctx context.Context, w Writer) error {
 				end = len(invs)
 			}
 			events = append(events, PlatformEvent{
-				Timestamp:  &now,
-				AccountID:  acc,
-				SystemIDs:  invs[start:end],
-				RequestIDs: reqs[acc][start:end],
-				OrgID:      orgs[acc],
+				Timestamp:   &now,
+				AccountID:   acc,
+				SystemIDs:   invs[start:end],
+				RequestIDs:  reqs[acc][start:end],
+				OrgID:       orgs[acc],
+				RecalcJobID: jobs[acc],
 			})
 		}
 	}
@@ -34,3 +34,23 @@ func TestWriteEventsOfInventoryAccounts(t *testing.T) {
 	assert.Equal(t, inv2, event.SystemIDs[0])
 	assert.Equal(t, inv3, event.SystemIDs[1])
 }
+
+func TestWriteEventsRecalcJob(t *testing.T) {
+	var writer Writer = &MockKafkaWriter{}
+
+	jobID := int64(5)
+	var invs EvalDataSlice = []EvalData{
+		{InventoryID: "00000000-0000-0000-0000-000000000002", RhAccountID: 1, RecalcJobID: &jobID},
+		{InventoryID: "00000000-0000-0000-0000-000000000012", RhAccountID: 3, RecalcJobID: &jobID}}
+	assert.Equal(t, 2, invs.CountMessages())
+
+	assert.Nil(t, SendMessages(context.Background(), writer, &invs))
+
+	mockWriter := writer.(*MockKafkaWriter)
+	assert.Equal(t, 2, len(mockWriter.Messages))
+	for _, msg := range mockWriter.Messages {
+		var event PlatformEvent
+		assert.Nil(t, json.Unmarshal(msg.Value, &event))
+		assert.Equal(t, jobID, *event.RecalcJobID)
+	}
+}
@@ -1,9 +1,50 @@
 package rbac
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 type AccessPagination struct {
 	Data []Access `json:"data"`
 }
 
 type Access struct {
-	Permission string `json:"permission"`
+	Permission          string               `json:"permission"`
+	ResourceDefinitions []ResourceDefinition `json:"resourceDefinitions,omitempty"`
+}
+
+type ResourceDefinition struct {
+	AttributeFilter AttributeFilter `json:"attributeFilter"`
+}
+
+type AttributeFilter struct {
+	Key       string `json:"key"`
+	Operation string `json:"operation"`
+	// single value for "equal" operation, list of values for "in" operation
+	Value json.RawMessage `json:"value"`
+}
+
+// Values returns filter values, null value is returned as nil
+func (f AttributeFilter) Values() ([]*string, error) {
+	var values []*string
+	if f.Operation == "in" {
+		err := json.Unmarshal(f.Value, &values)
+		if err == nil {
+			return values, nil
+		}
+		// older RBAC versions send comma separated string
+		var value string
+		if json.Unmarshal(f.Value, &value) != nil {
+			return nil, err
+		}
+		for _, v := range strings.Split(value, ",") {
+			v := strings.TrimSpace(v)
+			values = append(values, &v)
+		}
+		return values, nil
+	}
+	var value *string
+	err := json.Unmarshal(f.Value, &value)
+	return []*string{value}, err
 }
@@ -3,6 +3,7 @@ package vmaas
 import (
 	"app/base/types"
 	"strings"
+	"time"
 )
 
 type UpdatesV3Request struct {
@@ -177,6 +178,14 @@ type ErrataResponseErrataList struct {
 	ThirdParty        *bool     `json:"third_party,omitempty"`
 	RequiresReboot    bool      `json:"requires_reboot,omitempty"`
 	ReleaseVersions   *[]string `json:"release_versions,omitempty"`
+	// Module streams of modular packages of the erratum
+	ModulesList []ErrataResponseModule `json:"modules_list,omitempty"`
+}
+
+type ErrataResponseModule struct {
+	ModuleName   string   `json:"module_name"`
+	ModuleStream string   `json:"module_stream"`
+	PackageList  []string `json:"package_list,omitempty"`
 }
 
 type PkgListRequest struct {
@@ -241,3 +250,24 @@ func (o *DBChangeResponse) GetExported() types.Rfc3339TimestampNoT {
 	}
 	return *o.DBChange.Exported
 }
+
+func (o *DBChangeResponse) GetErrataChanges() *time.Time {
+	if o == nil {
+		return nil
+	}
+	return o.DBChange.ErrataChanges.Time()
+}
+
+func (o *DBChangeResponse) GetCVEChanges() *time.Time {
+	if o == nil {
+		return nil
+	}
+	return o.DBChange.CVEChanges.Time()
+}
+
+func (o *DBChangeResponse) GetRepositoryChanges() *time.Time {
+	if o == nil {
+		return nil
+	}
+	return o.DBChange.RepositoryChanges.Time()
+}
@@ -0,0 +1,322 @@
+package vmaasdump
+
+import (
+	"app/base/types"
+	"app/base/utils"
+	"app/base/vmaas"
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Page size used by VMaaS when the request doesn't set it
+const DefaultPageSize = 5000
+
+// Dump holds VMaaS data exported to files, the files contain the same JSON as VMaaS API responses:
+// errata*.json (/errata), pkglist*.json (/pkglist), repos*.json (/repos) and optional dbchange.json (/dbchange).
+// Responses split into more files (pages) are merged.
+type Dump struct {
+	Errata   map[string]vmaas.ErrataResponseErrataList
+	Packages []vmaas.PkgListItem
+	Repos    map[string][]map[string]interface{}
+	DBChange *vmaas.DBChangeResponse
+}
+
+func newDump() *Dump {
+	return &Dump{
+		Errata:   map[string]vmaas.ErrataResponseErrataList{},
+		Packages: []vmaas.PkgListItem{},
+		Repos:    map[string][]map[string]interface{}{},
+	}
+}
+
+// Load reads the dump from a directory or a tarball (.tar, .tar.gz, .tgz)
+func Load(path string) (*Dump, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open vmaas dump")
+	}
+
+	dump := newDump()
+	if info.IsDir() {
+		err = dump.loadDir(path)
+	} else {
+		err = dump.loadTarball(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("path", path, "errata", len(dump.Errata), "packages", len(dump.Packages), "repos", len(dump.Repos)).
+		Info("VMaaS dump loaded")
+	return dump, nil
+}
+
+func (d *Dump) loadDir(path string) error {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return errors.Wrap(err, "Unable to list vmaas dump directory")
+	}
+	for _, f := range files {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err = d.loadFile(filepath.Join(path, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dump) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "Unable to open vmaas dump file")
+	}
+	defer f.Close()
+	return d.add(filepath.Base(path), f)
+}
+
+func (d *Dump) loadTarball(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "Unable to open vmaas dump tarball")
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch {
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return errors.Wrap(err, "Unable to decompress vmaas dump tarball")
+		}
+		defer gz.Close()
+		r = gz
+	case strings.HasSuffix(path, ".tar"):
+	default:
+		return errors.Errorf("unsupported vmaas dump file: %s", path)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "Unable to read vmaas dump tarball")
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err = d.add(filepath.Base(hdr.Name), tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Merge file content into the dump according to the file name
+func (d *Dump) add(name string, r io.Reader) error {
+	if !strings.HasSuffix(name, ".json") {
+		return nil
+	}
+
+	var err error
+	dec := json.NewDecoder(r)
+	switch {
+	case strings.HasPrefix(name, "errata"):
+		var resp vmaas.ErrataResponse
+		if err = dec.Decode(&resp); err == nil {
+			for k, v := range resp.ErrataList {
+				d.Errata[k] = v
+			}
+		}
+	case strings.HasPrefix(name, "pkglist"):
+		var resp vmaas.PkgListResponse
+		if err = dec.Decode(&resp); err == nil {
+			d.Packages = append(d.Packages, resp.PackageList...)
+		}
+	case strings.HasPrefix(name, "repos"):
+		var resp vmaas.ReposResponse
+		if err = dec.Decode(&resp); err == nil {
+			for k, v := range resp.RepositoryList {
+				d.Repos[k] = v
+			}
+		}
+	case strings.HasPrefix(name, "dbchange"):
+		var resp vmaas.DBChangeResponse
+		if err = dec.Decode(&resp); err == nil {
+			d.DBChange = &resp
+		}
+	default:
+		utils.Log("file", name).Debug("Skipping unknown vmaas dump file")
+	}
+	return errors.Wrapf(err, "Unable to parse vmaas dump file %s", name)
+}
+
+// Returns slice bounds of the page and number of pages, pages are numbered from 1 as in VMaaS
+func pageBounds(page, pageSize, total int) (start, end, pages int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	pages = (total + pageSize - 1) / pageSize
+	start = (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end = start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end, pages
+}
+
+func compileList(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %s", p)
+		}
+		res[i] = re
+	}
+	return res, nil
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func modifiedSince(modified string, layout string, since *string) bool {
+	if since == nil {
+		return true
+	}
+	sinceTS, err := time.Parse(time.RFC3339, *since)
+	if err != nil {
+		return true
+	}
+	ts, err := time.Parse(layout, modified)
+	return err != nil || !ts.Before(sinceTS)
+}
+
+// ErrataPage answers /errata request from the dump
+func (d *Dump) ErrataPage(req *vmaas.ErrataRequest) (*vmaas.ErrataResponse, error) {
+	patterns, err := compileList(req.ErrataList)
+	if err != nil {
+		return nil, err
+	}
+	thirdParty := req.ThirdParty != nil && *req.ThirdParty
+
+	names := make([]string, 0, len(d.Errata))
+	for name, erratum := range d.Errata {
+		if !matchAny(patterns, name) || (!thirdParty && erratum.ThirdParty != nil && *erratum.ThirdParty) {
+			continue
+		}
+		if !modifiedSince(erratum.Updated, types.Rfc3339NoTz, req.ModifiedSince) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	start, end, pages := pageBounds(req.Page, req.PageSize, len(names))
+	resp := vmaas.ErrataResponse{Page: req.Page, PageSize: req.PageSize, Pages: pages,
+		ErrataList: make(map[string]vmaas.ErrataResponseErrataList, end-start)}
+	for _, name := range names[start:end] {
+		resp.ErrataList[name] = d.Errata[name]
+	}
+	return &resp, nil
+}
+
+// PkgListPage answers /pkglist request from the dump
+func (d *Dump) PkgListPage(req *vmaas.PkgListRequest) *vmaas.PkgListResponse {
+	pkgs := make([]vmaas.PkgListItem, 0, len(d.Packages))
+	for _, pkg := range d.Packages {
+		if modifiedSince(pkg.Modified, time.RFC3339, req.ModifiedSince) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	start, end, pages := pageBounds(req.Page, req.PageSize, len(pkgs))
+	return &vmaas.PkgListResponse{Page: req.Page, PageSize: req.PageSize, Pages: pages,
+		PackageList: pkgs[start:end], Total: len(pkgs)}
+}
+
+// ReposPage answers /repos request from the dump, repos are always returned as a whole regardless ModifiedSince
+func (d *Dump) ReposPage(req *vmaas.ReposRequest) (*vmaas.ReposResponse, error) {
+	patterns, err := compileList(req.RepositoryList)
+	if err != nil {
+		return nil, err
+	}
+	thirdParty := req.ThirdParty != nil && *req.ThirdParty
+
+	names := make([]string, 0, len(d.Repos))
+	for name, repos := range d.Repos {
+		if matchAny(patterns, name) && (thirdParty || !isThirdPartyRepo(repos)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start, end, pages := pageBounds(req.Page, req.PageSize, len(names))
+	resp := vmaas.ReposResponse{Page: req.Page, PageSize: req.PageSize, Pages: pages,
+		RepositoryList: make(map[string][]map[string]interface{}, end-start)}
+	for _, name := range names[start:end] {
+		resp.RepositoryList[name] = d.Repos[name]
+	}
+	return &resp, nil
+}
+
+func isThirdPartyRepo(repos []map[string]interface{}) bool {
+	for _, repo := range repos {
+		if repo["third_party"] == (interface{})(true) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdatesRepos returns metadata of the dump repositories for UpdatesIndex, label may cover more content sets,
+// it's third party when any of them is and its releasever is set only when all of them agree
+func (d *Dump) UpdatesRepos() map[string]Repo {
+	repos := make(map[string]Repo, len(d.Repos))
+	for label, contentSets := range d.Repos {
+		repo := Repo{ThirdParty: isThirdPartyRepo(contentSets)}
+		for i, cs := range contentSets {
+			releasever, _ := cs["releasever"].(string)
+			if i > 0 && releasever != repo.Releasever {
+				repo.Releasever = ""
+				break
+			}
+			repo.Releasever = releasever
+		}
+		repos[label] = repo
+	}
+	return repos
+}
+
+// GetDBChange returns /dbchange of the dump, empty one when the dump doesn't contain it
+func (d *Dump) GetDBChange() *vmaas.DBChangeResponse {
+	if d.DBChange == nil {
+		return &vmaas.DBChangeResponse{}
+	}
+	return d.DBChange
+}
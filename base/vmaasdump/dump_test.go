@@ -0,0 +1,166 @@
+package vmaasdump
+
+import (
+	"app/base/utils"
+	"app/base/vmaas"
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFiles = map[string]string{
+	"errata.json": `{"errata_list": {
+		"RH-1": {"updated": "2020-01-01T00:00:00+00:00", "type": "security",
+			"package_list": ["kernel-5.14.0-2.el9.x86_64"], "release_versions": ["9"]},
+		"RH-2": {"updated": "2021-01-01T00:00:00+00:00", "type": "bugfix",
+			"package_list": ["kernel-5.14.0-3.el9.x86_64", "kernel-doc-5.14.0-3.el9.noarch"]}}}`,
+	"errata_2.json": `{"errata_list": {
+		"EPEL-1": {"updated": "2021-01-01T00:00:00+00:00", "type": "enhancement", "third_party": true,
+			"package_list": ["kernel-5.14.0-4.el9.x86_64"]}}}`,
+	"pkglist.json": `{"package_list": [{"nevra": "kernel-5.14.0-2.el9.x86_64"},
+		{"nevra": "kernel-5.14.0-3.el9.x86_64"}, {"nevra": "kernel-doc-5.14.0-3.el9.noarch"}]}`,
+	"repos.json": `{"repository_list": {"rhel-9": [{"label": "rhel-9", "releasever": "9"}],
+		"epel-9": [{"label": "epel-9", "third_party": true}]}}`,
+	"dbchange.json": `{"dbchange": {"errata_changes": "2021-04-16 20:07:58.500192+00",
+		"repository_changes": "2021-04-16 20:07:55.214266+00"}}`,
+	"README.txt": "not loaded",
+}
+
+func writeTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "vmaasdump")
+	assert.Nil(t, err)
+	for name, content := range testFiles {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+func writeTestTarball(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "dump.tar.gz")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range testFiles {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{Name: "dump/" + name, Mode: 0600, Size: int64(len(content)),
+			Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gz.Close())
+	return path
+}
+
+func checkTestDump(t *testing.T, dump *Dump) {
+	assert.Equal(t, 3, len(dump.Errata))
+	assert.Equal(t, 3, len(dump.Packages))
+	assert.Equal(t, 2, len(dump.Repos))
+	assert.NotNil(t, dump.GetDBChange().GetErrataChanges())
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := writeTestDir(t)
+	defer os.RemoveAll(dir)
+
+	dump, err := Load(dir)
+	assert.Nil(t, err)
+	checkTestDump(t, dump)
+}
+
+func TestLoadTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vmaasdump")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	dump, err := Load(writeTestTarball(t, dir))
+	assert.Nil(t, err)
+	checkTestDump(t, dump)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vmaasdump")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = Load(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "errata.json"), []byte("{"), 0600))
+	_, err = Load(dir)
+	assert.NotNil(t, err)
+
+	zipPath := filepath.Join(dir, "dump.zip")
+	assert.Nil(t, ioutil.WriteFile(zipPath, []byte{}, 0600))
+	_, err = Load(zipPath)
+	assert.NotNil(t, err)
+}
+
+func loadTestDump(t *testing.T) *Dump {
+	dir := writeTestDir(t)
+	defer os.RemoveAll(dir)
+	dump, err := Load(dir)
+	assert.Nil(t, err)
+	return dump
+}
+
+func TestErrataPage(t *testing.T) {
+	dump := loadTestDump(t)
+
+	resp, err := dump.ErrataPage(&vmaas.ErrataRequest{ErrataList: []string{".*"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, resp.Pages)
+	assert.Equal(t, 2, len(resp.ErrataList)) // without third party
+
+	req := vmaas.ErrataRequest{Page: 2, PageSize: 2, ErrataList: []string{".*"}, ThirdParty: utils.PtrBool(true)}
+	resp, err = dump.ErrataPage(&req)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, resp.Pages)
+	assert.Equal(t, 1, len(resp.ErrataList))
+	assert.Contains(t, resp.ErrataList, "RH-2")
+
+	resp, err = dump.ErrataPage(&vmaas.ErrataRequest{ErrataList: []string{"RH-.*"},
+		ModifiedSince: utils.PtrString("2020-06-01T00:00:00Z")})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.ErrataList))
+	assert.Contains(t, resp.ErrataList, "RH-2")
+
+	_, err = dump.ErrataPage(&vmaas.ErrataRequest{ErrataList: []string{"("}})
+	assert.NotNil(t, err)
+}
+
+func TestPkgListPage(t *testing.T) {
+	dump := loadTestDump(t)
+
+	resp := dump.PkgListPage(&vmaas.PkgListRequest{Page: 0, PageSize: 2})
+	assert.Equal(t, 2, resp.Pages)
+	assert.Equal(t, 3, resp.Total)
+	assert.Equal(t, 2, len(resp.PackageList))
+
+	resp = dump.PkgListPage(&vmaas.PkgListRequest{Page: 3, PageSize: 2})
+	assert.Equal(t, 0, len(resp.PackageList))
+}
+
+func TestReposPage(t *testing.T) {
+	dump := loadTestDump(t)
+
+	resp, err := dump.ReposPage(&vmaas.ReposRequest{Page: 1, RepositoryList: []string{".*"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.RepositoryList))
+
+	resp, err = dump.ReposPage(&vmaas.ReposRequest{Page: 1, RepositoryList: []string{".*"},
+		ThirdParty: utils.PtrBool(true)})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(resp.RepositoryList))
+}
+
+func TestEmptyDBChange(t *testing.T) {
+	dump := newDump()
+	assert.Nil(t, dump.GetDBChange().GetErrataChanges())
+}
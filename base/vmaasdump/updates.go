@@ -0,0 +1,251 @@
+package vmaasdump
+
+import (
+	"app/base/utils"
+	"app/base/vmaas"
+	"sort"
+	"strings"
+)
+
+const (
+	noarch           = "noarch"
+	securityType     = "security"
+	releaseSeparator = "."
+)
+
+// Package fixed by an erratum
+type update struct {
+	nevra           *utils.Nevra
+	pkg             string
+	erratum         string
+	security        bool
+	thirdParty      bool
+	releaseVersions []string
+	modules         []string // "name:stream" of module streams of modular package, one has to be enabled
+}
+
+// RepoFilter tells how updates of a request were limited by the system repositories
+type RepoFilter string
+
+const (
+	RepoFilterEnabled RepoFilter = "enabled" // limited by metadata of the enabled repositories
+	RepoFilterNone    RepoFilter = "none"    // request or index without repositories, updates are not limited
+	RepoFilterUnknown RepoFilter = "unknown" // none of the enabled repositories is known, no updates are returned
+)
+
+// Repo holds repository metadata limiting updates of systems with the repository enabled
+type Repo struct {
+	ThirdParty bool
+	Releasever string
+}
+
+// UpdatesIndex answers /updates requests from errata package lists.
+// Errata don't map packages to repositories so updates are limited by metadata of the enabled repositories only:
+// systems with unknown repositories get no updates, third party updates need a third party repository enabled
+// and repository release versions are used when the request doesn't set releasever. An update released
+// for the system release can still be offered even when no enabled repository contains it, how requests were
+// limited is returned by Updates so it can be exposed as a metric. Modular packages are offered only to systems
+// with the module stream enabled.
+type UpdatesIndex struct {
+	updates map[string][]update // updates by package name ordered by EVRA
+	repos   map[string]Repo     // repositories by label, nil when the index has no repository data
+}
+
+// Request limits of updates resolved from the request and the enabled repositories
+type updatesFilter struct {
+	thirdParty   bool
+	securityOnly bool
+	releasevers  []string
+	modules      map[string]bool
+}
+
+func NewUpdatesIndex(errata map[string]vmaas.ErrataResponseErrataList, repos map[string]Repo) *UpdatesIndex {
+	index := UpdatesIndex{updates: map[string][]update{}, repos: repos}
+	for name, erratum := range errata {
+		thirdParty := erratum.ThirdParty != nil && *erratum.ThirdParty
+		var releaseVersions []string
+		if erratum.ReleaseVersions != nil {
+			releaseVersions = *erratum.ReleaseVersions
+		}
+		pkgModules := map[string][]string{}
+		for _, m := range erratum.ModulesList {
+			for _, pkg := range m.PackageList {
+				pkgModules[pkg] = append(pkgModules[pkg], moduleKey(m.ModuleName, m.ModuleStream))
+			}
+		}
+		for _, pkg := range erratum.PackageList {
+			nevra, err := utils.ParseNevra(pkg)
+			if err != nil {
+				utils.Log("package", pkg, "erratum", name).Warn("Skipping unparsable erratum package")
+				continue
+			}
+			index.updates[nevra.Name] = append(index.updates[nevra.Name], update{
+				nevra:           nevra,
+				pkg:             pkg,
+				erratum:         name,
+				security:        erratum.Type == securityType,
+				thirdParty:      thirdParty,
+				releaseVersions: releaseVersions,
+				modules:         pkgModules[pkg],
+			})
+		}
+	}
+
+	for _, updates := range index.updates {
+		sort.Slice(updates, func(i, j int) bool {
+			if cmp := updates[i].nevra.Cmp(updates[j].nevra); cmp != 0 {
+				return cmp < 0
+			}
+			return updates[i].erratum < updates[j].erratum
+		})
+	}
+	return &index
+}
+
+func moduleKey(name, stream string) string {
+	return name + ":" + stream
+}
+
+// Resolve limits of the request, nil filter means no updates are available
+func (idx *UpdatesIndex) newUpdatesFilter(req *vmaas.UpdatesV3Request) (*updatesFilter, RepoFilter) {
+	filter := updatesFilter{
+		thirdParty:   req.ThirdParty != nil && *req.ThirdParty,
+		securityOnly: req.SecurityOnly != nil && *req.SecurityOnly,
+		modules:      map[string]bool{},
+	}
+	for _, m := range req.GetModulesList() {
+		filter.modules[moduleKey(m.ModuleName, m.ModuleStream)] = true
+	}
+	if req.Releasever != nil && *req.Releasever != "" {
+		filter.releasevers = []string{*req.Releasever}
+	}
+
+	labels := req.GetRepositoryList()
+	if idx.repos == nil || len(labels) == 0 {
+		return &filter, RepoFilterNone
+	}
+	known, thirdPartyRepo := false, false
+	var repoReleasevers []string
+	for _, label := range labels {
+		repo, ok := idx.repos[label]
+		if !ok {
+			continue
+		}
+		known = true
+		thirdPartyRepo = thirdPartyRepo || repo.ThirdParty
+		if repo.Releasever != "" {
+			repoReleasevers = append(repoReleasevers, repo.Releasever)
+		}
+	}
+	if !known {
+		return nil, RepoFilterUnknown
+	}
+	filter.thirdParty = filter.thirdParty && thirdPartyRepo
+	if len(filter.releasevers) == 0 {
+		filter.releasevers = repoReleasevers
+	}
+	return &filter, RepoFilterEnabled
+}
+
+// Update can replace installed package of the same arch, noarch package can replace any arch and vice versa
+func archCompatible(installed, update string) bool {
+	return installed == update || installed == noarch || update == noarch
+}
+
+// Erratum without release versions applies to all releases, major version matches minor releases as well
+func releaseMatches(releaseVersions []string, releasevers []string) bool {
+	if len(releaseVersions) == 0 || len(releasevers) == 0 {
+		return true
+	}
+	for _, r := range releaseVersions {
+		for _, releasever := range releasevers {
+			if r == releasever || strings.HasPrefix(releasever, r+releaseSeparator) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (u *update) modulesEnabled(enabled map[string]bool) bool {
+	if len(u.modules) == 0 {
+		return true
+	}
+	for _, m := range u.modules {
+		if enabled[m] {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *update) applicable(installed *utils.Nevra, filter *updatesFilter) bool {
+	if !archCompatible(installed.Arch, u.nevra.Arch) {
+		return false
+	}
+	// compare EVR only, arch may differ for noarch updates
+	candidate := *u.nevra
+	candidate.Arch = installed.Arch
+	if candidate.EVRACmp(installed) <= 0 {
+		return false
+	}
+	if u.thirdParty && !filter.thirdParty {
+		return false
+	}
+	if filter.securityOnly && !u.security {
+		return false
+	}
+	return u.modulesEnabled(filter.modules) && releaseMatches(u.releaseVersions, filter.releasevers)
+}
+
+// Updates answers /updates request, unparsable packages are returned without updates
+func (idx *UpdatesIndex) Updates(req *vmaas.UpdatesV3Request) (*vmaas.UpdatesV2Response, RepoFilter) {
+	filter, repoFilter := idx.newUpdatesFilter(req)
+	updateList := make(map[string]vmaas.UpdatesV2ResponseUpdateList, len(req.PackageList))
+	for _, pkg := range req.PackageList {
+		available := []vmaas.UpdatesV2ResponseAvailableUpdates{}
+		installed, err := utils.ParseNevra(pkg)
+		if err == nil && filter != nil {
+			available = idx.packageUpdates(installed, req, filter)
+		}
+		updateList[pkg] = vmaas.UpdatesV2ResponseUpdateList{AvailableUpdates: &available}
+	}
+
+	return &vmaas.UpdatesV2Response{
+		UpdateList:     &updateList,
+		RepositoryList: req.RepositoryList,
+		ModulesList:    req.ModulesList,
+		Releasever:     req.Releasever,
+		Basearch:       req.Basearch,
+	}, repoFilter
+}
+
+func (idx *UpdatesIndex) packageUpdates(installed *utils.Nevra, req *vmaas.UpdatesV3Request,
+	filter *updatesFilter) []vmaas.UpdatesV2ResponseAvailableUpdates {
+	var applicable []update
+	for _, u := range idx.updates[installed.Name] {
+		if u.applicable(installed, filter) {
+			applicable = append(applicable, u)
+		}
+	}
+
+	if req.LatestOnly != nil && *req.LatestOnly && len(applicable) > 0 {
+		latest := applicable[len(applicable)-1].nevra
+		i := len(applicable) - 1
+		for i > 0 && applicable[i-1].nevra.Cmp(latest) == 0 {
+			i--
+		}
+		applicable = applicable[i:]
+	}
+
+	available := make([]vmaas.UpdatesV2ResponseAvailableUpdates, len(applicable))
+	for i := range applicable {
+		available[i] = vmaas.UpdatesV2ResponseAvailableUpdates{
+			Package:    &applicable[i].pkg,
+			Erratum:    &applicable[i].erratum,
+			Releasever: req.Releasever,
+			Basearch:   req.Basearch,
+		}
+	}
+	return available
+}
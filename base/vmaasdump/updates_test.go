@@ -0,0 +1,108 @@
+package vmaasdump
+
+import (
+	"app/base/utils"
+	"app/base/vmaas"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getUpdates(t *testing.T, resp *vmaas.UpdatesV2Response, pkg string) []string {
+	list, ok := resp.GetUpdateList()[pkg]
+	assert.True(t, ok)
+	updates := []string{}
+	for _, u := range list.GetAvailableUpdates() {
+		updates = append(updates, u.GetPackage()+" "+u.GetErratum())
+	}
+	return updates
+}
+
+func TestUpdates(t *testing.T) {
+	index := NewUpdatesIndex(loadTestDump(t).Errata, nil)
+
+	req := vmaas.UpdatesV3Request{
+		PackageList: []string{"kernel-5.14.0-1.el9.x86_64", "kernel-doc-5.14.0-3.el9.noarch", "unparsable"},
+		Releasever:  utils.PtrString("9"),
+		Basearch:    utils.PtrString("x86_64"),
+	}
+	resp, repoFilter := index.Updates(&req)
+	assert.Equal(t, []string{"kernel-5.14.0-2.el9.x86_64 RH-1", "kernel-5.14.0-3.el9.x86_64 RH-2"},
+		getUpdates(t, resp, "kernel-5.14.0-1.el9.x86_64"))
+	assert.Equal(t, []string{}, getUpdates(t, resp, "kernel-doc-5.14.0-3.el9.noarch"))
+	assert.Equal(t, []string{}, getUpdates(t, resp, "unparsable"))
+	assert.Equal(t, "9", *resp.Releasever)
+	assert.Equal(t, RepoFilterNone, repoFilter)
+
+	req.ThirdParty = utils.PtrBool(true)
+	req.LatestOnly = utils.PtrBool(true)
+	resp, _ = index.Updates(&req)
+	assert.Equal(t, []string{"kernel-5.14.0-4.el9.x86_64 EPEL-1"}, getUpdates(t, resp, "kernel-5.14.0-1.el9.x86_64"))
+
+	req.LatestOnly = nil
+	req.SecurityOnly = utils.PtrBool(true)
+	resp, _ = index.Updates(&req)
+	assert.Equal(t, []string{"kernel-5.14.0-2.el9.x86_64 RH-1"}, getUpdates(t, resp, "kernel-5.14.0-1.el9.x86_64"))
+
+	// RH-1 is released for RHEL 9 only
+	req.Releasever = utils.PtrString("8.6")
+	resp, _ = index.Updates(&req)
+	assert.Equal(t, []string{}, getUpdates(t, resp, "kernel-5.14.0-1.el9.x86_64"))
+}
+
+func TestUpdatesRepos(t *testing.T) {
+	dump := loadTestDump(t)
+	index := NewUpdatesIndex(dump.Errata, dump.UpdatesRepos())
+	pkg := "kernel-5.14.0-1.el9.x86_64"
+	req := vmaas.UpdatesV3Request{PackageList: []string{pkg}, RepositoryList: &[]string{"rhel-9"},
+		ThirdParty: utils.PtrBool(true)}
+
+	// releasever of the repository is used, third party update needs third party repository
+	resp, repoFilter := index.Updates(&req)
+	assert.Equal(t, RepoFilterEnabled, repoFilter)
+	assert.Equal(t, []string{"kernel-5.14.0-2.el9.x86_64 RH-1", "kernel-5.14.0-3.el9.x86_64 RH-2"},
+		getUpdates(t, resp, pkg))
+
+	req.RepositoryList = &[]string{"rhel-9", "epel-9"}
+	resp, _ = index.Updates(&req)
+	assert.Equal(t, 3, len(getUpdates(t, resp, pkg)))
+
+	req.RepositoryList = &[]string{"unknown"}
+	resp, repoFilter = index.Updates(&req)
+	assert.Equal(t, RepoFilterUnknown, repoFilter)
+	assert.Equal(t, []string{}, getUpdates(t, resp, pkg))
+}
+
+func TestUpdatesModules(t *testing.T) {
+	errata := map[string]vmaas.ErrataResponseErrataList{
+		"RH-3": {PackageList: []string{"nodejs-14.0.0-1.module+el8.x86_64"},
+			ModulesList: []vmaas.ErrataResponseModule{{ModuleName: "nodejs", ModuleStream: "14",
+				PackageList: []string{"nodejs-14.0.0-1.module+el8.x86_64"}}}},
+	}
+	index := NewUpdatesIndex(errata, nil)
+	pkg := "nodejs-12.0.0-1.module+el8.x86_64"
+	req := vmaas.UpdatesV3Request{PackageList: []string{pkg},
+		ModulesList: &[]vmaas.UpdatesV3RequestModulesList{{ModuleName: "nodejs", ModuleStream: "12"}}}
+	resp, _ := index.Updates(&req)
+	assert.Equal(t, []string{}, getUpdates(t, resp, pkg))
+
+	req.ModulesList = &[]vmaas.UpdatesV3RequestModulesList{{ModuleName: "nodejs", ModuleStream: "14"}}
+	resp, _ = index.Updates(&req)
+	assert.Equal(t, []string{"nodejs-14.0.0-1.module+el8.x86_64 RH-3"}, getUpdates(t, resp, pkg))
+}
+
+func TestReleaseMatches(t *testing.T) {
+	assert.True(t, releaseMatches(nil, []string{"9"}))
+	assert.True(t, releaseMatches([]string{"9"}, nil))
+	assert.True(t, releaseMatches([]string{"8", "9"}, []string{"9.2"}))
+	assert.True(t, releaseMatches([]string{"9"}, []string{"8", "9"}))
+	assert.False(t, releaseMatches([]string{"9"}, []string{"8"}))
+	assert.False(t, releaseMatches([]string{"9"}, []string{"90"}))
+}
+
+func TestArchCompatible(t *testing.T) {
+	assert.True(t, archCompatible("x86_64", "x86_64"))
+	assert.True(t, archCompatible("x86_64", "noarch"))
+	assert.True(t, archCompatible("noarch", "i686"))
+	assert.False(t, archCompatible("x86_64", "i686"))
+}
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS recalc_job
+(
+    id                BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    scope             JSONB                                   NOT NULL,
+    created           TIMESTAMP WITH TIME ZONE                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished          TIMESTAMP WITH TIME ZONE,
+    systems_total     INT                                     NOT NULL DEFAULT 0,
+    messages_queued   INT                                     NOT NULL DEFAULT 0,
+    systems_evaluated INT                                     NOT NULL DEFAULT 0
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON recalc_job TO vmaas_sync;
+GRANT SELECT, UPDATE (systems_evaluated, finished) ON recalc_job TO evaluator;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO evaluator;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO listener;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO vmaas_sync;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS sync_run
+(
+    id                  BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    started             TIMESTAMP WITH TIME ZONE                NOT NULL,
+    finished            TIMESTAMP WITH TIME ZONE,
+    -- NULL means full sync
+    modified_since      TIMESTAMP WITH TIME ZONE,
+    advisories_inserted INT                                     NOT NULL DEFAULT 0,
+    advisories_updated  INT                                     NOT NULL DEFAULT 0,
+    packages_inserted   INT                                     NOT NULL DEFAULT 0,
+    packages_updated    INT                                     NOT NULL DEFAULT 0,
+    repos_updated       INT                                     NOT NULL DEFAULT 0,
+    -- VMaaS /dbchange response at the time of the sync
+    dbchange            JSONB,
+    error               TEXT
+) TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS sync_run_started_idx ON sync_run (started);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON sync_run TO vmaas_sync;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO evaluator;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO listener;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO vmaas_sync;
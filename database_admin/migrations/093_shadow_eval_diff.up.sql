@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS shadow_eval_diff
+(
+    id                      BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    rh_account_id           INT                                     NOT NULL,
+    inventory_id            UUID                                    NOT NULL,
+    created                 TIMESTAMP WITH TIME ZONE                NOT NULL,
+    -- "local" engine or VMaaS address used by shadow evaluation
+    source                  TEXT                                    NOT NULL CHECK (NOT empty(source)),
+    advisories_only_primary JSONB,
+    advisories_only_shadow  JSONB,
+    packages_only_primary   JSONB,
+    packages_only_shadow    JSONB
+) TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS shadow_eval_diff_created_idx ON shadow_eval_diff (created);
+
+GRANT SELECT, INSERT ON shadow_eval_diff TO evaluator;
+GRANT SELECT, DELETE ON shadow_eval_diff TO vmaas_sync;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO evaluator;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO listener;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO vmaas_sync;
@@ -0,0 +1,149 @@
+CREATE TABLE IF NOT EXISTS module
+(
+    id     BIGINT GENERATED BY DEFAULT AS IDENTITY,
+    name   TEXT NOT NULL,
+    stream TEXT NOT NULL,
+    UNIQUE (name, stream),
+    CHECK (NOT empty(name)),
+    PRIMARY KEY (id)
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON module TO listener;
+
+-- system_module
+CREATE TABLE IF NOT EXISTS system_module
+(
+    system_id     BIGINT NOT NULL,
+    module_id     BIGINT NOT NULL,
+    rh_account_id INT NOT NULL,
+    UNIQUE (rh_account_id, system_id, module_id),
+    CONSTRAINT system_platform_id
+        FOREIGN KEY (rh_account_id, system_id)
+            REFERENCES system_platform (rh_account_id, id),
+    CONSTRAINT module_id
+        FOREIGN KEY (module_id)
+            REFERENCES module (id)
+) TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS system_module_module_id_idx ON system_module (module_id);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON system_module TO listener;
+GRANT DELETE ON system_module TO manager;
+GRANT SELECT, DELETE on system_module to vmaas_sync;
+
+CREATE OR REPLACE FUNCTION delete_system(inventory_id_in uuid)
+    RETURNS TABLE
+            (
+                deleted_inventory_id uuid
+            )
+AS
+$delete_system$
+DECLARE
+    v_system_id  INT;
+    v_account_id INT;
+BEGIN
+    -- opt out to refresh cache and then delete
+    SELECT id, rh_account_id
+    FROM system_platform
+    WHERE inventory_id = inventory_id_in
+    LIMIT 1
+        FOR UPDATE OF system_platform
+    INTO v_system_id, v_account_id;
+
+    IF v_system_id IS NULL OR v_account_id IS NULL THEN
+        RAISE NOTICE 'Not found';
+        RETURN;
+    END IF;
+
+    UPDATE system_platform
+    SET stale = true
+    WHERE rh_account_id = v_account_id
+      AND id = v_system_id;
+
+    DELETE
+    FROM system_advisories
+    WHERE rh_account_id = v_account_id
+      AND system_id = v_system_id;
+
+    DELETE
+    FROM system_repo
+    WHERE rh_account_id = v_account_id
+      AND system_id = v_system_id;
+
+    DELETE
+    FROM system_module
+    WHERE rh_account_id = v_account_id
+      AND system_id = v_system_id;
+
+    DELETE
+    FROM system_package
+    WHERE rh_account_id = v_account_id
+      AND system_id = v_system_id;
+
+    RETURN QUERY DELETE FROM system_platform
+        WHERE rh_account_id = v_account_id AND
+              id = v_system_id
+        RETURNING inventory_id;
+END;
+$delete_system$ LANGUAGE 'plpgsql';
+
+CREATE OR REPLACE FUNCTION delete_systems(inventory_ids UUID[])
+    RETURNS INTEGER
+AS
+$$
+DECLARE
+    tmp_cnt INTEGER;
+BEGIN
+
+    WITH systems as (
+        SELECT rh_account_id, id
+        FROM system_platform
+        WHERE inventory_id = ANY (inventory_ids)
+        ORDER BY rh_account_id, id FOR UPDATE OF system_platform),
+         marked as (
+             UPDATE system_platform sp
+                 SET stale = true
+                 WHERE (rh_account_id, id) in (select rh_account_id, id from systems)
+         ),
+         advisories as (
+             DELETE
+                 FROM system_advisories
+                     WHERE (rh_account_id, system_id) in (select rh_account_id, id from systems)
+         ),
+         repos as (
+             DELETE
+                 FROM system_repo
+                     WHERE (rh_account_id, system_id) in (select rh_account_id, id from systems)
+         ),
+         modules as (
+             DELETE
+                 FROM system_module
+                     WHERE (rh_account_id, system_id) in (select rh_account_id, id from systems)
+         ),
+         packages as (
+             DELETE
+                 FROM system_package
+                     WHERE (rh_account_id, system_id) in (select rh_account_id, id from systems)
+         ),
+         deleted as (
+             DELETE
+                 FROM system_platform
+                     WHERE (rh_account_id, id) in (select rh_account_id, id from systems)
+                     RETURNING id
+         )
+    SELECT count(*)
+    FROM deleted
+    INTO tmp_cnt;
+
+    RETURN tmp_cnt;
+END
+$$ LANGUAGE plpgsql;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO evaluator;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO listener;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO vmaas_sync;
@@ -0,0 +1,8 @@
+ALTER TABLE system_platform DROP COLUMN IF EXISTS running_kernel;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS installed_kernel;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS last_boot;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS releasever;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS infrastructure_type;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS cloud_provider;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS sap_system;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS ansible;
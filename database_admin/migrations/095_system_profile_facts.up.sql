@@ -0,0 +1,8 @@
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS running_kernel TEXT;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS installed_kernel TEXT;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS last_boot TIMESTAMP WITH TIME ZONE;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS releasever TEXT;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS infrastructure_type TEXT;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS cloud_provider TEXT;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS sap_system BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS ansible BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+ALTER TABLE system_platform DROP COLUMN IF EXISTS reboot_pending;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS reboot_patched;
@@ -0,0 +1,2 @@
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS reboot_pending BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS reboot_patched TIMESTAMP WITH TIME ZONE;
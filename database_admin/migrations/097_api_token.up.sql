@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS api_token
+(
+    id            BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    rh_account_id INT                                     NOT NULL REFERENCES rh_account (id),
+    name          TEXT                                    NOT NULL CHECK (NOT empty(name)),
+    -- SHA-256 hash of the token, the token itself is shown only when created
+    token_hash    TEXT                                    NOT NULL UNIQUE,
+    -- list of token scopes, e.g. ["read-only"]
+    scopes        JSONB                                   NOT NULL,
+    created       TIMESTAMP WITH TIME ZONE                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    TEXT,
+    expires       TIMESTAMP WITH TIME ZONE,
+    last_used     TIMESTAMP WITH TIME ZONE,
+    revoked       TIMESTAMP WITH TIME ZONE,
+    UNIQUE (rh_account_id, name)
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE ON api_token TO manager;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO evaluator;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO listener;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO vmaas_sync;
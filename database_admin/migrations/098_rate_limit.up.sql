@@ -0,0 +1,19 @@
+-- per-account overrides of manager API rate limits
+CREATE TABLE IF NOT EXISTS rate_limit
+(
+    rh_account_id       INT  NOT NULL REFERENCES rh_account (id),
+    -- route class: list, export or write
+    route_class         TEXT NOT NULL CHECK (route_class IN ('list', 'export', 'write')),
+    requests_per_minute INT  NOT NULL CHECK (requests_per_minute > 0),
+    burst               INT  NOT NULL CHECK (burst > 0),
+    PRIMARY KEY (rh_account_id, route_class)
+) TABLESPACE pg_default;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO evaluator;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO listener;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO vmaas_sync;
@@ -0,0 +1,8 @@
+ALTER TABLE system_advisories DROP COLUMN IF EXISTS in_template;
+ALTER TABLE system_platform DROP CONSTRAINT IF EXISTS template_id;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS template_applicable;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS template_installable;
+ALTER TABLE system_platform DROP COLUMN IF EXISTS template_id;
+DROP TABLE IF EXISTS template_package;
+DROP TABLE IF EXISTS template_advisory;
+DROP TABLE IF EXISTS template;
@@ -0,0 +1,64 @@
+-- named, versioned set of approved advisories and package updates
+CREATE TABLE IF NOT EXISTS template
+(
+    id            INT                      GENERATED BY DEFAULT AS IDENTITY,
+    rh_account_id INT                      NOT NULL REFERENCES rh_account (id),
+    name          TEXT                     NOT NULL CHECK (NOT empty(name)),
+    description   TEXT                     CHECK (NOT empty(description)),
+    -- incremented on each change of approved advisories and packages
+    version       INT                      NOT NULL DEFAULT 1,
+    created       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rh_account_id, id),
+    UNIQUE (rh_account_id, name)
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON template TO manager;
+
+CREATE TABLE IF NOT EXISTS template_advisory
+(
+    rh_account_id INT NOT NULL,
+    template_id   INT NOT NULL,
+    advisory_id   INT NOT NULL REFERENCES advisory_metadata (id),
+    PRIMARY KEY (rh_account_id, template_id, advisory_id),
+    CONSTRAINT template_id
+        FOREIGN KEY (rh_account_id, template_id)
+            REFERENCES template (rh_account_id, id) ON DELETE CASCADE
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON template_advisory TO manager;
+
+-- approved package updates, EVRAs of updates don't need to be in the package table
+CREATE TABLE IF NOT EXISTS template_package
+(
+    rh_account_id INT  NOT NULL,
+    template_id   INT  NOT NULL,
+    name          TEXT NOT NULL CHECK (NOT empty(name)),
+    evra          TEXT NOT NULL CHECK (NOT empty(evra)),
+    PRIMARY KEY (rh_account_id, template_id, name, evra),
+    CONSTRAINT template_id
+        FOREIGN KEY (rh_account_id, template_id)
+            REFERENCES template (rh_account_id, id) ON DELETE CASCADE
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON template_package TO manager;
+
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS template_id INT;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS template_installable INT NOT NULL DEFAULT 0;
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS template_applicable INT NOT NULL DEFAULT 0;
+ALTER TABLE system_platform
+    ADD CONSTRAINT template_id
+        FOREIGN KEY (rh_account_id, template_id)
+            REFERENCES template (rh_account_id, id);
+
+-- NULL when the system has no template
+ALTER TABLE system_advisories ADD COLUMN IF NOT EXISTS in_template BOOLEAN;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO evaluator;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO listener;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO vmaas_sync;
@@ -0,0 +1 @@
+ALTER TABLE baseline DROP COLUMN IF EXISTS selector;
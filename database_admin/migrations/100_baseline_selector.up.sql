@@ -0,0 +1,2 @@
+-- selector of systems dynamically associated with the baseline, NULL for static membership
+ALTER TABLE baseline ADD COLUMN IF NOT EXISTS selector JSONB;
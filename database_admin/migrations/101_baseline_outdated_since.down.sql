@@ -0,0 +1 @@
+ALTER TABLE system_platform DROP COLUMN IF EXISTS baseline_outdated_since;
@@ -0,0 +1,2 @@
+-- time when the system became out of date with its baseline, NULL when it's up to date
+ALTER TABLE system_platform ADD COLUMN IF NOT EXISTS baseline_outdated_since TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS sync_run_unfinished_idx;
@@ -0,0 +1,8 @@
+-- runs interrupted before the index existed would block new syncs forever
+UPDATE sync_run
+SET finished = now(),
+    error    = 'sync run interrupted'
+WHERE finished IS NULL;
+
+-- at most one unfinished sync run, it serves as a lock shared by all vmaas_sync instances
+CREATE UNIQUE INDEX IF NOT EXISTS sync_run_unfinished_idx ON sync_run ((finished IS NULL)) WHERE finished IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS module_package;
+
+REVOKE INSERT ON module FROM vmaas_sync;
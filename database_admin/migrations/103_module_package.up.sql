@@ -0,0 +1,16 @@
+-- modular package builds of module streams, taken from modules_list of synced advisories
+CREATE TABLE IF NOT EXISTS module_package
+(
+    module_id BIGINT NOT NULL REFERENCES module (id),
+    name      TEXT   NOT NULL CHECK (NOT empty(name)),
+    evra      TEXT   NOT NULL CHECK (NOT empty(evra)),
+    PRIMARY KEY (name, evra, module_id)
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT ON module TO vmaas_sync;
+GRANT SELECT, INSERT, UPDATE, DELETE ON module_package TO vmaas_sync;
+
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO evaluator;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO listener;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO manager;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO vmaas_sync;
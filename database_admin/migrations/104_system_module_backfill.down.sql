@@ -0,0 +1 @@
+-- backfilled rows can't be told apart from rows stored by listener, they are kept
@@ -0,0 +1,25 @@
+-- enabled module streams of systems uploaded before system_module existed are only in stored vmaas_json
+CREATE TEMPORARY TABLE system_module_backfill AS
+SELECT DISTINCT sp.rh_account_id,
+                sp.id                       AS system_id,
+                m.value ->> 'module_name'   AS name,
+                m.value ->> 'module_stream' AS stream
+FROM (SELECT rh_account_id, id, vmaas_json::jsonb -> 'modules_list' AS modules
+      FROM system_platform
+      WHERE vmaas_json LIKE '%modules_list%') sp,
+     jsonb_array_elements(CASE WHEN jsonb_typeof(sp.modules) = 'array' THEN sp.modules ELSE '[]' END) m
+WHERE NOT empty(m.value ->> 'module_name')
+  AND NOT empty(m.value ->> 'module_stream');
+
+INSERT INTO module (name, stream)
+SELECT DISTINCT name, stream
+FROM system_module_backfill
+ON CONFLICT DO NOTHING;
+
+INSERT INTO system_module (rh_account_id, system_id, module_id)
+SELECT b.rh_account_id, b.system_id, m.id
+FROM system_module_backfill b
+         JOIN module m ON m.name = b.name AND m.stream = b.stream
+ON CONFLICT DO NOTHING;
+
+DROP TABLE system_module_backfill;
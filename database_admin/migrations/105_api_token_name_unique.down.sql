@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS api_token_rh_account_id_name_idx;
+ALTER TABLE api_token ADD CONSTRAINT api_token_rh_account_id_name_key UNIQUE (rh_account_id, name);
@@ -0,0 +1,5 @@
+ALTER TABLE api_token DROP CONSTRAINT IF EXISTS api_token_rh_account_id_name_key;
+
+-- names of revoked tokens can be reused, revoked tokens are kept for auditing
+CREATE UNIQUE INDEX IF NOT EXISTS api_token_rh_account_id_name_idx ON api_token (rh_account_id, name)
+    WHERE revoked IS NULL;
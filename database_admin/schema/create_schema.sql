@@ -7,7 +7,7 @@ CREATE TABLE IF NOT EXISTS schema_migrations
 
 
 INSERT INTO schema_migrations
-VALUES (90, false);
+VALUES (105, false);
 
 -- ---------------------------------------------------------------------------
 -- Functions
@@ -375,6 +375,11 @@ BEGIN
     WHERE rh_account_id = v_account_id
       AND system_id = v_system_id;
 
+    DELETE
+    FROM system_module
+    WHERE rh_account_id = v_account_id
+      AND system_id = v_system_id;
+
     DELETE
     FROM system_package
     WHERE rh_account_id = v_account_id
@@ -415,6 +420,11 @@ BEGIN
                  FROM system_repo
                      WHERE (rh_account_id, system_id) in (select rh_account_id, id from systems)
          ),
+         modules as (
+             DELETE
+                 FROM system_module
+                     WHERE (rh_account_id, system_id) in (select rh_account_id, id from systems)
+         ),
          packages as (
              DELETE
                  FROM system_package
@@ -613,6 +623,7 @@ CREATE TABLE IF NOT EXISTS baseline
     name          TEXT              NOT NULL CHECK (not empty(name)),
     config        JSONB,
     description   TEXT              CHECK (NOT empty(description)),
+    selector      JSONB,
     PRIMARY KEY (rh_account_id, id),
     UNIQUE(rh_account_id, name)
 ) PARTITION BY HASH (rh_account_id);
@@ -625,6 +636,23 @@ GRANT SELECT, UPDATE, DELETE ON baseline TO vmaas_sync;
 SELECT create_table_partitions('baseline', 16,
                                $$WITH (fillfactor = '70', autovacuum_vacuum_scale_factor = '0.05')$$);
 
+-- template
+CREATE TABLE IF NOT EXISTS template
+(
+    id            INT                      GENERATED BY DEFAULT AS IDENTITY,
+    rh_account_id INT                      NOT NULL REFERENCES rh_account (id),
+    name          TEXT                     NOT NULL CHECK (NOT empty(name)),
+    description   TEXT                     CHECK (NOT empty(description)),
+    -- incremented on each change of approved advisories and packages
+    version       INT                      NOT NULL DEFAULT 1,
+    created       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rh_account_id, id),
+    UNIQUE (rh_account_id, name)
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON template TO manager;
+
 -- system_platform
 CREATE TABLE IF NOT EXISTS system_platform
 (
@@ -653,11 +681,28 @@ CREATE TABLE IF NOT EXISTS system_platform
     third_party              BOOLEAN                  NOT NULL DEFAULT false,
     baseline_id              INT,
     baseline_uptodate        BOOLEAN,
+    -- time when the system became out of date with its baseline
+    baseline_outdated_since  TIMESTAMP WITH TIME ZONE,
     yum_updates              JSONB,
+    running_kernel           TEXT,
+    installed_kernel         TEXT,
+    last_boot                TIMESTAMP WITH TIME ZONE,
+    releasever               TEXT,
+    infrastructure_type      TEXT,
+    cloud_provider           TEXT,
+    sap_system               BOOLEAN                  NOT NULL DEFAULT false,
+    ansible                  BOOLEAN                  NOT NULL DEFAULT false,
+    reboot_pending           BOOLEAN                  NOT NULL DEFAULT false,
+    -- previous evaluation time when reboot requiring advisory was found patched
+    reboot_patched           TIMESTAMP WITH TIME ZONE,
+    template_id              INT,
+    template_installable     INT                      NOT NULL DEFAULT 0,
+    template_applicable      INT                      NOT NULL DEFAULT 0,
     PRIMARY KEY (rh_account_id, id),
     UNIQUE (rh_account_id, inventory_id),
     CONSTRAINT reporter_id FOREIGN KEY (reporter_id) REFERENCES reporter (id),
-    CONSTRAINT baseline_id FOREIGN KEY (rh_account_id, baseline_id) REFERENCES baseline (rh_account_id, id)
+    CONSTRAINT baseline_id FOREIGN KEY (rh_account_id, baseline_id) REFERENCES baseline (rh_account_id, id),
+    CONSTRAINT template_id FOREIGN KEY (rh_account_id, template_id) REFERENCES template (rh_account_id, id)
 ) PARTITION BY HASH (rh_account_id);
 
 SELECT create_table_partitions('system_platform', 16,
@@ -806,6 +851,8 @@ CREATE TABLE IF NOT EXISTS system_advisories
     first_reported TIMESTAMP WITH TIME ZONE NOT NULL,
     when_patched   TIMESTAMP WITH TIME ZONE DEFAULT NULL,
     status_id      INT                      DEFAULT 0,
+    -- NULL when the system has no template
+    in_template    BOOLEAN,
     PRIMARY KEY (rh_account_id, system_id, advisory_id),
     CONSTRAINT system_platform_id
         FOREIGN KEY (rh_account_id, system_id)
@@ -912,6 +959,52 @@ GRANT DELETE ON system_repo TO manager;
 GRANT SELECT, INSERT, UPDATE, DELETE ON system_repo TO evaluator;
 GRANT SELECT, DELETE on system_repo to vmaas_sync;
 
+-- module
+CREATE TABLE IF NOT EXISTS module
+(
+    id     BIGINT GENERATED BY DEFAULT AS IDENTITY,
+    name   TEXT NOT NULL,
+    stream TEXT NOT NULL,
+    UNIQUE (name, stream),
+    CHECK (NOT empty(name)),
+    PRIMARY KEY (id)
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON module TO listener;
+GRANT SELECT, INSERT ON module TO vmaas_sync;
+
+-- module_package, modular package builds of module streams, taken from modules_list of synced advisories
+CREATE TABLE IF NOT EXISTS module_package
+(
+    module_id BIGINT NOT NULL REFERENCES module (id),
+    name      TEXT   NOT NULL CHECK (NOT empty(name)),
+    evra      TEXT   NOT NULL CHECK (NOT empty(evra)),
+    PRIMARY KEY (name, evra, module_id)
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON module_package TO vmaas_sync;
+
+-- system_module
+CREATE TABLE IF NOT EXISTS system_module
+(
+    system_id     BIGINT NOT NULL,
+    module_id     BIGINT NOT NULL,
+    rh_account_id INT NOT NULL,
+    UNIQUE (rh_account_id, system_id, module_id),
+    CONSTRAINT system_platform_id
+        FOREIGN KEY (rh_account_id, system_id)
+            REFERENCES system_platform (rh_account_id, id),
+    CONSTRAINT module_id
+        FOREIGN KEY (module_id)
+            REFERENCES module (id)
+) TABLESPACE pg_default;
+
+CREATE INDEX ON system_module (module_id);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON system_module TO listener;
+GRANT DELETE ON system_module TO manager;
+GRANT SELECT, DELETE on system_module to vmaas_sync;
+
 CREATE TABLE IF NOT EXISTS package_name
 (
     id   INT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
@@ -987,6 +1080,129 @@ CREATE TABLE IF NOT EXISTS timestamp_kv
 
 GRANT SELECT, INSERT, UPDATE, DELETE ON timestamp_kv TO vmaas_sync;
 
+-- recalc_job
+CREATE TABLE IF NOT EXISTS recalc_job
+(
+    id                BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    scope             JSONB                                   NOT NULL,
+    created           TIMESTAMP WITH TIME ZONE                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished          TIMESTAMP WITH TIME ZONE,
+    systems_total     INT                                     NOT NULL DEFAULT 0,
+    messages_queued   INT                                     NOT NULL DEFAULT 0,
+    systems_evaluated INT                                     NOT NULL DEFAULT 0
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON recalc_job TO vmaas_sync;
+GRANT SELECT, UPDATE (systems_evaluated, finished) ON recalc_job TO evaluator;
+
+-- sync_run
+CREATE TABLE IF NOT EXISTS sync_run
+(
+    id                  BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    started             TIMESTAMP WITH TIME ZONE                NOT NULL,
+    finished            TIMESTAMP WITH TIME ZONE,
+    -- NULL means full sync
+    modified_since      TIMESTAMP WITH TIME ZONE,
+    advisories_inserted INT                                     NOT NULL DEFAULT 0,
+    advisories_updated  INT                                     NOT NULL DEFAULT 0,
+    packages_inserted   INT                                     NOT NULL DEFAULT 0,
+    packages_updated    INT                                     NOT NULL DEFAULT 0,
+    repos_updated       INT                                     NOT NULL DEFAULT 0,
+    -- VMaaS /dbchange response at the time of the sync
+    dbchange            JSONB,
+    error               TEXT
+) TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS sync_run_started_idx ON sync_run (started);
+-- at most one unfinished sync run, it serves as a lock shared by all vmaas_sync instances
+CREATE UNIQUE INDEX IF NOT EXISTS sync_run_unfinished_idx ON sync_run ((finished IS NULL)) WHERE finished IS NULL;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON sync_run TO vmaas_sync;
+
+-- shadow_eval_diff
+CREATE TABLE IF NOT EXISTS shadow_eval_diff
+(
+    id                      BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    rh_account_id           INT                                     NOT NULL,
+    inventory_id            UUID                                    NOT NULL,
+    created                 TIMESTAMP WITH TIME ZONE                NOT NULL,
+    -- "local" engine or VMaaS address used by shadow evaluation
+    source                  TEXT                                    NOT NULL CHECK (NOT empty(source)),
+    advisories_only_primary JSONB,
+    advisories_only_shadow  JSONB,
+    packages_only_primary   JSONB,
+    packages_only_shadow    JSONB
+) TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS shadow_eval_diff_created_idx ON shadow_eval_diff (created);
+
+GRANT SELECT, INSERT ON shadow_eval_diff TO evaluator;
+GRANT SELECT, DELETE ON shadow_eval_diff TO vmaas_sync;
+
+-- api_token
+CREATE TABLE IF NOT EXISTS api_token
+(
+    id            BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+    rh_account_id INT                                     NOT NULL REFERENCES rh_account (id),
+    name          TEXT                                    NOT NULL CHECK (NOT empty(name)),
+    -- SHA-256 hash of the token, the token itself is shown only when created
+    token_hash    TEXT                                    NOT NULL UNIQUE,
+    -- list of token scopes, e.g. ["read-only"]
+    scopes        JSONB                                   NOT NULL,
+    created       TIMESTAMP WITH TIME ZONE                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    TEXT,
+    expires       TIMESTAMP WITH TIME ZONE,
+    last_used     TIMESTAMP WITH TIME ZONE,
+    revoked       TIMESTAMP WITH TIME ZONE
+) TABLESPACE pg_default;
+
+-- names of revoked tokens can be reused, revoked tokens are kept for auditing
+CREATE UNIQUE INDEX IF NOT EXISTS api_token_rh_account_id_name_idx ON api_token (rh_account_id, name)
+    WHERE revoked IS NULL;
+
+GRANT SELECT, INSERT, UPDATE ON api_token TO manager;
+
+-- rate_limit
+CREATE TABLE IF NOT EXISTS rate_limit
+(
+    rh_account_id       INT  NOT NULL REFERENCES rh_account (id),
+    -- route class: list, export or write
+    route_class         TEXT NOT NULL CHECK (route_class IN ('list', 'export', 'write')),
+    requests_per_minute INT  NOT NULL CHECK (requests_per_minute > 0),
+    burst               INT  NOT NULL CHECK (burst > 0),
+    PRIMARY KEY (rh_account_id, route_class)
+) TABLESPACE pg_default;
+
+-- template_advisory
+CREATE TABLE IF NOT EXISTS template_advisory
+(
+    rh_account_id INT NOT NULL,
+    template_id   INT NOT NULL,
+    advisory_id   INT NOT NULL REFERENCES advisory_metadata (id),
+    PRIMARY KEY (rh_account_id, template_id, advisory_id),
+    CONSTRAINT template_id
+        FOREIGN KEY (rh_account_id, template_id)
+            REFERENCES template (rh_account_id, id) ON DELETE CASCADE
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON template_advisory TO manager;
+
+-- template_package
+-- approved package updates, EVRAs of updates don't need to be in the package table
+CREATE TABLE IF NOT EXISTS template_package
+(
+    rh_account_id INT  NOT NULL,
+    template_id   INT  NOT NULL,
+    name          TEXT NOT NULL CHECK (NOT empty(name)),
+    evra          TEXT NOT NULL CHECK (NOT empty(evra)),
+    PRIMARY KEY (rh_account_id, template_id, name, evra),
+    CONSTRAINT template_id
+        FOREIGN KEY (rh_account_id, template_id)
+            REFERENCES template (rh_account_id, id) ON DELETE CASCADE
+) TABLESPACE pg_default;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON template_package TO manager;
+
 -- vmaas_sync needs to delete from this tables to sync CVEs correctly
 GRANT DELETE ON system_advisories TO vmaas_sync;
 GRANT DELETE ON advisory_account_data TO vmaas_sync;
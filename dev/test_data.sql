@@ -1,15 +1,26 @@
 DELETE FROM system_advisories;
 DELETE FROM system_repo;
+DELETE FROM system_module;
 DELETE FROM system_package;
 DELETE FROM system_platform;
 DELETE FROM deleted_system;
 DELETE FROM repo;
+DELETE FROM module_package;
+DELETE FROM module;
 DELETE FROM timestamp_kv;
+DELETE FROM recalc_job;
+DELETE FROM sync_run;
+DELETE FROM shadow_eval_diff;
 DELETE FROM advisory_account_data;
+DELETE FROM template_package;
+DELETE FROM template_advisory;
+DELETE FROM template;
 DELETE FROM package;
 DELETE FROM package_name;
 DELETE FROM advisory_metadata;
 DELETE FROM baseline;
+DELETE FROM api_token;
+DELETE FROM rate_limit;
 DELETE FROM rh_account;
 DELETE FROM strings;
 
@@ -21,6 +32,20 @@ INSERT INTO baseline (id, rh_account_id, name, config, description) VALUES
 (2, 1, 'baseline_1-2', '{"to_time": "2021-01-01T00:00:00+00:00"}', NULL),
 (3, 1, 'baseline_1-3', '{"to_time": "2000-01-01T00:00:00+00:00"}', NULL);
 
+INSERT INTO template (id, rh_account_id, name, description, version, created, updated) VALUES
+(1, 1, 'template_1-1', 'desc', 2, '2022-01-01 12:00:00-04', '2022-02-01 12:00:00-04'),
+(2, 1, 'template_1-2', NULL, 1, '2022-01-01 12:00:00-04', '2022-01-01 12:00:00-04');
+
+INSERT INTO rate_limit (rh_account_id, route_class, requests_per_minute, burst) VALUES
+(2, 'export', 120, 20);
+
+-- tokens: patch_read-token, patch_expired-token, patch_revoked-token, patch_org2-token
+INSERT INTO api_token (id, rh_account_id, name, token_hash, scopes, created, created_by, expires, last_used, revoked) VALUES
+(1, 1, 'token_1-1', 'b6836049552d868e1d5c0d11e9a05a9edf4eca11b8d429eb4b06f90a513948c4', '["read-only"]', '2020-01-01 00:00:00+00', 'user-1', '2100-01-01 00:00:00+00', NULL, NULL),
+(2, 1, 'token_1-2', '6c3e2b092e16ceb5c37f56f194847915a6cc286a0cbe49c718dcc2b5c873dd93', '["read-only", "baseline-write"]', '2020-01-01 00:00:00+00', 'user-1', '2020-02-01 00:00:00+00', '2020-01-15 00:00:00+00', NULL),
+(3, 1, 'token_1-3', '27bfe32569fefeb06ddaa40f0e6943b3df968ebeb0aa34f20aec879909321df3', '["status-write"]', '2020-01-01 00:00:00+00', 'user-1', '2100-01-01 00:00:00+00', NULL, '2020-03-01 00:00:00+00'),
+(4, 2, 'token_2-1', '13985cbc141d8375720f867d61686bcdd45000fed5a75aed04ebc879ca3b9dae', '["read-only"]', '2020-01-01 00:00:00+00', 'user-2', '2100-01-01 00:00:00+00', NULL, NULL);
+
 INSERT INTO system_platform (id, inventory_id, display_name, rh_account_id, reporter_id, vmaas_json, json_checksum, last_evaluation, last_upload, packages_installed, packages_updatable, third_party, baseline_id, baseline_uptodate) VALUES
 (1, '00000000-0000-0000-0000-000000000001','00000000-0000-0000-0000-000000000001', 1, 1, '{ "package_list": [ "kernel-2.6.32-696.20.1.el6.x86_64" ]}', '1', '2018-09-22 12:00:00-04', '2020-09-22 12:00:00-04',0,0, true, 1, true),
 (2, '00000000-0000-0000-0000-000000000002','00000000-0000-0000-0000-000000000002', 1, 1, '{ "package_list": [ "kernel-2.6.32-696.20.1.el6.x86_64" ]}', '1', '2018-09-22 12:00:00-04', '2018-09-22 12:00:00-04',0,0, false, 1, true),
@@ -45,6 +70,17 @@ INSERT INTO system_platform (id, inventory_id, display_name, rh_account_id,  vma
 (15, '00000000-0000-0000-0000-000000000015','00000000-0000-0000-0000-000000000015', 3, '{ "package_list": [ "kernel-2.6.32-696.20.1.el6.x86_64" ]}', '1', '2018-09-22 12:00:00-04', '2018-01-22 12:00:00-04', 0,0,
  '{"update_list": {"suricata-6.0.3-2.fc35.i686": {"available_updates": [{"erratum": "RHSA-2021:3801", "basearch": "i686", "releasever": "ser1", "repository": "group_oisf:suricata-6.0", "package": "suricata-6.0.4-2.fc35.i686"}]}}, "basearch": "i686", "releasever": "ser1"}');
 
+UPDATE system_platform SET running_kernel = '4.18.0-305.el8.x86_64', installed_kernel = '4.18.0-348.el8.x86_64',
+                           last_boot = '2018-09-20 12:00:00-04', releasever = '8.4', infrastructure_type = 'virtual',
+                           cloud_provider = 'aws', sap_system = true, ansible = true,
+                           reboot_pending = true
+WHERE id = 2;
+UPDATE system_platform SET running_kernel = '4.18.0-348.el8.x86_64', installed_kernel = '4.18.0-348.el8.x86_64',
+                           last_boot = '2018-09-21 12:00:00-04', releasever = '8.4', infrastructure_type = 'physical'
+WHERE id = 3;
+UPDATE system_platform SET template_id = 1, template_installable = 1, template_applicable = 7 WHERE id = 1;
+UPDATE system_platform SET baseline_outdated_since = '2018-09-20 12:00:00-04' WHERE id = 3;
+
 INSERT INTO advisory_metadata (id, name, description, synopsis, summary, solution, advisory_type_id,
                                public_date, modified_date, url, severity_id, cve_list, release_versions) VALUES
 (1, 'RH-1', 'adv-1-des', 'adv-1-syn', 'adv-1-sum', 'adv-1-sol', 1, '2016-09-22 12:00:00-04', '2017-09-22 12:00:00-04', 'url1', NULL, NULL, '["7.0","7Server"]'),
@@ -92,6 +128,16 @@ INSERT INTO system_advisories (rh_account_id, system_id, advisory_id, first_repo
 (2, 10, 1, '2016-09-22 12:00:00-04', NULL, 1),
 (2, 11, 1, '2016-09-22 12:00:00-04', NULL, 0);
 
+UPDATE system_advisories SET in_template = (advisory_id = 1) WHERE system_id = 1 AND when_patched IS NULL;
+
+INSERT INTO template_advisory (rh_account_id, template_id, advisory_id) VALUES
+(1, 1, 1),
+(1, 2, 2),
+(1, 2, 3);
+
+INSERT INTO template_package (rh_account_id, template_id, name, evra) VALUES
+(1, 1, 'firefox', '77.0.1-1.fc31.x86_64');
+
 INSERT INTO repo (id, name, third_party) VALUES
 (1, 'repo1', false),
 (2, 'repo2', false),
@@ -104,6 +150,19 @@ INSERT INTO system_repo (rh_account_id, system_id, repo_id) VALUES
 (1, 3, 1),
 (1, 2, 2);
 
+INSERT INTO module (id, name, stream) VALUES
+(1, 'nodejs', '12'),
+(2, 'postgresql', '12'),
+(3, 'firefox', 'stable');
+
+INSERT INTO module_package (module_id, name, evra) VALUES
+(3, 'firefox', '77.0.1-1.module+el8.4.0+1+abcdef.x86_64');
+
+INSERT INTO system_module (rh_account_id, system_id, module_id) VALUES
+(1, 2, 1),
+(1, 3, 1),
+(1, 2, 2),
+(3, 12, 3);
 
 INSERT INTO package_name(id, name, summary) VALUES
 (101, 'kernel', 'The Linux kernel'),
@@ -170,9 +229,12 @@ ALTER TABLE advisory_metadata ALTER COLUMN id RESTART WITH 100;
 ALTER TABLE system_platform ALTER COLUMN id RESTART WITH 100;
 ALTER TABLE rh_account ALTER COLUMN id RESTART WITH 100;
 ALTER TABLE repo ALTER COLUMN id RESTART WITH 100;
+ALTER TABLE module ALTER COLUMN id RESTART WITH 100;
 ALTER TABLE package ALTER COLUMN id RESTART WITH 100;
 ALTER TABLE package_name ALTER COLUMN id RESTART WITH 150;
 ALTER TABLE baseline ALTER COLUMN id RESTART WITH 100;
+ALTER TABLE api_token ALTER COLUMN id RESTART WITH 100;
+ALTER TABLE template ALTER COLUMN id RESTART WITH 100;
 
 -- Create "inventory.hosts" for testing purposes. In deployment it's created by remote Cyndi service.
 
@@ -186,6 +248,7 @@ CREATE TABLE IF NOT EXISTS inventory.hosts_v1_0 (
     created timestamp with time zone NOT NULL,
     stale_timestamp timestamp with time zone NOT NULL,
     system_profile jsonb NOT NULL,
+    groups jsonb NOT NULL DEFAULT '[]',
     PRIMARY KEY (id)
 );
 
@@ -208,7 +271,8 @@ CREATE OR REPLACE VIEW inventory.hosts AS
     (hosts_v1_0.stale_timestamp + ('1 day'::interval day * '7'::double precision)) AS stale_warning_timestamp,
     (hosts_v1_0.stale_timestamp + ('1 day'::interval day * '14'::double precision)) AS culled_timestamp,
     hosts_v1_0.tags,
-    hosts_v1_0.system_profile
+    hosts_v1_0.system_profile,
+    hosts_v1_0.groups
  FROM inventory.hosts_v1_0;
 
 GRANT SELECT ON TABLE inventory.hosts TO cyndi_reader;
@@ -249,3 +313,8 @@ INSERT INTO inventory.hosts_v1_0 (id, insights_id, account, display_name, tags,
 ('00000000000000000000000000000017', '00000000-0000-0000-0017-000000000001', '3', '00000000-0000-0000-0000-000000000017', '[]',
  '2018-09-22 12:00:00-04', '2018-08-26 12:00:00-04', '2018-08-26 12:00:00-04',
  '{"rhsm": {"version": "8.1"}, "operating_system": {"name": "RHEL", "major": 8, "minor": 1}, "ansible": {"controller_version": "1.0", "hub_version": "3.4.1", "catalog_worker_version": "100.387.9846.12", "sso_version": "1.28.3.52641.10000513168495123"}, "mssql": { "version": "15.3.0"}}');
+
+UPDATE inventory.hosts_v1_0 SET groups = '[{"id": "inventory-group-1", "name": "group1"}]'
+WHERE id IN ('00000000000000000000000000000001', '00000000000000000000000000000002');
+UPDATE inventory.hosts_v1_0 SET groups = '[{"id": "inventory-group-2", "name": "group2"}]'
+WHERE id = '00000000000000000000000000000003';
@@ -37,6 +37,7 @@ type openapiData struct {
 
 type EndpointsConfig struct {
 	EnableBaselines bool
+	EnableTemplates bool
 }
 
 func Init(app *gin.Engine, config EndpointsConfig) string {
@@ -90,6 +91,10 @@ func filterOpenAPI(config EndpointsConfig, inputOpenapiPath, outputOpenapiPath s
 			removedPaths++
 			continue
 		}
+		if !config.EnableTemplates && strings.Contains(path, "/templates") {
+			removedPaths++
+			continue
+		}
 		filteredPaths[path] = sw.Paths[path]
 	}
 
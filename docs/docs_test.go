@@ -20,6 +20,7 @@ func TestValidateOpenAPI3DocStr(t *testing.T) {
 func TestFilterOpenAPIPaths1(t *testing.T) {
 	nRemovedPaths := filterOpenAPI(EndpointsConfig{
 		EnableBaselines: true,
+		EnableTemplates: true,
 	}, openAPIPath, "/tmp/openapi-filter-test.json")
 	assert.Equal(t, 0, nRemovedPaths)
 }
@@ -27,6 +28,15 @@ func TestFilterOpenAPIPaths1(t *testing.T) {
 func TestFilterOpenAPIPaths2(t *testing.T) {
 	nRemovedPaths := filterOpenAPI(EndpointsConfig{
 		EnableBaselines: false,
+		EnableTemplates: true,
 	}, openAPIPath, "/tmp/openapi-filter-test.json")
-	assert.Equal(t, 4, nRemovedPaths)
+	assert.Equal(t, 10, nRemovedPaths)
+}
+
+func TestFilterOpenAPIPathsTemplates(t *testing.T) {
+	nRemovedPaths := filterOpenAPI(EndpointsConfig{
+		EnableBaselines: true,
+		EnableTemplates: false,
+	}, openAPIPath, "/tmp/openapi-filter-test.json")
+	assert.Equal(t, 2, nRemovedPaths)
 }
@@ -44,6 +44,7 @@ var (
 	enableStaleSysEval            bool
 	enableLazyPackageSave         bool
 	enableBaselineEval            bool
+	enableTemplateEval            bool
 	prunePackageLatestOnly        bool
 	enablePackageCache            bool
 	preloadPackageCache           bool
@@ -73,6 +74,7 @@ func configure() {
 	enableStaleSysEval = utils.GetBoolEnvOrDefault("ENABLE_STALE_SYSTEM_EVALUATION", true)
 	enableLazyPackageSave = utils.GetBoolEnvOrDefault("ENABLE_LAZY_PACKAGE_SAVE", true)
 	enableBaselineEval = utils.GetBoolEnvOrDefault("ENABLE_BASELINE_EVAL", true)
+	enableTemplateEval = utils.GetBoolEnvOrDefault("ENABLE_TEMPLATE_EVAL", true)
 	prunePackageLatestOnly = utils.GetBoolEnvOrDefault("PRUNE_UPDATES_LATEST_ONLY", false)
 	enableBypass = utils.GetBoolEnvOrDefault("ENABLE_BYPASS", false)
 	useTraceLevel := strings.ToLower(utils.Getenv("LOG_LEVEL", "INFO")) == "trace"
@@ -80,7 +82,11 @@ func configure() {
 		HTTPClient: &http.Client{Transport: &http.Transport{DisableCompression: disableCompression}},
 		Debug:      useTraceLevel,
 	}
-	vmaasUpdatesURL = utils.FailIfEmpty(utils.Cfg.VmaasAddress, "VMAAS_ADDRESS") + base.VMaaSAPIPrefix + "/updates"
+	// resolve updates from VMaaS data files instead of VMaaS API
+	vmaasDumpPath := utils.Getenv("VMAAS_DUMP_PATH", "")
+	if vmaasDumpPath == "" {
+		vmaasUpdatesURL = utils.FailIfEmpty(utils.Cfg.VmaasAddress, "VMAAS_ADDRESS") + base.VMaaSAPIPrefix + "/updates"
+	}
 	enablePackageCache = utils.GetBoolEnvOrDefault("ENABLE_PACKAGE_CACHE", true)
 	preloadPackageCache = utils.GetBoolEnvOrDefault("PRELOAD_PACKAGE_CACHE", true)
 	packageCacheSize = utils.GetIntEnvOrDefault("PACKAGE_CACHE_SIZE", 1000000)
@@ -93,6 +99,9 @@ func configure() {
 	enableInstantNotifications = utils.GetBoolEnvOrDefault("ENABLE_INSTANT_NOTIFICATIONS", true)
 	configureRemediations()
 	configureNotifications()
+	configureUpdatesIndex(vmaasDumpPath)
+	configureLocalUpdates()
+	configureShadowEval()
 }
 
 func Evaluate(ctx context.Context, event *mqueue.PlatformEvent, inventoryID, evaluationType string) error {
@@ -278,11 +287,14 @@ func getVmaasUpdates(ctx context.Context, tx *gorm.DB,
 	useOptimisticUpdates := thirdParty || vmaasCallUseOptimisticUpdates
 	updatesReq.OptimisticUpdates = utils.PtrBool(useOptimisticUpdates)
 
-	vmaasData, err := callVMaas(ctx, updatesReq)
+	vmaasData, err := resolveUpdates(ctx, updatesReq)
 	if err != nil {
 		evaluationCnt.WithLabelValues("error-call-vmaas-updates").Inc()
 		return nil, errors.Wrap(err, "vmaas API call failed")
 	}
+	if isShadowEvalSampled() {
+		startShadowEvaluate(system, updatesReq, vmaasData)
+	}
 
 	return vmaasData, nil
 }
@@ -431,7 +443,14 @@ func updateSystemPlatform(tx *gorm.DB, system *models.SystemPlatform,
 		data["advisory_enh_count_cache"] = enhCount
 		data["advisory_bug_count_cache"] = bugCount
 		data["advisory_sec_count_cache"] = secCount
+		data["reboot_patched"] = system.RebootPatched
+		data["template_installable"] = system.TemplateInstallable
+		data["template_applicable"] = system.TemplateApplicable
+		system.BaselineUpToDate, system.BaselineOutdatedSince = baselineCompliance(system, count, time.Now())
+		data["baseline_uptodate"] = system.BaselineUpToDate
+		data["baseline_outdated_since"] = system.BaselineOutdatedSince
 	}
+	data["reboot_pending"] = isRebootPending(system)
 
 	if enablePackageAnalysis {
 		data["packages_installed"] = installed
@@ -448,6 +467,9 @@ func updateSystemPlatform(tx *gorm.DB, system *models.SystemPlatform,
 func callVMaas(ctx context.Context, request *vmaas.UpdatesV3Request) (*vmaas.UpdatesV2Response, error) {
 	tStart := time.Now()
 	defer utils.ObserveSecondsSince(tStart, evaluationPartDuration.WithLabelValues("vmaas-updates-call"))
+	if updatesIndex != nil {
+		return getIndexUpdates(request), nil
+	}
 
 	vmaasCallFunc := func() (interface{}, *http.Response, error) {
 		utils.Log("request", *request).Trace("vmaas /updates request")
@@ -505,6 +527,18 @@ func evaluateHandler(event mqueue.PlatformEvent) error {
 		StatusMsg: "advisories evaluation",
 	}
 
+	nEvaluated := 0
+	evaluate := func(inventoryID string) {
+		var evalErr error
+		ptEvent, evalErr = runEvaluate(base.Context, event, inventoryID, evalLabel, ptEvent, &wg, guard)
+		ptEvents = append(ptEvents, ptEvent)
+		if evalErr != nil {
+			err = evalErr
+			return
+		}
+		nEvaluated++
+	}
+
 	if event.SystemIDs != nil {
 		// Evaluate in bulk
 		nRequestIDs := len(event.RequestIDs)
@@ -513,15 +547,15 @@ func evaluateHandler(event mqueue.PlatformEvent) error {
 			if nRequestIDs > i {
 				ptEvent.RequestID = &event.RequestIDs[i]
 			}
-			ptEvent, err = runEvaluate(base.Context, event, id, evalLabel, ptEvent, &wg, guard)
-			ptEvents = append(ptEvents, ptEvent)
+			evaluate(id)
 		}
 	} else {
-		ptEvent, err = runEvaluate(base.Context, event, event.ID, evalLabel, ptEvent, &wg, guard)
-		ptEvents = append(ptEvents, ptEvent)
+		evaluate(event.ID)
 	}
 	wg.Wait()
 
+	ackRecalcJob(&event, nEvaluated)
+
 	// send kafka message to payload tracker
 	if evalLabel == uploadLabel {
 		ptErr := mqueue.SendMessages(base.Context, ptWriter, &ptEvents)
@@ -533,6 +567,21 @@ func evaluateHandler(event mqueue.PlatformEvent) error {
 	return err
 }
 
+// Acknowledge systems evaluated within re-calc job sent from admin API to track its progress.
+// Only systems evaluated successfully are acknowledged.
+func ackRecalcJob(event *mqueue.PlatformEvent, nSystems int) {
+	if event.RecalcJobID == nil || nSystems == 0 {
+		return
+	}
+	err := database.Db.Exec(`UPDATE recalc_job
+		SET systems_evaluated = systems_evaluated + ?,
+		    finished = COALESCE(finished, CASE WHEN systems_evaluated + ? >= systems_total THEN CURRENT_TIMESTAMP END)
+		WHERE id = ?`, nSystems, nSystems, *event.RecalcJobID).Error
+	if err != nil {
+		utils.Log("err", err.Error(), "job", *event.RecalcJobID).Warn("unable to acknowledge re-calc job")
+	}
+}
+
 func loadCache() {
 	memoryPackageCache = NewPackageCache(enablePackageCache, preloadPackageCache, packageCacheSize, packageNameCacheSize)
 	memoryPackageCache.Load()
@@ -25,11 +25,23 @@ func analyzeAdvisories(tx *gorm.DB, system *models.SystemPlatform, vmaasData *vm
 		return nil, errors.Wrap(err, "Unable to process system advisories")
 	}
 
+	if err = markRebootPatched(tx, system, patched); err != nil {
+		evaluationCnt.WithLabelValues("error-reboot-patched").Inc()
+		return nil, errors.Wrap(err, "Unable to check reboot requiring advisories")
+	}
+
 	newSystemAdvisories, err := storeAdvisoryData(tx, system, patched, unpatched)
 	if err != nil {
 		evaluationCnt.WithLabelValues("error-store-advisories").Inc()
 		return nil, errors.Wrap(err, "Unable to store advisory data")
 	}
+
+	if enableTemplateEval {
+		if err = evaluateTemplate(tx, system, vmaasData, newSystemAdvisories); err != nil {
+			evaluationCnt.WithLabelValues("error-template").Inc()
+			return nil, errors.Wrap(err, "Unable to evaluate template")
+		}
+	}
 	return newSystemAdvisories, nil
 }
 
@@ -50,3 +50,19 @@ func limitVmaasToBaseline(tx *gorm.DB, system *models.SystemPlatform, vmaasData
 
 	return nil
 }
+
+// System is up to date with its baseline when no advisory is left after limiting updates to the baseline.
+// Returns nil values for systems without baseline, time of becoming out of date is kept until it's up to date again.
+func baselineCompliance(system *models.SystemPlatform, advisoryCount int, now time.Time) (*bool, *time.Time) {
+	if system.BaselineID == nil {
+		return nil, nil
+	}
+	upToDate := advisoryCount == 0
+	if upToDate {
+		return &upToDate, nil
+	}
+	if system.BaselineUpToDate != nil && !*system.BaselineUpToDate && system.BaselineOutdatedSince != nil {
+		return &upToDate, system.BaselineOutdatedSince
+	}
+	return &upToDate, &now
+}
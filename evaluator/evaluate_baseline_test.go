@@ -9,6 +9,7 @@ import (
 
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -40,6 +41,35 @@ func TestLimitVmaasToBaseline(t *testing.T) {
 	assert.Equal(t, []string{"RH-100"}, errataInVmaasData(vmaasData))
 }
 
+func TestBaselineCompliance(t *testing.T) {
+	now := time.Now()
+	since := now.Add(-time.Hour)
+
+	// a system without baseline
+	upToDate, outdatedSince := baselineCompliance(&models.SystemPlatform{}, 1, now)
+	assert.Nil(t, upToDate)
+	assert.Nil(t, outdatedSince)
+
+	// no advisory left after limiting updates to the baseline
+	system := models.SystemPlatform{BaselineID: utils.PtrInt(1), BaselineOutdatedSince: &since}
+	upToDate, outdatedSince = baselineCompliance(&system, 0, now)
+	assert.True(t, *upToDate)
+	assert.Nil(t, outdatedSince)
+
+	// a system becoming out of date
+	system = models.SystemPlatform{BaselineID: utils.PtrInt(1), BaselineUpToDate: utils.PtrBool(true)}
+	upToDate, outdatedSince = baselineCompliance(&system, 2, now)
+	assert.False(t, *upToDate)
+	assert.Equal(t, now, *outdatedSince)
+
+	// a system staying out of date keeps the original time
+	system = models.SystemPlatform{BaselineID: utils.PtrInt(1), BaselineUpToDate: utils.PtrBool(false),
+		BaselineOutdatedSince: &since}
+	upToDate, outdatedSince = baselineCompliance(&system, 2, now)
+	assert.False(t, *upToDate)
+	assert.Equal(t, since, *outdatedSince)
+}
+
 func errataInVmaasData(vmaasData vmaas.UpdatesV2Response) []string {
 	errata := make([]string, 0)
 	for _, updates := range vmaasData.GetUpdateList() {
@@ -0,0 +1,51 @@
+package evaluator
+
+import (
+	"app/base/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Running kernel is compared only when reported in full `uname -r` format (version-release.arch). Inventory
+// usually reports version only (e.g. 4.18.0) which can't tell kernel builds apart, such systems are found
+// pending reboot only by reboot requiring advisories patched after the last boot.
+func isKernelPending(running, installed *string) bool {
+	if running == nil || installed == nil || !strings.Contains(*running, "-") || *installed == "" {
+		return false
+	}
+	return *running != *installed
+}
+
+// Records time since which reboot requiring advisory is patched on the system. The patched package was installed
+// after the previous evaluation found the advisory applicable, so the previous evaluation time is used.
+func markRebootPatched(tx *gorm.DB, system *models.SystemPlatform, patched []int) error {
+	if len(patched) == 0 {
+		return nil
+	}
+
+	var rebootRequired int64
+	err := tx.Model(&models.AdvisoryMetadata{}).
+		Where("id IN (?) AND reboot_required = true", patched).
+		Count(&rebootRequired).Error
+	if err != nil || rebootRequired == 0 {
+		return err
+	}
+
+	patchedSince := time.Now()
+	if system.LastEvaluation != nil {
+		patchedSince = *system.LastEvaluation
+	}
+	system.RebootPatched = &patchedSince
+	return nil
+}
+
+// System needs reboot when it doesn't run the newest installed kernel
+// or reboot requiring advisory was patched after the last boot
+func isRebootPending(system *models.SystemPlatform) bool {
+	if isKernelPending(system.RunningKernel, system.InstalledKernel) {
+		return true
+	}
+	return system.LastBoot != nil && system.RebootPatched != nil && system.LastBoot.Before(*system.RebootPatched)
+}
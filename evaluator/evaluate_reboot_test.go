@@ -0,0 +1,56 @@
+package evaluator
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsKernelPending(t *testing.T) {
+	installed := utils.PtrString("4.18.0-348.el8.x86_64")
+	assert.False(t, isKernelPending(utils.PtrString("4.18.0-348.el8.x86_64"), installed))
+	assert.True(t, isKernelPending(utils.PtrString("4.18.0-305.el8.x86_64"), installed))
+	assert.False(t, isKernelPending(utils.PtrString("4.18.0"), installed))
+	assert.False(t, isKernelPending(utils.PtrString("4.17.1"), installed))
+	assert.False(t, isKernelPending(utils.PtrString(""), installed))
+	assert.False(t, isKernelPending(nil, installed))
+	assert.False(t, isKernelPending(utils.PtrString("4.18.0-305.el8.x86_64"), nil))
+}
+
+func TestIsRebootPending(t *testing.T) {
+	lastBoot := time.Date(2021, 9, 20, 12, 0, 0, 0, time.UTC)
+	patchedBefore := lastBoot.Add(-time.Hour)
+	patchedAfter := lastBoot.Add(time.Hour)
+
+	assert.False(t, isRebootPending(&models.SystemPlatform{}))
+	assert.False(t, isRebootPending(&models.SystemPlatform{LastBoot: &lastBoot, RebootPatched: &patchedBefore}))
+	assert.True(t, isRebootPending(&models.SystemPlatform{LastBoot: &lastBoot, RebootPatched: &patchedAfter}))
+	// boot time unknown, only kernels can be compared
+	assert.False(t, isRebootPending(&models.SystemPlatform{RebootPatched: &patchedAfter}))
+	assert.True(t, isRebootPending(&models.SystemPlatform{
+		RunningKernel:   utils.PtrString("4.18.0-305.el8.x86_64"),
+		InstalledKernel: utils.PtrString("4.18.0-348.el8.x86_64"),
+	}))
+}
+
+func TestMarkRebootPatched(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+
+	// RH-1 requires reboot only for the duration of the test
+	assert.Nil(t, database.Db.Model(&models.AdvisoryMetadata{}).Where("id = 1").
+		Update("reboot_required", true).Error)
+	defer database.Db.Model(&models.AdvisoryMetadata{}).Where("id = 1").Update("reboot_required", false)
+
+	lastEvaluation := time.Date(2021, 9, 20, 12, 0, 0, 0, time.UTC)
+	system := models.SystemPlatform{LastEvaluation: &lastEvaluation}
+	assert.Nil(t, markRebootPatched(database.Db, &system, []int{2, 3}))
+	assert.Nil(t, system.RebootPatched)
+	assert.Nil(t, markRebootPatched(database.Db, &system, []int{1, 2}))
+	assert.Equal(t, lastEvaluation, *system.RebootPatched)
+}
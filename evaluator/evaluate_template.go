@@ -0,0 +1,106 @@
+package evaluator
+
+import (
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+
+	"gorm.io/gorm"
+)
+
+// Splits system advisories to installable per the system template and applicable but not in the template.
+// Advisories of systems without template are not marked.
+func evaluateTemplate(tx *gorm.DB, system *models.SystemPlatform, vmaasData *vmaas.UpdatesV2Response,
+	advisories SystemAdvisoryMap) error {
+	systemAdvisories := tx.Model(&models.SystemAdvisories{}).
+		Where("rh_account_id = ? AND system_id = ?", system.RhAccountID, system.ID)
+	if system.TemplateID == nil {
+		system.TemplateInstallable = 0
+		system.TemplateApplicable = 0
+		return systemAdvisories.Where("in_template IS NOT NULL").Update("in_template", nil).Error
+	}
+
+	approvedAdvisories, approvedPackages, err := loadTemplateApproved(tx, system, vmaasData)
+	if err != nil {
+		return err
+	}
+
+	installable := templateInstallable(vmaasData, approvedAdvisories, approvedPackages)
+	installableIDs := make([]int, 0, len(installable))
+	for name, sa := range advisories {
+		if installable[name] {
+			installableIDs = append(installableIDs, sa.AdvisoryID)
+		}
+	}
+	system.TemplateInstallable = len(installableIDs)
+	system.TemplateApplicable = len(advisories) - len(installableIDs)
+
+	// empty list evaluates to NULL, only changed rows are updated
+	inTemplate := gorm.Expr("COALESCE(advisory_id IN (?), false)", installableIDs)
+	return systemAdvisories.Where("when_patched IS NULL AND in_template IS DISTINCT FROM (?)", inTemplate).
+		Update("in_template", inTemplate).Error
+}
+
+// Loads advisories and package updates approved by the system template, only the reported ones are loaded
+func loadTemplateApproved(tx *gorm.DB, system *models.SystemPlatform, vmaasData *vmaas.UpdatesV2Response) (
+	advisories, packages map[string]bool, err error) {
+	reportedAdvisories := make([]string, 0)
+	for name := range getReportedAdvisories(vmaasData) {
+		reportedAdvisories = append(reportedAdvisories, name)
+	}
+	reportedPackages := make([]string, 0)
+	for nevra := range getReportedPackageUpdates(vmaasData) {
+		if parsed, err := utils.ParseNevra(nevra); err == nil {
+			reportedPackages = append(reportedPackages, parsed.Name)
+		}
+	}
+
+	var advisoryNames []string
+	err = tx.Table("template_advisory ta").
+		Joins("JOIN advisory_metadata am ON am.id = ta.advisory_id").
+		Where("ta.rh_account_id = ? AND ta.template_id = ?", system.RhAccountID, system.TemplateID).
+		Where("am.name IN (?)", reportedAdvisories).
+		Pluck("am.name", &advisoryNames).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var templatePackages []models.TemplatePackage
+	err = tx.Where("rh_account_id = ? AND template_id = ?", system.RhAccountID, system.TemplateID).
+		Where("name IN (?)", reportedPackages).
+		Find(&templatePackages).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	advisories = make(map[string]bool, len(advisoryNames))
+	for _, name := range advisoryNames {
+		advisories[name] = true
+	}
+	packages = make(map[string]bool, len(templatePackages))
+	for _, p := range templatePackages {
+		packages[p.Name+"-"+p.EVRA] = true
+	}
+	return advisories, packages, nil
+}
+
+// Advisory is installable when it is approved by the template
+// or when it updates a package to EVRA approved by the template
+func templateInstallable(vmaasData *vmaas.UpdatesV2Response, approvedAdvisories,
+	approvedPackages map[string]bool) map[string]bool {
+	installable := map[string]bool{}
+	for _, updates := range vmaasData.GetUpdateList() {
+		for _, u := range updates.GetAvailableUpdates() {
+			advisory := u.GetErratum()
+			if approvedAdvisories[advisory] {
+				installable[advisory] = true
+				continue
+			}
+			if nevra, err := utils.ParseNevra(u.GetPackage()); err == nil &&
+				approvedPackages[nevra.Name+"-"+nevra.EVRAString()] {
+				installable[advisory] = true
+			}
+		}
+	}
+	return installable
+}
@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func templateVmaasData() *vmaas.UpdatesV2Response {
+	return &vmaas.UpdatesV2Response{UpdateList: &map[string]vmaas.UpdatesV2ResponseUpdateList{
+		"firefox-0:76.0.1-1.fc31.x86_64": {AvailableUpdates: &[]vmaas.UpdatesV2ResponseAvailableUpdates{
+			{Erratum: utils.PtrString("RH-2"), Package: utils.PtrString("firefox-0:77.0.1-1.fc31.x86_64")},
+			{Erratum: utils.PtrString("RH-3"), Package: utils.PtrString("firefox-0:78.0.1-1.fc31.x86_64")},
+		}},
+		"kernel-0:5.6.13-200.fc31.x86_64": {AvailableUpdates: &[]vmaas.UpdatesV2ResponseAvailableUpdates{
+			{Erratum: utils.PtrString("RH-1"), Package: utils.PtrString("kernel-0:5.10.13-200.fc31.x86_64")},
+		}},
+	}}
+}
+
+func TestTemplateInstallable(t *testing.T) {
+	vmaasData := templateVmaasData()
+	installable := templateInstallable(vmaasData, map[string]bool{}, map[string]bool{})
+	assert.Equal(t, map[string]bool{}, installable)
+
+	installable = templateInstallable(vmaasData, map[string]bool{"RH-1": true},
+		map[string]bool{"firefox-77.0.1-1.fc31.x86_64": true})
+	assert.Equal(t, map[string]bool{"RH-1": true, "RH-2": true}, installable)
+}
+
+func TestEvaluateTemplate(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	system := models.SystemPlatform{ID: 1, RhAccountID: 1, TemplateID: utils.PtrInt(1)}
+	advisories := SystemAdvisoryMap{
+		"RH-1": {RhAccountID: 1, SystemID: 1, AdvisoryID: 1},
+		"RH-2": {RhAccountID: 1, SystemID: 1, AdvisoryID: 2},
+		"RH-3": {RhAccountID: 1, SystemID: 1, AdvisoryID: 3},
+	}
+	assert.NoError(t, evaluateTemplate(database.Db, &system, templateVmaasData(), advisories))
+	// RH-1 is approved advisory, RH-2 updates to approved firefox EVRA
+	assert.Equal(t, 2, system.TemplateInstallable)
+	assert.Equal(t, 1, system.TemplateApplicable)
+
+	var inTemplate []int
+	assert.NoError(t, database.Db.Model(&models.SystemAdvisories{}).
+		Where("rh_account_id = 1 AND system_id = 1 AND in_template").
+		Order("advisory_id").Pluck("advisory_id", &inTemplate).Error)
+	assert.Equal(t, []int{1, 2}, inTemplate)
+
+	// restore fixture state
+	assert.NoError(t, database.Db.Model(&models.SystemAdvisories{}).
+		Where("rh_account_id = 1 AND system_id = 1 AND when_patched IS NULL").
+		Update("in_template", gorm.Expr("advisory_id = 1")).Error)
+}
+
+func TestEvaluateTemplateNoTemplate(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	system := models.SystemPlatform{ID: 2, RhAccountID: 1, TemplateInstallable: 1, TemplateApplicable: 1}
+	assert.NoError(t, evaluateTemplate(database.Db, &system, templateVmaasData(), SystemAdvisoryMap{}))
+	assert.Equal(t, 0, system.TemplateInstallable)
+	assert.Equal(t, 0, system.TemplateApplicable)
+
+	var cnt int64
+	assert.NoError(t, database.Db.Model(&models.SystemAdvisories{}).
+		Where("rh_account_id = 1 AND system_id = 2 AND in_template IS NOT NULL").Count(&cnt).Error)
+	assert.Equal(t, int64(0), cnt)
+}
@@ -155,6 +155,55 @@ func TestEvaluateDontPruneUpdates(t *testing.T) {
 		"At least one package should have multiple updates (OR we have package pruning enabled)")
 }
 
+func TestAckRecalcJob(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+
+	job := models.RecalcJob{Scope: []byte("{}"), Created: time.Now(), SystemsTotal: 3}
+	assert.Nil(t, database.Db.Create(&job).Error)
+	defer database.Db.Delete(&job)
+
+	ackRecalcJob(&mqueue.PlatformEvent{RecalcJobID: &job.ID}, 2)
+	assert.Nil(t, database.Db.Where("id = ?", job.ID).Take(&job).Error)
+	assert.Equal(t, 2, job.SystemsEvaluated)
+	assert.Nil(t, job.Finished)
+
+	ackRecalcJob(&mqueue.PlatformEvent{RecalcJobID: &job.ID}, 1)
+	assert.Nil(t, database.Db.Where("id = ?", job.ID).Take(&job).Error)
+	assert.Equal(t, 3, job.SystemsEvaluated)
+	assert.NotNil(t, job.Finished)
+}
+
+func TestEvaluateHandlerAckRecalcJob(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+	evalLabel = recalcLabel
+
+	const brokenID = "00000000-0000-0000-0000-000000000001"
+	var system models.SystemPlatform
+	assert.Nil(t, database.Db.Where("inventory_id = ?::uuid", brokenID).Take(&system).Error)
+	vmaasJSON := *system.VmaasJSON
+	assert.Nil(t, database.Db.Model(&system).Update("vmaas_json", "invalid").Error)
+	defer database.Db.Model(&system).Update("vmaas_json", vmaasJSON)
+
+	job := models.RecalcJob{Scope: []byte("{}"), Created: time.Now(), SystemsTotal: 3}
+	assert.Nil(t, database.Db.Create(&job).Error)
+	defer database.Db.Delete(&job)
+
+	// system with broken vmaas json fails, unknown systems are skipped successfully
+	err := evaluateHandler(mqueue.PlatformEvent{
+		SystemIDs: []string{"00000000-0000-0000-0000-000000000998", brokenID,
+			"00000000-0000-0000-0000-000000000999"},
+		AccountID:   1,
+		RecalcJobID: &job.ID})
+	assert.Error(t, err)
+
+	assert.Nil(t, database.Db.Where("id = ?", job.ID).Take(&job).Error)
+	assert.Equal(t, 2, job.SystemsEvaluated)
+	assert.Nil(t, job.Finished)
+}
+
 func TestRun(t *testing.T) {
 	configure()
 	var nReaders int32
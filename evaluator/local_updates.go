@@ -0,0 +1,343 @@
+package evaluator
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"app/base/vmaasdump"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Modes of the local update resolution engine
+const (
+	localUpdatesPrimary  = "primary"  // resolve updates locally only
+	localUpdatesFallback = "fallback" // resolve updates locally when VMaaS call fails
+	localUpdatesShadow   = "shadow"   // compare VMaaS updates with local ones in background, VMaaS updates are used
+)
+
+var (
+	localUpdatesMode           string
+	localUpdatesRefresh        time.Duration
+	localUpdatesCompareTimeout time.Duration
+	localUpdatesCompareSlots   chan struct{} // limits number of shadow comparisons running in background
+	localUpdates               localUpdatesEngine
+)
+
+func configureLocalUpdates() {
+	localUpdatesMode = strings.ToLower(utils.Getenv("LOCAL_UPDATES_MODE", ""))
+	switch localUpdatesMode {
+	case "", localUpdatesPrimary, localUpdatesFallback, localUpdatesShadow:
+	default:
+		panic(fmt.Sprintf("invalid LOCAL_UPDATES_MODE: %s", localUpdatesMode))
+	}
+	localUpdatesRefresh = time.Duration(utils.GetIntEnvOrDefault("LOCAL_UPDATES_REFRESH_SEC", 600)) * time.Second
+	localUpdatesCompareTimeout = time.Duration(utils.GetIntEnvOrDefault("LOCAL_UPDATES_COMPARE_TIMEOUT_SEC", 30)) *
+		time.Second
+	localUpdatesCompareSlots = make(chan struct{}, utils.GetIntEnvOrDefault("LOCAL_UPDATES_COMPARE_MAX_RUNNING", 4))
+}
+
+// localUpdatesEngine resolves updates from synced advisories package lists limited by synced repositories
+// and module streams, the index is rebuilt periodically to pick up advisories synced by vmaas_sync
+type localUpdatesEngine struct {
+	current   atomic.Value // *loadedUpdatesIndex
+	loadLock  sync.Mutex   // held while the first index is loaded, callers wait for it
+	reloading int32        // 1 while the index is being rebuilt in background
+}
+
+type loadedUpdatesIndex struct {
+	index  *vmaasdump.UpdatesIndex
+	loaded time.Time
+}
+
+type advisoryPackages struct {
+	Name            string
+	Type            string
+	PackageData     []byte
+	ReleaseVersions []byte
+}
+
+func loadAdvisoriesErrata(ctx context.Context) (map[string]vmaas.ErrataResponseErrataList, error) {
+	var rows []advisoryPackages
+	err := database.Db.WithContext(ctx).Table("advisory_metadata am").
+		Select("am.name, at.name AS type, am.package_data, am.release_versions").
+		Joins("JOIN advisory_type at ON at.id = am.advisory_type_id").
+		Where("am.package_data IS NOT NULL").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	modules, err := loadPackageModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	errata := make(map[string]vmaas.ErrataResponseErrataList, len(rows))
+	for _, row := range rows {
+		pkgs, err := parseAdvisoryPackages(row.PackageData)
+		if err != nil {
+			utils.Log("advisory", row.Name, "err", err.Error()).Warn("Skipping advisory with invalid package data")
+			continue
+		}
+		erratum := vmaas.ErrataResponseErrataList{Type: strings.ToLower(row.Type), PackageList: pkgs,
+			ModulesList: advisoryModules(pkgs, modules)}
+		if row.ReleaseVersions != nil {
+			var releaseVersions []string
+			if err = json.Unmarshal(row.ReleaseVersions, &releaseVersions); err == nil {
+				erratum.ReleaseVersions = &releaseVersions
+			}
+		}
+		errata[row.Name] = erratum
+	}
+	return errata, nil
+}
+
+// Loads module streams of modular package builds by "<name>-<evra>" stored by vmaas_sync
+func loadPackageModules(ctx context.Context) (map[string][]vmaas.ErrataResponseModule, error) {
+	var rows []struct {
+		Name   string
+		EVRA   string `gorm:"column:evra"`
+		Module string
+		Stream string
+	}
+	err := database.Db.WithContext(ctx).Table("module_package mp").
+		Select("mp.name, mp.evra, m.name AS module, m.stream").
+		Joins("JOIN module m ON m.id = mp.module_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load module packages")
+	}
+	modules := make(map[string][]vmaas.ErrataResponseModule, len(rows))
+	for _, row := range rows {
+		key := row.Name + "-" + row.EVRA
+		modules[key] = append(modules[key], vmaas.ErrataResponseModule{ModuleName: row.Module, ModuleStream: row.Stream})
+	}
+	return modules, nil
+}
+
+// Module streams of the advisory packages, packages without module are not modular
+func advisoryModules(pkgs []string, modules map[string][]vmaas.ErrataResponseModule) []vmaas.ErrataResponseModule {
+	var res []vmaas.ErrataResponseModule
+	index := map[string]int{} // position in res by "name:stream"
+	for _, pkg := range pkgs {
+		nevra, err := utils.ParseNevra(pkg)
+		if err != nil {
+			continue
+		}
+		for _, m := range modules[nevra.Name+"-"+nevra.EVRAString()] {
+			key := m.ModuleName + ":" + m.ModuleStream
+			i, ok := index[key]
+			if !ok {
+				i = len(res)
+				index[key] = i
+				res = append(res, m)
+			}
+			res[i].PackageList = append(res[i].PackageList, pkg)
+		}
+	}
+	return res
+}
+
+func loadUpdatesRepos(ctx context.Context) (map[string]vmaasdump.Repo, error) {
+	var rows []struct {
+		Name       string
+		ThirdParty bool
+	}
+	if err := database.Db.WithContext(ctx).Table("repo").Select("name, third_party").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	repos := make(map[string]vmaasdump.Repo, len(rows))
+	for _, row := range rows {
+		repos[row.Name] = vmaasdump.Repo{ThirdParty: row.ThirdParty}
+	}
+	return repos, nil
+}
+
+// Package data is list of nevras, older data has {"<name>": "<evra>"} format
+func parseAdvisoryPackages(packageData []byte) (models.AdvisoryPackageData, error) {
+	var pkgs models.AdvisoryPackageData
+	err := json.Unmarshal(packageData, &pkgs)
+	if err == nil {
+		return pkgs, nil
+	}
+	var nameEvras map[string]string
+	if json.Unmarshal(packageData, &nameEvras) != nil {
+		return nil, err
+	}
+	for name, evra := range nameEvras {
+		pkgs = append(pkgs, fmt.Sprintf("%s-%s", name, evra))
+	}
+	return pkgs, nil
+}
+
+func loadUpdatesIndex(ctx context.Context) (*vmaasdump.UpdatesIndex, error) {
+	tStart := time.Now()
+	errata, err := loadAdvisoriesErrata(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load advisories for local updates")
+	}
+	repos, err := loadUpdatesRepos(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load repos for local updates")
+	}
+	index := vmaasdump.NewUpdatesIndex(errata, repos)
+	utils.Log("advisories", len(errata), "repos", len(repos), "duration", utils.SinceStr(tStart, time.Millisecond)).
+		Info("Local updates index loaded")
+	return index, nil
+}
+
+// Rebuilds the index in background, evaluations use the previous index until the new one is swapped in
+func (e *localUpdatesEngine) refresh() {
+	defer atomic.StoreInt32(&e.reloading, 0)
+	defer utils.LogPanics(false)
+	index, err := loadUpdatesIndex(base.Context)
+	if err != nil {
+		utils.Log("err", err.Error()).Warn("Unable to refresh local updates index, using the previous one")
+		return
+	}
+	e.current.Store(&loadedUpdatesIndex{index: index, loaded: time.Now()})
+}
+
+// Returns the current index, outdated index is refreshed in background.
+// The first index is loaded by a single caller, other callers wait for it.
+func (e *localUpdatesEngine) getIndex(ctx context.Context) (*vmaasdump.UpdatesIndex, error) {
+	if current, _ := e.current.Load().(*loadedUpdatesIndex); current != nil {
+		if time.Since(current.loaded) >= localUpdatesRefresh && atomic.CompareAndSwapInt32(&e.reloading, 0, 1) {
+			go e.refresh()
+		}
+		return current.index, nil
+	}
+
+	e.loadLock.Lock()
+	defer e.loadLock.Unlock()
+	if current, _ := e.current.Load().(*loadedUpdatesIndex); current != nil {
+		return current.index, nil
+	}
+	index, err := loadUpdatesIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.current.Store(&loadedUpdatesIndex{index: index, loaded: time.Now()})
+	return index, nil
+}
+
+func (e *localUpdatesEngine) Updates(ctx context.Context, request *vmaas.UpdatesV3Request) (
+	*vmaas.UpdatesV2Response, error) {
+	tStart := time.Now()
+	defer utils.ObserveSecondsSince(tStart, evaluationPartDuration.WithLabelValues("local-updates"))
+	index, err := e.getIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updates, repoFilter := index.Updates(request)
+	localUpdatesRepoFilterCnt.WithLabelValues(string(repoFilter)).Inc()
+	return updates, nil
+}
+
+// Resolve updates with VMaaS and/or the local engine according to LOCAL_UPDATES_MODE
+func resolveUpdates(ctx context.Context, request *vmaas.UpdatesV3Request) (*vmaas.UpdatesV2Response, error) {
+	switch localUpdatesMode {
+	case localUpdatesPrimary:
+		localUpdatesCnt.WithLabelValues("primary").Inc()
+		return localUpdates.Updates(ctx, request)
+	case localUpdatesFallback:
+		vmaasData, err := callVMaas(ctx, request)
+		if err == nil {
+			return vmaasData, nil
+		}
+		localUpdatesCnt.WithLabelValues("fallback").Inc()
+		utils.Log("err", err.Error()).Warn("VMaaS call failed, resolving updates locally")
+		return localUpdates.Updates(ctx, request)
+	case localUpdatesShadow:
+		vmaasData, err := callVMaas(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		startCompareLocalUpdates(request, vmaasData)
+		return vmaasData, nil
+	}
+	return callVMaas(ctx, request)
+}
+
+// Start comparison with local updates in background so it doesn't delay the evaluation, comparisons over
+// the limit of running ones are skipped. VMaaS updates are listed before as the evaluation goes on with them.
+func startCompareLocalUpdates(request *vmaas.UpdatesV3Request, vmaasData *vmaas.UpdatesV2Response) {
+	select {
+	case localUpdatesCompareSlots <- struct{}{}:
+	default:
+		localUpdatesCnt.WithLabelValues("skipped").Inc()
+		return
+	}
+
+	vmaasUpdates := listUpdates(vmaasData)
+	compareRequest := *request
+	go func() {
+		defer func() { <-localUpdatesCompareSlots }()
+		defer utils.LogPanics(false)
+		ctx, cancel := context.WithTimeout(base.Context, localUpdatesCompareTimeout)
+		defer cancel()
+		compareLocalUpdates(ctx, &compareRequest, vmaasUpdates)
+	}()
+}
+
+func compareLocalUpdates(ctx context.Context, request *vmaas.UpdatesV3Request, vmaasUpdates map[string]bool) {
+	localData, err := localUpdates.Updates(ctx, request)
+	if err != nil {
+		localUpdatesCnt.WithLabelValues("error").Inc()
+		utils.Log("err", err.Error()).Error("Local updates resolution failed")
+		return
+	}
+
+	onlyVmaas, onlyLocal := diffSets(vmaasUpdates, listUpdates(localData))
+	if len(onlyVmaas) == 0 && len(onlyLocal) == 0 {
+		localUpdatesCnt.WithLabelValues("match").Inc()
+		return
+	}
+	localUpdatesCnt.WithLabelValues("mismatch").Inc()
+	localUpdatesDiffCnt.WithLabelValues("vmaas").Add(float64(len(onlyVmaas)))
+	localUpdatesDiffCnt.WithLabelValues("local").Add(float64(len(onlyLocal)))
+	utils.Log("only_vmaas", onlyVmaas, "only_local", onlyLocal).Debug("Local updates differ from VMaaS")
+}
+
+// Updates as "<installed nevra> <update nevra> <erratum>" strings
+func listUpdates(data *vmaas.UpdatesV2Response) map[string]bool {
+	updates := map[string]bool{}
+	for pkg, updateList := range data.GetUpdateList() {
+		for _, u := range updateList.GetAvailableUpdates() {
+			updates[fmt.Sprintf("%s %s %s", pkg, u.GetPackage(), u.GetErratum())] = true
+		}
+	}
+	return updates
+}
+
+// Returns sorted updates found only in the first and only in the second response
+func diffUpdates(a, b *vmaas.UpdatesV2Response) (onlyA, onlyB []string) {
+	return diffSets(listUpdates(a), listUpdates(b))
+}
+
+// Returns sorted items found only in the first and only in the second set
+func diffSets(a, b map[string]bool) (onlyA, onlyB []string) {
+	for item := range a {
+		if !b[item] {
+			onlyA = append(onlyA, item)
+		}
+	}
+	for item := range b {
+		if !a[item] {
+			onlyB = append(onlyB, item)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return onlyA, onlyB
+}
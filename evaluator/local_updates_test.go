@@ -0,0 +1,141 @@
+package evaluator
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"app/base/vmaas"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdvisoryPackages(t *testing.T) {
+	pkgs, err := parseAdvisoryPackages([]byte(`["firefox-77.0.1-1.fc31.x86_64"]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"firefox-77.0.1-1.fc31.x86_64"}, []string(pkgs))
+
+	pkgs, err = parseAdvisoryPackages([]byte(`{"firefox": "77.0.1-1.fc31.x86_64"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"firefox-77.0.1-1.fc31.x86_64"}, []string(pkgs))
+
+	_, err = parseAdvisoryPackages([]byte(`"invalid"`))
+	assert.NotNil(t, err)
+}
+
+func testUpdatesResponse(pkg string, updates ...string) *vmaas.UpdatesV2Response {
+	available := []vmaas.UpdatesV2ResponseAvailableUpdates{}
+	for i := range updates {
+		available = append(available, vmaas.UpdatesV2ResponseAvailableUpdates{
+			Package: &updates[i], Erratum: utils.PtrString("RH-1")})
+	}
+	updateList := map[string]vmaas.UpdatesV2ResponseUpdateList{pkg: {AvailableUpdates: &available}}
+	return &vmaas.UpdatesV2Response{UpdateList: &updateList}
+}
+
+func TestDiffUpdates(t *testing.T) {
+	a := testUpdatesResponse("kernel-1-1.x86_64", "kernel-2-1.x86_64", "kernel-3-1.x86_64")
+	b := testUpdatesResponse("kernel-1-1.x86_64", "kernel-3-1.x86_64", "kernel-4-1.x86_64")
+
+	onlyA, onlyB := diffUpdates(a, b)
+	assert.Equal(t, []string{"kernel-1-1.x86_64 kernel-2-1.x86_64 RH-1"}, onlyA)
+	assert.Equal(t, []string{"kernel-1-1.x86_64 kernel-4-1.x86_64 RH-1"}, onlyB)
+
+	onlyA, onlyB = diffUpdates(a, a)
+	assert.Nil(t, onlyA)
+	assert.Nil(t, onlyB)
+}
+
+func TestAdvisoryModules(t *testing.T) {
+	modules := map[string][]vmaas.ErrataResponseModule{
+		"nodejs-1:14.1-1.module+el8.x86_64": {{ModuleName: "nodejs", ModuleStream: "14"}},
+		"npm-1:6.14-1.module+el8.x86_64":    {{ModuleName: "nodejs", ModuleStream: "14"}},
+	}
+	res := advisoryModules([]string{"nodejs-1:14.1-1.module+el8.x86_64", "npm-1:6.14-1.module+el8.x86_64",
+		"kernel-5.1-1.el8.x86_64", "invalid"}, modules)
+	assert.Equal(t, []vmaas.ErrataResponseModule{{ModuleName: "nodejs", ModuleStream: "14",
+		PackageList: []string{"nodejs-1:14.1-1.module+el8.x86_64", "npm-1:6.14-1.module+el8.x86_64"}}}, res)
+	assert.Nil(t, advisoryModules([]string{"kernel-5.1-1.el8.x86_64"}, modules))
+}
+
+func TestLocalUpdates(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	req := vmaas.UpdatesV3Request{PackageList: []string{"firefox-76.0.1-1.fc31.x86_64"}}
+	resp, err := localUpdates.Updates(context.Background(), &req)
+	assert.Nil(t, err)
+	updateList := resp.GetUpdateList()["firefox-76.0.1-1.fc31.x86_64"]
+	updates := updateList.GetAvailableUpdates()
+	assert.Equal(t, 1, len(updates))
+	assert.Equal(t, "firefox-77.0.1-1.fc31.x86_64", updates[0].GetPackage())
+	assert.Equal(t, "RH-9", updates[0].GetErratum())
+
+	// systems with repositories unknown to patch get no updates
+	req.RepositoryList = &[]string{"unknown-repo"}
+	resp, err = localUpdates.Updates(context.Background(), &req)
+	assert.Nil(t, err)
+	updateList = resp.GetUpdateList()["firefox-76.0.1-1.fc31.x86_64"]
+	assert.Equal(t, 0, len(updateList.GetAvailableUpdates()))
+}
+
+func TestLocalUpdatesPrimary(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+	localUpdatesMode = localUpdatesPrimary
+	defer func() { localUpdatesMode = "" }()
+
+	req := vmaas.UpdatesV3Request{PackageList: []string{"firefox-76.0.1-1.fc31.x86_64"}}
+	resp, err := resolveUpdates(context.Background(), &req)
+	assert.Nil(t, err)
+	updateList := resp.GetUpdateList()["firefox-76.0.1-1.fc31.x86_64"]
+	updates := updateList.GetAvailableUpdates()
+	assert.Equal(t, 1, len(updates))
+	assert.Equal(t, "firefox-77.0.1-1.fc31.x86_64", updates[0].GetPackage())
+	assert.Equal(t, "RH-9", updates[0].GetErratum())
+}
+
+func TestLocalUpdatesFallback(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+	localUpdatesMode = localUpdatesFallback
+	defer func() { localUpdatesMode = "" }()
+	vmaasURL := vmaasUpdatesURL
+	defer func() { vmaasUpdatesURL = vmaasURL }()
+
+	// VMaaS is not available, updates are resolved locally
+	vmaasUpdatesURL = "http://localhost:1/api/v3/updates"
+	req := vmaas.UpdatesV3Request{PackageList: []string{"firefox-76.0.1-1.fc31.x86_64"}}
+	resp, err := resolveUpdates(context.Background(), &req)
+	assert.Nil(t, err)
+	updateList := resp.GetUpdateList()["firefox-76.0.1-1.fc31.x86_64"]
+	assert.Equal(t, 1, len(updateList.GetAvailableUpdates()))
+}
+
+func TestStartCompareLocalUpdatesSkipped(t *testing.T) {
+	localUpdatesCompareSlots = make(chan struct{}, 1)
+	localUpdatesCompareSlots <- struct{}{}
+	defer func() { localUpdatesCompareSlots = nil }()
+
+	// no slot is free so comparison is skipped without blocking
+	startCompareLocalUpdates(&vmaas.UpdatesV3Request{}, testUpdatesResponse("kernel-1-1.x86_64"))
+	assert.Equal(t, 1, len(localUpdatesCompareSlots))
+}
+
+func TestConfigureLocalUpdatesMode(t *testing.T) {
+	defer os.Unsetenv("LOCAL_UPDATES_MODE")
+	defer func() { localUpdatesMode = "" }()
+
+	for _, mode := range []string{"Primary", "fallback", "shadow", ""} {
+		os.Setenv("LOCAL_UPDATES_MODE", mode)
+		configureLocalUpdates()
+		assert.Equal(t, strings.ToLower(mode), localUpdatesMode)
+	}
+	os.Setenv("LOCAL_UPDATES_MODE", "other")
+	assert.Panics(t, configureLocalUpdates)
+}
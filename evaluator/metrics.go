@@ -54,11 +54,47 @@ var (
 		Name:      "two_evaluations_interval_hours",
 		Buckets:   []float64{1, 2, 6, 24, 72, 168},
 	})
+
+	localUpdatesCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Help:      "How many updates were resolved by local engine with which result",
+		Namespace: "patchman_engine",
+		Subsystem: "evaluator",
+		Name:      "local_updates",
+	}, []string{"type"})
+
+	localUpdatesDiffCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Help:      "How many updates were found only by VMaaS or only by local engine in shadow mode",
+		Namespace: "patchman_engine",
+		Subsystem: "evaluator",
+		Name:      "local_updates_diff",
+	}, []string{"source"})
+
+	localUpdatesRepoFilterCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Help:      "How many updates requests resolved locally were limited by the system repositories in which way",
+		Namespace: "patchman_engine",
+		Subsystem: "evaluator",
+		Name:      "local_updates_repo_filter",
+	}, []string{"filter"})
+
+	shadowEvalCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Help:      "How many systems were shadow evaluated with which result",
+		Namespace: "patchman_engine",
+		Subsystem: "evaluator",
+		Name:      "shadow_evaluation",
+	}, []string{"type"})
+
+	shadowEvalDiffCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Help:      "How many advisories or packages were found only by primary or only by shadow evaluation",
+		Namespace: "patchman_engine",
+		Subsystem: "evaluator",
+		Name:      "shadow_evaluation_diff",
+	}, []string{"kind", "source"})
 )
 
 func RunMetrics() {
 	prometheus.MustRegister(evaluationCnt, updatesCnt, evaluationDuration, evaluationPartDuration,
-		uploadEvaluationDelay, twoEvaluationsInterval)
+		uploadEvaluationDelay, twoEvaluationsInterval, localUpdatesCnt, localUpdatesDiffCnt,
+		localUpdatesRepoFilterCnt, shadowEvalCnt, shadowEvalDiffCnt)
 
 	// create web app
 	app := gin.New()
@@ -0,0 +1,164 @@
+package evaluator
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Shadow evaluation source resolving updates with the local engine, other values are VMaaS addresses
+const shadowSourceLocal = "local"
+
+var (
+	shadowEvalSource        string
+	shadowEvalSamplePercent int
+	shadowUpdatesURL        string
+	shadowEvalTimeout       time.Duration
+	shadowEvalSlots         chan struct{} // limits number of shadow evaluations running in background
+)
+
+func configureShadowEval() {
+	shadowEvalSource = utils.Getenv("SHADOW_EVAL_SOURCE", "")
+	shadowEvalSamplePercent = utils.GetIntEnvOrDefault("SHADOW_EVAL_SAMPLE_PERCENT", 0)
+	shadowEvalTimeout = time.Duration(utils.GetIntEnvOrDefault("SHADOW_EVAL_TIMEOUT_SEC", 30)) * time.Second
+	shadowEvalSlots = make(chan struct{}, utils.GetIntEnvOrDefault("SHADOW_EVAL_MAX_RUNNING", 4))
+	if shadowEvalSource != "" && shadowEvalSource != shadowSourceLocal {
+		shadowUpdatesURL = shadowEvalSource + base.VMaaSAPIPrefix + "/updates"
+	}
+}
+
+func isShadowEvalSampled() bool {
+	if shadowEvalSource == "" || shadowEvalSamplePercent <= 0 {
+		return false
+	}
+	return rand.Intn(100) < shadowEvalSamplePercent // nolint: gosec
+}
+
+// Advisories and update packages of the response
+func listAdvisoriesPackages(data *vmaas.UpdatesV2Response) (advisories, packages map[string]bool) {
+	advisories, packages = map[string]bool{}, map[string]bool{}
+	for _, updateList := range data.GetUpdateList() {
+		for _, u := range updateList.GetAvailableUpdates() {
+			if u.GetErratum() != "" {
+				advisories[u.GetErratum()] = true
+			}
+			packages[u.GetPackage()] = true
+		}
+	}
+	return advisories, packages
+}
+
+func getShadowUpdates(ctx context.Context, request *vmaas.UpdatesV3Request) (*vmaas.UpdatesV2Response, error) {
+	if shadowEvalSource == shadowSourceLocal {
+		return localUpdates.Updates(ctx, request)
+	}
+	shadowData := vmaas.UpdatesV2Response{}
+	_, err := vmaasClient.Request(&ctx, http.MethodPost, shadowUpdatesURL, request, &shadowData) // nolint: bodyclose
+	if err != nil {
+		return nil, errors.Wrap(err, "shadow /updates call failed")
+	}
+	return &shadowData, nil
+}
+
+func newShadowEvalDiff(system *models.SystemPlatform, primaryAdvisories, primaryPackages map[string]bool,
+	shadowData *vmaas.UpdatesV2Response) (*models.ShadowEvalDiff, error) {
+	shadowAdvisories, shadowPackages := listAdvisoriesPackages(shadowData)
+	advisoriesOnlyPrimary, advisoriesOnlyShadow := diffSets(primaryAdvisories, shadowAdvisories)
+	packagesOnlyPrimary, packagesOnlyShadow := diffSets(primaryPackages, shadowPackages)
+
+	shadowEvalDiffCnt.WithLabelValues("advisory", "primary").Add(float64(len(advisoriesOnlyPrimary)))
+	shadowEvalDiffCnt.WithLabelValues("advisory", "shadow").Add(float64(len(advisoriesOnlyShadow)))
+	shadowEvalDiffCnt.WithLabelValues("package", "primary").Add(float64(len(packagesOnlyPrimary)))
+	shadowEvalDiffCnt.WithLabelValues("package", "shadow").Add(float64(len(packagesOnlyShadow)))
+	if len(advisoriesOnlyPrimary)+len(advisoriesOnlyShadow)+len(packagesOnlyPrimary)+len(packagesOnlyShadow) == 0 {
+		return nil, nil
+	}
+
+	diff := models.ShadowEvalDiff{
+		RhAccountID: system.RhAccountID,
+		InventoryID: system.InventoryID,
+		Created:     time.Now(),
+		Source:      shadowEvalSource,
+	}
+	var err error
+	lists := []struct {
+		items []string
+		dest  *[]byte
+	}{
+		{advisoriesOnlyPrimary, &diff.AdvisoriesOnlyPrimary},
+		{advisoriesOnlyShadow, &diff.AdvisoriesOnlyShadow},
+		{packagesOnlyPrimary, &diff.PackagesOnlyPrimary},
+		{packagesOnlyShadow, &diff.PackagesOnlyShadow},
+	}
+	for _, l := range lists {
+		if l.items == nil {
+			l.items = []string{}
+		}
+		if *l.dest, err = json.Marshal(l.items); err != nil {
+			return nil, err
+		}
+	}
+	return &diff, nil
+}
+
+// Start shadow evaluation in background so it doesn't delay the evaluation, evaluations over the limit
+// of running ones are skipped. Primary updates are listed before as the evaluation goes on with them.
+func startShadowEvaluate(system *models.SystemPlatform, request *vmaas.UpdatesV3Request,
+	primaryData *vmaas.UpdatesV2Response) {
+	select {
+	case shadowEvalSlots <- struct{}{}:
+	default:
+		shadowEvalCnt.WithLabelValues("skipped").Inc()
+		return
+	}
+
+	primaryAdvisories, primaryPackages := listAdvisoriesPackages(primaryData)
+	shadowSystem := models.SystemPlatform{RhAccountID: system.RhAccountID, InventoryID: system.InventoryID}
+	shadowRequest := *request
+	go func() {
+		defer func() { <-shadowEvalSlots }()
+		defer utils.LogPanics(false)
+		ctx, cancel := context.WithTimeout(base.Context, shadowEvalTimeout)
+		defer cancel()
+		shadowEvaluate(ctx, &shadowSystem, &shadowRequest, primaryAdvisories, primaryPackages)
+	}()
+}
+
+// Evaluate the system against shadow source and store differences, evaluation results are not persisted
+func shadowEvaluate(ctx context.Context, system *models.SystemPlatform, request *vmaas.UpdatesV3Request,
+	primaryAdvisories, primaryPackages map[string]bool) {
+	tStart := time.Now()
+	defer utils.ObserveSecondsSince(tStart, evaluationPartDuration.WithLabelValues("shadow-evaluation"))
+
+	shadowData, err := getShadowUpdates(ctx, request)
+	if err != nil {
+		shadowEvalCnt.WithLabelValues("error").Inc()
+		utils.Log("err", err.Error(), "inventoryID", system.InventoryID).Error("Shadow evaluation failed")
+		return
+	}
+
+	diff, err := newShadowEvalDiff(system, primaryAdvisories, primaryPackages, shadowData)
+	if err != nil {
+		shadowEvalCnt.WithLabelValues("error").Inc()
+		utils.Log("err", err.Error(), "inventoryID", system.InventoryID).Error("Shadow evaluation diff failed")
+		return
+	}
+	if diff == nil {
+		shadowEvalCnt.WithLabelValues("match").Inc()
+		return
+	}
+
+	shadowEvalCnt.WithLabelValues("mismatch").Inc()
+	if err = database.Db.WithContext(ctx).Create(diff).Error; err != nil {
+		utils.Log("err", err.Error(), "inventoryID", system.InventoryID).Error("Unable to store shadow evaluation diff")
+	}
+}
@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShadowEvalDiff(t *testing.T) {
+	system := models.SystemPlatform{RhAccountID: 1, InventoryID: "00000000-0000-0000-0000-000000000001"}
+	a := testUpdatesResponse("kernel-1-1.x86_64", "kernel-2-1.x86_64")
+	b := testUpdatesResponse("kernel-1-1.x86_64", "kernel-3-1.x86_64")
+
+	aAdvisories, aPackages := listAdvisoriesPackages(a)
+	diff, err := newShadowEvalDiff(&system, aAdvisories, aPackages, a)
+	assert.Nil(t, err)
+	assert.Nil(t, diff)
+
+	diff, err = newShadowEvalDiff(&system, aAdvisories, aPackages, b)
+	assert.Nil(t, err)
+	assert.Equal(t, system.InventoryID, diff.InventoryID)
+	assert.Equal(t, `[]`, string(diff.AdvisoriesOnlyPrimary))
+	assert.Equal(t, `[]`, string(diff.AdvisoriesOnlyShadow))
+	assert.Equal(t, `["kernel-2-1.x86_64"]`, string(diff.PackagesOnlyPrimary))
+	assert.Equal(t, `["kernel-3-1.x86_64"]`, string(diff.PackagesOnlyShadow))
+}
+
+func TestShadowEvaluateLocal(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+	shadowEvalSource = shadowSourceLocal
+	defer func() { shadowEvalSource = "" }()
+
+	system := models.SystemPlatform{RhAccountID: 1, InventoryID: "00000000-0000-0000-0000-000000000001"}
+	req := vmaas.UpdatesV3Request{PackageList: []string{"firefox-76.0.1-1.fc31.x86_64"}}
+	primaryAdvisories, primaryPackages := listAdvisoriesPackages(testUpdatesResponse("firefox-76.0.1-1.fc31.x86_64"))
+	shadowEvaluate(context.Background(), &system, &req, primaryAdvisories, primaryPackages)
+
+	var diffs []models.ShadowEvalDiff
+	assert.Nil(t, database.Db.Where("inventory_id = ?", system.InventoryID).Find(&diffs).Error)
+	assert.Equal(t, 1, len(diffs))
+	assert.Equal(t, shadowSourceLocal, diffs[0].Source)
+	assert.Equal(t, `["RH-9"]`, string(diffs[0].AdvisoriesOnlyShadow))
+	assert.Equal(t, `["firefox-77.0.1-1.fc31.x86_64"]`, string(diffs[0].PackagesOnlyShadow))
+	assert.Nil(t, database.Db.Where("inventory_id = ?", system.InventoryID).Delete(&models.ShadowEvalDiff{}).Error)
+}
+
+func TestStartShadowEvaluateSkipped(t *testing.T) {
+	slots := shadowEvalSlots
+	defer func() { shadowEvalSlots = slots }()
+	shadowEvalSlots = make(chan struct{}, 1)
+	shadowEvalSlots <- struct{}{}
+
+	system := models.SystemPlatform{RhAccountID: 1, InventoryID: "00000000-0000-0000-0000-000000000001"}
+	// no slot for the shadow evaluation so it's not started
+	startShadowEvaluate(&system, &vmaas.UpdatesV3Request{}, testUpdatesResponse("kernel-1-1.x86_64"))
+	assert.Equal(t, 1, len(shadowEvalSlots))
+}
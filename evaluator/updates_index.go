@@ -0,0 +1,29 @@
+package evaluator
+
+import (
+	"app/base/utils"
+	"app/base/vmaas"
+	"app/base/vmaasdump"
+)
+
+// Index of VMaaS data files answering /updates locally in disconnected deployments
+var updatesIndex *vmaasdump.UpdatesIndex
+
+func configureUpdatesIndex(dumpPath string) {
+	if dumpPath == "" {
+		return
+	}
+	dump, err := vmaasdump.Load(dumpPath)
+	if err != nil {
+		panic(err)
+	}
+	updatesIndex = vmaasdump.NewUpdatesIndex(dump.Errata, dump.UpdatesRepos())
+	utils.Log("path", dumpPath).Info("Updates are resolved from vmaas dump")
+}
+
+func getIndexUpdates(request *vmaas.UpdatesV3Request) *vmaas.UpdatesV2Response {
+	utils.Log("request", *request).Trace("local /updates request")
+	updates, repoFilter := updatesIndex.Updates(request)
+	localUpdatesRepoFilterCnt.WithLabelValues(string(repoFilter)).Inc()
+	return updates
+}
@@ -38,6 +38,7 @@ require (
 	golang.org/x/net v0.0.0-20211104170005-ce137452f963
 	golang.org/x/tools v0.0.0-20200825202427-b303f430e36d // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
+	gopkg.in/go-jose/go-jose.v2 v2.6.3
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gorm.io/driver/postgres v1.3.1
 	gorm.io/gorm v1.23.1
@@ -0,0 +1,82 @@
+package listener
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Keeps the system in a baseline whose selector it matches. The system leaves its selector baseline
+// when it stops matching and joins the first matching one when it has no baseline.
+// Systems of baselines without selector are not changed. Returns whether the system baseline was changed.
+func updateSelectorBaseline(tx *gorm.DB, system *models.SystemPlatform, facts *database.SelectorFacts) (bool, error) {
+	var baselines []models.Baseline
+	err := tx.Select("id, selector").
+		Where("rh_account_id = ? AND selector IS NOT NULL", system.RhAccountID).
+		Order("id").
+		Find(&baselines).Error
+	if err != nil || len(baselines) == 0 {
+		return false, err
+	}
+
+	var current []*int
+	err = tx.Model(&models.SystemPlatform{}).
+		Where("rh_account_id = ? AND id = ?", system.RhAccountID, system.ID).
+		Pluck("baseline_id", &current).Error
+	if err != nil || len(current) == 0 {
+		return false, err
+	}
+	currentID := current[0]
+
+	var matching []int
+	isCurrentSelector := false
+	for _, baseline := range baselines {
+		selector, err := database.ParseBaselineSelector(baseline.Selector)
+		if err != nil {
+			utils.Log("baseline_id", baseline.ID, "err", err.Error()).Error("Unable to parse baseline selector")
+			continue
+		}
+		if currentID != nil && *currentID == baseline.ID {
+			isCurrentSelector = true
+		}
+		if selector.Matches(facts) {
+			matching = append(matching, baseline.ID)
+		}
+	}
+
+	if currentID != nil && !isCurrentSelector {
+		return false, nil // static baseline membership
+	}
+	var newID *int
+	for _, id := range matching {
+		id := id
+		if currentID != nil && *currentID == id {
+			return false, nil // system still matches its baseline
+		}
+		if newID == nil {
+			newID = &id
+		}
+	}
+	if currentID == nil && newID == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	err = tx.Model(&models.SystemPlatform{}).
+		Where("rh_account_id = ? AND id = ?", system.RhAccountID, system.ID).
+		Updates(map[string]interface{}{"baseline_id": newID, "unchanged_since": now,
+			"baseline_uptodate": nil, "baseline_outdated_since": nil}).Error
+	if err != nil {
+		return false, err
+	}
+	system.BaselineID = newID
+	system.UnchangedSince = &now
+	system.BaselineUpToDate = nil
+	system.BaselineOutdatedSince = nil
+	utils.Log("inventoryID", system.InventoryID, "oldBaselineID", currentID, "newBaselineID", newID).
+		Info("System baseline updated by selector")
+	return true, nil
+}
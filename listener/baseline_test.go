@@ -0,0 +1,48 @@
+package listener
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateSelectorBaseline(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+
+	baseline := models.Baseline{RhAccountID: 1, Name: "selector_baseline",
+		Selector: []byte(`{"display_name": "00000000-0000-0000-0000-00000000000*", "os_major": 8}`)}
+	assert.Nil(t, database.Db.Create(&baseline).Error)
+
+	// system without baseline joins matching baseline
+	system := models.SystemPlatform{ID: 5, RhAccountID: 1, InventoryID: "00000000-0000-0000-0000-000000000005"}
+	facts := database.SelectorFacts{DisplayName: "00000000-0000-0000-0000-000000000005", OSMajor: 8}
+	changed, err := updateSelectorBaseline(database.Db, &system, &facts)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, baseline.ID, *system.BaselineID)
+
+	changed, err = updateSelectorBaseline(database.Db, &system, &facts)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	// system leaves the baseline when it stops matching
+	facts.OSMajor = 7
+	changed, err = updateSelectorBaseline(database.Db, &system, &facts)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Nil(t, system.BaselineID)
+
+	// system of baseline without selector is kept
+	system = models.SystemPlatform{ID: 1, RhAccountID: 1, InventoryID: "00000000-0000-0000-0000-000000000001"}
+	facts = database.SelectorFacts{DisplayName: "00000000-0000-0000-0000-000000000001", OSMajor: 8}
+	changed, err = updateSelectorBaseline(database.Db, &system, &facts)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	database.DeleteBaseline(t, baseline.ID)
+}
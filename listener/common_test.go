@@ -32,6 +32,9 @@ func deleteData(t *testing.T) {
 		Delete(&models.SystemRepo{}).Error)
 	assert.Nil(t, database.Db.Unscoped().Where("name NOT IN ('repo1', 'repo2', 'repo3', 'repo4')").
 		Delete(&models.Repo{}).Error)
+	assert.Nil(t, database.Db.Unscoped().Where("module_id >= 100 OR system_id NOT IN (2, 3, 12)").
+		Delete(&models.SystemModule{}).Error)
+	assert.Nil(t, database.Db.Unscoped().Where("id >= 100").Delete(&models.Module{}).Error)
 	assert.Nil(t, database.Db.Unscoped().Where("inventory_id = ?::uuid", id).Delete(&models.SystemPlatform{}).Error)
 	assert.Nil(t, database.Db.Unscoped().Where("name = ?", id).Delete(&models.RhAccount{}).Error)
 }
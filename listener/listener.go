@@ -22,6 +22,8 @@ var (
 	excludedHostTypes map[string]bool
 	enableBypass      bool
 	uploadEvalTimeout time.Duration
+	// assign systems to baselines by selectors on upload
+	enableBaselineSelectors bool
 )
 
 func configure() {
@@ -41,6 +43,7 @@ func configure() {
 	excludedHostTypes = getEnvVarStringsSet("EXCLUDED_HOST_TYPES")
 
 	enableBypass = utils.GetBoolEnvOrDefault("ENABLE_BYPASS", false)
+	enableBaselineSelectors = utils.GetBoolEnvOrDefault("ENABLE_BASELINE_SELECTORS", true)
 
 	uploadEvalTimeout = time.Duration(utils.GetIntEnvOrDefault("UPLOAD_EVAL_TIMEOUT_MS", 500)) * time.Millisecond
 }
@@ -48,6 +48,13 @@ var (
 		Name:      "repos_added",
 	})
 
+	modulesAddedCnt = prometheus.NewCounter(prometheus.CounterOpts{
+		Help:      "How many dnf module streams were added",
+		Namespace: "patchman_engine",
+		Subsystem: "listener",
+		Name:      "modules_added",
+	})
+
 	receivedFromReporter = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Help:      "How many messages were received from which reporter",
 		Namespace: "patchman_engine",
@@ -64,8 +71,8 @@ var (
 )
 
 func RunMetrics() {
-	prometheus.MustRegister(messagesReceivedCnt, messageHandlingDuration, reposAddedCnt, receivedFromReporter,
-		messagePartDuration)
+	prometheus.MustRegister(messagesReceivedCnt, messageHandlingDuration, reposAddedCnt, modulesAddedCnt,
+		receivedFromReporter, messagePartDuration)
 
 	// create web app
 	app := gin.New()
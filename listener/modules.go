@@ -0,0 +1,138 @@
+package listener
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Stores enabled dnf module streams of the system, modules which are no longer enabled are removed
+func updateModules(tx *gorm.DB, rhAccountID int, systemID int, modules []vmaas.UpdatesV3RequestModulesList) (
+	addedModules int64, addedSysModules int64, deletedSysModules int64, err error) {
+	tStart := time.Now()
+	defer utils.ObserveSecondsSince(tStart, messagePartDuration.WithLabelValues("update-modules"))
+	moduleIDs, addedModules, err := ensureModulesInDB(tx, modules)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	addedSysModules, deletedSysModules, err = updateSystemModules(tx, rhAccountID, systemID, moduleIDs)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return addedModules, addedSysModules, deletedSysModules, nil
+}
+
+type moduleKey struct {
+	name   string
+	stream string
+}
+
+func ensureModulesInDB(tx *gorm.DB, modules []vmaas.UpdatesV3RequestModulesList) (
+	moduleIDs []int64, added int64, err error) {
+	keys := make([]moduleKey, 0, len(modules))
+	names := make([]string, 0, len(modules))
+	seen := map[moduleKey]bool{}
+	for _, m := range modules {
+		key := moduleKey{m.ModuleName, m.ModuleStream}
+		if len(strings.TrimSpace(key.name)) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+		names = append(names, key.name)
+	}
+	if len(keys) == 0 {
+		return moduleIDs, 0, nil
+	}
+	moduleIDs = make([]int64, 0, len(keys))
+
+	var existingModules models.ModuleSlice
+	err = tx.Model(&models.Module{}).Where("name IN (?)", names).Find(&existingModules).Error
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "unable to load modules")
+	}
+
+	inDBIDs := make(map[moduleKey]int64)
+	for _, em := range existingModules {
+		inDBIDs[moduleKey{em.Name, em.Stream}] = em.ID
+	}
+
+	toStore := make(models.ModuleSlice, 0, len(keys))
+	for _, key := range keys {
+		if id, has := inDBIDs[key]; has {
+			moduleIDs = append(moduleIDs, id)
+		} else {
+			toStore = append(toStore, models.Module{Name: key.name, Stream: key.stream})
+		}
+	}
+
+	if len(toStore) > 0 {
+		txOnConflict := tx.Clauses(clause.OnConflict{
+			DoNothing: true,
+		})
+		err = txOnConflict.Create(&toStore).Error
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "unable to update modules")
+		}
+		added = txOnConflict.RowsAffected
+		for _, module := range toStore {
+			moduleIDs = append(moduleIDs, module.ID)
+		}
+	}
+	modulesAddedCnt.Add(float64(added))
+
+	return moduleIDs, added, nil
+}
+
+func updateSystemModules(tx *gorm.DB, rhAccountID int, systemID int, moduleIDs []int64) (
+	nAdded int64, nDeleted int64, err error) {
+	systemModuleObjs := make(models.SystemModuleSlice, len(moduleIDs))
+	for i, moduleID := range moduleIDs {
+		systemModuleObjs[i] = models.SystemModule{RhAccountID: int64(rhAccountID), SystemID: int64(systemID),
+			ModuleID: moduleID}
+	}
+
+	txOnConflict := tx.Clauses(clause.OnConflict{
+		DoNothing: true,
+	})
+	err = database.BulkInsert(txOnConflict, systemModuleObjs)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "unable to update system modules")
+	}
+	nAdded = txOnConflict.RowsAffected
+
+	nDeleted, err = deleteOtherSystemModules(tx, rhAccountID, systemID, moduleIDs)
+	if err != nil {
+		return nAdded, 0, errors.Wrap(err, "unable to delete out-of-date system modules")
+	}
+
+	return nAdded, nDeleted, nil
+}
+
+func deleteOtherSystemModules(tx *gorm.DB, rhAccountID int, systemID int, moduleIDs []int64) (
+	nDeleted int64, err error) {
+	type result struct{ DeletedCount int64 }
+	var res result
+	if len(moduleIDs) > 0 {
+		err = tx.Raw("WITH deleted AS "+ // to count deleted items
+			"(DELETE FROM system_module WHERE rh_account_id = ? AND system_id = ? AND module_id NOT IN (?) "+
+			"RETURNING module_id) SELECT count(*) AS deleted_count FROM deleted",
+			rhAccountID, systemID, moduleIDs).Scan(&res).Error
+	} else {
+		err = tx.Raw("WITH deleted AS "+
+			"(DELETE FROM system_module WHERE rh_account_id = ? AND system_id = ? RETURNING module_id) "+
+			"SELECT count(*) AS deleted_count FROM deleted", rhAccountID, systemID).Scan(&res).Error
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
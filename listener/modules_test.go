@@ -0,0 +1,60 @@
+package listener
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureModulesInDB(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	deleteData(t)
+
+	modules := []vmaas.UpdatesV3RequestModulesList{
+		{ModuleName: "nodejs", ModuleStream: "12"},
+		{ModuleName: "nodejs", ModuleStream: "14"},
+		{ModuleName: "nodejs", ModuleStream: "14"},
+		{ModuleName: "", ModuleStream: "1"},
+	}
+	moduleIDs, nAdded, err := ensureModulesInDB(database.Db, modules)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), nAdded)
+	assert.Equal(t, 2, len(moduleIDs))
+
+	var cnt int64
+	assert.Nil(t, database.Db.Model(&models.Module{}).Where("name = 'nodejs'").Count(&cnt).Error)
+	assert.Equal(t, int64(2), cnt)
+	deleteData(t)
+}
+
+func TestUpdateSystemModules(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	deleteData(t)
+
+	systemID := 5
+	rhAccountID := 1
+	database.Db.Create(models.SystemModule{RhAccountID: int64(rhAccountID), SystemID: int64(systemID), ModuleID: 1})
+	database.Db.Create(models.SystemModule{RhAccountID: int64(rhAccountID), SystemID: int64(systemID), ModuleID: 2})
+
+	modules := []vmaas.UpdatesV3RequestModulesList{
+		{ModuleName: "nodejs", ModuleStream: "12"},
+		{ModuleName: "perl", ModuleStream: "5.26"},
+	}
+	nModulesAdded, nAdded, nDeleted, err := updateModules(database.Db, rhAccountID, systemID, modules)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), nModulesAdded)
+	assert.Equal(t, int64(1), nAdded)
+	assert.Equal(t, int64(1), nDeleted)
+
+	_, _, nDeleted, err = updateModules(database.Db, rhAccountID, systemID, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), nDeleted)
+	deleteData(t)
+}
@@ -0,0 +1,66 @@
+package listener
+
+import (
+	"app/base/inventory"
+	"app/base/models"
+	"app/base/utils"
+	"fmt"
+	"time"
+)
+
+const kernelPackage = "kernel"
+
+var profileFactsCols = []string{
+	"running_kernel",
+	"installed_kernel",
+	"last_boot",
+	"releasever",
+	"infrastructure_type",
+	"cloud_provider",
+	"sap_system",
+	"ansible",
+}
+
+// Returns the newest installed kernel in `uname -r` format (version-release.arch), nil if there is none
+func latestInstalledKernel(packages []string) *string {
+	var latest *utils.Nevra
+	for _, pkg := range packages {
+		nevra, err := utils.ParseNevra(pkg)
+		if err != nil || nevra.Name != kernelPackage {
+			continue
+		}
+		if latest == nil || nevra.EVRACmp(latest) > 0 {
+			latest = nevra
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	kernel := fmt.Sprintf("%s-%s.%s", latest.Version, latest.Release, latest.Arch)
+	return &kernel
+}
+
+func parseLastBoot(lastBootTime *string) *time.Time {
+	if lastBootTime == nil || *lastBootTime == "" {
+		return nil
+	}
+	lastBoot, err := time.Parse(time.RFC3339, *lastBootTime)
+	if err != nil {
+		utils.Log("last_boot_time", *lastBootTime).Warn("Unable to parse last boot time")
+		return nil
+	}
+	return &lastBoot
+}
+
+// Fills system profile facts used for systems filtering
+func setProfileFacts(system *models.SystemPlatform, profile *inventory.SystemProfile) {
+	releasever := profile.Rhsm.Version
+	system.RunningKernel = utils.EmptyToNil(profile.OSKernelVersion)
+	system.InstalledKernel = latestInstalledKernel(profile.GetInstalledPackages())
+	system.LastBoot = parseLastBoot(profile.LastBootTime)
+	system.Releasever = utils.EmptyToNil(&releasever)
+	system.InfrastructureType = utils.EmptyToNil(profile.InfrastructureType)
+	system.CloudProvider = utils.EmptyToNil(profile.CloudProvider)
+	system.SapSystem = profile.SapSystem
+	system.Ansible = profile.Ansible != nil
+}
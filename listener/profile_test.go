@@ -0,0 +1,58 @@
+package listener
+
+import (
+	"app/base/inventory"
+	"app/base/models"
+	"app/base/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestInstalledKernel(t *testing.T) {
+	kernel := latestInstalledKernel([]string{
+		"kernel-4.18.0-305.el8.x86_64",
+		"kernel-headers-4.18.0-372.el8.x86_64",
+		"kernel-4.18.0-348.el8.x86_64",
+		"firefox-77.0.1-1.fc31.x86_64",
+		"invalid",
+	})
+	assert.Equal(t, "4.18.0-348.el8.x86_64", *kernel)
+	assert.Nil(t, latestInstalledKernel([]string{"firefox-77.0.1-1.fc31.x86_64"}))
+}
+
+func TestSetProfileFacts(t *testing.T) {
+	profile := inventory.SystemProfile{
+		InstalledPackages:  &[]string{"kernel-4.18.0-348.el8.x86_64"},
+		Rhsm:               inventory.Rhsm{Version: "8.4"},
+		OSKernelVersion:    utils.PtrString("4.18.0-305.el8.x86_64"),
+		LastBootTime:       utils.PtrString("2021-09-20T12:00:00+00:00"),
+		SapSystem:          true,
+		Ansible:            &inventory.Ansible{ControllerVersion: "1.0"},
+		InfrastructureType: utils.PtrString("virtual"),
+		CloudProvider:      utils.PtrString(""),
+	}
+	var system models.SystemPlatform
+	setProfileFacts(&system, &profile)
+	assert.Equal(t, "4.18.0-305.el8.x86_64", *system.RunningKernel)
+	assert.Equal(t, "4.18.0-348.el8.x86_64", *system.InstalledKernel)
+	assert.Equal(t, time.Date(2021, 9, 20, 12, 0, 0, 0, time.UTC), system.LastBoot.UTC())
+	assert.Equal(t, "8.4", *system.Releasever)
+	assert.Equal(t, "virtual", *system.InfrastructureType)
+	assert.Nil(t, system.CloudProvider)
+	assert.True(t, system.SapSystem)
+	assert.True(t, system.Ansible)
+}
+
+func TestSetProfileFactsEmpty(t *testing.T) {
+	profile := inventory.SystemProfile{LastBootTime: utils.PtrString("yesterday")}
+	var system models.SystemPlatform
+	setProfileFacts(&system, &profile)
+	assert.Nil(t, system.RunningKernel)
+	assert.Nil(t, system.InstalledKernel)
+	assert.Nil(t, system.LastBoot)
+	assert.Nil(t, system.Releasever)
+	assert.False(t, system.SapSystem)
+	assert.False(t, system.Ansible)
+}
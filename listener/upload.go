@@ -50,6 +50,7 @@ type Host struct {
 	CulledTimestamp       *types.Rfc3339Timestamp `json:"culled_timestamp,omitempty"`
 	Reporter              string                  `json:"reporter,omitempty"`
 	SystemProfile         inventory.SystemProfile `json:"system_profile,omitempty"`
+	Tags                  []database.ScopeTag     `json:"tags,omitempty"`
 }
 
 type HostMetadata struct {
@@ -276,6 +277,8 @@ func updateSystemPlatform(tx *gorm.DB, inventoryID string, accountID int, host *
 		ReporterID:            getReporterID(host.Reporter),
 		YumUpdates:            yumUpdates,
 	}
+	setProfileFacts(&systemPlatform, &host.SystemProfile)
+	colsToUpdate = append(colsToUpdate, profileFactsCols...)
 
 	var oldChecksums map[string]string
 	// Lock the row for update & return checksum
@@ -287,6 +290,7 @@ func updateSystemPlatform(tx *gorm.DB, inventoryID string, accountID int, host *
 
 	shouldUpdateRepos := false
 	var addedRepos, addedSysRepos, deletedSysRepos int64
+	var addedModules, addedSysModules, deletedSysModules int64
 
 	// Skip updating vmaas_json if the checksum haven't changed. Should reduce TOAST trashing
 	if oldChecksums["json_checksum"] != jsonChecksum {
@@ -308,8 +312,21 @@ func updateSystemPlatform(tx *gorm.DB, inventoryID string, accountID int, host *
 		return nil, errors.Wrap(err, "Unable to save or update system in database")
 	}
 
+	if enableBaselineSelectors {
+		facts := database.SelectorFacts{
+			DisplayName: displayName,
+			Tags:        host.Tags,
+			OSMajor:     host.SystemProfile.OperatingSystem.Major,
+			OSMinor:     host.SystemProfile.OperatingSystem.Minor,
+			Repos:       updatesReq.GetRepositoryList(),
+		}
+		if _, err := updateSelectorBaseline(tx, &systemPlatform, &facts); err != nil {
+			return nil, errors.Wrap(err, "Unable to update system baseline")
+		}
+	}
+
 	if shouldUpdateRepos {
-		// We also don't need to update repos if vmaas_json haven't changed
+		// We also don't need to update repos and modules if vmaas_json haven't changed
 		addedRepos, addedSysRepos, deletedSysRepos, err = updateRepos(tx, host.SystemProfile, accountID,
 			systemPlatform.ID, updatesReq.GetRepositoryList())
 		if err != nil {
@@ -317,11 +334,19 @@ func updateSystemPlatform(tx *gorm.DB, inventoryID string, accountID int, host *
 				Error("repos failed to insert")
 			return nil, errors.Wrap(err, "unable to update system repos")
 		}
+		addedModules, addedSysModules, deletedSysModules, err = updateModules(tx, accountID, systemPlatform.ID,
+			updatesReq.GetModulesList())
+		if err != nil {
+			utils.Log("modules_list", updatesReq.ModulesList, "inventoryID", systemPlatform.ID).
+				Error("modules failed to insert")
+			return nil, errors.Wrap(err, "unable to update system modules")
+		}
 	}
 
 	utils.Log("inventoryID", inventoryID, "packages", len(updatesReq.PackageList), "repos",
 		len(updatesReq.GetRepositoryList()), "modules", len(updatesReq.GetModulesList()),
-		"addedRepos", addedRepos, "addedSysRepos", addedSysRepos, "deletedSysRepos", deletedSysRepos).
+		"addedRepos", addedRepos, "addedSysRepos", addedSysRepos, "deletedSysRepos", deletedSysRepos,
+		"addedModules", addedModules, "addedSysModules", addedSysModules, "deletedSysModules", deletedSysModules).
 		Info("System created or updated successfully")
 	return &systemPlatform, nil
 }
@@ -12,6 +12,7 @@ import (
 var AdvisoriesFields = database.MustGetQueryAttrs(&AdvisoriesDBLookup{})
 var AdvisoriesSelect = database.MustGetSelect(&AdvisoriesDBLookup{})
 var AdvisoriesSumFields = database.MustGetSelect(&AdvisoriesSums{})
+var AdvisoriesFieldsetHelpers = map[string]string{"release_versions": "release_versions_json"}
 var AdvisoriesOpts = ListOpts{
 	Fields:         AdvisoriesFields,
 	DefaultFilters: nil,
@@ -34,6 +35,8 @@ type AdvisoriesDBLookup struct {
 type AdvisoryItemAttributes struct {
 	SystemAdvisoryItemAttributes
 	ApplicableSystems int `json:"applicable_systems" query:"COALESCE(aad.systems_affected, 0)" csv:"applicable_systems" gorm:"column:applicable_systems"`
+	// Count of the applicable systems which can install the advisory according to their templates
+	InstallableSystems int `json:"installable_systems" query:"COALESCE(ti.systems_installable, 0)" csv:"installable_systems" gorm:"column:installable_systems"`
 }
 
 type AdvisoryItem struct {
@@ -76,21 +79,24 @@ func advisoriesSubtotal(tx *gorm.DB) (total int, subTotals map[string]int, err e
 	return total, subTotals, err
 }
 
-func advisoriesCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
+func advisoriesCommon(c *gin.Context, fieldset Fieldset) (*gorm.DB, *ListMeta, *Links, error) {
 	account := c.GetInt(middlewares.KeyAccount)
 	var query *gorm.DB
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	if disableCachedCounts || HasTags(c) {
+	if !useCachedCounts(c) {
 		var err error
-		query = buildQueryAdvisoriesTagged(filters, account)
+		query = buildQueryAdvisoriesTagged(filters, account, middlewares.GetSystemScope(c), fieldset)
 		if err != nil {
 			return nil, nil, nil, err
 		} // Error handled in method itself
 	} else {
-		query = buildQueryAdvisories(account)
+		query = buildQueryAdvisories(account, fieldset)
+	}
+	if isAttrUsed(c, fieldset, "installable_systems", "installable_systems") {
+		query = joinTemplateInstallable(query, filters, account, middlewares.GetSystemScope(c))
 	}
 
 	query, meta, links, err := ListCommon(query, c, filters, AdvisoriesOpts)
@@ -107,7 +113,7 @@ func advisoriesCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 // @Produce  json
 // @Param    limit          query   int     false   "Limit for paging, set -1 to return all"
 // @Param    offset         query   int     false   "Offset for paging"
-// @Param    sort           query   string  false   "Sort field"    Enums(id,name,advisory_type,synopsis,public_date,applicable_systems)
+// @Param    sort           query   string  false   "Sort field"    Enums(id,name,advisory_type,synopsis,public_date,applicable_systems,installable_systems)
 // @Param    search         query   string  false   "Find matching text"
 // @Param    filter[id]                  query   string  false "Filter "
 // @Param    filter[description]         query   string  false "Filter"
@@ -117,6 +123,7 @@ func advisoriesCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 // @Param    filter[advisory_type_name]  query   string  false "Filter"
 // @Param    filter[severity]            query   string  false "Filter"
 // @Param    filter[applicable_systems]  query   string  false "Filter"
+// @Param    filter[installable_systems] query   string  false "Filter"
 // @Param    tags                        query   []string  false "Tag filter"
 // @Param    filter[system_profile][sap_system]						query string  	false "Filter only SAP systems"
 // @Param    filter[system_profile][sap_sids][in]					query []string  false "Filter systems by their SAP SIDs"
@@ -124,13 +131,19 @@ func advisoriesCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 // @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
 // @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
 // @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Param    fields[advisories]          query   string    false "Comma separated list of returned attributes"
 // @Success 200 {object} AdvisoriesResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /advisories [get]
 func AdvisoriesListHandler(c *gin.Context) {
-	query, meta, links, err := advisoriesCommon(c)
+	fieldset, err := ParseFieldset(c, "advisories", &AdvisoryItemAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query, meta, links, err := advisoriesCommon(c, fieldset)
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -146,7 +159,7 @@ func AdvisoriesListHandler(c *gin.Context) {
 		Links: *links,
 		Meta:  *meta,
 	}
-	c.JSON(http.StatusOK, &resp)
+	JSONWithFieldset(c, http.StatusOK, &resp, fieldset)
 }
 
 // nolint:lll
@@ -158,7 +171,7 @@ func AdvisoriesListHandler(c *gin.Context) {
 // @Produce  json
 // @Param    limit          query   int     false   "Limit for paging, set -1 to return all"
 // @Param    offset         query   int     false   "Offset for paging"
-// @Param    sort           query   string  false   "Sort field"    Enums(id,name,advisory_type,synopsis,public_date,applicable_systems)
+// @Param    sort           query   string  false   "Sort field"    Enums(id,name,advisory_type,synopsis,public_date,applicable_systems,installable_systems)
 // @Param    search         query   string  false   "Find matching text"
 // @Param    filter[id]                  query   string  false "Filter "
 // @Param    filter[description]         query   string  false "Filter"
@@ -168,6 +181,7 @@ func AdvisoriesListHandler(c *gin.Context) {
 // @Param    filter[advisory_type_name]  query   string  false "Filter"
 // @Param    filter[severity]            query   string  false "Filter"
 // @Param    filter[applicable_systems]  query   string  false "Filter"
+// @Param    filter[installable_systems] query   string  false "Filter"
 // @Param    tags                        query   []string  false "Tag filter"
 // @Param    filter[system_profile][sap_system]						query string  	false "Filter only SAP systems"
 // @Param    filter[system_profile][sap_sids][in]					query []string  false "Filter systems by their SAP SIDs"
@@ -181,7 +195,7 @@ func AdvisoriesListHandler(c *gin.Context) {
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /ids/advisories [get]
 func AdvisoriesListIDsHandler(c *gin.Context) {
-	query, _, _, err := advisoriesCommon(c)
+	query, _, _, err := advisoriesCommon(c, Fieldset{})
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -196,17 +210,22 @@ func AdvisoriesListIDsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, &resp)
 }
 
-func buildQueryAdvisories(account int) *gorm.DB {
+func buildQueryAdvisories(account int, fieldset Fieldset) *gorm.DB {
 	query := database.Db.Table("advisory_metadata am").
-		Select(AdvisoriesSelect).
+		Select(fieldset.Select(&AdvisoriesDBLookup{}, AdvisoriesFieldsetHelpers, "id")).
 		Joins("JOIN advisory_account_data aad ON am.id = aad.advisory_id and aad.systems_affected > 0").
 		Joins("JOIN advisory_type at ON am.advisory_type_id = at.id").
 		Where("aad.rh_account_id = ?", account)
 	return query
 }
 
-func buildAdvisoryAccountDataQuery(account int) *gorm.DB {
-	query := database.SystemAdvisories(database.Db, account).
+// Cached counts in advisory_account_data can't be used with tag filters or for users restricted to some systems
+func useCachedCounts(c *gin.Context) bool {
+	return !disableCachedCounts && !HasTags(c) && middlewares.GetSystemScope(c) == nil
+}
+
+func buildAdvisoryAccountDataQuery(account int, scope *database.SystemScope) *gorm.DB {
+	query := database.SystemAdvisories(database.Db, account, scope).
 		Select("sa.advisory_id, sp.rh_account_id as rh_account_id, 0 as status_id, count(sp.id) as systems_affected, " +
 			"0 as systems_status_divergent").
 		Where("sp.stale = false").
@@ -215,18 +234,30 @@ func buildAdvisoryAccountDataQuery(account int) *gorm.DB {
 	return query
 }
 
-func buildQueryAdvisoriesTagged(filters map[string]FilterData, account int) *gorm.DB {
-	subq := buildAdvisoryAccountDataQuery(account)
+func buildQueryAdvisoriesTagged(filters map[string]FilterData, account int, scope *database.SystemScope,
+	fieldset Fieldset) *gorm.DB {
+	subq := buildAdvisoryAccountDataQuery(account, scope)
 	subq, _ = ApplyTagsFilter(filters, subq, "sp.inventory_id")
 
 	query := database.Db.Table("advisory_metadata am").
-		Select(AdvisoriesSelect).
+		Select(fieldset.Select(&AdvisoriesDBLookup{}, AdvisoriesFieldsetHelpers, "id")).
 		Joins("JOIN advisory_type at ON am.advisory_type_id = at.id").
 		Joins("JOIN (?) aad ON am.id = aad.advisory_id and aad.systems_affected > 0", subq)
 
 	return query
 }
 
+// Joins count of systems which can install the advisory according to their templates
+func joinTemplateInstallable(query *gorm.DB, filters map[string]FilterData, account int,
+	scope *database.SystemScope) *gorm.DB {
+	subq := database.SystemAdvisories(database.Db, account, scope).
+		Select("sa.advisory_id, count(sp.id) as systems_installable").
+		Where("sp.stale = false AND sa.in_template").
+		Group("sa.advisory_id")
+	subq, _ = ApplyTagsFilter(filters, subq, "sp.inventory_id")
+	return query.Joins("LEFT JOIN (?) ti ON ti.advisory_id = am.id", subq)
+}
+
 func buildAdvisoriesData(advisories []AdvisoriesDBLookup) []AdvisoryItem {
 	data := make([]AdvisoryItem, len(advisories))
 	for i := 0; i < len(advisories); i++ {
@@ -236,6 +267,7 @@ func buildAdvisoriesData(advisories []AdvisoriesDBLookup) []AdvisoryItem {
 			Attributes: AdvisoryItemAttributes{
 				SystemAdvisoryItemAttributes: advisory.SystemAdvisoryItemAttributes,
 				ApplicableSystems:            advisory.ApplicableSystems,
+				InstallableSystems:           advisory.InstallableSystems,
 			},
 			ID:   advisory.ID,
 			Type: "advisory",
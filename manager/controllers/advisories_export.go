@@ -2,9 +2,6 @@ package controllers
 
 import (
 	"app/manager/middlewares"
-	"fmt"
-	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -25,25 +22,34 @@ import (
 // @Param    filter[advisory_type_name] query   string  false "Filter"
 // @Param    filter[severity]           query   string  false "Filter"
 // @Param    filter[applicable_systems] query   string  false "Filter"
+// @Param    fields[advisories]          query   string    false "Comma separated list of returned attributes"
 // @Success 200 {array} AdvisoryInlineItem
 // @Failure 415 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /export/advisories [get]
 func AdvisoriesExportHandler(c *gin.Context) {
 	account := c.GetInt(middlewares.KeyAccount)
+	fieldset, err := ParseFieldset(c, "advisories", &AdvisoryItemAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return
 	}
 	var query *gorm.DB
-	if disableCachedCounts || HasTags(c) {
+	if !useCachedCounts(c) {
 		var err error
-		query = buildQueryAdvisoriesTagged(filters, account)
+		query = buildQueryAdvisoriesTagged(filters, account, middlewares.GetSystemScope(c), fieldset)
 		if err != nil {
 			return
 		} // Error handled in method itself
 	} else {
-		query = buildQueryAdvisories(account)
+		query = buildQueryAdvisories(account, fieldset)
+	}
+	if isAttrUsed(c, fieldset, "installable_systems", "installable_systems") {
+		query = joinTemplateInstallable(query, filters, account, middlewares.GetSystemScope(c))
 	}
 
 	var advisories []AdvisoriesDBLookup
@@ -67,13 +73,5 @@ func AdvisoriesExportHandler(c *gin.Context) {
 		v.SystemAdvisoryItemAttributes = systemAdvisoryItemAttributeParse(v.SystemAdvisoryItemAttributes)
 		data[i] = AdvisoryInlineItem(v)
 	}
-	accept := c.GetHeader("Accept")
-	if strings.Contains(accept, "application/json") { // nolint: gocritic
-		c.JSON(http.StatusOK, data)
-	} else if strings.Contains(accept, "text/csv") {
-		Csv(c, http.StatusOK, data)
-	} else {
-		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
-	}
+	ExportWithFieldset(c, data, fieldset)
 }
@@ -213,6 +213,13 @@ func TestAdvisoriesFilterApplicableSystems(t *testing.T) {
 	assert.Equal(t, "RH-1", output.Data[0].ID)
 }
 
+func TestAdvisoriesFilterInstallableSystems(t *testing.T) {
+	output := testAdvisories(t, "/?filter[installable_systems]=gt:0")
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "RH-1", output.Data[0].ID)
+	assert.Equal(t, 1, output.Data[0].Attributes.InstallableSystems)
+}
+
 func TestAdvisoriesPossibleSorts(t *testing.T) {
 	core.SetupTest(t)
 
@@ -360,3 +367,13 @@ func TestAdvisoryMetadataSums(t *testing.T) {
 	assert.Equal(t, bugfix, st["bugfix"])
 	assert.Equal(t, security, st["security"])
 }
+
+func TestAdvisoriesFieldset(t *testing.T) {
+	output := testAdvisories(t, "/?fields[advisories]=synopsis,release_versions&filter[id]=RH-1")
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "RH-1", output.Data[0].ID)
+	assert.Equal(t, "adv-1-syn", output.Data[0].Attributes.Synopsis)
+	assert.Equal(t, RelList{"7.0", "7Server"}, output.Data[0].Attributes.ReleaseVersions)
+	assert.Equal(t, "", output.Data[0].Attributes.Description)
+	assert.Equal(t, 0, output.Data[0].Attributes.ApplicableSystems)
+}
@@ -4,6 +4,7 @@ import (
 	"app/base/database"
 	"app/base/models"
 	"app/base/utils"
+	"app/manager/middlewares"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -69,6 +70,8 @@ type AdvisoryDetailAttributesV1 struct {
 type AdvisoryDetailAttributesV2 struct {
 	AdvisoryDetailAttributes
 	Packages packagesV2 `json:"packages"`
+	// Applicability of each fixed package to systems in the account, loaded only when requested in `fields[advisories]`
+	PackagesApplicability []AdvisoryPackageItem `json:"packages_applicability,omitempty"`
 }
 
 type packagesV1 map[string]string
@@ -88,6 +91,7 @@ func AdvisoryDetailHandlerV1(c *gin.Context) {
 // @Accept   json
 // @Produce  json
 // @Param    advisory_id    path    string   true "Advisory ID"
+// @Param    fields[advisories] query string false "Comma separated list of returned attributes"
 // @Success 200 {object} AdvisoryDetailResponseV2
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -108,7 +112,11 @@ func advisoryDetailHandler(c *gin.Context, apiver string) {
 		return
 	}
 
-	var err error
+	fieldset, err := ParseFieldset(c, "advisories", &AdvisoryDetailAttributesV2{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
 	var respV1 *AdvisoryDetailResponseV1
 	var respV2 *AdvisoryDetailResponseV2
 	switch apiver {
@@ -126,11 +134,21 @@ func advisoryDetailHandler(c *gin.Context, apiver string) {
 		return
 	}
 
+	// cached advisory detail is shared by all accounts, applicability is loaded for each request which asks for it
+	if respV2 != nil && fieldset["packages_applicability"] {
+		respV2.Data.Attributes.PackagesApplicability, err = advisoryPackagesApplicability(
+			c.GetInt(middlewares.KeyAccount), middlewares.GetSystemScope(c), advisoryName)
+		if err != nil {
+			LogAndRespError(c, err, "advisory detail error")
+			return
+		}
+	}
+
 	switch apiver {
 	case "v1":
-		c.JSON(http.StatusOK, respV1)
+		JSONWithFieldset(c, http.StatusOK, respV1, fieldset)
 	case "v2":
-		c.JSON(http.StatusOK, respV2)
+		JSONWithFieldset(c, http.StatusOK, respV2, fieldset)
 	}
 }
 
@@ -0,0 +1,342 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+var AdvisoryPackageSystemsFields = database.MustGetQueryAttrs(&AdvisoryPackageSystemItem{})
+var AdvisoryPackageSystemsSelect = database.MustGetSelect(&AdvisoryPackageSystemItem{})
+var AdvisoryPackageSystemsOpts = ListOpts{
+	Fields:         AdvisoryPackageSystemsFields,
+	DefaultFilters: map[string]FilterData{},
+	DefaultSort:    "display_name",
+	StableSort:     "res.nevra, res.inventory_id",
+	SearchFields:   []string{"res.display_name"},
+	TotalFunc:      CountRows,
+}
+
+type AdvisoryPackageItem struct {
+	// Package fixed by the advisory
+	Nevra string `json:"nevra" example:"kernel-5.6.13-201.fc31.x86_64" gorm:"column:nevra"`
+	// Systems with only older versions of the package installed
+	SystemsAffected int `json:"systems_affected" example:"2" gorm:"column:systems_affected"`
+	// Systems with the fixed or newer version of the package installed
+	SystemsFixed int `json:"systems_fixed" example:"1" gorm:"column:systems_fixed"`
+}
+
+type AdvisoryPackagesResponse struct {
+	Data []AdvisoryPackageItem `json:"data"`
+}
+
+// nolint: lll
+type AdvisoryPackageSystemItem struct {
+	InventoryID string `json:"inventory_id" query:"res.inventory_id" gorm:"column:inventory_id" example:"00000000-0000-0000-0000-000000000001"`
+	DisplayName string `json:"display_name" query:"res.display_name" gorm:"column:display_name" example:"my-system"`
+	// Package fixed by the advisory
+	Nevra string `json:"nevra" query:"res.nevra" gorm:"column:nevra" example:"kernel-5.6.13-201.fc31.x86_64"`
+	// The latest installed version of the package
+	InstalledEVRA string `json:"installed_evra" query:"res.installed_evra" gorm:"column:installed_evra" example:"5.6.13-200.fc31.x86_64"`
+}
+
+type AdvisoryPackageSystemsResponse struct {
+	Data  []AdvisoryPackageSystemItem `json:"data"`
+	Links Links                       `json:"links"`
+	Meta  ListMeta                    `json:"meta"`
+}
+
+type advisoryFixedPackage struct {
+	NameID int64  `gorm:"column:name_id"`
+	Name   string `gorm:"column:name"`
+	EVRA   string `gorm:"column:evra"`
+}
+
+type advisoryInstalledVersion struct {
+	ID     int64  `gorm:"column:id"`
+	NameID int64  `gorm:"column:name_id"`
+	EVRA   string `gorm:"column:evra"`
+}
+
+// Installed package version compared with the fixed package, rank orders versions of the same fixed package
+type advisoryPackageVersion struct {
+	Nevra     string
+	PackageID int64
+	Fixed     bool
+	Rank      int
+}
+
+// @Summary Show applicability of packages fixed by the advisory to my systems
+// @Description Show for each package fixed by the advisory how many systems have only older versions installed
+// @Description and how many systems have the fixed or newer version
+// @ID listAdvisoryPackages
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    advisory_id    path    string   true "Advisory ID"
+// @Success 200 {object} AdvisoryPackagesResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /advisories/{advisory_id}/packages [get]
+func AdvisoryPackagesHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+	advisoryName, err := loadAdvisoryName(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	items, err := advisoryPackagesApplicability(account, middlewares.GetSystemScope(c), advisoryName)
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+	c.JSON(http.StatusOK, &AdvisoryPackagesResponse{Data: items})
+}
+
+// @Summary Show my systems with older versions of packages fixed by the advisory
+// @Description Show systems with only older versions of packages fixed by the advisory installed,
+// @Description one item for each system and fixed package
+// @ID listAdvisoryPackageSystems
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    advisory_id    path    string  true    "Advisory ID"
+// @Param    limit          query   int     false   "Limit for paging, set -1 to return all"
+// @Param    offset         query   int     false   "Offset for paging"
+// @Param    sort           query   string  false   "Sort field" Enums(inventory_id,display_name,nevra,installed_evra)
+// @Param    search         query   string  false   "Find matching text"
+// @Param    filter[inventory_id]   query   string  false "Filter"
+// @Param    filter[display_name]   query   string  false "Filter"
+// @Param    filter[nevra]          query   string  false "Filter"
+// @Param    filter[installed_evra] query   string  false "Filter"
+// @Param    tags                   query   []string  false "Tag filter"
+// @Success 200 {object} AdvisoryPackageSystemsResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /advisories/{advisory_id}/packages/systems [get]
+func AdvisoryPackageSystemsHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+	advisoryName, err := loadAdvisoryName(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	filters, err := ParseTagsFilters(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	_, versions, err := advisoryPackageVersions(account, advisoryName)
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	query := advisoryPackageSystemsQuery(filters, account, middlewares.GetSystemScope(c), versions)
+	query, meta, links, err := ListCommon(query, c, filters, AdvisoryPackageSystemsOpts)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	systems := make([]AdvisoryPackageSystemItem, 0)
+	if err = query.Scan(&systems).Error; err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	c.JSON(http.StatusOK, &AdvisoryPackageSystemsResponse{
+		Data:  systems,
+		Links: *links,
+		Meta:  *meta,
+	})
+}
+
+// Returns advisory name given in path, error is sent in response
+func loadAdvisoryName(c *gin.Context) (string, error) {
+	advisoryName := c.Param("advisory_id")
+	if advisoryName == "" {
+		err := errors.New("advisory_id param not found")
+		LogAndRespBadRequest(c, err, err.Error())
+		return "", err
+	}
+
+	var advisory models.AdvisoryMetadata
+	err := database.Db.Select("id").Take(&advisory, "name = ?", advisoryName).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			LogAndRespNotFound(c, err, "advisory not found")
+		} else {
+			LogAndRespError(c, err, "database error")
+		}
+		return "", err
+	}
+	return advisoryName, nil
+}
+
+// Counts fresh systems with only older versions and with the fixed or newer version of each fixed package
+func advisoryPackagesApplicability(account int, scope *database.SystemScope, advisoryName string) (
+	[]AdvisoryPackageItem, error) {
+	nevras, versions, err := advisoryPackageVersions(account, advisoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []AdvisoryPackageItem
+	if len(versions) > 0 {
+		systems := advisoryPackageVersionSystems(account, scope, versions).
+			Select("apv.nevra, bool_or(apv.fixed) AS fixed")
+		err = database.Db.Table("(?) AS s", systems).
+			Select("s.nevra, count(*) FILTER (WHERE s.fixed) AS systems_fixed, " +
+				"count(*) FILTER (WHERE NOT s.fixed) AS systems_affected").
+			Group("s.nevra").
+			Scan(&counts).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	countsMap := make(map[string]AdvisoryPackageItem, len(counts))
+	for _, item := range counts {
+		countsMap[item.Nevra] = item
+	}
+	items := make([]AdvisoryPackageItem, 0, len(nevras))
+	for _, nevra := range nevras {
+		item := countsMap[nevra]
+		item.Nevra = nevra
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func advisoryPackageSystemsQuery(filters map[string]FilterData, account int, scope *database.SystemScope,
+	versions []advisoryPackageVersion) *gorm.DB {
+	systems := advisoryPackageVersionSystems(account, scope, versions).
+		Select("apv.nevra, sp.inventory_id, sp.display_name, " +
+			"(array_agg(p.evra ORDER BY apv.rank DESC))[1] AS installed_evra").
+		Group("sp.inventory_id, sp.display_name").
+		Having("NOT bool_or(apv.fixed)")
+	systems, _ = ApplyTagsFilter(filters, systems, "sp.inventory_id")
+	return database.Db.Table("(?) AS res", systems).Select(AdvisoryPackageSystemsSelect)
+}
+
+// Installed versions of fixed packages grouped by fresh system and fixed package
+func advisoryPackageVersionSystems(account int, scope *database.SystemScope, versions []advisoryPackageVersion,
+) *gorm.DB {
+	join, args := advisoryPackageVersionsJoin(versions)
+	return database.SystemPackages(database.Db, account, scope).
+		Joins(join, args...).
+		Where("sp.stale = false").
+		Group("apv.nevra, sp.id")
+}
+
+// Versions are joined with installed packages as arrays passed in 4 parameters
+// so the number of query parameters doesn't grow with the number of versions
+func advisoryPackageVersionsJoin(versions []advisoryPackageVersion) (string, []interface{}) {
+	nevras := make(pq.StringArray, 0, len(versions))
+	packageIDs := make(pq.Int64Array, 0, len(versions))
+	fixed := make(pq.BoolArray, 0, len(versions))
+	ranks := make(pq.Int64Array, 0, len(versions))
+	for _, v := range versions {
+		nevras = append(nevras, v.Nevra)
+		packageIDs = append(packageIDs, v.PackageID)
+		fixed = append(fixed, v.Fixed)
+		ranks = append(ranks, int64(v.Rank))
+	}
+	return "JOIN unnest(?::text[], ?::bigint[], ?::boolean[], ?::int[]) AS apv (nevra, package_id, fixed, rank) " +
+		"ON apv.package_id = spkg.package_id", []interface{}{nevras, packageIDs, fixed, ranks}
+}
+
+// Loads packages fixed by the advisory and versions of the same packages installed in the account
+func advisoryPackageVersions(account int, advisoryName string) ([]string, []advisoryPackageVersion, error) {
+	var fixed []advisoryFixedPackage
+	err := database.Db.Table("package p").
+		Select("p.name_id, pn.name, p.evra").
+		Joins("JOIN package_name pn ON pn.id = p.name_id").
+		Joins("JOIN advisory_metadata am ON am.id = p.advisory_id").
+		Where("am.name = ?", advisoryName).
+		Order("pn.name, p.evra").
+		Scan(&fixed).Error
+	if err != nil || len(fixed) == 0 {
+		return []string{}, nil, err
+	}
+
+	nameIDs := make([]int64, 0, len(fixed))
+	for _, pkg := range fixed {
+		nameIDs = append(nameIDs, pkg.NameID)
+	}
+	var installed []advisoryInstalledVersion
+	err = database.Db.Table("package p").
+		Select("p.id, p.name_id, p.evra").
+		Where("p.name_id IN (?)", nameIDs).
+		Where("EXISTS (SELECT 1 FROM system_package spkg WHERE spkg.rh_account_id = ? "+
+			"AND spkg.name_id = p.name_id AND spkg.package_id = p.id)", account).
+		Scan(&installed).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	nevras, versions := buildAdvisoryPackageVersions(fixed, installed)
+	return nevras, versions, nil
+}
+
+// Installed package of the same arch can be replaced by the fixed one,
+// noarch package can replace any arch and vice versa
+func archCompatible(installed, fixed string) bool {
+	return installed == fixed || installed == "noarch" || fixed == "noarch"
+}
+
+type installedNevra struct {
+	id    int64
+	nevra *utils.Nevra
+}
+
+// Compares installed versions of each fixed package with the fixed version
+func buildAdvisoryPackageVersions(fixed []advisoryFixedPackage, installed []advisoryInstalledVersion) (
+	nevras []string, versions []advisoryPackageVersion) {
+	nevras = make([]string, 0, len(fixed))
+	for _, pkg := range fixed {
+		fixedNevra, err := utils.ParseNameEVRA(pkg.Name, pkg.EVRA)
+		if err != nil {
+			utils.Log("name", pkg.Name, "evra", pkg.EVRA, "err", err.Error()).Warn("Unable to parse fixed package")
+			continue
+		}
+		nevras = append(nevras, fixedNevra.String())
+
+		candidates := make([]installedNevra, 0)
+		for _, inst := range installed {
+			if inst.NameID != pkg.NameID {
+				continue
+			}
+			nevra, err := utils.ParseNameEVRA(pkg.Name, inst.EVRA)
+			if err != nil || !archCompatible(nevra.Arch, fixedNevra.Arch) {
+				continue
+			}
+			candidates = append(candidates, installedNevra{id: inst.ID, nevra: nevra})
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].nevra.EVRACmp(candidates[j].nevra) < 0
+		})
+
+		for rank, candidate := range candidates {
+			// compare EVR only, arch may differ for noarch packages
+			fixedEVR := *fixedNevra
+			fixedEVR.Arch = candidate.nevra.Arch
+			versions = append(versions, advisoryPackageVersion{
+				Nevra:     fixedNevra.String(),
+				PackageID: candidate.id,
+				Fixed:     candidate.nevra.EVRACmp(&fixedEVR) >= 0,
+				Rank:      rank,
+			})
+		}
+	}
+	return nevras, versions
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAdvisoryPackages(t *testing.T, url string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithAccount("GET", url, nil, "", AdvisoryPackagesHandler, "/:advisory_id/packages", 3)
+	CheckResponse(t, w, expectedStatus, &output)
+}
+
+func testAdvisoryPackageSystems(t *testing.T, url string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithAccount("GET", url, nil, "", AdvisoryPackageSystemsHandler,
+		"/:advisory_id/packages/systems", 3)
+	CheckResponse(t, w, expectedStatus, &output)
+}
+
+func TestAdvisoryPackagesAffected(t *testing.T) {
+	var output AdvisoryPackagesResponse
+	testAdvisoryPackages(t, "/RH-7/packages", http.StatusOK, &output)
+	assert.Equal(t, []AdvisoryPackageItem{
+		{Nevra: "kernel-5.6.13-201.fc31.x86_64", SystemsAffected: 2},
+		{Nevra: "sed-4.5-1.el8.x86_64"},
+	}, output.Data)
+}
+
+func TestAdvisoryPackagesFixed(t *testing.T) {
+	var output AdvisoryPackagesResponse
+	testAdvisoryPackages(t, "/RH-1/packages", http.StatusOK, &output)
+	assert.Equal(t, []AdvisoryPackageItem{
+		{Nevra: "firefox-76.0.1-1.fc31.x86_64", SystemsFixed: 2},
+		{Nevra: "kernel-5.6.13-200.fc31.x86_64", SystemsFixed: 2},
+	}, output.Data)
+}
+
+func TestAdvisoryPackagesNotFound(t *testing.T) {
+	var output utils.ErrorResponse
+	testAdvisoryPackages(t, "/foo/packages", http.StatusNotFound, &output)
+	assert.Equal(t, "advisory not found", output.Error)
+}
+
+func TestAdvisoryPackageSystems(t *testing.T) {
+	var output AdvisoryPackageSystemsResponse
+	testAdvisoryPackageSystems(t, "/RH-7/packages/systems?limit=1", http.StatusOK, &output)
+	assert.Equal(t, 2, output.Meta.TotalItems)
+	assert.Equal(t, []AdvisoryPackageSystemItem{{InventoryID: "00000000-0000-0000-0000-000000000012",
+		DisplayName: "00000000-0000-0000-0000-000000000012", Nevra: "kernel-5.6.13-201.fc31.x86_64",
+		InstalledEVRA: "5.6.13-200.fc31.x86_64"}}, output.Data)
+}
+
+func TestAdvisoryPackageSystemsFixed(t *testing.T) {
+	var output AdvisoryPackageSystemsResponse
+	testAdvisoryPackageSystems(t, "/RH-1/packages/systems", http.StatusOK, &output)
+	assert.Equal(t, 0, output.Meta.TotalItems)
+	assert.Equal(t, 0, len(output.Data))
+}
+
+func TestAdvisoryDetailPackagesApplicability(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithAccount("GET", "/RH-7?fields[advisories]=packages_applicability", nil, "",
+		AdvisoryDetailHandlerV2, "/:advisory_id", 3)
+
+	var output AdvisoryDetailResponseV2
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, 2, len(output.Data.Attributes.PackagesApplicability))
+	assert.Equal(t, 2, output.Data.Attributes.PackagesApplicability[0].SystemsAffected)
+	assert.Equal(t, "", output.Data.Attributes.Synopsis)
+
+	// applicability is not loaded by default
+	w = CreateRequestRouterWithAccount("GET", "/RH-7", nil, "", AdvisoryDetailHandlerV2, "/:advisory_id", 3)
+	output = AdvisoryDetailResponseV2{}
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Nil(t, output.Data.Attributes.PackagesApplicability)
+}
+
+func TestBuildAdvisoryPackageVersions(t *testing.T) {
+	fixed := []advisoryFixedPackage{
+		{NameID: 1, Name: "kernel", EVRA: "5.6.13-201.fc31.x86_64"},
+		{NameID: 2, Name: "tzdata", EVRA: "2021a-1.el8.noarch"},
+		{NameID: 3, Name: "broken", EVRA: "invalid"},
+	}
+	installed := []advisoryInstalledVersion{
+		{ID: 10, NameID: 1, EVRA: "5.6.13-202.fc31.x86_64"},
+		{ID: 11, NameID: 1, EVRA: "5.6.13-200.fc31.x86_64"},
+		{ID: 12, NameID: 1, EVRA: "5.6.13-100.fc31.i686"},
+		{ID: 20, NameID: 2, EVRA: "2020a-1.el8.noarch"},
+		{ID: 30, NameID: 3, EVRA: "1-1.el8.x86_64"},
+	}
+	nevras, versions := buildAdvisoryPackageVersions(fixed, installed)
+	assert.Equal(t, []string{"kernel-5.6.13-201.fc31.x86_64", "tzdata-2021a-1.el8.noarch"}, nevras)
+	assert.Equal(t, []advisoryPackageVersion{
+		{Nevra: "kernel-5.6.13-201.fc31.x86_64", PackageID: 11, Fixed: false, Rank: 0},
+		{Nevra: "kernel-5.6.13-201.fc31.x86_64", PackageID: 10, Fixed: true, Rank: 1},
+		{Nevra: "tzdata-2021a-1.el8.noarch", PackageID: 20, Fixed: false, Rank: 0},
+	}, versions)
+}
+
+func TestAdvisoryPackageVersionsJoin(t *testing.T) {
+	join, args := advisoryPackageVersionsJoin(nil)
+	assert.Contains(t, join, "unnest(?::text[], ?::bigint[], ?::boolean[], ?::int[])")
+	assert.Equal(t, []interface{}{pq.StringArray{}, pq.Int64Array{}, pq.BoolArray{}, pq.Int64Array{}}, args)
+
+	versions := make([]advisoryPackageVersion, 0, 20000)
+	for i := 0; i < 20000; i++ {
+		versions = append(versions, advisoryPackageVersion{Nevra: "a-1-1.noarch", PackageID: int64(i), Rank: i})
+	}
+	versions[1].Fixed = true
+	join2, args := advisoryPackageVersionsJoin(versions)
+	assert.Equal(t, join, join2)
+	// number of parameters doesn't depend on number of versions
+	assert.Equal(t, 4, len(args))
+	assert.Equal(t, 20000, len(args[0].(pq.StringArray)))
+	assert.Equal(t, "a-1-1.noarch", args[0].(pq.StringArray)[0])
+	assert.Equal(t, int64(1), args[1].(pq.Int64Array)[1])
+	assert.Equal(t, pq.BoolArray{false, true}, args[2].(pq.BoolArray)[:2])
+	assert.Equal(t, int64(19999), args[3].(pq.Int64Array)[19999])
+}
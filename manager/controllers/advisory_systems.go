@@ -33,7 +33,7 @@ var AdvisorySystemOpts = ListOpts{
 	TotalFunc:    CountRows,
 }
 
-func advisorySystemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
+func advisorySystemsCommon(c *gin.Context, fieldset Fieldset) (*gorm.DB, *ListMeta, *Links, error) {
 	account := c.GetInt(middlewares.KeyAccount)
 
 	advisoryName := c.Param("advisory_id")
@@ -55,7 +55,7 @@ func advisorySystemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error)
 		return nil, nil, nil, err
 	}
 
-	query := buildAdvisorySystemsQuery(account, advisoryName)
+	query := buildAdvisorySystemsQuery(c, account, advisoryName, fieldset)
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return nil, nil, nil, err
@@ -103,13 +103,19 @@ func advisorySystemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error)
 // @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
 // @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
 // @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Param    fields[systems]         query   string    false "Comma separated list of returned attributes"
 // @Success 200 {object} AdvisorySystemsResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /advisories/{advisory_id}/systems [get]
 func AdvisorySystemsListHandler(c *gin.Context) {
-	query, meta, links, err := advisorySystemsCommon(c)
+	fieldset, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query, meta, links, err := advisorySystemsCommon(c, fieldset)
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -127,7 +133,7 @@ func AdvisorySystemsListHandler(c *gin.Context) {
 		Links: *links,
 		Meta:  *meta,
 	}
-	c.JSON(http.StatusOK, &resp)
+	JSONWithFieldset(c, http.StatusOK, &resp, fieldset)
 }
 
 // nolint: lll
@@ -173,7 +179,7 @@ func AdvisorySystemsListHandler(c *gin.Context) {
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /ids/advisories/{advisory_id}/systems [get]
 func AdvisorySystemsListIDsHandler(c *gin.Context) {
-	query, _, _, err := advisorySystemsCommon(c)
+	query, _, _, err := advisorySystemsCommon(c, Fieldset{})
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -190,13 +196,15 @@ func AdvisorySystemsListIDsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, &resp)
 }
 
-func buildAdvisorySystemsQuery(account int, advisoryName string) *gorm.DB {
-	query := database.SystemAdvisories(database.Db, account).
-		Select(SystemsSelect).
+func buildAdvisorySystemsQuery(c *gin.Context, account int, advisoryName string, fieldset Fieldset) *gorm.DB {
+	query := database.SystemAdvisories(database.Db, account, middlewares.GetSystemScope(c)).
+		Select(systemsSelect(fieldset)).
 		Joins("JOIN advisory_metadata am ON am.id = sa.advisory_id").
-		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
-		Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id").
-		Where("am.name = ?", advisoryName).
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id")
+	if isAttrUsed(c, fieldset, "baseline_name", "baseline_name") {
+		query = query.Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id")
+	}
+	query = query.Where("am.name = ?", advisoryName).
 		Where("sp.stale = false")
 
 	return query
@@ -6,9 +6,7 @@ import (
 	"app/base/utils"
 	"app/manager/middlewares"
 	"errors"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -43,6 +41,7 @@ import (
 // @Param    filter[osmajor] query string false "Filter"
 // @Param    filter[os]              query   string    false "Filter OS version"
 // @Param    tags                    query   []string  false "Tag filter"
+// @Param    fields[systems]         query   string    false "Comma separated list of returned attributes"
 // @Success 200 {array} SystemInlineItem
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -68,7 +67,12 @@ func AdvisorySystemsExportHandler(c *gin.Context) {
 		return
 	}
 
-	query := buildAdvisorySystemsQuery(account, advisoryName)
+	fieldset, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := buildAdvisorySystemsQuery(c, account, advisoryName, fieldset)
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return
@@ -89,14 +93,6 @@ func AdvisorySystemsExportHandler(c *gin.Context) {
 		return
 	}
 
-	accept := c.GetHeader("Accept")
 	parseAndFillTags(&systems)
-	if strings.Contains(accept, "application/json") { // nolint: gocritic
-		c.JSON(http.StatusOK, systems)
-	} else if strings.Contains(accept, "text/csv") {
-		Csv(c, 200, systems)
-	} else {
-		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
-	}
+	ExportWithFieldset(c, systems, fieldset)
 }
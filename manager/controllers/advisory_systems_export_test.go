@@ -32,12 +32,15 @@ func TestAdvisorySystemsExportCSV(t *testing.T) {
 	assert.Equal(t, 8, len(lines))
 	assert.Equal(t,
 		"id,display_name,last_evaluation,last_upload,rhsa_count,rhba_count,rhea_count,other_count,stale,third_party,"+
-			"insights_id,packages_installed,packages_updatable,os_name,os_major,os_minor,os,rhsm,stale_timestamp,"+
-			"stale_warning_timestamp,culled_timestamp,created,tags,baseline_name,baseline_uptodate", lines[0])
+			"insights_id,packages_installed,packages_updatable,os_name,os_major,os_minor,os,rhsm,"+
+			"running_kernel,installed_kernel,last_boot,releasever,infrastructure_type,cloud_provider,sap_system,"+
+			"ansible,reboot_pending,stale_timestamp,stale_warning_timestamp,culled_timestamp,created,tags,repos,"+
+			"baseline_name,baseline_uptodate,template_name,template_installable,template_applicable", lines[0])
 
 	assert.Equal(t, "00000000-0000-0000-0000-000000000001,00000000-0000-0000-0000-000000000001,"+
 		"2018-09-22T16:00:00Z,2020-09-22T16:00:00Z,2,3,3,0,false,true,00000000-0000-0000-0001-000000000001,0,0,"+
-		"RHEL,8,10,RHEL 8.10,8.10,2018-08-26T16:00:00Z,2018-09-02T16:00:00Z,2018-09-09T16:00:00Z,2018-08-26T16:00:00Z,"+
+		"RHEL,8,10,RHEL 8.10,8.10,,,,,,,false,false,false,2018-08-26T16:00:00Z,2018-09-02T16:00:00Z,2018-09-09T16:00:00Z,"+
+		"2018-08-26T16:00:00Z,"+
 		"\"[{'key':'k1','namespace':'ns1','value':'val1'},{'key':'k2','namespace':'ns1','value':'val2'}]\","+
-		"baseline_1-1,true", lines[1])
+		",baseline_1-1,true,template_1-1,1,7", lines[1])
 }
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CloneBaselineRequest struct {
+	// Name of the new baseline
+	Name string `json:"name" example:"my_cloned_baseline"`
+	// Description of the new baseline, description of the cloned baseline is used when missing (optional)
+	Description *string `json:"description"`
+}
+
+// @Summary Clone a baseline
+// @Description Create a new baseline with config of the given baseline, systems are not associated with the new one
+// @ID cloneBaseline
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    baseline_id path    int                  true "Baseline ID"
+// @Param    body        body    CloneBaselineRequest true "Request body"
+// @Success 200 {object} CreateBaselineResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /baselines/{baseline_id}/clone [post]
+func BaselineCloneHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	var req CloneBaselineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		LogAndRespBadRequest(c, err, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Name == "" {
+		LogAndRespBadRequest(c, errors.New(BaselineMissingNameErr), BaselineMissingNameErr)
+		return
+	}
+
+	baselineIDstr := c.Param("baseline_id")
+	baselineID, err := strconv.Atoi(baselineIDstr)
+	if err != nil {
+		LogAndRespBadRequest(c, err, "Invalid baseline_id: "+baselineIDstr)
+		return
+	}
+
+	var baseline models.Baseline
+	err = database.Db.Where("rh_account_id = ? AND id = ?", account, baselineID).First(&baseline).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			LogAndRespNotFound(c, err, "baseline not found")
+		} else {
+			LogAndRespError(c, err, "Database error")
+		}
+		return
+	}
+
+	clone := models.Baseline{
+		RhAccountID: account,
+		Name:        req.Name,
+		Description: baseline.Description,
+		Config:      baseline.Config,
+	}
+	if req.Description != nil {
+		clone.Description = utils.EmptyToNil(req.Description)
+	}
+	if err = database.Db.Create(&clone).Error; err != nil {
+		if database.IsPgErrorCode(err, database.PgErrorDuplicateKey) {
+			LogAndRespBadRequest(c, err, DuplicateBaselineNameErr)
+			return
+		}
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	c.JSON(http.StatusOK, &CreateBaselineResponse{BaselineID: clone.ID})
+}
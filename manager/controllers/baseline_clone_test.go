@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/utils"
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBaselineClone(t *testing.T, url, data string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("POST", url, bytes.NewBufferString(data), "", BaselineCloneHandler, 1, "POST",
+		"/:baseline_id/clone")
+	CheckResponse(t, w, expectedStatus, output)
+}
+
+func TestBaselineClone(t *testing.T) {
+	var resp CreateBaselineResponse
+	testBaselineClone(t, "/1/clone", `{"name": "cloned_baseline"}`, http.StatusOK, &resp)
+	desc := "desc"
+	database.CheckBaseline(t, resp.BaselineID, []string{}, `{"to_time": "2010-09-22T00:00:00+00:00"}`,
+		"cloned_baseline", &desc)
+	database.DeleteBaseline(t, resp.BaselineID)
+}
+
+func TestBaselineCloneDescription(t *testing.T) {
+	var resp CreateBaselineResponse
+	testBaselineClone(t, "/1/clone", `{"name": "cloned_baseline", "description": ""}`, http.StatusOK, &resp)
+	database.CheckBaseline(t, resp.BaselineID, []string{}, `{"to_time": "2010-09-22T00:00:00+00:00"}`,
+		"cloned_baseline", nil)
+	database.DeleteBaseline(t, resp.BaselineID)
+}
+
+func TestBaselineCloneDuplicatedName(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testBaselineClone(t, "/1/clone", `{"name": "baseline_1-2"}`, http.StatusBadRequest, &errResp)
+	assert.Equal(t, DuplicateBaselineNameErr, errResp.Error)
+}
+
+func TestBaselineCloneMissingName(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testBaselineClone(t, "/1/clone", `{}`, http.StatusBadRequest, &errResp)
+	assert.Equal(t, BaselineMissingNameErr, errResp.Error)
+}
+
+func TestBaselineCloneNotFound(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testBaselineClone(t, "/10000/clone", `{"name": "cloned_baseline"}`, http.StatusNotFound, &errResp)
+	assert.Equal(t, "baseline not found", errResp.Error)
+}
@@ -28,6 +28,8 @@ type CreateBaselineRequest struct {
 	Config *BaselineConfig `json:"config"`
 	// Description of the baseline (optional).
 	Description *string `json:"description"`
+	// Selector of systems dynamically associated with this baseline, it can't be combined with inventory_ids (optional).
+	Selector *BaselineSelector `json:"selector"`
 }
 
 type CreateBaselineResponse struct {
@@ -61,7 +63,11 @@ func CreateBaselineHandler(c *gin.Context) {
 	}
 	request.Description = utils.EmptyToNil(request.Description)
 
-	missingIDs, err := checkInventoryIDs(accountID, request.InventoryIDs)
+	if err := checkBaselineSelector(c, request.Selector, len(request.InventoryIDs) > 0); err != nil {
+		return
+	} // Error handled in method itself
+
+	missingIDs, err := checkInventoryIDs(accountID, middlewares.GetSystemScope(c), request.InventoryIDs)
 	if err != nil {
 		LogAndRespError(c, err, "Database error")
 		return
@@ -73,7 +79,7 @@ func CreateBaselineHandler(c *gin.Context) {
 		return
 	}
 
-	baselineID, err := buildCreateBaselineQuery(request, accountID)
+	baselineID, selectorIDs, err := buildCreateBaselineQuery(request, accountID)
 	if err != nil {
 		if database.IsPgErrorCode(err, database.PgErrorDuplicateKey) {
 			LogAndRespBadRequest(c, err, DuplicateBaselineNameErr)
@@ -84,14 +90,15 @@ func CreateBaselineHandler(c *gin.Context) {
 	}
 
 	configUpdated := request.Config != nil
-	inventoryIDs := kafka.GetInventoryIDsToEvaluate(&baselineID, accountID, configUpdated, nil)
+	inventoryIDs := kafka.GetInventoryIDsToEvaluate(&baselineID, accountID, configUpdated, selectorIDs)
 	kafka.EvaluateBaselineSystems(inventoryIDs)
 
 	resp := CreateBaselineResponse{BaselineID: baselineID}
 	c.JSON(http.StatusOK, &resp)
 }
 
-func buildCreateBaselineQuery(request CreateBaselineRequest, accountID int) (int, error) {
+// Returns created baseline ID and inventory IDs of systems associated by the selector
+func buildCreateBaselineQuery(request CreateBaselineRequest, accountID int) (int, []string, error) {
 	tx := database.Db.WithContext(base.Context).Begin()
 	defer tx.Rollback()
 
@@ -104,29 +111,44 @@ func buildCreateBaselineQuery(request CreateBaselineRequest, accountID int) (int
 	if request.Config != nil {
 		config, err := json.Marshal(request.Config)
 		if err != nil {
-			return 0, err
+			return 0, nil, err
 		}
 		baseline.Config = config
 	}
 
-	if err := tx.Model(models.Baseline{}).Create(&baseline).Error; err != nil {
-		return baseline.ID, err
+	selector, err := marshalBaselineSelector(request.Selector)
+	if err != nil {
+		return 0, nil, err
+	}
+	baseline.Selector = selector
+
+	if err = tx.Model(models.Baseline{}).Create(&baseline).Error; err != nil {
+		return baseline.ID, nil, err
 	}
 
 	if len(request.InventoryIDs) > 0 {
-		err := updateSystemsBaselineID(tx, accountID, request.InventoryIDs, &baseline.ID, nil)
+		err = updateSystemsBaselineID(tx, accountID, request.InventoryIDs, &baseline.ID, nil)
+		if err != nil {
+			return baseline.ID, nil, err
+		}
+	}
+
+	var selectorIDs []string
+	if request.Selector.isSet() {
+		selectorIDs, err = applyBaselineSelector(tx, accountID, baseline.ID, request.Selector)
 		if err != nil {
-			return baseline.ID, err
+			return baseline.ID, nil, err
 		}
 	}
 
-	err := tx.Commit().Error
-	return baseline.ID, err
+	err = tx.Commit().Error
+	return baseline.ID, selectorIDs, err
 }
 
-func checkInventoryIDs(accountID int, inventoryIDs []string) (missingIDs []string, err error) {
+func checkInventoryIDs(accountID int, scope *database.SystemScope, inventoryIDs []string) (
+	missingIDs []string, err error) {
 	var containingIDs []string
-	err = database.Db.Table("system_platform sp").
+	err = database.ApplySystemScope(database.Db.Table("system_platform sp"), scope).
 		Where("rh_account_id = ? AND inventory_id::text IN (?)", accountID, inventoryIDs).
 		Pluck("sp.inventory_id", &containingIDs).Error
 	if err != nil {
@@ -25,9 +25,10 @@ type BaselineDetailItem struct {
 }
 
 type BaselineDetailAttributes struct {
-	Name        string          `json:"name" example:"my_baseline"` // Baseline name
-	Config      *BaselineConfig `json:"config"`                     // Baseline config
-	Description string          `json:"description"`
+	Name        string            `json:"name" example:"my_baseline"` // Baseline name
+	Config      *BaselineConfig   `json:"config"`                     // Baseline config
+	Description string            `json:"description"`
+	Selector    *BaselineSelector `json:"selector"` // Selector of systems dynamically associated with the baseline
 }
 
 // @Summary Show baseline detail by given baseline ID
@@ -87,6 +88,7 @@ func getBaseline(accountID, baselineID int) (*BaselineDetailItem, error) {
 			Name:        baseline.Name,
 			Config:      config,
 			Description: description,
+			Selector:    tryParseBaselineSelector(baseline.Selector),
 		},
 		Type: "baseline",
 	}
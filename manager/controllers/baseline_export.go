@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/manager/middlewares"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Portable baseline document, it can be kept in git and imported to another organization
+type BaselineDocument struct {
+	// Baseline name, baselines are matched by name on import
+	Name string `json:"name" yaml:"name" example:"my_baseline"`
+	// Description of the baseline (optional)
+	Description *string `json:"description,omitempty" yaml:"description,omitempty" example:"desc"`
+	// Baseline config to filter applicable advisories and package updates (optional)
+	Config *BaselineConfig `json:"config,omitempty" yaml:"config,omitempty"`
+	// Display names of systems associated with the baseline, membership is kept when missing (optional)
+	Systems []string `json:"systems,omitempty" yaml:"systems,omitempty" example:"host1.example.com"`
+	// Selector of systems dynamically associated with the baseline, it can't be combined with systems (optional)
+	Selector *BaselineSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+}
+
+// @Summary Export a baseline as a portable document
+// @Description Export a baseline as a portable JSON or YAML document which can be imported to another organization
+// @ID exportBaseline
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json,application/yaml
+// @Param    baseline_id        path    int     true    "Baseline ID"
+// @Param    include_systems    query   bool    false   "Include display names of the baseline systems"
+// @Success 200 {object} BaselineDocument
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 415 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /baselines/{baseline_id}/export [get]
+func BaselineExportHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	baselineIDstr := c.Param("baseline_id")
+	baselineID, err := strconv.Atoi(baselineIDstr)
+	if err != nil {
+		LogAndRespBadRequest(c, err, "Invalid baseline_id: "+baselineIDstr)
+		return
+	}
+
+	var baseline models.Baseline
+	err = database.Db.Where("rh_account_id = ? AND id = ?", account, baselineID).First(&baseline).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			LogAndRespNotFound(c, err, "baseline not found")
+		} else {
+			LogAndRespError(c, err, "Database error")
+		}
+		return
+	}
+
+	doc := BaselineDocument{
+		Name:        baseline.Name,
+		Description: baseline.Description,
+		Config:      tryParseBaselineConfig(baseline.Config),
+		Selector:    tryParseBaselineSelector(baseline.Selector),
+	}
+	// systems of baseline with selector are associated dynamically
+	if c.Query("include_systems") == "true" && !doc.Selector.isSet() {
+		err = database.ApplySystemScope(database.Db.Table("system_platform sp"), middlewares.GetSystemScope(c)).
+			Where("sp.rh_account_id = ? AND sp.baseline_id = ?", account, baselineID).
+			Order("sp.display_name").
+			Pluck("sp.display_name", &doc.Systems).Error
+		if err != nil {
+			LogAndRespError(c, err, "Database error")
+			return
+		}
+	}
+
+	accept := c.GetHeader("Accept")
+	if strings.Contains(accept, "application/json") { // nolint: gocritic
+		c.JSON(http.StatusOK, &doc)
+	} else if strings.Contains(accept, "yaml") {
+		c.YAML(http.StatusOK, &doc)
+	} else {
+		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'application/yaml'", accept))
+	}
+}
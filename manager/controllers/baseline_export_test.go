@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaselineExportJSON(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/1/export?include_systems=true", nil, "application/json",
+		BaselineExportHandler, "/:baseline_id/export")
+
+	var output BaselineDocument
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, "baseline_1-1", output.Name)
+	assert.Equal(t, "desc", *output.Description)
+	assert.Equal(t, "2010-09-22T00:00:00Z", output.Config.ToTime.Format(time.RFC3339))
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"},
+		output.Systems)
+}
+
+func TestBaselineExportYAML(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/2/export", nil, "application/yaml",
+		BaselineExportHandler, "/:baseline_id/export")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.True(t, strings.Contains(body, "name: baseline_1-2\n"))
+	assert.True(t, strings.Contains(body, "to_time: 2021-01-01T00:00:00Z\n"))
+	assert.False(t, strings.Contains(body, "description"))
+	assert.False(t, strings.Contains(body, "systems"))
+}
+
+func TestBaselineExportNotFound(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/10000/export", nil, "application/json",
+		BaselineExportHandler, "/:baseline_id/export")
+
+	var output utils.ErrorResponse
+	CheckResponse(t, w, http.StatusNotFound, &output)
+	assert.Equal(t, "baseline not found", output.Error)
+}
+
+func TestBaselineExportUnsupportedType(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/1/export", nil, "text/csv", BaselineExportHandler, "/:baseline_id/export")
+
+	var output utils.ErrorResponse
+	CheckResponse(t, w, http.StatusUnsupportedMediaType, &output)
+}
@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/kafka"
+	"app/manager/middlewares"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// Status of the imported baseline
+const (
+	BaselineImportCreated   = "created"
+	BaselineImportUpdated   = "updated"
+	BaselineImportUnchanged = "unchanged"
+)
+
+type BaselineImportItem struct {
+	BaselineID int    `json:"baseline_id" example:"1"`    // Created or updated baseline ID
+	Name       string `json:"name" example:"my_baseline"` // Baseline name
+	Status     string `json:"status" example:"created"`   // Import status (created, updated, unchanged)
+}
+
+type BaselineImportResponse struct {
+	Baselines []BaselineImportItem `json:"baselines"`
+}
+
+// Imported baseline with resolved systems
+type baselineImport struct {
+	BaselineDocument
+	InventoryIDs []string
+	// filled during import
+	ID             int
+	ConfigUpdated  bool
+	UpdatedSystems []string
+}
+
+// @Summary Import baselines from portable documents
+// @Description Create or update baselines by name from a JSON or YAML list of documents (or a single document),
+// @Description importing the same documents again leaves the baselines unchanged
+// @ID importBaselines
+// @Security RhIdentity
+// @Accept   json,application/yaml
+// @Produce  json
+// @Param    body    body    []BaselineDocument true "Request body"
+// @Success 200 {object} BaselineImportResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /baselines/import [post]
+func BaselineImportHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	docs, err := parseBaselineDocuments(c)
+	if err != nil {
+		LogAndRespBadRequest(c, err, "Invalid request body: "+err.Error())
+		return
+	}
+
+	imports, err := validateBaselineImports(c, account, docs)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	resp, err := importBaselines(account, middlewares.GetSystemScope(c), imports)
+	if err != nil {
+		if database.IsPgErrorCode(err, database.PgErrorDuplicateKey) {
+			LogAndRespBadRequest(c, err, DuplicateBaselineNameErr)
+			return
+		}
+		if e := err.Error(); e == ForeignBaselineViolationErr {
+			LogAndRespBadRequest(c, err, "Invalid systems: "+e)
+			return
+		}
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	for _, imp := range imports {
+		if !imp.ConfigUpdated && len(imp.UpdatedSystems) == 0 {
+			continue
+		}
+		inventoryAIDs := kafka.GetInventoryIDsToEvaluate(&imp.ID, account, imp.ConfigUpdated, imp.UpdatedSystems)
+		kafka.EvaluateBaselineSystems(inventoryAIDs)
+	}
+
+	c.JSON(http.StatusOK, &resp)
+}
+
+// Parses list of documents, or a single document, from JSON or YAML body
+func parseBaselineDocuments(c *gin.Context) ([]BaselineDocument, error) {
+	data, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+
+	var bind binding.BindingBody = binding.JSON
+	if strings.Contains(c.ContentType(), "yaml") {
+		bind = binding.YAML
+	}
+
+	var docs []BaselineDocument
+	if err = bind.BindBody(data, &docs); err == nil {
+		return docs, nil
+	}
+	var doc BaselineDocument
+	if err = bind.BindBody(data, &doc); err != nil {
+		return nil, err
+	}
+	return []BaselineDocument{doc}, nil
+}
+
+// Checks names and resolves display names of the systems to inventory IDs
+func validateBaselineImports(c *gin.Context, account int, docs []BaselineDocument) ([]*baselineImport, error) {
+	names := map[string]bool{}
+	systems := map[string]bool{}
+	for _, doc := range docs {
+		if doc.Name == "" {
+			err := errors.New(BaselineMissingNameErr)
+			LogAndRespBadRequest(c, err, BaselineMissingNameErr)
+			return nil, err
+		}
+		if names[doc.Name] {
+			msg := "Duplicate baseline name: " + doc.Name
+			err := errors.New(msg)
+			LogAndRespBadRequest(c, err, msg)
+			return nil, err
+		}
+		names[doc.Name] = true
+		for _, system := range doc.Systems {
+			if systems[system] {
+				msg := "System in multiple baselines: " + system
+				err := errors.New(msg)
+				LogAndRespBadRequest(c, err, msg)
+				return nil, err
+			}
+			systems[system] = true
+		}
+		if err := checkBaselineSelector(c, doc.Selector, len(doc.Systems) > 0); err != nil {
+			return nil, err
+		} // Error handled in method itself
+	}
+
+	inventoryIDs, err := resolveDisplayNames(c, account, map2list(systems))
+	if err != nil {
+		return nil, err
+	} // Error handled in method itself
+
+	imports := make([]*baselineImport, 0, len(docs))
+	for _, doc := range docs {
+		imp := baselineImport{BaselineDocument: doc}
+		imp.Description = utils.EmptyToNil(imp.Description)
+		if doc.Systems != nil {
+			imp.InventoryIDs = make([]string, 0)
+		}
+		for _, system := range doc.Systems {
+			imp.InventoryIDs = append(imp.InventoryIDs, inventoryIDs[system]...)
+		}
+		imports = append(imports, &imp)
+	}
+	return imports, nil
+}
+
+// Returns inventory IDs of the systems by display name, display names are not unique
+func resolveDisplayNames(c *gin.Context, account int, displayNames []string) (map[string][]string, error) {
+	inventoryIDs := map[string][]string{}
+	if len(displayNames) == 0 {
+		return inventoryIDs, nil
+	}
+
+	var rows []struct {
+		InventoryID string
+		DisplayName string
+	}
+	err := database.ApplySystemScope(database.Db.Table("system_platform sp"), middlewares.GetSystemScope(c)).
+		Select("sp.inventory_id, sp.display_name").
+		Where("sp.rh_account_id = ? AND sp.display_name IN (?)", account, displayNames).
+		Scan(&rows).Error
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return nil, err
+	}
+
+	for _, row := range rows {
+		inventoryIDs[row.DisplayName] = append(inventoryIDs[row.DisplayName], row.InventoryID)
+	}
+	missing := make([]string, 0)
+	for _, name := range displayNames {
+		if _, ok := inventoryIDs[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		msg := fmt.Sprintf("Missing systems: %v", missing)
+		err = errors.New(msg)
+		LogAndRespNotFound(c, err, msg)
+		return nil, err
+	}
+	return inventoryIDs, nil
+}
+
+func importBaselines(account int, scope *database.SystemScope, imports []*baselineImport) (
+	*BaselineImportResponse, error) {
+	tx := database.Db.WithContext(base.Context).Begin()
+	defer tx.Rollback()
+
+	resp := BaselineImportResponse{Baselines: make([]BaselineImportItem, 0, len(imports))}
+	for _, imp := range imports {
+		status, err := importBaseline(tx, account, scope, imp)
+		if err != nil {
+			return nil, err
+		}
+		resp.Baselines = append(resp.Baselines, BaselineImportItem{BaselineID: imp.ID, Name: imp.Name, Status: status})
+	}
+	return &resp, tx.Commit().Error
+}
+
+// Creates or updates the baseline with the same name, returns import status
+func importBaseline(tx *gorm.DB, account int, scope *database.SystemScope, imp *baselineImport) (string, error) {
+	var config []byte
+	if imp.Config != nil {
+		var err error
+		if config, err = json.Marshal(imp.Config); err != nil {
+			return "", err
+		}
+	}
+	selector, err := marshalBaselineSelector(imp.Selector)
+	if err != nil {
+		return "", err
+	}
+
+	status := BaselineImportUnchanged
+	var baseline models.Baseline
+	err = tx.Where("rh_account_id = ? AND name = ?", account, imp.Name).First(&baseline).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		baseline = models.Baseline{RhAccountID: account, Name: imp.Name, Description: imp.Description, Config: config,
+			Selector: selector}
+		if err = tx.Create(&baseline).Error; err != nil {
+			return "", err
+		}
+		status = BaselineImportCreated
+		imp.ConfigUpdated = config != nil
+	case err != nil:
+		return "", err
+	default:
+		updated, err := updateImportedBaseline(tx, &baseline, imp, config, selector)
+		if err != nil {
+			return "", err
+		}
+		if updated {
+			status = BaselineImportUpdated
+		}
+	}
+	imp.ID = baseline.ID
+
+	if imp.InventoryIDs != nil {
+		if err = importBaselineSystems(tx, account, scope, imp); err != nil {
+			return "", err
+		}
+	}
+	if imp.Selector.isSet() {
+		selectorIDs, err := applyBaselineSelector(tx, account, imp.ID, imp.Selector)
+		if err != nil {
+			return "", err
+		}
+		imp.UpdatedSystems = append(imp.UpdatedSystems, selectorIDs...)
+	}
+	if len(imp.UpdatedSystems) > 0 && status == BaselineImportUnchanged {
+		status = BaselineImportUpdated
+	}
+	return status, nil
+}
+
+// Updates description, config and selector of existing baseline when they differ from the imported ones
+func updateImportedBaseline(tx *gorm.DB, baseline *models.Baseline, imp *baselineImport, config, selector []byte) (
+	bool, error) {
+	var current []byte
+	if currentConfig := tryParseBaselineConfig(baseline.Config); currentConfig != nil {
+		var err error
+		if current, err = json.Marshal(currentConfig); err != nil {
+			return false, err
+		}
+	}
+	imp.ConfigUpdated = !bytes.Equal(current, config)
+	currentSelector, err := marshalBaselineSelector(tryParseBaselineSelector(baseline.Selector))
+	if err != nil {
+		return false, err
+	}
+	selectorUpdated := !bytes.Equal(currentSelector, selector)
+	currentDescription := utils.EmptyToNil(baseline.Description)
+	descriptionUpdated := (currentDescription == nil) != (imp.Description == nil) ||
+		currentDescription != nil && *currentDescription != *imp.Description
+	if !imp.ConfigUpdated && !selectorUpdated && !descriptionUpdated {
+		return false, nil
+	}
+
+	err = tx.Model(models.Baseline{}).
+		Where("id = ? AND rh_account_id = ?", baseline.ID, baseline.RhAccountID).
+		Updates(map[string]interface{}{"description": imp.Description, "config": config, "selector": selector}).Error
+	return err == nil, err
+}
+
+// Sets baseline systems to the imported ones, systems outside of the user scope are kept
+func importBaselineSystems(tx *gorm.DB, account int, scope *database.SystemScope, imp *baselineImport) error {
+	var current []string
+	err := database.ApplySystemScope(tx.Table("system_platform sp"), scope).
+		Where("sp.rh_account_id = ? AND sp.baseline_id = ?", account, imp.ID).
+		Pluck("sp.inventory_id", &current).Error
+	if err != nil {
+		return err
+	}
+
+	members := map[string]bool{}
+	for _, inventoryID := range current {
+		members[inventoryID] = false
+	}
+	var newIDs []string
+	for _, inventoryID := range imp.InventoryIDs {
+		if _, ok := members[inventoryID]; !ok {
+			newIDs = append(newIDs, inventoryID)
+		}
+		members[inventoryID] = true
+	}
+	var obsoleteIDs []string
+	for inventoryID, keep := range members {
+		if !keep {
+			obsoleteIDs = append(obsoleteIDs, inventoryID)
+		}
+	}
+
+	if len(newIDs) > 0 {
+		if err = updateSystemsBaselineID(tx, account, newIDs, &imp.ID, nil); err != nil {
+			return err
+		}
+	}
+	if len(obsoleteIDs) > 0 {
+		if err = updateSystemsBaselineID(tx, account, obsoleteIDs, nil, &imp.ID); err != nil {
+			return err
+		}
+	}
+	imp.UpdatedSystems = append(newIDs, obsoleteIDs...)
+	return nil
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/utils"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBaselineImport(t *testing.T, data, contentType string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	req, _ := http.NewRequest("POST", "/import", bytes.NewBufferString(data))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	core.InitRouterWithParams(BaselineImportHandler, 1, "POST", "/import").ServeHTTP(w, req)
+	CheckResponse(t, w, expectedStatus, output)
+}
+
+func TestBaselineImport(t *testing.T) {
+	data := `[
+		{"name": "imported_baseline", "config": {"to_time": "2022-12-31T12:00:00-04:00"},
+		 "systems": ["00000000-0000-0000-0000-000000000005"]},
+		{"name": "baseline_1-3", "description": "imported", "config": {"to_time": "2000-01-01T00:00:00Z"}}
+	]`
+	var resp BaselineImportResponse
+	testBaselineImport(t, data, "application/json", http.StatusOK, &resp)
+	assert.Equal(t, 2, len(resp.Baselines))
+	assert.Equal(t, "imported_baseline", resp.Baselines[0].Name)
+	assert.Equal(t, BaselineImportCreated, resp.Baselines[0].Status)
+	assert.Equal(t, 3, resp.Baselines[1].BaselineID)
+	assert.Equal(t, BaselineImportUpdated, resp.Baselines[1].Status)
+
+	importedID := resp.Baselines[0].BaselineID
+	database.CheckBaseline(t, importedID, []string{"00000000-0000-0000-0000-000000000005"},
+		`{"to_time": "2022-12-31T12:00:00-04:00"}`, "imported_baseline", nil)
+	desc := "imported"
+	database.CheckBaseline(t, 3, []string{}, `{"to_time": "2000-01-01T00:00:00Z"}`, "baseline_1-3", &desc)
+
+	// importing the same documents again changes nothing
+	testBaselineImport(t, data, "application/json", http.StatusOK, &resp)
+	assert.Equal(t, BaselineImportUnchanged, resp.Baselines[0].Status)
+	assert.Equal(t, importedID, resp.Baselines[0].BaselineID)
+	assert.Equal(t, BaselineImportUnchanged, resp.Baselines[1].Status)
+
+	// empty systems list removes all systems
+	testBaselineImport(t, `{"name": "imported_baseline", "config": {"to_time": "2022-12-31T12:00:00-04:00"},
+		"systems": []}`, "application/json", http.StatusOK, &resp)
+	assert.Equal(t, BaselineImportUpdated, resp.Baselines[0].Status)
+	database.CheckBaseline(t, importedID, []string{}, `{"to_time": "2022-12-31T12:00:00-04:00"}`,
+		"imported_baseline", nil)
+
+	database.DeleteBaseline(t, importedID)
+	assert.Nil(t, database.Db.Exec(
+		`UPDATE baseline SET description = NULL, config = '{"to_time": "2000-01-01T00:00:00+00:00"}' WHERE id = 3`).Error)
+}
+
+func TestBaselineImportYAML(t *testing.T) {
+	data := `
+name: imported_baseline
+description: desc
+config:
+  to_time: 2022-12-31T12:00:00-04:00
+`
+	var resp BaselineImportResponse
+	testBaselineImport(t, data, "application/yaml", http.StatusOK, &resp)
+	assert.Equal(t, 1, len(resp.Baselines))
+	assert.Equal(t, BaselineImportCreated, resp.Baselines[0].Status)
+	desc := "desc"
+	database.CheckBaseline(t, resp.Baselines[0].BaselineID, []string{}, `{"to_time": "2022-12-31T12:00:00-04:00"}`,
+		"imported_baseline", &desc)
+	database.DeleteBaseline(t, resp.Baselines[0].BaselineID)
+}
+
+func TestBaselineImportMissingName(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testBaselineImport(t, `[{"name": "imported_baseline"}, {"description": "desc"}]`, "application/json",
+		http.StatusBadRequest, &errResp)
+	assert.Equal(t, BaselineMissingNameErr, errResp.Error)
+}
+
+func TestBaselineImportDuplicateName(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testBaselineImport(t, `[{"name": "imported_baseline"}, {"name": "imported_baseline"}]`, "application/json",
+		http.StatusBadRequest, &errResp)
+	assert.Equal(t, "Duplicate baseline name: imported_baseline", errResp.Error)
+}
+
+func TestBaselineImportMissingSystems(t *testing.T) {
+	var errResp utils.ErrorResponse
+	data := `{"name": "imported_baseline", "systems": ["unknown-host", "00000000-0000-0000-0000-000000000009"]}`
+	testBaselineImport(t, data, "application/json", http.StatusNotFound, &errResp)
+	assert.Equal(t, "Missing systems: [00000000-0000-0000-0000-000000000009 unknown-host]", errResp.Error)
+}
+
+func TestBaselineImportSelectorWithSystems(t *testing.T) {
+	var errResp utils.ErrorResponse
+	data := `{"name": "imported_baseline", "systems": ["00000000-0000-0000-0000-000000000005"],
+		"selector": {"os_major": 8}}`
+	testBaselineImport(t, data, "application/json", http.StatusBadRequest, &errResp)
+	assert.Equal(t, BaselineSelectorSystemsErr, errResp.Error)
+}
+
+func TestBaselineImportInvalid(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testBaselineImport(t, `{"name": 0}`, "application/json", http.StatusBadRequest, &errResp)
+	assert.Contains(t, errResp.Error, "Invalid request body")
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/manager/middlewares"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var baselineComplianceCountsSelect = database.MustGetSelect(&BaselineComplianceCounts{})
+var baselineReportAdvisorySelect = database.MustGetSelect(&BaselineReportAdvisory{})
+var baselineReportSystemSelect = database.MustGetSelect(&BaselineReportSystem{})
+
+// nolint: lll
+type BaselineComplianceCounts struct {
+	// Fresh systems associated with the baseline
+	Systems int `json:"systems" query:"count(*)" gorm:"column:systems"`
+	// Systems with all advisories up to the baseline applied
+	SystemsUpToDate int `json:"systems_uptodate" query:"count(*) filter (where sp.baseline_uptodate = true)" gorm:"column:systems_uptodate"`
+	// Systems with applicable advisories up to the baseline
+	SystemsOutdated int `json:"systems_outdated" query:"count(*) filter (where sp.baseline_uptodate = false)" gorm:"column:systems_outdated"`
+	// Systems not evaluated since they were associated with the baseline
+	SystemsNotEvaluated int `json:"systems_not_evaluated" query:"count(*) filter (where sp.baseline_uptodate is null)" gorm:"column:systems_not_evaluated"`
+}
+
+type BaselineReportAdvisory struct {
+	ID               string `json:"id" query:"am.name" gorm:"column:id"`
+	Synopsis         string `json:"synopsis" query:"am.synopsis" gorm:"column:synopsis"`
+	AdvisoryTypeName string `json:"advisory_type_name" query:"at.name" gorm:"column:advisory_type_name"`
+	Severity         *int   `json:"severity,omitempty" query:"am.severity_id" gorm:"column:severity"`
+	// Out of date systems the advisory is applicable to
+	OutdatedSystems int `json:"outdated_systems" query:"count(sp.id)" gorm:"column:outdated_systems"`
+}
+
+// nolint: lll
+type BaselineReportSystem struct {
+	InventoryID string `json:"inventory_id" csv:"inventory_id" query:"sp.inventory_id" gorm:"column:inventory_id"`
+	DisplayName string `json:"display_name" csv:"display_name" query:"sp.display_name" gorm:"column:display_name"`
+	// Whether the system is up to date with the baseline, null when it's not evaluated yet
+	UpToDate *bool `json:"uptodate" csv:"uptodate" query:"sp.baseline_uptodate" gorm:"column:uptodate"`
+	// Time when the system became out of date with the baseline
+	OutdatedSince *time.Time `json:"outdated_since" csv:"outdated_since" query:"sp.baseline_outdated_since" gorm:"column:outdated_since"`
+	// Days since the system became out of date with the baseline
+	OutdatedDays *int `json:"outdated_days" csv:"outdated_days" query:"date_part('day', now() - sp.baseline_outdated_since)::int" gorm:"column:outdated_days"`
+	// Advisories applicable to the system up to the baseline
+	ApplicableAdvisories int `json:"applicable_advisories" csv:"applicable_advisories" query:"sp.advisory_count_cache" gorm:"column:applicable_advisories"`
+}
+
+type BaselineReportAttributes struct {
+	Name string `json:"name" example:"my_baseline"` // Baseline name
+	BaselineComplianceCounts
+	Advisories []BaselineReportAdvisory `json:"advisories"` // Advisories causing the drift of out of date systems
+	Systems    []BaselineReportSystem   `json:"systems"`    // Baseline systems, the longest out of date first
+}
+
+type BaselineReportItem struct {
+	Attributes BaselineReportAttributes `json:"attributes"`                     // Baseline compliance report
+	ID         int                      `json:"id" example:"1"`                 // Baseline ID
+	Type       string                   `json:"type" example:"baseline_report"` // Document type name
+}
+
+type BaselineReportResponse struct {
+	Data BaselineReportItem `json:"data"`
+}
+
+// @Summary Show compliance report of the baseline systems
+// @Description Show counts of up to date and out of date baseline systems, advisories causing the drift
+// @Description and time since each system became out of date
+// @ID baselineReport
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    baseline_id    path    int     true    "Baseline ID"
+// @Success 200 {object} BaselineReportResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /baselines/{baseline_id}/report [get]
+func BaselineReportHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+	baseline, err := loadReportBaseline(c, account)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	attrs, err := baselineReport(account, middlewares.GetSystemScope(c), baseline)
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	c.JSON(http.StatusOK, &BaselineReportResponse{Data: BaselineReportItem{
+		Attributes: *attrs,
+		ID:         baseline.ID,
+		Type:       "baseline_report",
+	}})
+}
+
+// @Summary Export compliance report of the baseline systems
+// @Description Export baseline systems with time since each system became out of date
+// @ID exportBaselineReport
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json,text/csv
+// @Param    baseline_id    path    int     true    "Baseline ID"
+// @Success 200 {array} BaselineReportSystem
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 415 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /export/baselines/{baseline_id}/report [get]
+func BaselineReportExportHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+	baseline, err := loadReportBaseline(c, account)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	systems, err := baselineReportSystems(account, middlewares.GetSystemScope(c), baseline.ID)
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+	ExportWithFieldset(c, systems, nil)
+}
+
+// Returns the baseline given in path, error is sent in response
+func loadReportBaseline(c *gin.Context, account int) (*models.Baseline, error) {
+	baselineIDstr := c.Param("baseline_id")
+	baselineID, err := strconv.Atoi(baselineIDstr)
+	if err != nil {
+		LogAndRespBadRequest(c, err, "Invalid baseline_id: "+baselineIDstr)
+		return nil, err
+	}
+
+	var baseline models.Baseline
+	err = database.Db.Where("rh_account_id = ? AND id = ?", account, baselineID).First(&baseline).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			LogAndRespNotFound(c, err, "baseline not found")
+		} else {
+			LogAndRespError(c, err, "Database error")
+		}
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+func baselineReportQuery(account int, scope *database.SystemScope, baselineID int) *gorm.DB {
+	return database.Systems(database.Db, account, scope).
+		Where("sp.baseline_id = ? AND sp.stale = false", baselineID)
+}
+
+func baselineReport(account int, scope *database.SystemScope, baseline *models.Baseline) (
+	*BaselineReportAttributes, error) {
+	attrs := BaselineReportAttributes{Name: baseline.Name, Advisories: make([]BaselineReportAdvisory, 0)}
+	err := baselineReportQuery(account, scope, baseline.ID).
+		Select(baselineComplianceCountsSelect).
+		Scan(&attrs.BaselineComplianceCounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// advisories up to the baseline applicable to out of date systems
+	err = baselineReportQuery(account, scope, baseline.ID).
+		Joins("JOIN system_advisories sa ON sa.system_id = sp.id AND sa.rh_account_id = sp.rh_account_id").
+		Joins("JOIN advisory_metadata am ON am.id = sa.advisory_id").
+		Joins("JOIN advisory_type at ON am.advisory_type_id = at.id").
+		Where("sp.baseline_uptodate = false AND sa.when_patched IS NULL").
+		Select(baselineReportAdvisorySelect).
+		Group("am.id, at.id").
+		Order("outdated_systems DESC, am.name").
+		Scan(&attrs.Advisories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	attrs.Systems, err = baselineReportSystems(account, scope, baseline.ID)
+	return &attrs, err
+}
+
+func baselineReportSystems(account int, scope *database.SystemScope, baselineID int) ([]BaselineReportSystem, error) {
+	systems := make([]BaselineReportSystem, 0)
+	err := baselineReportQuery(account, scope, baselineID).
+		Select(baselineReportSystemSelect).
+		Order("sp.baseline_outdated_since ASC NULLS LAST, sp.display_name, sp.id").
+		Scan(&systems).Error
+	return systems, err
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBaselineReport(t *testing.T, url string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", url, nil, "", BaselineReportHandler, "/:baseline_id/report")
+	CheckResponse(t, w, expectedStatus, &output)
+}
+
+func TestBaselineReportOutdated(t *testing.T) {
+	var output BaselineReportResponse
+	testBaselineReport(t, "/2/report", http.StatusOK, &output)
+	assert.Equal(t, 2, output.Data.ID)
+	assert.Equal(t, "baseline_report", output.Data.Type)
+	attrs := output.Data.Attributes
+	assert.Equal(t, "baseline_1-2", attrs.Name)
+	assert.Equal(t, BaselineComplianceCounts{Systems: 1, SystemsOutdated: 1}, attrs.BaselineComplianceCounts)
+
+	assert.Equal(t, 1, len(attrs.Advisories))
+	assert.Equal(t, "RH-1", attrs.Advisories[0].ID)
+	assert.Equal(t, 1, attrs.Advisories[0].OutdatedSystems)
+
+	assert.Equal(t, 1, len(attrs.Systems))
+	system := attrs.Systems[0]
+	assert.Equal(t, "00000000-0000-0000-0000-000000000003", system.InventoryID)
+	assert.False(t, *system.UpToDate)
+	assert.Equal(t, "2018-09-20T16:00:00Z", system.OutdatedSince.UTC().Format(time.RFC3339))
+	assert.Equal(t, int(time.Since(*system.OutdatedSince).Hours()/24), *system.OutdatedDays)
+}
+
+func TestBaselineReportUpToDate(t *testing.T) {
+	var output BaselineReportResponse
+	testBaselineReport(t, "/1/report", http.StatusOK, &output)
+	attrs := output.Data.Attributes
+	assert.Equal(t, BaselineComplianceCounts{Systems: 2, SystemsUpToDate: 2}, attrs.BaselineComplianceCounts)
+	assert.Equal(t, 0, len(attrs.Advisories))
+	assert.Equal(t, 2, len(attrs.Systems))
+	assert.Nil(t, attrs.Systems[0].OutdatedSince)
+	assert.Nil(t, attrs.Systems[0].OutdatedDays)
+}
+
+func TestBaselineReportNotFound(t *testing.T) {
+	var output utils.ErrorResponse
+	testBaselineReport(t, "/10000/report", http.StatusNotFound, &output)
+	assert.Equal(t, "baseline not found", output.Error)
+}
+
+func TestBaselineReportInvalid(t *testing.T) {
+	var output utils.ErrorResponse
+	testBaselineReport(t, "/invalidID/report", http.StatusBadRequest, &output)
+	assert.Equal(t, "Invalid baseline_id: invalidID", output.Error)
+}
+
+func TestBaselineReportExportCSV(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/2/report", nil, "text/csv", BaselineReportExportHandler, 1,
+		"GET", "/:baseline_id/report")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	lines := strings.Split(w.Body.String(), "\n")
+	assert.Equal(t, "inventory_id,display_name,uptodate,outdated_since,outdated_days,applicable_advisories", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1],
+		"00000000-0000-0000-0000-000000000003,00000000-0000-0000-0000-000000000003,false,2018-09-20"))
+}
+
+func TestBaselineReportExportJSON(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/1/report", nil, "application/json", BaselineReportExportHandler, 1,
+		"GET", "/:baseline_id/report")
+
+	var output []BaselineReportSystem
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, 2, len(output))
+	assert.True(t, *output[0].UpToDate)
+}
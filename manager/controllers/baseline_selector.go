@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const BaselineSelectorSystemsErr = "systems can not be assigned to baseline with selector"
+const BaselineSelectorScopeErr = "baseline selector requires access to all systems"
+const BaselineSelectorTagErr = "missing key of baseline selector tag"
+
+type BaselineSelector database.BaselineSelector
+
+func (s *BaselineSelector) isSet() bool {
+	return s != nil && !(*database.BaselineSelector)(s).IsEmpty()
+}
+
+// Checks the selector can be set, error is sent in response
+func checkBaselineSelector(c *gin.Context, selector *BaselineSelector, hasSystems bool) error {
+	if selector == nil {
+		return nil
+	}
+	if middlewares.GetSystemScope(c) != nil {
+		// selector is applied to all systems of the account
+		err := errors.New(BaselineSelectorScopeErr)
+		LogAndRespStatusError(c, http.StatusForbidden, err, BaselineSelectorScopeErr)
+		return err
+	}
+	if selector.isSet() && hasSystems {
+		err := errors.New(BaselineSelectorSystemsErr)
+		LogAndRespBadRequest(c, err, BaselineSelectorSystemsErr)
+		return err
+	}
+	for _, tag := range selector.Tags {
+		if tag.Key == "" {
+			err := errors.New(BaselineSelectorTagErr)
+			LogAndRespBadRequest(c, err, BaselineSelectorTagErr)
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns selector stored in the database, empty selector is stored as NULL
+func marshalBaselineSelector(selector *BaselineSelector) ([]byte, error) {
+	if !selector.isSet() {
+		return nil, nil
+	}
+	return json.Marshal(selector)
+}
+
+func tryParseBaselineSelector(selectorJSON []byte) *BaselineSelector {
+	selector, err := database.ParseBaselineSelector(selectorJSON)
+	if err != nil {
+		utils.Log("err", err.Error()).Warn("Unable to parse baseline selector json")
+		return nil
+	}
+	return (*BaselineSelector)(selector)
+}
+
+// Associates matching systems without baseline with the baseline and removes baseline systems
+// which don't match anymore, returns inventory IDs of the updated systems
+func applyBaselineSelector(tx *gorm.DB, account, baselineID int, selector *BaselineSelector) ([]string, error) {
+	cond, args := (*database.BaselineSelector)(selector).Condition()
+
+	var newIDs []string
+	err := tx.Table("system_platform sp").
+		Where("sp.rh_account_id = ? AND sp.baseline_id IS NULL", account).
+		Where(cond, args...).
+		Pluck("sp.inventory_id", &newIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var obsoleteIDs []string
+	err = tx.Table("system_platform sp").
+		Where("sp.rh_account_id = ? AND sp.baseline_id = ?", account, baselineID).
+		Where("NOT ("+cond+")", args...).
+		Pluck("sp.inventory_id", &obsoleteIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(newIDs) > 0 {
+		if err = updateSystemsBaselineID(tx, account, newIDs, &baselineID, nil); err != nil {
+			return nil, err
+		}
+	}
+	if len(obsoleteIDs) > 0 {
+		if err = updateSystemsBaselineID(tx, account, obsoleteIDs, nil, &baselineID); err != nil {
+			return nil, err
+		}
+	}
+	return append(newIDs, obsoleteIDs...), nil
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/utils"
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBaselineSelector(t *testing.T) {
+	core.SetupTest(t)
+	data := `{"name": "selector_baseline", "selector": {"tags": [{"key": "k3", "value": "val4"}]}}`
+	w := CreateRequestRouterWithParams("PUT", "/", bytes.NewBufferString(data), "", CreateBaselineHandler, 1, "PUT", "/")
+
+	var resp CreateBaselineResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+	// system 3 matches the selector but it's already associated with baseline 2
+	database.CheckBaseline(t, resp.BaselineID, []string{"00000000-0000-0000-0000-000000000004"}, "",
+		"selector_baseline", nil)
+
+	var output BaselineDetailResponse
+	testBaselineDetail(t, fmt.Sprintf("/%v", resp.BaselineID), http.StatusOK, &output)
+	assert.Equal(t, 1, len(output.Data.Attributes.Selector.Tags))
+	assert.Equal(t, "k3", output.Data.Attributes.Selector.Tags[0].Key)
+	assert.Equal(t, "val4", *output.Data.Attributes.Selector.Tags[0].Value)
+	database.DeleteBaseline(t, resp.BaselineID)
+}
+
+func TestCreateBaselineSelectorWithSystems(t *testing.T) {
+	core.SetupTest(t)
+	data := `{
+		"name": "selector_baseline",
+		"inventory_ids": ["00000000-0000-0000-0000-000000000005"],
+		"selector": {"display_name": "00000000-*"}
+	}`
+	w := CreateRequestRouterWithParams("PUT", "/", bytes.NewBufferString(data), "", CreateBaselineHandler, 1, "PUT", "/")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, BaselineSelectorSystemsErr, errResp.Error)
+}
+
+func TestCreateBaselineSelectorScoped(t *testing.T) {
+	core.SetupTest(t)
+	data := `{"name": "selector_baseline", "selector": {"display_name": "00000000-*"}}`
+	scope := &database.SystemScope{Tags: []database.ScopeTag{{Key: "k1"}}}
+	w := CreateRequestRouterWithParams("PUT", "/", bytes.NewBufferString(data), "",
+		withSystemScope(scope, CreateBaselineHandler), 1, "PUT", "/")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusForbidden, &errResp)
+	assert.Equal(t, BaselineSelectorScopeErr, errResp.Error)
+}
+
+func TestCreateBaselineSelectorMissingTagKey(t *testing.T) {
+	core.SetupTest(t)
+	data := `{"name": "selector_baseline", "selector": {"tags": [{"value": "val4"}]}}`
+	w := CreateRequestRouterWithParams("PUT", "/", bytes.NewBufferString(data), "", CreateBaselineHandler, 1, "PUT", "/")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, BaselineSelectorTagErr, errResp.Error)
+}
+
+func testUpdateBaselineSelector(t *testing.T, baselineID int, data string) {
+	path := fmt.Sprintf(`/%v`, baselineID)
+	w := CreateRequestRouterWithParams("PUT", path, bytes.NewBufferString(data), "", BaselineUpdateHandler, 1,
+		"PUT", "/:baseline_id")
+	var resp UpdateBaselineResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+	assert.Equal(t, baselineID, resp.BaselineID)
+}
+
+func TestUpdateBaselineSelector(t *testing.T) {
+	core.SetupTest(t)
+	baselineID := database.CreateBaseline(t, "", []string{"00000000-0000-0000-0000-000000000005"})
+	config := `{"to_time": "2021-01-01T12:00:00-04:00"}`
+
+	// system 5 doesn't match and leaves the baseline, matching system 4 joins it
+	testUpdateBaselineSelector(t, baselineID, `{"selector": {"display_name": "*-000000000004"}}`)
+	database.CheckBaseline(t, baselineID, []string{"00000000-0000-0000-0000-000000000004"}, config,
+		"temporary_baseline", nil)
+
+	// systems are kept when the selector is removed
+	testUpdateBaselineSelector(t, baselineID, `{"selector": {}}`)
+	database.CheckBaseline(t, baselineID, []string{"00000000-0000-0000-0000-000000000004"}, config,
+		"temporary_baseline", nil)
+	var output BaselineDetailResponse
+	testBaselineDetail(t, fmt.Sprintf("/%v", baselineID), http.StatusOK, &output)
+	assert.Nil(t, output.Data.Attributes.Selector)
+	database.DeleteBaseline(t, baselineID)
+}
+
+func TestUpdateBaselineSelectorSystems(t *testing.T) {
+	core.SetupTest(t)
+	data := `{"name": "selector_baseline", "selector": {"display_name": "*-000000000004"}}`
+	w := CreateRequestRouterWithParams("PUT", "/", bytes.NewBufferString(data), "", CreateBaselineHandler, 1, "PUT", "/")
+	var resp CreateBaselineResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+
+	data = `{"inventory_ids": {"00000000-0000-0000-0000-000000000005": true}}`
+	path := fmt.Sprintf(`/%v`, resp.BaselineID)
+	w = CreateRequestRouterWithParams("PUT", path, bytes.NewBufferString(data), "", BaselineUpdateHandler, 1,
+		"PUT", "/:baseline_id")
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, BaselineSelectorSystemsErr, errResp.Error)
+	database.DeleteBaseline(t, resp.BaselineID)
+}
@@ -88,7 +88,7 @@ func BaselineSystemsListHandler(c *gin.Context) {
 		return
 	}
 
-	query := buildQueryBaselineSystems(account, baselineID)
+	query := buildQueryBaselineSystems(account, middlewares.GetSystemScope(c), baselineID)
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return
@@ -116,12 +116,12 @@ func BaselineSystemsListHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, &resp)
 }
 
-func buildQueryBaselineSystems(account int, baselineID string) *gorm.DB {
+func buildQueryBaselineSystems(account int, scope *database.SystemScope, baselineID string) *gorm.DB {
 	query := database.Db.Table("system_platform AS sp").Select(BaselineSystemSelect).
 		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
 		Where("sp.rh_account_id = ? AND sp.baseline_id = ?", account, baselineID).
 		Where("sp.stale = false")
-	return query
+	return database.ApplySystemScope(query, scope)
 }
 
 func buildBaselineSystemData(baselineSystems []BaselineSystemsDBLookup) []BaselineSystemItem {
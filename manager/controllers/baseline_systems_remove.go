@@ -41,7 +41,7 @@ func BaselineSystemsRemoveHandler(c *gin.Context) {
 		return
 	}
 
-	err := buildBaselineSystemsRemoveQuery(req.InventoryIDs, account)
+	err := buildBaselineSystemsRemoveQuery(req.InventoryIDs, account, middlewares.GetSystemScope(c))
 	if err != nil {
 		switch e := err.Error(); e {
 		case InvalidInventoryIDsErr:
@@ -56,7 +56,7 @@ func BaselineSystemsRemoveHandler(c *gin.Context) {
 }
 
 func buildBaselineSystemsRemoveQuery(inventoryIDs []string,
-	accountID int) error {
+	accountID int, scope *database.SystemScope) error {
 	if len(inventoryIDs) == 0 {
 		return errors.New(InvalidInventoryIDsErr)
 	}
@@ -73,6 +73,7 @@ func buildBaselineSystemsRemoveQuery(inventoryIDs []string,
 			"baseline_id is NOT NULL AND "+
 			"inventory_id::uuid IN (?)",
 			accountID, inventoryIDs).
+		Where("id IN (?)", database.Systems(database.Db, accountID, scope).Select("sp.id")).
 		Update("baseline_id", nil)
 	if e := tx.Error; e != nil {
 		return e
@@ -30,6 +30,9 @@ type UpdateBaselineRequest struct {
 	Config *BaselineConfig `json:"config"`
 	// Description of the baseline (optional).
 	Description *string `json:"description,omitempty"`
+	// Updated selector of systems dynamically associated with the baseline, empty selector removes it,
+	// systems are kept in the baseline (optional)
+	Selector *BaselineSelector `json:"selector"`
 }
 
 type UpdateBaselineResponse struct {
@@ -66,20 +69,27 @@ func BaselineUpdateHandler(c *gin.Context) {
 		return
 	}
 
-	var exists int64
-	err = database.Db.Model(&models.Baseline{}).
-		Where("id = ? AND rh_account_id = ?", baselineID, account).Count(&exists).Error
+	var baseline models.Baseline
+	err = database.Db.Where("id = ? AND rh_account_id = ?", baselineID, account).First(&baseline).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			LogAndRespNotFound(c, err, "Baseline not found")
+			return
+		}
 		LogAndRespError(c, err, "Database error")
 		return
 	}
-	if exists == 0 {
-		LogAndRespNotFound(c, errors.New("Baseline not found"), "Baseline not found")
+
+	inventoryIDsList := map2list(req.InventoryIDs)
+	if err = checkBaselineSelector(c, req.Selector, len(inventoryIDsList) > 0); err != nil {
+		return
+	} // Error handled in method itself
+	if req.Selector == nil && len(inventoryIDsList) > 0 && tryParseBaselineSelector(baseline.Selector).isSet() {
+		LogAndRespBadRequest(c, errors.New(BaselineSelectorSystemsErr), BaselineSelectorSystemsErr)
 		return
 	}
 
-	inventoryIDsList := map2list(req.InventoryIDs)
-	missingIDs, err := checkInventoryIDs(account, inventoryIDsList)
+	missingIDs, err := checkInventoryIDs(account, middlewares.GetSystemScope(c), inventoryIDsList)
 	if err != nil {
 		LogAndRespError(c, err, "Database error")
 		return
@@ -92,7 +102,7 @@ func BaselineUpdateHandler(c *gin.Context) {
 	}
 
 	newAssociations, obsoleteAssociations := sortInventoryIDs(req.InventoryIDs)
-	err = buildUpdateBaselineQuery(baselineID, req, newAssociations, obsoleteAssociations, account)
+	selectorIDs, err := buildUpdateBaselineQuery(baselineID, req, newAssociations, obsoleteAssociations, account)
 	if err != nil {
 		if e := err.Error(); e == ForeignBaselineViolationErr {
 			LogAndRespBadRequest(c, err, "Invalid inventory IDs: "+e)
@@ -106,7 +116,8 @@ func BaselineUpdateHandler(c *gin.Context) {
 		return
 	}
 
-	inventoryAIDs := kafka.GetInventoryIDsToEvaluate(&baselineID, account, req.Config != nil, inventoryIDsList)
+	inventoryAIDs := kafka.GetInventoryIDsToEvaluate(&baselineID, account, req.Config != nil,
+		append(inventoryIDsList, selectorIDs...))
 	kafka.EvaluateBaselineSystems(inventoryAIDs)
 
 	resp := UpdateBaselineResponse{BaselineID: baselineID}
@@ -134,7 +145,9 @@ func sortInventoryIDs(inventoryIDs map[string]bool) (newIDs, obsoleteIDs []strin
 
 func updateSystemsBaselineID(tx *gorm.DB, rhAccountID int, inventoryIDs []string,
 	newBaselineID, oldBaselineID *int) error {
-	updateFields := map[string]interface{}{"baseline_id": newBaselineID, "unchanged_since": time.Now()}
+	// compliance with the previous baseline is reset until the systems are evaluated
+	updateFields := map[string]interface{}{"baseline_id": newBaselineID, "unchanged_since": time.Now(),
+		"baseline_uptodate": nil, "baseline_outdated_since": nil}
 	tx = tx.Model(models.SystemPlatform{}).
 		Where("rh_account_id = (?) AND inventory_id::text IN (?)", rhAccountID, inventoryIDs)
 
@@ -155,8 +168,9 @@ func updateSystemsBaselineID(tx *gorm.DB, rhAccountID int, inventoryIDs []string
 	return nil
 }
 
+// Returns inventory IDs of systems associated or removed by the updated selector
 func buildUpdateBaselineQuery(baselineID int, req UpdateBaselineRequest, newIDs, obsoleteIDs []string,
-	account int) error {
+	account int) ([]string, error) {
 	data := map[string]interface{}{}
 	if req.Name != nil {
 		data["name"] = req.Name
@@ -165,7 +179,7 @@ func buildUpdateBaselineQuery(baselineID int, req UpdateBaselineRequest, newIDs,
 	if req.Config != nil {
 		config, err := json.Marshal(req.Config)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		data["config"] = config
 	}
@@ -174,32 +188,49 @@ func buildUpdateBaselineQuery(baselineID int, req UpdateBaselineRequest, newIDs,
 		data["description"] = req.Description
 	}
 
+	if req.Selector != nil {
+		selector, err := marshalBaselineSelector(req.Selector)
+		if err != nil {
+			return nil, err
+		}
+		data["selector"] = selector
+	}
+
 	tx := database.Db.WithContext(base.Context).Begin()
 	defer tx.Rollback()
 
-	if req.Name != nil || req.Config != nil || req.Description != nil {
+	if len(data) > 0 {
 		err := tx.Model(models.Baseline{}).
 			Where("id = ? AND rh_account_id = ?", baselineID, account).
 			Updates(&data).Error
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if len(newIDs) > 0 {
 		err := updateSystemsBaselineID(tx, account, newIDs, &baselineID, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if len(obsoleteIDs) > 0 {
 		err := updateSystemsBaselineID(tx, account, obsoleteIDs, nil, &baselineID)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	var selectorIDs []string
+	if req.Selector.isSet() {
+		var err error
+		selectorIDs, err = applyBaselineSelector(tx, account, baselineID, req.Selector)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	err := tx.Commit().Error
-	return err
+	return selectorIDs, err
 }
@@ -75,7 +75,7 @@ func BaselinesListHandler(c *gin.Context) {
 	}
 
 	var query *gorm.DB
-	query = buildQueryBaselines(filters, account)
+	query = buildQueryBaselines(filters, account, middlewares.GetSystemScope(c))
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -101,7 +101,7 @@ func BaselinesListHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, &resp)
 }
 
-func buildQueryBaselines(filters map[string]FilterData, account int) *gorm.DB {
+func buildQueryBaselines(filters map[string]FilterData, account int, scope *database.SystemScope) *gorm.DB {
 	subq := database.Db.Table("system_platform sp").
 		Select("sp.baseline_id, count(sp.inventory_id) as systems").
 		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
@@ -110,6 +110,7 @@ func buildQueryBaselines(filters map[string]FilterData, account int) *gorm.DB {
 		Group("sp.baseline_id")
 
 	subq, _ = ApplyTagsFilter(filters, subq, "sp.inventory_id")
+	subq = database.ApplySystemScope(subq, scope)
 
 	query := database.Db.Table("baseline as bl").
 		Select(BaselineSelect).
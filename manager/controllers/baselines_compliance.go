@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/manager/middlewares"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var baselineComplianceSelect = database.MustGetSelect(&baselineComplianceDBLookup{})
+
+type baselineComplianceDBLookup struct {
+	BaselineID int `query:"sp.baseline_id" gorm:"column:baseline_id"`
+	BaselineComplianceCounts
+	OutdatedSince *time.Time `query:"min(sp.baseline_outdated_since)" gorm:"column:outdated_since"`
+}
+
+type BaselineComplianceItem struct {
+	ID   int    `json:"id" example:"1"`             // Baseline ID
+	Name string `json:"name" example:"my_baseline"` // Baseline name
+	BaselineComplianceCounts
+	// Time when the longest out of date system became out of date with the baseline
+	OutdatedSince *time.Time `json:"outdated_since"`
+}
+
+type BaselinesComplianceData struct {
+	Totals    BaselineComplianceCounts `json:"totals"`    // Sums of systems of all baselines
+	Baselines []BaselineComplianceItem `json:"baselines"` // Compliance of each baseline, sorted by name
+}
+
+type BaselinesComplianceResponse struct {
+	Data BaselinesComplianceData `json:"data"`
+}
+
+// @Summary Show compliance summary of all baselines
+// @Description Show counts of up to date and out of date systems of all baselines in the account
+// @ID baselinesCompliance
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    tags                    query   []string  false "Tag filter"
+// @Param    filter[system_profile][sap_system]						query string  	false "Filter only SAP systems"
+// @Param    filter[system_profile][sap_sids][in]					query []string  false "Filter systems by their SAP SIDs"
+// @Param    filter[system_profile][ansible]						query string 	false "Filter systems by ansible"
+// @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
+// @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
+// @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Success 200 {object} BaselinesComplianceResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /baselines/compliance [get]
+func BaselinesComplianceHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+	filters, err := ParseTagsFilters(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := database.Systems(database.Db, account, middlewares.GetSystemScope(c)).
+		Select(baselineComplianceSelect).
+		Where("sp.baseline_id IS NOT NULL AND sp.stale = false").
+		Group("sp.baseline_id")
+	query, _ = ApplyTagsFilter(filters, query, "sp.inventory_id")
+	var counts []baselineComplianceDBLookup
+	if err = query.Scan(&counts).Error; err != nil {
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	var baselines []models.Baseline
+	err = database.Db.Select("id, name").Where("rh_account_id = ?", account).Order("name, id").Find(&baselines).Error
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	c.JSON(http.StatusOK, &BaselinesComplianceResponse{Data: buildBaselinesComplianceData(baselines, counts)})
+}
+
+// Baselines without matching systems are listed with zero counts
+func buildBaselinesComplianceData(baselines []models.Baseline, lookups []baselineComplianceDBLookup) (
+	data BaselinesComplianceData) {
+	counts := make(map[int]baselineComplianceDBLookup, len(lookups))
+	for _, c := range lookups {
+		counts[c.BaselineID] = c
+	}
+
+	data.Baselines = make([]BaselineComplianceItem, 0, len(baselines))
+	for _, baseline := range baselines {
+		item := BaselineComplianceItem{ID: baseline.ID, Name: baseline.Name}
+		if c, ok := counts[baseline.ID]; ok {
+			item.BaselineComplianceCounts = c.BaselineComplianceCounts
+			item.OutdatedSince = c.OutdatedSince
+		}
+		data.Totals.Systems += item.Systems
+		data.Totals.SystemsUpToDate += item.SystemsUpToDate
+		data.Totals.SystemsOutdated += item.SystemsOutdated
+		data.Totals.SystemsNotEvaluated += item.SystemsNotEvaluated
+		data.Baselines = append(data.Baselines, item)
+	}
+	return data
+}
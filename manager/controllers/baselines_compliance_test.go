@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/models"
+	"app/base/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBaselinesCompliance(t *testing.T, url string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", url, nil, "", BaselinesComplianceHandler, "/compliance")
+	CheckResponse(t, w, expectedStatus, &output)
+}
+
+func TestBaselinesCompliance(t *testing.T) {
+	var output BaselinesComplianceResponse
+	testBaselinesCompliance(t, "/compliance", http.StatusOK, &output)
+	assert.Equal(t, BaselineComplianceCounts{Systems: 3, SystemsUpToDate: 2, SystemsOutdated: 1},
+		output.Data.Totals)
+	assert.Equal(t, 3, len(output.Data.Baselines))
+	assert.Equal(t, "baseline_1-1", output.Data.Baselines[0].Name)
+	assert.Equal(t, 2, output.Data.Baselines[0].SystemsUpToDate)
+	assert.Nil(t, output.Data.Baselines[0].OutdatedSince)
+	assert.Equal(t, "baseline_1-2", output.Data.Baselines[1].Name)
+	assert.Equal(t, 1, output.Data.Baselines[1].SystemsOutdated)
+	assert.Equal(t, "2018-09-20T16:00:00Z", output.Data.Baselines[1].OutdatedSince.UTC().Format(time.RFC3339))
+	assert.Equal(t, BaselineComplianceCounts{}, output.Data.Baselines[2].BaselineComplianceCounts)
+}
+
+func TestBaselinesComplianceTags(t *testing.T) {
+	var output BaselinesComplianceResponse
+	testBaselinesCompliance(t, "/compliance?tags=ns1/k3=val4", http.StatusOK, &output)
+	assert.Equal(t, BaselineComplianceCounts{Systems: 1, SystemsOutdated: 1}, output.Data.Totals)
+	assert.Equal(t, 3, len(output.Data.Baselines))
+	assert.Equal(t, 0, output.Data.Baselines[0].Systems)
+	assert.Equal(t, 1, output.Data.Baselines[1].Systems)
+}
+
+func TestBaselinesComplianceInvalidTag(t *testing.T) {
+	var output utils.ErrorResponse
+	testBaselinesCompliance(t, "/compliance?tags=invalidTag", http.StatusBadRequest, &output)
+}
+
+func TestBuildBaselinesComplianceData(t *testing.T) {
+	baselines := []models.Baseline{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	lookups := []baselineComplianceDBLookup{
+		{BaselineID: 2, BaselineComplianceCounts: BaselineComplianceCounts{Systems: 3, SystemsUpToDate: 1,
+			SystemsOutdated: 1, SystemsNotEvaluated: 1}},
+	}
+	data := buildBaselinesComplianceData(baselines, lookups)
+	assert.Equal(t, lookups[0].BaselineComplianceCounts, data.Totals)
+	assert.Equal(t, []BaselineComplianceItem{
+		{ID: 1, Name: "a"},
+		{ID: 2, Name: "b", BaselineComplianceCounts: lookups[0].BaselineComplianceCounts},
+	}, data.Baselines)
+}
@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"app/base/core"
+	"app/base/database"
+	"app/manager/middlewares"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -58,6 +60,14 @@ func CreateRequestRouterWithAccount(method string, url string, body io.Reader, c
 	return w
 }
 
+// Wrap handler to run with systems restricted to the RBAC scope
+func withSystemScope(scope *database.SystemScope, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middlewares.KeySystemScope, scope)
+		handler(c)
+	}
+}
+
 // Check status and parse response body
 func CheckResponse(t *testing.T, w *httptest.ResponseRecorder, expectedStatus int, output interface{}) {
 	assert.Equal(t, expectedStatus, w.Code)
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const DashboardDefaultTop = 5
+const DashboardMaxTop = 100
+const InvalidTopMsg = "Invalid top parameter, use number between 1 and %d"
+
+var enableDashboardCache = utils.GetBoolEnvOrDefault("ENABLE_DASHBOARD_CACHE", true)
+var dashboardCacheSize = utils.GetIntEnvOrDefault("DASHBOARD_CACHE_SIZE", 100)
+var dashboardCacheTTL = time.Duration(utils.GetIntEnvOrDefault("DASHBOARD_CACHE_TTL_SEC", 60)) * time.Second
+var dashboardCache = initDashboardCache()
+var dashboardSystemSumsSelect = database.MustGetSelect(&dashboardSystemSums{})
+
+// nolint: lll
+type dashboardSystemSums struct {
+	SystemSums
+	StaleWarning     int64 `query:"count(*) filter (where sp.stale_warning_timestamp < now())" gorm:"column:stale_warning"`
+	BaselineAssigned int64 `query:"count(*) filter (where sp.stale = false and sp.baseline_id is not null)" gorm:"column:baseline_assigned"`
+	BaselineUpToDate int64 `query:"count(*) filter (where sp.stale = false and sp.baseline_uptodate = true)" gorm:"column:baseline_uptodate"`
+	BaselineOutdated int64 `query:"count(*) filter (where sp.stale = false and sp.baseline_uptodate = false)" gorm:"column:baseline_outdated"`
+}
+
+type DashboardSystems struct {
+	Total        int `json:"total"`
+	Patched      int `json:"patched"`
+	Unpatched    int `json:"unpatched"`
+	Stale        int `json:"stale"`
+	StaleWarning int `json:"stale_warning"`
+}
+
+type DashboardBaselines struct {
+	Total           int `json:"total"`            // Number of account baselines
+	SystemsAssigned int `json:"systems_assigned"` // Fresh systems with a baseline assigned
+	SystemsUpToDate int `json:"systems_uptodate"` // Fresh systems which are up to date with their baseline
+	SystemsOutdated int `json:"systems_outdated"` // Fresh systems which are not up to date with their baseline
+}
+
+type DashboardAdvisory struct {
+	ID                string `json:"id"`
+	Synopsis          string `json:"synopsis"`
+	AdvisoryTypeName  string `json:"advisory_type_name"`
+	Severity          *int   `json:"severity,omitempty"`
+	ApplicableSystems int    `json:"applicable_systems"`
+}
+
+type DashboardData struct {
+	Systems       DashboardSystems    `json:"systems"`
+	Advisories    map[string]int      `json:"advisories"`
+	TopAdvisories []DashboardAdvisory `json:"top_advisories"`
+	TopPackages   []PackageItem       `json:"top_packages"`
+	Baselines     DashboardBaselines  `json:"baselines"`
+}
+
+type DashboardResponse struct {
+	Data DashboardData         `json:"data"`
+	Meta map[string]FilterData `json:"meta"`
+}
+
+type dashboardCacheItem struct {
+	Response DashboardResponse
+	Expires  time.Time
+}
+
+// nolint: lll
+// @Summary Show me summary of my systems, advisories, packages and baselines
+// @Description Show me summary of my systems, advisories, packages and baselines
+// @ID dashboard
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    top                            query   int     false   "Number of returned top advisories and packages"
+// @Param    tags                           query   []string false  "Tag filter"
+// @Param    filter[system_profile][sap_system]                     query   string  false   "Filter only SAP systems"
+// @Param    filter[system_profile][sap_sids][in]                   query   []string false  "Filter systems by their SAP SIDs"
+// @Param    filter[system_profile][ansible]                        query   string  false   "Filter systems by ansible"
+// @Param    filter[system_profile][ansible][controller_version]    query   string  false   "Filter systems by ansible version"
+// @Param    filter[system_profile][mssql]                          query   string  false   "Filter systems by mssql version"
+// @Param    filter[system_profile][mssql][version]                 query   string  false   "Filter systems by mssql version"
+// @Success 200 {object} DashboardResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /dashboard [get]
+func DashboardHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	top, err := strconv.Atoi(c.DefaultQuery("top", strconv.Itoa(DashboardDefaultTop)))
+	if err != nil || top < 1 || top > DashboardMaxTop {
+		msg := fmt.Sprintf(InvalidTopMsg, DashboardMaxTop)
+		LogAndRespBadRequest(c, errors.New(msg), msg)
+		return
+	}
+
+	filters, err := ParseTagsFilters(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	cacheKey := fmt.Sprintf("%d?%s", account, c.Request.URL.RawQuery)
+	if scope := middlewares.GetSystemScope(c); scope != nil {
+		// users restricted to different systems of the account don't share the dashboard
+		scopeJSON, _ := json.Marshal(scope)
+		cacheKey = fmt.Sprintf("%s#%s", cacheKey, scopeJSON)
+	}
+	if resp := tryGetDashboardFromCache(cacheKey); resp != nil {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	data, err := getDashboardData(c, account, filters, top)
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	resp := DashboardResponse{Data: *data, Meta: filters}
+	tryAddDashboardToCache(cacheKey, &resp)
+	c.JSON(http.StatusOK, &resp)
+}
+
+func getDashboardData(c *gin.Context, account int, filters map[string]FilterData, top int) (*DashboardData, error) {
+	var data DashboardData
+	var err error
+
+	if data.Systems, data.Baselines, err = dashboardSystems(account, middlewares.GetSystemScope(c), filters); err != nil {
+		return nil, errors.Wrap(err, "systems summary failed")
+	}
+
+	if data.Advisories, data.TopAdvisories, err = dashboardAdvisories(c, account, filters, top); err != nil {
+		return nil, errors.Wrap(err, "advisories summary failed")
+	}
+
+	if data.TopPackages, err = dashboardPackages(account, middlewares.GetSystemScope(c), filters, top); err != nil {
+		return nil, errors.Wrap(err, "packages summary failed")
+	}
+	return &data, nil
+}
+
+func dashboardSystems(account int, scope *database.SystemScope, filters map[string]FilterData) (
+	DashboardSystems, DashboardBaselines, error) {
+	var sums dashboardSystemSums
+	var baselines int64
+	query := database.Systems(database.Db, account, scope).
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id")
+	query, _ = ApplyTagsFilter(filters, query, "sp.inventory_id")
+	err := query.Select(dashboardSystemSumsSelect).Scan(&sums).Error
+	if err != nil {
+		return DashboardSystems{}, DashboardBaselines{}, err
+	}
+
+	err = database.Db.Model(&models.Baseline{}).Where("rh_account_id = ?", account).Count(&baselines).Error
+	if err != nil {
+		return DashboardSystems{}, DashboardBaselines{}, err
+	}
+
+	systems := DashboardSystems{
+		Total:        int(sums.Total),
+		Patched:      int(sums.Patched),
+		Unpatched:    int(sums.Unpatched),
+		Stale:        int(sums.Stale),
+		StaleWarning: int(sums.StaleWarning),
+	}
+	baselineSums := DashboardBaselines{
+		Total:           int(baselines),
+		SystemsAssigned: int(sums.BaselineAssigned),
+		SystemsUpToDate: int(sums.BaselineUpToDate),
+		SystemsOutdated: int(sums.BaselineOutdated),
+	}
+	return systems, baselineSums, nil
+}
+
+func dashboardAdvisories(c *gin.Context, account int, filters map[string]FilterData, top int) (
+	map[string]int, []DashboardAdvisory, error) {
+	fieldset := Fieldset{"synopsis": true, "advisory_type_name": true, "severity": true, "applicable_systems": true}
+	buildQuery := func() *gorm.DB {
+		if !useCachedCounts(c) {
+			return buildQueryAdvisoriesTagged(filters, account, middlewares.GetSystemScope(c), fieldset)
+		}
+		return buildQueryAdvisories(account, fieldset)
+	}
+
+	_, subTotals, err := advisoriesSubtotal(buildQuery())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var advisories []AdvisoriesDBLookup
+	err = buildQuery().
+		Order("applicable_systems DESC, am.severity_id DESC NULLS LAST, am.public_date DESC").
+		Limit(top).
+		Find(&advisories).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	topAdvisories := make([]DashboardAdvisory, len(advisories))
+	for i, a := range advisories {
+		topAdvisories[i] = DashboardAdvisory{
+			ID:                a.ID,
+			Synopsis:          a.Synopsis,
+			AdvisoryTypeName:  a.AdvisoryTypeName,
+			Severity:          a.Severity,
+			ApplicableSystems: a.ApplicableSystems,
+		}
+	}
+	return subTotals, topAdvisories, nil
+}
+
+func dashboardPackages(account int, scope *database.SystemScope, filters map[string]FilterData, top int) (
+	[]PackageItem, error) {
+	fieldset := Fieldset{"summary": true, "systems_installed": true, "systems_updatable": true}
+	var packages []PackageItem
+	err := packagesQuery(filters, nil, account, scope, fieldset).
+		Where("res.systems_updatable > 0").
+		Order("res.systems_updatable DESC, pn.name").
+		Limit(top).
+		Scan(&packages).Error
+	return packages, err
+}
+
+func initDashboardCache() *lru.Cache {
+	if !enableDashboardCache || dashboardCacheTTL <= 0 {
+		return nil
+	}
+
+	cache, err := lru.New(dashboardCacheSize)
+	if err != nil {
+		panic(err)
+	}
+	return cache
+}
+
+func tryGetDashboardFromCache(key string) *DashboardResponse {
+	if dashboardCache == nil {
+		return nil
+	}
+
+	val, ok := dashboardCache.Get(key)
+	if !ok {
+		return nil
+	}
+	item := val.(dashboardCacheItem)
+	if time.Now().After(item.Expires) {
+		dashboardCache.Remove(key)
+		return nil
+	}
+	return &item.Response
+}
+
+func tryAddDashboardToCache(key string, resp *DashboardResponse) {
+	if dashboardCache == nil {
+		return
+	}
+	dashboardCache.Add(key, dashboardCacheItem{Response: *resp, Expires: time.Now().Add(dashboardCacheTTL)})
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doTestDashboard(t *testing.T, q string, account int) DashboardResponse {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", q, nil, "", DashboardHandler, account, "GET", "/")
+
+	var output DashboardResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+func TestDashboardDefault(t *testing.T) {
+	output := doTestDashboard(t, "/", 1)
+	assert.Equal(t, 8, output.Data.Systems.Total)
+	assert.Equal(t, 8, output.Data.Systems.Patched)
+	assert.Equal(t, 0, output.Data.Systems.Unpatched)
+	assert.Equal(t, 0, output.Data.Systems.Stale)
+	assert.Equal(t, 3, output.Data.Baselines.Total)
+	assert.Equal(t, 3, output.Data.Baselines.SystemsAssigned)
+	assert.Equal(t, 2, output.Data.Baselines.SystemsUpToDate)
+	assert.Equal(t, 1, output.Data.Baselines.SystemsOutdated)
+
+	assert.Equal(t, 12, output.Data.Advisories["total"])
+	assert.Equal(t, DashboardDefaultTop, len(output.Data.TopAdvisories))
+	for i := 1; i < len(output.Data.TopAdvisories); i++ {
+		assert.GreaterOrEqual(t, output.Data.TopAdvisories[i-1].ApplicableSystems,
+			output.Data.TopAdvisories[i].ApplicableSystems)
+	}
+}
+
+func TestDashboardTopPackages(t *testing.T) {
+	output := doTestDashboard(t, "/?top=1", 3)
+	assert.Equal(t, 1, len(output.Data.TopPackages))
+	assert.Greater(t, output.Data.TopPackages[0].SystemsUpdatable, 0)
+	assert.LessOrEqual(t, len(output.Data.TopAdvisories), 1)
+}
+
+func TestDashboardTags(t *testing.T) {
+	output := doTestDashboard(t, "/?tags=ns1/k3=val4&tags=ns1/k1=val1", 1)
+	assert.Equal(t, 1, output.Data.Systems.Total)
+	assert.Equal(t, 1, output.Data.Baselines.SystemsAssigned)
+	assert.Equal(t, 1, output.Data.Baselines.SystemsOutdated)
+	assert.Equal(t, FilterData{"eq", []string{"val1"}}, output.Meta["ns1/k1"])
+}
+
+func TestDashboardInvalidTop(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/?top=0", nil, "", DashboardHandler, 1, "GET", "/")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, fmt.Sprintf(InvalidTopMsg, DashboardMaxTop), errResp.Error)
+}
+
+func TestDashboardCached(t *testing.T) {
+	doTestDashboard(t, "/?top=2", 1)
+	assert.NotNil(t, tryGetDashboardFromCache("1?top=2"))
+}
+
+func TestDashboardCacheExpired(t *testing.T) {
+	if dashboardCache == nil {
+		t.Skip("dashboard cache disabled")
+	}
+	dashboardCache.Add("expired", dashboardCacheItem{Expires: time.Now().Add(-time.Second)})
+	assert.Nil(t, tryGetDashboardFromCache("expired"))
+	assert.False(t, dashboardCache.Contains("expired"))
+
+	tryAddDashboardToCache("fresh", &DashboardResponse{Data: DashboardData{Systems: DashboardSystems{Total: 1}}})
+	cached := tryGetDashboardFromCache("fresh")
+	assert.NotNil(t, cached)
+	assert.Equal(t, 1, cached.Data.Systems.Total)
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"app/base/database"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocarina/gocsv"
+	"github.com/pkg/errors"
+)
+
+const InvalidFieldsetMsg = "Invalid field in fields[%s]: %s"
+
+// Item identifiers which are always returned regardless of requested fieldset
+var fieldsetIdentifiers = map[string]bool{"id": true, "type": true, "name": true}
+
+// Fieldset holds attributes requested using JSON:API-like `fields[<type>]=attr1,attr2` query parameter.
+// Nil fieldset means no restriction, all attributes are returned.
+type Fieldset map[string]bool
+
+// Parse `fields[resourceType]` query parameter. Requested attributes are validated against json names of `attrs`.
+func ParseFieldset(c *gin.Context, resourceType string, attrs interface{}) (Fieldset, error) {
+	param := c.Query(fmt.Sprintf("fields[%s]", resourceType))
+	if param == "" {
+		return nil, nil
+	}
+
+	allowed := database.MustGetJSONColumns(attrs)
+	fieldset := Fieldset{}
+	for _, attr := range strings.Split(param, ",") {
+		attr = strings.TrimSpace(attr)
+		if _, ok := allowed[attr]; !ok && !fieldsetIdentifiers[attr] {
+			err := errors.Errorf(InvalidFieldsetMsg, resourceType, attr)
+			LogAndRespBadRequest(c, err, err.Error())
+			return nil, err
+		}
+		fieldset[attr] = true
+	}
+	return fieldset, nil
+}
+
+// Check whether any of given attributes is requested
+func (f Fieldset) Has(attrs ...string) bool {
+	if f == nil {
+		return true
+	}
+	for _, attr := range attrs {
+		if f[attr] {
+			return true
+		}
+	}
+	return false
+}
+
+// Build select for `lookup` struct loading only columns needed by the requested attributes.
+// `helpers` maps attributes to helper columns they are parsed from (e.g. tags => tags_str),
+// `required` columns are selected always.
+func (f Fieldset) Select(lookup interface{}, helpers map[string]string, required ...string) string {
+	if f == nil {
+		return database.MustGetSelect(lookup)
+	}
+
+	columns := map[string]bool{}
+	for _, column := range required {
+		columns[column] = true
+	}
+	jsonColumns := database.MustGetJSONColumns(lookup)
+	for attr := range f {
+		if column := jsonColumns[attr]; column != "" {
+			columns[column] = true
+		}
+		if helper, ok := helpers[attr]; ok {
+			columns[helper] = true
+		}
+	}
+	return database.MustGetSelectColumns(lookup, columns)
+}
+
+// Check whether attribute is requested in fieldset, or its column is used in filter or sort query parameters.
+// Used to skip joins which are not needed to build the response.
+func isAttrUsed(c *gin.Context, fieldset Fieldset, attr, column string) bool {
+	if fieldset.Has(attr) {
+		return true
+	}
+	if NestedQueryMap(c, "filter").Path(column) != nil {
+		return true
+	}
+	for _, sortField := range strings.Split(c.Query("sort"), ",") {
+		if strings.TrimPrefix(sortField, "-") == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove not requested attributes from json object
+func filterJSONObject(obj map[string]interface{}, fieldset Fieldset) {
+	if attributes, ok := obj["attributes"].(map[string]interface{}); ok {
+		filterJSONObject(attributes, fieldset)
+		return
+	}
+	for key := range obj {
+		if !fieldset[key] && !fieldsetIdentifiers[key] {
+			delete(obj, key)
+		}
+	}
+}
+
+// Remove not requested attributes from response items. Response is either a list of items (exports),
+// or an object with items (list endpoints) or a single item (detail endpoints) in "data" field.
+func filterJSONFieldset(res interface{}, fieldset Fieldset) interface{} {
+	switch val := res.(type) {
+	case []interface{}:
+		for _, item := range val {
+			if obj, ok := item.(map[string]interface{}); ok {
+				filterJSONObject(obj, fieldset)
+			}
+		}
+	case map[string]interface{}:
+		switch data := val["data"].(type) {
+		case []interface{}:
+			filterJSONFieldset(data, fieldset)
+		case map[string]interface{}:
+			filterJSONObject(data, fieldset)
+		}
+	}
+	return res
+}
+
+// Respond with json containing only attributes requested in fieldset
+func JSONWithFieldset(c *gin.Context, code int, res interface{}, fieldset Fieldset) {
+	if fieldset == nil {
+		c.JSON(code, res)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		LogAndRespError(c, err, "response serialization error")
+		return
+	}
+	var generic interface{}
+	if err = json.Unmarshal(b, &generic); err != nil {
+		LogAndRespError(c, err, "response serialization error")
+		return
+	}
+	c.JSON(code, filterJSONFieldset(generic, fieldset))
+}
+
+// Respond with csv containing only columns requested in fieldset
+func CsvWithFieldset(c *gin.Context, code int, res interface{}, fieldset Fieldset) {
+	if fieldset == nil {
+		Csv(c, code, res)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gocsv.Marshal(res, &buf); err != nil {
+		LogAndRespError(c, err, "response serialization error")
+		return
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		LogAndRespError(c, err, "response serialization error")
+		return
+	}
+
+	c.Status(code)
+	c.Header("Content-Type", "text/csv")
+	if len(rows) == 0 {
+		return
+	}
+	var columns []int
+	for i, header := range rows[0] {
+		if fieldset[header] || fieldsetIdentifiers[header] {
+			columns = append(columns, i)
+		}
+	}
+	writer := csv.NewWriter(c.Writer)
+	for _, row := range rows {
+		filtered := make([]string, len(columns))
+		for i, col := range columns {
+			filtered[i] = row[col]
+		}
+		if err = writer.Write(filtered); err != nil {
+			panic(err)
+		}
+	}
+	writer.Flush()
+}
+
+// Respond with json or csv according to Accept header, narrowed to the requested fieldset
+func ExportWithFieldset(c *gin.Context, res interface{}, fieldset Fieldset) {
+	accept := c.GetHeader("Accept")
+	if strings.Contains(accept, "application/json") { // nolint: gocritic
+		JSONWithFieldset(c, http.StatusOK, res, fieldset)
+	} else if strings.Contains(accept, "text/csv") {
+		CsvWithFieldset(c, http.StatusOK, res, fieldset)
+	} else {
+		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"app/base/database"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFieldsetContext(url string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", url, nil)
+	return c, w
+}
+
+func TestParseFieldset(t *testing.T) {
+	c, _ := testFieldsetContext("/?fields[systems]=display_name,rhsa_count")
+	fieldset, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	assert.Nil(t, err)
+	assert.Equal(t, Fieldset{"display_name": true, "rhsa_count": true}, fieldset)
+	assert.True(t, fieldset.Has("rhsa_count", "tags"))
+	assert.False(t, fieldset.Has("tags"))
+}
+
+func TestParseFieldsetEmpty(t *testing.T) {
+	c, _ := testFieldsetContext("/?fields[advisories]=synopsis")
+	fieldset, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	assert.Nil(t, err)
+	assert.Nil(t, fieldset)
+	assert.True(t, fieldset.Has("tags"))
+}
+
+func TestParseFieldsetInvalid(t *testing.T) {
+	c, w := testFieldsetContext("/?fields[systems]=display_name,unknown")
+	_, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	assert.Equal(t, "Invalid field in fields[systems]: unknown", err.Error())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFieldsetSelect(t *testing.T) {
+	fieldset := Fieldset{"display_name": true, "tags": true}
+	assert.Equal(t, "sp.inventory_id as id, ih.tags as tags_str, sp.display_name as display_name",
+		fieldset.Select(&SystemDBLookup{}, SystemsFieldsetHelpers, "id"))
+	assert.Equal(t, database.MustGetSelect(&SystemDBLookup{}),
+		Fieldset(nil).Select(&SystemDBLookup{}, SystemsFieldsetHelpers, "id"))
+}
+
+func TestSystemsSelectRepos(t *testing.T) {
+	assert.NotContains(t, systemsSelect(nil), "repos_json")
+	assert.Contains(t, systemsSelect(Fieldset{"repos": true}), "repos_json")
+}
+
+func TestFieldsetIsAttrUsed(t *testing.T) {
+	c, _ := testFieldsetContext("/?filter[baseline_name]=baseline_1-1")
+	assert.True(t, isAttrUsed(c, Fieldset{}, "baseline_name", "baseline_name"))
+	c, _ = testFieldsetContext("/?sort=-baseline_name")
+	assert.True(t, isAttrUsed(c, Fieldset{}, "baseline_name", "baseline_name"))
+	c, _ = testFieldsetContext("/?sort=display_name")
+	assert.False(t, isAttrUsed(c, Fieldset{}, "baseline_name", "baseline_name"))
+	assert.True(t, isAttrUsed(c, nil, "baseline_name", "baseline_name"))
+}
+
+func TestFilterJSONFieldset(t *testing.T) {
+	res := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{
+				"id":         "1",
+				"type":       "system",
+				"attributes": map[string]interface{}{"display_name": "a", "rhsa_count": 1.0, "stale": false},
+			},
+		},
+		"meta": map[string]interface{}{"limit": 20.0},
+	}
+	filterJSONFieldset(res, Fieldset{"rhsa_count": true})
+	item := res["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "1", item["id"])
+	assert.Equal(t, "system", item["type"])
+	assert.Equal(t, map[string]interface{}{"rhsa_count": 1.0}, item["attributes"])
+	assert.Equal(t, map[string]interface{}{"limit": 20.0}, res["meta"])
+
+	inline := []interface{}{map[string]interface{}{"name": "kernel", "summary": "s", "systems_installed": 1.0}}
+	filterJSONFieldset(inline, Fieldset{"summary": true})
+	assert.Equal(t, map[string]interface{}{"name": "kernel", "summary": "s"}, inline[0])
+}
+
+func TestCsvWithFieldset(t *testing.T) {
+	c, w := testFieldsetContext("/")
+	data := []PackageItem{{Name: "kernel", SystemsInstalled: 2, SystemsUpdatable: 1, Summary: "The Linux kernel"}}
+	CsvWithFieldset(c, http.StatusOK, data, Fieldset{"systems_updatable": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "name,systems_updatable\nkernel,1\n", w.Body.String())
+}
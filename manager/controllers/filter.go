@@ -15,6 +15,9 @@ type FilterData struct {
 
 type Filters map[string]FilterData
 
+// Attributes holding a list of values (sql array), filter matches items whose list contains the value
+var arrayFilterFields = map[string]bool{"repos": true}
+
 // Parse a filter from field name and field value specification
 func ParseFilterValue(val string) (FilterData, error) {
 	idx := strings.Index(val, ":")
@@ -72,6 +75,10 @@ func (t *FilterData) ToWhere(fieldName string, attributes database.AttrMap) (str
 		return "", nil,
 			errors.Errorf("Invalid number of values: %v for operator '%s'", len(t.Values), t.Operator)
 	}
+	if arrayFilterFields[fieldName] {
+		return arrayFilterToWhere(attributes[fieldName].DataQuery, transformedOperator, values)
+	}
+
 	// We need to look up expression used to create the attribute, because FROM clause can't contain
 	// column aliases
 	switch transformedOperator {
@@ -101,6 +108,26 @@ func (t *FilterData) ToWhere(fieldName string, attributes database.AttrMap) (str
 	}
 }
 
+func arrayFilterToWhere(arrayQuery, operator string, values []interface{}) (string, []interface{}, error) {
+	switch operator {
+	case "eq":
+		return fmt.Sprintf("? = ANY(%s) ", arrayQuery), values, nil
+	case "neq":
+		return fmt.Sprintf("NOT (? = ANY(%s)) ", arrayQuery), values, nil
+	case "in":
+		return fmt.Sprintf("%s && ARRAY[%s]::text[] ", arrayQuery, arrayPlaceholders(len(values))), values, nil
+	case "notin":
+		return fmt.Sprintf("NOT (%s && ARRAY[%s]::text[]) ", arrayQuery, arrayPlaceholders(len(values))), values, nil
+	default:
+		return "", []interface{}{}, errors.Errorf("Unsupported operator for list attribute: %s", operator)
+	}
+}
+
+// Slice passed as a single query var is rendered as a row, so every array item needs its own placeholder
+func arrayPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
 // transformFilterParams Allow exceptions in ToWhere values usage (e.g. "other").
 func transformFilterParams(fieldName string, originalValues []string, originalOperator string) (
 	transformedValues []string, transformedOperator string) {
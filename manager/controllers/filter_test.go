@@ -98,3 +98,27 @@ func TestFilterOtherAdvisoryTypes(t *testing.T) {
 	// Check the list is loaded from database correctly
 	assert.Equal(t, []string{"unknown", "unspecified"}, database.OtherAdvisoryTypes)
 }
+
+// nolint: govet
+func TestFilterArrayToSql(t *testing.T) {
+	queries := map[string]string{
+		"eq:repo1":          "? = ANY(arr) ",
+		"neq:repo1":         "NOT (? = ANY(arr)) ",
+		"in:repo1,repo2":    "arr && ARRAY[?,?]::text[] ",
+		"notin:repo1,repo2": "NOT (arr && ARRAY[?,?]::text[]) ",
+	}
+
+	attrMap := database.AttrMap{"repos": {"arr", "arr", dummyParser}}
+	for f, expected := range queries {
+		filter, err := ParseFilterValue(f)
+		assert.Nil(t, err)
+		query, _, err := filter.ToWhere("repos", attrMap)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, query)
+	}
+
+	filter, err := ParseFilterValue("gt:repo1")
+	assert.Nil(t, err)
+	_, _, err = filter.ToWhere("repos", attrMap)
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/manager/middlewares"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Joins `pm` with the module stream the package build belongs to, it requires `spkg`, `p` and `pn`.
+// Modular builds are known from modules_list of synced advisories, a build shared by several streams
+// is attributed to the one enabled on the system. Packages not listed by any advisory have no module.
+const packageModuleJoin = "LEFT JOIN LATERAL (SELECT m.name, m.stream FROM module_package mp " +
+	"JOIN module m ON m.id = mp.module_id " +
+	"LEFT JOIN system_module sm ON sm.module_id = mp.module_id " +
+	"AND sm.rh_account_id = spkg.rh_account_id AND sm.system_id = spkg.system_id " +
+	"WHERE mp.name = pn.name AND mp.evra = p.evra " +
+	"ORDER BY sm.module_id IS NULL, m.name, m.stream LIMIT 1) pm ON true"
+
+var ModulesFields = database.MustGetQueryAttrs(&ModuleItem{})
+var ModulesSelect = database.MustGetSelect(&ModuleItem{})
+var ModulesOpts = ListOpts{
+	Fields:         ModulesFields,
+	DefaultFilters: map[string]FilterData{},
+	DefaultSort:    "name",
+	StableSort:     "m.id",
+	SearchFields:   []string{"m.name"},
+	TotalFunc:      CountRows,
+}
+
+// nolint: lll
+type ModuleItem struct {
+	Name    string `json:"name" csv:"name" query:"m.name" gorm:"column:name"`
+	Stream  string `json:"stream" csv:"stream" query:"m.stream" gorm:"column:stream"`
+	Systems int    `json:"systems" csv:"systems" query:"res.systems" gorm:"column:systems"` // Fresh systems with the module stream enabled
+}
+
+type ModulesResponse struct {
+	Data  []ModuleItem `json:"data"`
+	Links Links        `json:"links"`
+	Meta  ListMeta     `json:"meta"`
+}
+
+// Used as a subquery counting systems per module stream which is joined with module table
+type moduleQueryItem struct {
+	ModuleID int64 `query:"sm.module_id" gorm:"column:module_id"`
+	Systems  int   `query:"count(sm.system_id)" gorm:"column:systems"`
+}
+
+var moduleQueryItemSelect = database.MustGetSelect(&moduleQueryItem{})
+
+func modulesQuery(filters map[string]FilterData, acc int, scope *database.SystemScope) *gorm.DB {
+	systemsQ := database.Systems(database.Db, acc, scope).
+		Select("id").
+		Where("sp.stale = false")
+
+	// We need to apply tag filtering on subquery
+	systemsQ, _ = ApplyTagsFilter(filters, systemsQ, "sp.inventory_id")
+	subQ := database.Db.Table("system_module sm").
+		Select(moduleQueryItemSelect).
+		Where("sm.rh_account_id = ?", acc).
+		Where("sm.system_id IN (?)", systemsQ).
+		Group("sm.module_id")
+
+	return database.Db.
+		Select(ModulesSelect).
+		Table("module m").
+		Joins("JOIN (?) res ON res.module_id = m.id", subQ)
+}
+
+// @Summary Show me all dnf module streams enabled on my systems
+// @Description Show me all dnf module streams enabled on my systems
+// @ID listModules
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    limit          query      int     false   "Limit for paging, set -1 to return all"
+// @Param    offset         query      int     false   "Offset for paging"
+// @Param    sort           query      string  false   "Sort field" Enums(name,stream,systems)
+// @Param    search         query      string  false   "Find matching text"
+// @Param    filter[name]           query   string  false "Filter"
+// @Param    filter[stream]         query   string  false "Filter"
+// @Param    filter[systems]        query   string  false "Filter"
+// @Param    tags                   query   []string  false "Tag filter"
+// @Param    filter[system_profile][sap_system]						query string  	false "Filter only SAP systems"
+// @Param    filter[system_profile][sap_sids][in]					query []string  false "Filter systems by their SAP SIDs"
+// @Param    filter[system_profile][ansible]						query string 	false "Filter systems by ansible"
+// @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
+// @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
+// @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Success 200 {object} ModulesResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /modules [get]
+func ModulesListHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	filters, err := ParseTagsFilters(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := modulesQuery(filters, account, middlewares.GetSystemScope(c))
+	query, meta, links, err := ListCommon(query, c, filters, ModulesOpts)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	var modules = make([]ModuleItem, 0)
+	err = query.Scan(&modules).Error
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	c.JSON(http.StatusOK, ModulesResponse{
+		Data:  modules,
+		Links: *links,
+		Meta:  *meta,
+	})
+}
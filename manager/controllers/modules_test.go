@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doTestModules(t *testing.T, q string) ModulesResponse {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", q, nil, "", ModulesListHandler, 1, "GET", "/")
+
+	var output ModulesResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+// Install modular firefox build on system 12 which has firefox:stable module enabled
+func createModularPackage(t *testing.T) func() {
+	summary, description := []byte("2"), []byte("22")
+	pkg := models.Package{ID: 13, NameID: 102, EVRA: "77.0.1-1.module+el8.4.0+1+abcdef.x86_64",
+		SummaryHash: &summary, DescriptionHash: &description, Synced: true}
+	assert.Nil(t, database.Db.Create(&pkg).Error)
+	assert.Nil(t, database.Db.Create(&models.SystemPackage{RhAccountID: 3, SystemID: 12, PackageID: 13,
+		NameID: 102}).Error)
+	return func() {
+		database.DeleteSystemPackages(t, 12, 13)
+		assert.Nil(t, database.Db.Delete(&models.Package{}, 13).Error)
+	}
+}
+
+func TestModulesDefault(t *testing.T) {
+	output := doTestModules(t, "/")
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, ModuleItem{Name: "nodejs", Stream: "12", Systems: 2}, output.Data[0])
+	assert.Equal(t, ModuleItem{Name: "postgresql", Stream: "12", Systems: 1}, output.Data[1])
+	assert.Equal(t, 2, output.Meta.TotalItems)
+}
+
+func TestModulesFilterSystems(t *testing.T) {
+	output := doTestModules(t, "/?filter[systems]=gt:1")
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "nodejs", output.Data[0].Name)
+}
+
+func TestModulesSort(t *testing.T) {
+	output := doTestModules(t, "/?sort=systems")
+	assert.Equal(t, "postgresql", output.Data[0].Name)
+}
@@ -54,19 +54,22 @@ func packagesByNameQuery(pkgName string) *gorm.DB {
 		Where("pn.name = ?", pkgName)
 }
 
-func packageSystemsQuery(acc int, packageName string, packageIDs []int) *gorm.DB {
-	query := database.SystemPackages(database.Db, acc).
-		Select(PackageSystemsSelect).
-		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
-		Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id").
-		Where("sp.stale = false").
+func packageSystemsQuery(c *gin.Context, acc int, packageName string, packageIDs []int,
+	fieldset Fieldset) *gorm.DB {
+	query := database.SystemPackages(database.Db, acc, middlewares.GetSystemScope(c)).
+		Select(fieldset.Select(&PackageSystemDBLookup{}, SystemsFieldsetHelpers, "id")).
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id")
+	if isAttrUsed(c, fieldset, "baseline_name", "baseline_name") {
+		query = query.Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id")
+	}
+	query = query.Where("sp.stale = false").
 		Where("pn.name = ?", packageName).
 		Where("spkg.package_id in (?)", packageIDs)
 
 	return query
 }
 
-func packageSystemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
+func packageSystemsCommon(c *gin.Context, fieldset Fieldset) (*gorm.DB, *ListMeta, *Links, error) {
 	account := c.GetInt(middlewares.KeyAccount)
 	var filters map[string]FilterData
 
@@ -87,7 +90,7 @@ func packageSystemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 		return nil, nil, nil, errors.New("package not found")
 	}
 
-	query := packageSystemsQuery(account, packageName, packageIDs)
+	query := packageSystemsQuery(c, account, packageName, packageIDs, fieldset)
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return nil, nil, nil, err
@@ -115,13 +118,19 @@ func packageSystemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 // @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
 // @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
 // @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Param    fields[systems] query   string    false "Comma separated list of returned attributes"
 // @Success 200 {object} PackageSystemsResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /packages/{package_name}/systems [get]
 func PackageSystemsListHandler(c *gin.Context) {
-	query, meta, links, err := packageSystemsCommon(c)
+	fieldset, err := ParseFieldset(c, "systems", &PackageSystemItem{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query, meta, links, err := packageSystemsCommon(c, fieldset)
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -134,11 +143,11 @@ func PackageSystemsListHandler(c *gin.Context) {
 	}
 
 	outputItems := packageSystemDBLookups2PackageSystemItems(systems)
-	c.JSON(200, PackageSystemsResponse{
+	JSONWithFieldset(c, http.StatusOK, PackageSystemsResponse{
 		Data:  outputItems,
 		Links: *links,
 		Meta:  *meta,
-	})
+	}, fieldset)
 }
 
 // nolint: dupl
@@ -164,7 +173,7 @@ func PackageSystemsListHandler(c *gin.Context) {
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /ids/packages/{package_name}/systems [get]
 func PackageSystemsListIDsHandler(c *gin.Context) {
-	query, _, _, err := packageSystemsCommon(c)
+	query, _, _, err := packageSystemsCommon(c, Fieldset{})
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -4,9 +4,7 @@ import (
 	"app/base/utils"
 	"app/manager/middlewares"
 	"errors"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,6 +23,7 @@ import (
 // @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
 // @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
 // @Param    tags            query   []string  false "Tag filter"
+// @Param    fields[systems] query   string    false "Comma separated list of returned attributes"
 // @Success 200 {array} PackageSystemItem
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -51,7 +50,12 @@ func PackageSystemsExportHandler(c *gin.Context) {
 		return
 	}
 
-	query := packageSystemsQuery(account, packageName, packageIDs)
+	fieldset, err := ParseFieldset(c, "systems", &PackageSystemItem{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := packageSystemsQuery(c, account, packageName, packageIDs, fieldset)
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return
@@ -71,13 +75,5 @@ func PackageSystemsExportHandler(c *gin.Context) {
 
 	outputItems := packageSystemDBLookups2PackageSystemItems(systems)
 
-	accept := c.GetHeader("Accept")
-	if strings.Contains(accept, "application/json") { // nolint: gocritic
-		c.JSON(http.StatusOK, outputItems)
-	} else if strings.Contains(accept, "text/csv") {
-		Csv(c, 200, outputItems)
-	} else {
-		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
-	}
+	ExportWithFieldset(c, outputItems, fieldset)
 }
@@ -40,8 +40,8 @@ func packagesNameID(pkgName string) *gorm.DB {
 		Where("pn.name = ?", pkgName)
 }
 
-func packageVersionsQuery(acc int, packageNameIDs []int) *gorm.DB {
-	query := database.SystemPackages(database.Db, acc).
+func packageVersionsQuery(acc int, scope *database.SystemScope, packageNameIDs []int) *gorm.DB {
+	query := database.SystemPackages(database.Db, acc, scope).
 		Distinct(PackageVersionSelect).
 		Where("sp.stale = false").
 		Where("spkg.name_id in (?)", packageNameIDs)
@@ -82,7 +82,7 @@ func PackageVersionsListHandler(c *gin.Context) {
 		return
 	}
 
-	query := packageVersionsQuery(account, packageNameIDs)
+	query := packageVersionsQuery(account, middlewares.GetSystemScope(c), packageNameIDs)
 	// we don't support tags and filters for this endpoint
 	query, meta, links, err := ListCommon(query, c, nil, PackageVersionsOpts)
 	if err != nil {
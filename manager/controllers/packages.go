@@ -3,12 +3,13 @@ package controllers
 import (
 	"app/base/database"
 	"app/manager/middlewares"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-var PackagesFields = database.MustGetQueryAttrs(&PackageItem{})
+var PackagesFields = withModuleAttrs(database.MustGetQueryAttrs(&PackageItem{}), "res.module_name", "res.module_stream")
 var PackagesSelect = database.MustGetSelect(&PackageItem{})
 var PackagesOpts = ListOpts{
 	Fields: PackagesFields,
@@ -44,8 +45,68 @@ type queryItem struct {
 
 var queryItemSelect = database.MustGetSelect(&queryItem{})
 
-func packagesQuery(filters map[string]FilterData, acc int) *gorm.DB {
-	systemsWithPkgsInstalledQ := database.Systems(database.Db, acc).
+// Module of the packages counted in the subquery, only packages of the filtered modules are counted
+type packageModuleQueryItem struct {
+	ModuleName   string `query:"min(pm.name)" gorm:"column:module_name"`
+	ModuleStream string `query:"min(pm.stream)" gorm:"column:module_stream"`
+}
+
+var packagesModuleQueryItemSelect = database.MustGetSelect(&packageModuleQueryItem{})
+
+// Package module filters, applied on system packages before they are counted
+var packagesModuleFields = withModuleAttrs(database.AttrMap{}, "pm.name", "pm.stream")
+
+// Add filter and sort only module attributes, packages are listed by name so they don't have a single module
+func withModuleAttrs(fields database.AttrMap, nameQuery, streamQuery string) database.AttrMap {
+	stringAttr := database.MustGetQueryAttrs(&PackageItem{})["name"] // reuse string parser
+	for name, query := range map[string]string{"module_name": nameQuery, "module_stream": streamQuery} {
+		attr := stringAttr
+		attr.DataQuery = query
+		attr.OrderQuery = query
+		fields[name] = attr
+	}
+	return fields
+}
+
+// Parse module filters applied on system packages before they are counted,
+// nil is returned when module attributes are neither filtered nor sorted by
+func parsePackagesModuleFilters(c *gin.Context) Filters {
+	if !isAttrUsed(c, Fieldset{}, "module_name", "module_name") &&
+		!isAttrUsed(c, Fieldset{}, "module_stream", "module_stream") {
+		return nil
+	}
+
+	filters := Filters{}
+	query := NestedQueryMap(c, "filter")
+	for name := range packagesModuleFields {
+		if elem := query.Path(name); elem != nil {
+			// invalid filters are reported by ListCommon which applies them on the outer query as well
+			elem.Visit(func(path []string, val string) {
+				if filter, err := ParseFilterValue(val); err == nil && len(path) == 0 {
+					filters[name] = filter
+				}
+			})
+		}
+	}
+	return filters
+}
+
+// Count only packages belonging to the filtered module streams
+func applyPackagesModuleFilters(subQ *gorm.DB, moduleFilters Filters) *gorm.DB {
+	subQ = subQ.Select(queryItemSelect + ", " + packagesModuleQueryItemSelect).
+		Joins("JOIN package p ON p.id = spkg.package_id").
+		Joins("JOIN package_name pn ON pn.id = spkg.name_id").
+		Joins(packageModuleJoin).
+		Where("pm.name IS NOT NULL")
+	if filtered, err := moduleFilters.Apply(subQ, packagesModuleFields); err == nil {
+		return filtered
+	}
+	return subQ
+}
+
+func packagesQuery(filters map[string]FilterData, moduleFilters Filters, acc int, scope *database.SystemScope,
+	fieldset Fieldset) *gorm.DB {
+	systemsWithPkgsInstalledQ := database.Systems(database.Db, acc, scope).
 		Select("id").
 		Where("sp.stale = false AND sp.packages_installed > 0")
 
@@ -55,9 +116,12 @@ func packagesQuery(filters map[string]FilterData, acc int) *gorm.DB {
 		Select(queryItemSelect).
 		Where("spkg.system_id IN (?)", systemsWithPkgsInstalledQ).
 		Group("spkg.name_id")
+	if moduleFilters != nil {
+		subQ = applyPackagesModuleFilters(subQ, moduleFilters)
+	}
 
 	return database.Db.
-		Select(PackagesSelect).
+		Select(fieldset.Select(&PackageItem{}, nil, "name")).
 		Table("package_name pn").
 		Joins("JOIN (?) res ON res.name_id = pn.id", subQ)
 }
@@ -70,12 +134,14 @@ func packagesQuery(filters map[string]FilterData, acc int) *gorm.DB {
 // @Produce  json
 // @Param    limit          query      int     false   "Limit for paging, set -1 to return all"
 // @Param    offset         query      int     false   "Offset for paging"
-// @Param    sort           query      string  false   "Sort field" Enums(id,name,systems_installed,systems_updatable)
+// @Param    sort           query      string  false   "Sort field" Enums(name,systems_installed,systems_updatable)
 // @Param    search         query      string  false   "Find matching text"
 // @Param    filter[name]    query     string  false "Filter"
 // @Param    filter[systems_installed] query   string  false "Filter"
 // @Param    filter[systems_updatable] query   string  false "Filter"
 // @Param    filter[summary]           query   string  false "Filter"
+// @Param    filter[module_name]       query   string  false "Filter packages installed from dnf module"
+// @Param    filter[module_stream]     query   string  false "Filter packages installed from dnf module stream"
 // @Param    tags                      query   []string  false "Tag filter"
 // @Param    filter[system_profile][sap_system]						query string  	false "Filter only SAP systems"
 // @Param    filter[system_profile][sap_sids][in]					query []string  false "Filter systems by their SAP SIDs"
@@ -83,6 +149,7 @@ func packagesQuery(filters map[string]FilterData, acc int) *gorm.DB {
 // @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
 // @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
 // @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Param    fields[packages]          query   string    false "Comma separated list of returned attributes"
 // @Success 200 {object} PackagesResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -92,11 +159,16 @@ func PackagesListHandler(c *gin.Context) {
 	var filters map[string]FilterData
 	account := c.GetInt(middlewares.KeyAccount)
 
-	filters, err := ParseTagsFilters(c)
+	fieldset, err := ParseFieldset(c, "packages", &PackageItem{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	filters, err = ParseTagsFilters(c)
 	if err != nil {
 		return
 	}
-	query := packagesQuery(filters, account)
+	query := packagesQuery(filters, parsePackagesModuleFilters(c), account, middlewares.GetSystemScope(c), fieldset)
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -112,9 +184,9 @@ func PackagesListHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, PackagesResponse{
+	JSONWithFieldset(c, http.StatusOK, PackagesResponse{
 		Data:  packages,
 		Links: *links,
 		Meta:  *meta,
-	})
+	}, fieldset)
 }
@@ -2,9 +2,6 @@ package controllers
 
 import (
 	"app/manager/middlewares"
-	"fmt"
-	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,23 +12,31 @@ import (
 // @Security RhIdentity
 // @Accept   json
 // @Produce  json,text/csv
-// @Param    sort           query      string  false   "Sort field" Enums(id,name,systems_installed,systems_updatable)
+// @Param    sort           query      string  false   "Sort field" Enums(name,systems_installed,systems_updatable)
 // @Param    search         query      string  false   "Find matching text"
 // @Param    filter[name]    query     string  false "Filter"
 // @Param    filter[systems_installed] query   string  false "Filter"
 // @Param    filter[systems_updatable] query   string  false "Filter"
 // @Param    filter[summary]           query   string  false "Filter"
+// @Param    filter[module_name]       query   string  false "Filter packages installed from dnf module"
+// @Param    filter[module_stream]     query   string  false "Filter packages installed from dnf module stream"
+// @Param    fields[packages]          query   string    false "Comma separated list of returned attributes"
 // @Success 200 {array} PackageItem
 // @Failure 415 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /export/packages [get]
 func PackagesExportHandler(c *gin.Context) {
 	account := c.GetInt(middlewares.KeyAccount)
+	fieldset, err := ParseFieldset(c, "packages", &PackageItem{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return
 	}
-	query := packagesQuery(filters, account)
+	query := packagesQuery(filters, parsePackagesModuleFilters(c), account, middlewares.GetSystemScope(c), fieldset)
 	if err != nil {
 		return
 	}
@@ -49,13 +54,5 @@ func PackagesExportHandler(c *gin.Context) {
 		return
 	}
 
-	accept := c.GetHeader("Accept")
-	if strings.Contains(accept, "application/json") { // nolint: gocritic
-		c.JSON(http.StatusOK, data)
-	} else if strings.Contains(accept, "text/csv") {
-		Csv(c, 200, data)
-	} else {
-		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
-	}
+	ExportWithFieldset(c, data, fieldset)
 }
@@ -75,6 +75,15 @@ func TestPackageTagsInvalid(t *testing.T) {
 	assert.Equal(t, fmt.Sprintf(InvalidTagMsg, "invalidTag"), errResp.Error)
 }
 
+func TestPackagesSortInvalid(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/?sort=id", nil, "", PackagesListHandler, 3, "GET", "/")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, "Invalid sort field: id", errResp.Error)
+}
+
 func TestPackagesWrongOffset(t *testing.T) {
 	doTestWrongOffset(t, "/", "/?offset=1000", PackagesListHandler)
 }
@@ -93,3 +102,12 @@ func TestPackageTagsInMetadata(t *testing.T) {
 	}
 	assert.Equal(t, testMap, output.Meta.Filter)
 }
+
+func TestPackagesFilterModule(t *testing.T) {
+	core.SetupTest(t)
+	defer createModularPackage(t)()
+	output := doTestPackages(t, "/?filter[module_name]=firefox&filter[module_stream]=stable")
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "firefox", output.Data[0].Name)
+	assert.Equal(t, 1, output.Data[0].SystemsInstalled)
+}
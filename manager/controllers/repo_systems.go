@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var RepoSystemsFields = database.MustGetQueryAttrs(&RepoSystemDBLookup{})
+var RepoSystemsOpts = ListOpts{
+	Fields: RepoSystemsFields,
+	// By default, we show only fresh systems. If all systems are required, you must pass in:true,false filter into the api
+	DefaultFilters: map[string]FilterData{
+		"stale": {
+			Operator: "eq",
+			Values:   []string{"false"},
+		},
+	},
+	DefaultSort:  "id",
+	StableSort:   "sp.id",
+	SearchFields: []string{"sp.display_name"},
+	TotalFunc:    CountRows,
+}
+
+//nolint:lll
+type RepoSystemItem struct {
+	ID               string         `json:"id" csv:"id" query:"sp.inventory_id" gorm:"column:id"`
+	DisplayName      string         `json:"display_name" csv:"display_name" query:"sp.display_name" gorm:"column:display_name"`
+	LastUpload       *time.Time     `json:"last_upload" csv:"last_upload" query:"sp.last_upload" gorm:"column:last_upload"`
+	Stale            bool           `json:"stale" csv:"stale" query:"sp.stale" gorm:"column:stale"`
+	ThirdParty       bool           `json:"third_party" csv:"third_party" query:"sp.third_party" gorm:"column:third_party"`
+	Tags             SystemTagsList `json:"tags" csv:"tags" query:"null" gorm:"-"`
+	BaselineName     string         `json:"baseline_name" csv:"baseline_name" query:"bl.name" gorm:"column:baseline_name"`
+	BaselineUpToDate *bool          `json:"baseline_uptodate" csv:"baseline_uptodate" query:"sp.baseline_uptodate" gorm:"column:baseline_uptodate"`
+}
+
+type RepoSystemDBLookup struct {
+	// Just helper field to get tags from db in plain string, then parsed to "Tags" attr., excluded from output data.
+	TagsStr string `json:"-" csv:"-" query:"ih.tags" gorm:"column:tags_str"`
+
+	RepoSystemItem
+}
+
+type RepoSystemsResponse struct {
+	Data  []RepoSystemItem `json:"data"`
+	Links Links            `json:"links"`
+	Meta  ListMeta         `json:"meta"`
+}
+
+func repoSystemsQuery(c *gin.Context, acc int, repoID int64, fieldset Fieldset) *gorm.DB {
+	query := database.Systems(database.Db, acc, middlewares.GetSystemScope(c)).
+		Select(fieldset.Select(&RepoSystemDBLookup{}, SystemsFieldsetHelpers, "id")).
+		Joins("JOIN system_repo sr ON sr.system_id = sp.id AND sr.rh_account_id = sp.rh_account_id").
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id")
+	if isAttrUsed(c, fieldset, "baseline_name", "baseline_name") {
+		query = query.Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id")
+	}
+	return query.Where("sr.repo_id = ?", repoID)
+}
+
+// nolint: dupl
+// @Summary Show me all my systems which have a repository enabled
+// @Description  Show me all my systems which have a repository enabled
+// @ID repoSystems
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    limit          query   int     false   "Limit for paging, set -1 to return all"
+// @Param    offset         query   int     false   "Offset for paging"
+// @Param    sort           query   string  false   "Sort field" Enums(id,display_name,last_upload,stale,third_party,baseline_name)
+// @Param    search         query   string  false   "Find matching text"
+// @Param    repo_name      path    string  true    "Repository name"
+// @Param    filter[display_name]   query   string  false "Filter"
+// @Param    filter[last_upload]    query   string  false "Filter"
+// @Param    filter[stale]          query   string  false "Filter"
+// @Param    filter[third_party]    query   string  false "Filter"
+// @Param    filter[baseline_name]  query   string  false "Filter"
+// @Param    tags            query   []string  false "Tag filter"
+// @Param    filter[system_profile][sap_system]						query string  	false "Filter only SAP systems"
+// @Param    filter[system_profile][sap_sids][in]					query []string  false "Filter systems by their SAP SIDs"
+// @Param    filter[system_profile][ansible]						query string 	false "Filter systems by ansible"
+// @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
+// @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
+// @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Param    fields[systems] query   string    false "Comma separated list of returned attributes"
+// @Success 200 {object} RepoSystemsResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /repos/{repo_name}/systems [get]
+func RepoSystemsListHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	repoName := c.Param("repo_name")
+	if repoName == "" {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{Error: "repo_name param not found"})
+		return
+	}
+
+	var repoIDs []int64
+	if err := database.Db.Table("repo").Where("name = ?", repoName).Pluck("id", &repoIDs).Error; err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+	if len(repoIDs) == 0 {
+		LogAndRespNotFound(c, errors.New("not found"), "repo not found")
+		return
+	}
+
+	fieldset, err := ParseFieldset(c, "systems", &RepoSystemItem{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := repoSystemsQuery(c, account, repoIDs[0], fieldset)
+	filters, err := ParseTagsFilters(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+	query, _ = ApplyTagsFilter(filters, query, "sp.inventory_id")
+	query, meta, links, err := ListCommon(query, c, filters, RepoSystemsOpts)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	var systems []RepoSystemDBLookup
+	err = query.Find(&systems).Error
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	JSONWithFieldset(c, http.StatusOK, RepoSystemsResponse{
+		Data:  repoSystemDBLookups2RepoSystemItems(systems),
+		Links: *links,
+		Meta:  *meta,
+	}, fieldset)
+}
+
+func repoSystemDBLookups2RepoSystemItems(systems []RepoSystemDBLookup) []RepoSystemItem {
+	data := make([]RepoSystemItem, len(systems))
+	var err error
+	for i, system := range systems {
+		system.RepoSystemItem.Tags, err = parseSystemTags(system.TagsStr)
+		if err != nil {
+			utils.Log("err", err.Error(), "inventory_id", system.ID).Debug("system tags parsing failed")
+		}
+		data[i] = system.RepoSystemItem
+	}
+	return data
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doTestRepoSystems(t *testing.T, q string) RepoSystemsResponse {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", q, nil, "", RepoSystemsListHandler, 1, "GET", "/:repo_name/systems")
+
+	var output RepoSystemsResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+func TestRepoSystems(t *testing.T) {
+	output := doTestRepoSystems(t, "/repo1/systems")
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[0].ID)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000003", output.Data[1].ID)
+	assert.Equal(t, "baseline_1-1", output.Data[0].BaselineName)
+}
+
+func TestRepoSystemsFilter(t *testing.T) {
+	output := doTestRepoSystems(t, "/repo1/systems?filter[baseline_name]=baseline_1-2")
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000003", output.Data[0].ID)
+}
+
+func TestRepoSystemsNotFound(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/unknown-repo/systems", nil, "", RepoSystemsListHandler, 1,
+		"GET", "/:repo_name/systems")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusNotFound, &errResp)
+	assert.Equal(t, "repo not found", errResp.Error)
+}
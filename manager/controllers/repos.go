@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/manager/middlewares"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var ReposFields = database.MustGetQueryAttrs(&RepoItem{})
+var ReposSelect = database.MustGetSelect(&RepoItem{})
+var ReposOpts = ListOpts{
+	Fields:         ReposFields,
+	DefaultFilters: map[string]FilterData{},
+	DefaultSort:    "name",
+	StableSort:     "r.id",
+	SearchFields:   []string{"r.name"},
+	TotalFunc:      CountRows,
+}
+
+type RepoItem struct {
+	Name       string `json:"name" csv:"name" query:"r.name" gorm:"column:name"`
+	ThirdParty bool   `json:"third_party" csv:"third_party" query:"r.third_party" gorm:"column:third_party"`
+	Systems    int    `json:"systems" csv:"systems" query:"res.systems" gorm:"column:systems"` // Fresh systems with the repo enabled
+}
+
+type ReposResponse struct {
+	Data  []RepoItem `json:"data"`
+	Links Links      `json:"links"`
+	Meta  ListMeta   `json:"meta"`
+}
+
+// Used as a subquery counting systems per repo which is joined with repo table
+type repoQueryItem struct {
+	RepoID  int64 `query:"sr.repo_id" gorm:"column:repo_id"`
+	Systems int   `query:"count(sr.system_id)" gorm:"column:systems"`
+}
+
+var repoQueryItemSelect = database.MustGetSelect(&repoQueryItem{})
+
+func reposQuery(filters map[string]FilterData, acc int, scope *database.SystemScope) *gorm.DB {
+	systemsQ := database.Systems(database.Db, acc, scope).
+		Select("id").
+		Where("sp.stale = false")
+
+	// We need to apply tag filtering on subquery
+	systemsQ, _ = ApplyTagsFilter(filters, systemsQ, "sp.inventory_id")
+	subQ := database.Db.Table("system_repo sr").
+		Select(repoQueryItemSelect).
+		Where("sr.rh_account_id = ?", acc).
+		Where("sr.system_id IN (?)", systemsQ).
+		Group("sr.repo_id")
+
+	return database.Db.
+		Select(ReposSelect).
+		Table("repo r").
+		Joins("JOIN (?) res ON res.repo_id = r.id", subQ)
+}
+
+// @Summary Show me all repositories enabled on my systems
+// @Description Show me all repositories enabled on my systems
+// @ID listRepos
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    limit          query      int     false   "Limit for paging, set -1 to return all"
+// @Param    offset         query      int     false   "Offset for paging"
+// @Param    sort           query      string  false   "Sort field" Enums(name,third_party,systems)
+// @Param    search         query      string  false   "Find matching text"
+// @Param    filter[name]           query   string  false "Filter"
+// @Param    filter[third_party]    query   string  false "Filter"
+// @Param    filter[systems]        query   string  false "Filter"
+// @Param    tags                   query   []string  false "Tag filter"
+// @Param    filter[system_profile][sap_system]						query string  	false "Filter only SAP systems"
+// @Param    filter[system_profile][sap_sids][in]					query []string  false "Filter systems by their SAP SIDs"
+// @Param    filter[system_profile][ansible]						query string 	false "Filter systems by ansible"
+// @Param    filter[system_profile][ansible][controller_version]	query string 	false "Filter systems by ansible version"
+// @Param    filter[system_profile][mssql]							query string 	false "Filter systems by mssql version"
+// @Param    filter[system_profile][mssql][version]					query string 	false "Filter systems by mssql version"
+// @Success 200 {object} ReposResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /repos [get]
+func ReposListHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	filters, err := ParseTagsFilters(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := reposQuery(filters, account, middlewares.GetSystemScope(c))
+	query, meta, links, err := ListCommon(query, c, filters, ReposOpts)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	var repos = make([]RepoItem, 0)
+	err = query.Scan(&repos).Error
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	c.JSON(http.StatusOK, ReposResponse{
+		Data:  repos,
+		Links: *links,
+		Meta:  *meta,
+	})
+}
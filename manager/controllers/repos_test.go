@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doTestRepos(t *testing.T, q string) ReposResponse {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", q, nil, "", ReposListHandler, 1, "GET", "/")
+
+	var output ReposResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+func TestReposDefault(t *testing.T) {
+	output := doTestRepos(t, "/")
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, RepoItem{Name: "repo1", ThirdParty: false, Systems: 2}, output.Data[0])
+	assert.Equal(t, RepoItem{Name: "repo2", ThirdParty: false, Systems: 1}, output.Data[1])
+	assert.Equal(t, 2, output.Meta.TotalItems)
+}
+
+func TestReposFilterSystems(t *testing.T) {
+	output := doTestRepos(t, "/?filter[systems]=gt:1")
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "repo1", output.Data[0].Name)
+}
+
+func TestReposSort(t *testing.T) {
+	output := doTestRepos(t, "/?sort=systems")
+	assert.Equal(t, "repo2", output.Data[0].Name)
+}
+
+func TestReposTags(t *testing.T) {
+	output := doTestRepos(t, "/?tags=ns1/k3=val4&tags=ns1/k1=val1")
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, RepoItem{Name: "repo1", ThirdParty: false, Systems: 1}, output.Data[0])
+}
+
+func TestReposSortInvalid(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/?sort=id", nil, "", ReposListHandler, 1, "GET", "/")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, "Invalid sort field: id", errResp.Error)
+}
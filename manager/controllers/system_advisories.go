@@ -30,7 +30,7 @@ type RelList []string
 
 type SystemAdvisoriesDBLookup struct {
 	ID string `json:"id" csv:"id" query:"am.name" gorm:"column:id"`
-	SystemAdvisoryItemAttributes
+	SystemAdvisoryAttributes
 }
 
 // nolint:lll
@@ -49,10 +49,17 @@ type SystemAdvisoryItemAttributes struct {
 	ReleaseVersionsJSONB []byte `json:"-" csv:"-" query:"am.release_versions" gorm:"column:release_versions_json"`
 }
 
+// nolint:lll
+type SystemAdvisoryAttributes struct {
+	SystemAdvisoryItemAttributes
+	// Advisory status according to the system template: installable, applicable (not in template) or null (no template)
+	TemplateStatus *string `json:"template_status" csv:"template_status" query:"CASE WHEN sa.in_template THEN 'installable' WHEN NOT sa.in_template THEN 'applicable' END" gorm:"column:template_status"`
+}
+
 type SystemAdvisoryItem struct {
-	Attributes SystemAdvisoryItemAttributes `json:"attributes"`
-	ID         string                       `json:"id"`
-	Type       string                       `json:"type"`
+	Attributes SystemAdvisoryAttributes `json:"attributes"`
+	ID         string                   `json:"id"`
+	Type       string                   `json:"type"`
 }
 
 type SystemAdvisoriesResponse struct {
@@ -65,7 +72,7 @@ func (v RelList) String() string {
 	return strings.Join(v, ",")
 }
 
-func systemAdvisoriesCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
+func systemAdvisoriesCommon(c *gin.Context, fieldset Fieldset) (*gorm.DB, *ListMeta, *Links, error) {
 	account := c.GetInt(middlewares.KeyAccount)
 
 	inventoryID := c.Param("inventory_id")
@@ -93,7 +100,7 @@ func systemAdvisoriesCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error)
 		return nil, nil, nil, err
 	}
 
-	query := buildSystemAdvisoriesQuery(account, inventoryID)
+	query := buildSystemAdvisoriesQuery(account, middlewares.GetSystemScope(c), inventoryID, fieldset)
 	query, meta, links, err := ListCommon(query, c, nil, SystemAdvisoriesOpts)
 	// Error handling and setting of result code & content is done in ListCommon
 	return query, meta, links, err
@@ -118,13 +125,20 @@ func systemAdvisoriesCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error)
 // @Param    filter[advisory_type]       query   string  false "Filter"
 // @Param    filter[advisory_type_name]  query   string  false "Filter"
 // @Param    filter[severity]            query   string  false "Filter"
+// @Param    filter[template_status]     query   string  false "Filter"
+// @Param    fields[advisories] query  string  false   "Comma separated list of returned attributes"
 // @Success 200 {object} SystemAdvisoriesResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /systems/{inventory_id}/advisories [get]
 func SystemAdvisoriesHandler(c *gin.Context) {
-	query, meta, links, err := systemAdvisoriesCommon(c)
+	fieldset, err := ParseFieldset(c, "advisories", &SystemAdvisoryAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query, meta, links, err := systemAdvisoriesCommon(c, fieldset)
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -142,7 +156,7 @@ func SystemAdvisoriesHandler(c *gin.Context) {
 		Links: *links,
 		Meta:  *meta,
 	}
-	c.JSON(http.StatusOK, &resp)
+	JSONWithFieldset(c, http.StatusOK, &resp, fieldset)
 }
 
 // nolint:lll
@@ -164,13 +178,14 @@ func SystemAdvisoriesHandler(c *gin.Context) {
 // @Param    filter[advisory_type]       query   string  false "Filter"
 // @Param    filter[advisory_type_name]  query   string  false "Filter"
 // @Param    filter[severity]            query   string  false "Filter"
+// @Param    filter[template_status]     query   string  false "Filter"
 // @Success 200 {object} IDsResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /ids/systems/{inventory_id}/advisories [get]
 func SystemAdvisoriesIDsHandler(c *gin.Context) {
-	query, _, _, err := systemAdvisoriesCommon(c)
+	query, _, _, err := systemAdvisoriesCommon(c, Fieldset{})
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -186,11 +201,12 @@ func SystemAdvisoriesIDsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, &resp)
 }
 
-func buildSystemAdvisoriesQuery(account int, inventoryID string) *gorm.DB {
-	query := database.SystemAdvisoriesByInventoryID(database.Db, account, inventoryID).
+func buildSystemAdvisoriesQuery(account int, scope *database.SystemScope, inventoryID string, fieldset Fieldset,
+) *gorm.DB {
+	query := database.SystemAdvisoriesByInventoryID(database.Db, account, scope, inventoryID).
 		Joins("JOIN advisory_metadata am on am.id = sa.advisory_id").
 		Joins("JOIN advisory_type at ON am.advisory_type_id = at.id").
-		Select(SystemAdvisoriesSelect)
+		Select(fieldset.Select(&SystemAdvisoriesDBLookup{}, AdvisoriesFieldsetHelpers, "id"))
 	return query
 }
 
@@ -201,7 +217,7 @@ func buildSystemAdvisoriesData(models []SystemAdvisoriesDBLookup) []SystemAdviso
 		item := SystemAdvisoryItem{
 			ID:         advisory.ID,
 			Type:       "advisory",
-			Attributes: advisory.SystemAdvisoryItemAttributes,
+			Attributes: advisory.SystemAdvisoryAttributes,
 		}
 		data[i] = item
 	}
@@ -5,9 +5,7 @@ import (
 	"app/base/models"
 	"app/base/utils"
 	"app/manager/middlewares"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -28,6 +26,7 @@ import (
 // @Param    filter[advisory_type]       query   string  false "Filter"
 // @Param    filter[advisory_type_name]  query   string  false "Filter"
 // @Param    filter[severity]            query   string  false "Filter"
+// @Param    fields[advisories] query  string  false   "Comma separated list of returned attributes"
 // @Success 200 {array} SystemAdvisoriesDBLookup
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -60,7 +59,12 @@ func SystemAdvisoriesExportHandler(c *gin.Context) {
 		return
 	}
 
-	query := buildSystemAdvisoriesQuery(account, inventoryID)
+	fieldset, err := ParseFieldset(c, "advisories", &SystemAdvisoryAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := buildSystemAdvisoriesQuery(account, middlewares.GetSystemScope(c), inventoryID, fieldset)
 	query = query.Order("id")
 	query, err = ExportListCommon(query, c, AdvisoriesOpts)
 	if err != nil {
@@ -79,13 +83,5 @@ func SystemAdvisoriesExportHandler(c *gin.Context) {
 		return
 	}
 
-	accept := c.GetHeader("Accept")
-	if strings.Contains(accept, "application/json") { // nolint: gocritic
-		c.JSON(http.StatusOK, advisories)
-	} else if strings.Contains(accept, "text/csv") {
-		Csv(c, http.StatusOK, advisories)
-	} else {
-		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
-	}
+	ExportWithFieldset(c, advisories, fieldset)
 }
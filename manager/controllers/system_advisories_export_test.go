@@ -31,8 +31,9 @@ func TestSystemAdvisoriesExportCSV(t *testing.T) {
 
 	assert.Equal(t, 10, len(lines))
 	assert.Equal(t, "id,description,public_date,synopsis,advisory_type,advisory_type_name,severity,cve_count,"+
-		"reboot_required,release_versions", lines[0])
-	assert.Equal(t, "RH-1,adv-1-des,2016-09-22T16:00:00Z,adv-1-syn,1,enhancement,,0,false,\"7.0,7Server\"", lines[1])
+		"reboot_required,release_versions,template_status", lines[0])
+	assert.Equal(t, "RH-1,adv-1-des,2016-09-22T16:00:00Z,adv-1-syn,1,enhancement,,0,false,\"7.0,7Server\","+
+		"installable", lines[1])
 }
 
 func TestUnknownSystemAdvisoriesExport(t *testing.T) {
@@ -28,6 +28,24 @@ func TestSystemAdvisoriesDefault(t *testing.T) {
 	assert.Equal(t, "2017-09-22 19:00:00 +0000 UTC", output.Data[0].Attributes.PublicDate.String())
 	assert.Equal(t, 0, output.Data[0].Attributes.CveCount)
 	assert.Equal(t, false, output.Data[0].Attributes.RebootRequired)
+	assert.Equal(t, "applicable", *output.Data[0].Attributes.TemplateStatus)
+}
+
+func TestSystemAdvisoriesTemplateStatus(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/00000000-0000-0000-0000-000000000001?filter[template_status]=installable",
+		nil, "", SystemAdvisoriesHandler, "/:inventory_id")
+
+	var output SystemAdvisoriesResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "RH-1", output.Data[0].ID)
+
+	// system without template
+	w = CreateRequestRouterWithPath("GET", "/00000000-0000-0000-0000-000000000002", nil, "",
+		SystemAdvisoriesHandler, "/:inventory_id")
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Nil(t, output.Data[0].Attributes.TemplateStatus)
 }
 
 func TestSystemAdvisoriesIDsDefault(t *testing.T) {
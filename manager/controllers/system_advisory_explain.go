@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"app/manager/middlewares"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	ExplainSourceYum   = "yum_updates"
+	ExplainSourceVmaas = "vmaas"
+)
+
+type ExplainPackage struct {
+	Installed string   `json:"installed"` // Installed NEVRA which caused the advisory to be reported
+	FixedBy   []string `json:"fixed_by"`  // Target NEVRAs which fix the advisory
+	Repos     []string `json:"repos"`     // Repositories providing the fixing packages, known only for yum_updates source
+	Sources   []string `json:"sources"`   // Data used to find the update - yum_updates, vmaas
+}
+
+type ExplainModule struct {
+	Name   string `json:"name"`
+	Stream string `json:"stream"`
+}
+
+type ExplainBaseline struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	ToTime      *time.Time `json:"to_time"`
+	FilteredOut bool       `json:"filtered_out"` // Advisory is published after baseline to_time and it's not reported
+}
+
+type SystemAdvisoryExplain struct {
+	InventoryID string `json:"inventory_id"`
+	AdvisoryID  string `json:"advisory_id"`
+	// Advisory is applicable to the system (it's stored as not patched system advisory)
+	Applicable bool             `json:"applicable"`
+	Sources    []string         `json:"sources"` // Data used to find the updates - yum_updates, vmaas
+	Packages   []ExplainPackage `json:"packages"`
+	// Repositories and modules enabled on the system which were sent to VMaaS
+	Repos      []string         `json:"repos"`
+	Modules    []ExplainModule  `json:"modules"`
+	Releasever *string          `json:"releasever"`
+	Basearch   *string          `json:"basearch"`
+	Baseline   *ExplainBaseline `json:"baseline"`
+}
+
+type SystemAdvisoryExplainResponse struct {
+	Data SystemAdvisoryExplain `json:"data"`
+}
+
+type explainSystemDBLoad struct {
+	ID             int        `query:"sp.id" gorm:"column:id"`
+	VmaasJSON      *string    `query:"sp.vmaas_json" gorm:"column:vmaas_json"`
+	YumUpdates     []byte     `query:"sp.yum_updates" gorm:"column:yum_updates"`
+	BaselineID     *int       `query:"sp.baseline_id" gorm:"column:baseline_id"`
+	BaselineName   *string    `query:"bl.name" gorm:"column:baseline_name"`
+	BaselineConfig []byte     `query:"bl.config" gorm:"column:baseline_config"`
+	Applicable     bool       `query:"sa.system_id IS NOT NULL" gorm:"column:applicable"`
+	PublicDate     *time.Time `query:"am.public_date" gorm:"column:public_date"`
+}
+
+type explainPackageDBLoad struct {
+	Name       string `gorm:"column:name"`
+	EVRA       string `gorm:"column:evra"`
+	UpdateData []byte `gorm:"column:update_data"`
+}
+
+var explainSystemSelect = database.MustGetSelect(&explainSystemDBLoad{})
+
+// @Summary Explain why an advisory is applicable to a system
+// @Description Show installed packages, repos and modules which caused the advisory to be reported for the system,
+// @Description packages which fix it, data source of the updates and whether the advisory is filtered by a baseline
+// @ID explainSystemAdvisory
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    inventory_id    path    string   true "Inventory ID"
+// @Param    advisory_id     path    string   true "Advisory ID"
+// @Success 200 {object} SystemAdvisoryExplainResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /systems/{inventory_id}/advisories/{advisory_id}/explain [get]
+func SystemAdvisoryExplainHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	inventoryID := c.Param("inventory_id")
+	if !utils.IsValidUUID(inventoryID) {
+		LogAndRespBadRequest(c, errors.New("bad request"), "incorrect inventory_id format")
+		return
+	}
+	advisoryName := c.Param("advisory_id")
+	if advisoryName == "" {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{Error: "advisory_id param not found"})
+		return
+	}
+
+	var advisory models.AdvisoryMetadata
+	err := database.Db.Where("name = ?", advisoryName).Take(&advisory).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		LogAndRespNotFound(c, err, "advisory not found")
+		return
+	}
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	var system explainSystemDBLoad
+	err = database.Systems(database.Db, account, middlewares.GetSystemScope(c)).
+		Select(explainSystemSelect).
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
+		Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id").
+		Joins("LEFT JOIN system_advisories sa ON sa.system_id = sp.id AND sa.rh_account_id = sp.rh_account_id "+
+			"AND sa.advisory_id = ? AND sa.when_patched IS NULL", advisory.ID).
+		Joins("LEFT JOIN advisory_metadata am ON am.id = ?", advisory.ID).
+		Where("sp.inventory_id = ?::uuid", inventoryID).
+		Take(&system).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		LogAndRespNotFound(c, err, "inventory not found")
+		return
+	}
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	// only the advisory key is matched, models.PackageUpdate would add empty evra to the containment
+	updateData, err := json.Marshal([]map[string]string{{"advisory": advisoryName}})
+	if err != nil {
+		LogAndRespError(c, err, "unable to build query")
+		return
+	}
+	var packages []explainPackageDBLoad
+	err = database.SystemPackages(database.Db, account, middlewares.GetSystemScope(c)).
+		Select("pn.name AS name, p.evra AS evra, spkg.update_data AS update_data").
+		Where("sp.id = ?", system.ID).
+		Where("spkg.update_data @> ?::jsonb", string(updateData)).
+		Scan(&packages).Error
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	explain, err := buildSystemAdvisoryExplain(&system, packages, advisoryName)
+	if err != nil {
+		LogAndRespError(c, err, "unable to parse stored system data")
+		return
+	}
+	explain.InventoryID = inventoryID
+	c.JSON(http.StatusOK, SystemAdvisoryExplainResponse{Data: *explain})
+}
+
+func buildSystemAdvisoryExplain(system *explainSystemDBLoad, packages []explainPackageDBLoad,
+	advisoryName string) (*SystemAdvisoryExplain, error) {
+	explain := SystemAdvisoryExplain{
+		AdvisoryID: advisoryName,
+		Applicable: system.Applicable,
+		Sources:    []string{},
+		Packages:   []ExplainPackage{},
+		Repos:      []string{},
+		Modules:    []ExplainModule{},
+	}
+
+	if system.VmaasJSON != nil {
+		var updatesReq vmaas.UpdatesV3Request
+		if err := json.Unmarshal([]byte(*system.VmaasJSON), &updatesReq); err != nil {
+			return nil, errors.Wrap(err, "unable to parse vmaas json")
+		}
+		explain.Repos = append(explain.Repos, updatesReq.GetRepositoryList()...)
+		for _, m := range updatesReq.GetModulesList() {
+			explain.Modules = append(explain.Modules, ExplainModule{Name: m.ModuleName, Stream: m.ModuleStream})
+		}
+		explain.Releasever = updatesReq.Releasever
+		explain.Basearch = updatesReq.Basearch
+	}
+
+	byInstalled := map[string]*ExplainPackage{}
+	if err := explainYumUpdates(system.YumUpdates, advisoryName, byInstalled); err != nil {
+		return nil, err
+	}
+	if err := explainStoredUpdates(packages, advisoryName, byInstalled); err != nil {
+		return nil, err
+	}
+
+	sources := map[string]bool{}
+	for _, pkg := range byInstalled {
+		sort.Strings(pkg.FixedBy)
+		sort.Strings(pkg.Repos)
+		for _, source := range pkg.Sources {
+			sources[source] = true
+		}
+		explain.Packages = append(explain.Packages, *pkg)
+	}
+	sort.Slice(explain.Packages, func(i, j int) bool {
+		return explain.Packages[i].Installed < explain.Packages[j].Installed
+	})
+	for _, source := range []string{ExplainSourceYum, ExplainSourceVmaas} {
+		if sources[source] {
+			explain.Sources = append(explain.Sources, source)
+		}
+	}
+
+	baseline, err := explainBaseline(system)
+	if err != nil {
+		return nil, err
+	}
+	explain.Baseline = baseline
+	return &explain, nil
+}
+
+// Updates reported by the system itself (dnf/yum on the host) are stored unfiltered in yum_updates
+func explainYumUpdates(yumUpdates []byte, advisoryName string, byInstalled map[string]*ExplainPackage) error {
+	if yumUpdates == nil {
+		return nil
+	}
+	var resp vmaas.UpdatesV2Response
+	if err := json.Unmarshal(yumUpdates, &resp); err != nil {
+		return errors.Wrap(err, "unable to parse yum updates")
+	}
+	for installed, updates := range resp.GetUpdateList() {
+		for _, u := range updates.GetAvailableUpdates() {
+			if u.GetErratum() != advisoryName {
+				continue
+			}
+			pkg := explainPackageFor(byInstalled, normalizeNevra(installed))
+			pkg.FixedBy = appendUnique(pkg.FixedBy, normalizeNevra(u.GetPackage()))
+			if u.GetRepository() != "" {
+				pkg.Repos = appendUnique(pkg.Repos, u.GetRepository())
+			}
+			pkg.Sources = appendUnique(pkg.Sources, ExplainSourceYum)
+		}
+	}
+	return nil
+}
+
+// Updates stored in system_package.update_data are the evaluation result (merged VMaaS and yum updates
+// limited by baseline), those not reported in yum_updates were found by VMaaS
+func explainStoredUpdates(packages []explainPackageDBLoad, advisoryName string,
+	byInstalled map[string]*ExplainPackage) error {
+	for _, p := range packages {
+		var updates []models.PackageUpdate
+		if err := json.Unmarshal(p.UpdateData, &updates); err != nil {
+			return errors.Wrap(err, "unable to parse package update data")
+		}
+		pkg := explainPackageFor(byInstalled, nevraString(p.Name, p.EVRA))
+		for _, u := range updates {
+			if u.Advisory != advisoryName {
+				continue
+			}
+			fixedBy := nevraString(p.Name, u.EVRA)
+			if !containsString(pkg.FixedBy, fixedBy) {
+				pkg.FixedBy = append(pkg.FixedBy, fixedBy)
+				pkg.Sources = appendUnique(pkg.Sources, ExplainSourceVmaas)
+			}
+		}
+	}
+	return nil
+}
+
+func explainBaseline(system *explainSystemDBLoad) (*ExplainBaseline, error) {
+	if system.BaselineID == nil || system.BaselineName == nil {
+		return nil, nil
+	}
+	baseline := ExplainBaseline{ID: *system.BaselineID, Name: *system.BaselineName}
+	if len(system.BaselineConfig) == 0 {
+		return &baseline, nil
+	}
+
+	var config database.BaselineConfig
+	if err := json.Unmarshal(system.BaselineConfig, &config); err != nil {
+		return nil, errors.Wrap(err, "unable to parse baseline config")
+	}
+	baseline.ToTime = &config.ToTime
+	// Same condition as used by evaluator to limit reported advisories
+	if system.PublicDate != nil {
+		baseline.FilteredOut = !system.PublicDate.Before(config.ToTime.Truncate(24 * time.Hour))
+	}
+	return &baseline, nil
+}
+
+func explainPackageFor(byInstalled map[string]*ExplainPackage, installed string) *ExplainPackage {
+	if installed == "" {
+		installed = "unknown"
+	}
+	if pkg, ok := byInstalled[installed]; ok {
+		return pkg
+	}
+	pkg := &ExplainPackage{Installed: installed, FixedBy: []string{}, Repos: []string{}, Sources: []string{}}
+	byInstalled[installed] = pkg
+	return pkg
+}
+
+// Normalize NEVRA so packages from yum_updates and system_package match
+func normalizeNevra(nevraStr string) string {
+	nevra, err := utils.ParseNevra(nevraStr)
+	if err != nil {
+		return nevraStr
+	}
+	return nevra.String()
+}
+
+func nevraString(name, evra string) string {
+	return normalizeNevra(fmt.Sprintf("%s-%s", name, evra))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doTestSystemAdvisoryExplain(t *testing.T, inventoryID, advisoryID string, account int) SystemAdvisoryExplainResponse {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/"+inventoryID+"/advisories/"+advisoryID+"/explain", nil, "",
+		SystemAdvisoryExplainHandler, account, "GET", "/:inventory_id/advisories/:advisory_id/explain")
+
+	var output SystemAdvisoryExplainResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+func TestSystemAdvisoryExplainVmaas(t *testing.T) {
+	output := doTestSystemAdvisoryExplain(t, "00000000-0000-0000-0000-000000000012", "RH-1", 3)
+	assert.Equal(t, "RH-1", output.Data.AdvisoryID)
+	assert.Equal(t, []string{ExplainSourceVmaas}, output.Data.Sources)
+	assert.Equal(t, 1, len(output.Data.Packages))
+	assert.Equal(t, "firefox-76.0.1-1.fc31.x86_64", output.Data.Packages[0].Installed)
+	assert.Equal(t, []string{"firefox-77.0.1-1.fc31.x86_64"}, output.Data.Packages[0].FixedBy)
+	assert.Nil(t, output.Data.Baseline)
+}
+
+func TestSystemAdvisoryExplainYum(t *testing.T) {
+	output := doTestSystemAdvisoryExplain(t, "00000000-0000-0000-0000-000000000015", "RHSA-2021:3801", 3)
+	assert.Equal(t, []string{ExplainSourceYum}, output.Data.Sources)
+	assert.Equal(t, 1, len(output.Data.Packages))
+	assert.Equal(t, "suricata-6.0.3-2.fc35.i686", output.Data.Packages[0].Installed)
+	assert.Equal(t, []string{"suricata-6.0.4-2.fc35.i686"}, output.Data.Packages[0].FixedBy)
+	assert.Equal(t, []string{"group_oisf:suricata-6.0"}, output.Data.Packages[0].Repos)
+}
+
+func TestSystemAdvisoryExplainBaseline(t *testing.T) {
+	output := doTestSystemAdvisoryExplain(t, "00000000-0000-0000-0000-000000000001", "RH-1", 1)
+	assert.True(t, output.Data.Applicable)
+	assert.NotNil(t, output.Data.Baseline)
+	assert.Equal(t, 1, output.Data.Baseline.ID)
+	assert.Equal(t, "baseline_1-1", output.Data.Baseline.Name)
+	assert.True(t, output.Data.Baseline.FilteredOut)
+}
+
+func TestSystemAdvisoryExplainNotFound(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/00000000-0000-0000-0000-000000000001/advisories/RH-1/explain", nil, "",
+		SystemAdvisoryExplainHandler, 3, "GET", "/:inventory_id/advisories/:advisory_id/explain")
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusNotFound, &errResp)
+
+	w = CreateRequestRouterWithParams("GET", "/00000000-0000-0000-0000-000000000001/advisories/RH-X/explain", nil, "",
+		SystemAdvisoryExplainHandler, 1, "GET", "/:inventory_id/advisories/:advisory_id/explain")
+	CheckResponse(t, w, http.StatusNotFound, &errResp)
+	assert.Equal(t, "advisory not found", errResp.Error)
+}
+
+func TestBuildSystemAdvisoryExplainMerged(t *testing.T) {
+	vmaasJSON := `{"package_list": ["kernel-5.6.13-200.fc31.x86_64"], "repository_list": ["repo1"],
+		"modules_list": [{"module_name": "nodejs", "module_stream": "12"}], "releasever": "8"}`
+	yumUpdates := []byte(`{"update_list": {"kernel-0:5.6.13-200.fc31.x86_64": {"available_updates": [
+		{"erratum": "RH-1", "repository": "repo1", "package": "kernel-5.10.13-200.fc31.x86_64"},
+		{"erratum": "RH-2", "repository": "repo1", "package": "kernel-5.11.13-200.fc31.x86_64"}]}}}`)
+	packages := []explainPackageDBLoad{{
+		Name: "kernel", EVRA: "5.6.13-200.fc31.x86_64",
+		UpdateData: []byte(`[{"evra": "5.10.13-200.fc31.x86_64", "advisory": "RH-1"},
+			{"evra": "5.10.14-200.fc31.x86_64", "advisory": "RH-1"}]`),
+	}}
+
+	explain, err := buildSystemAdvisoryExplain(&explainSystemDBLoad{VmaasJSON: &vmaasJSON, YumUpdates: yumUpdates},
+		packages, "RH-1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"repo1"}, explain.Repos)
+	assert.Equal(t, []ExplainModule{{"nodejs", "12"}}, explain.Modules)
+	assert.Equal(t, "8", *explain.Releasever)
+	assert.Equal(t, []string{ExplainSourceYum, ExplainSourceVmaas}, explain.Sources)
+	assert.Equal(t, []ExplainPackage{{
+		Installed: "kernel-5.6.13-200.fc31.x86_64",
+		FixedBy:   []string{"kernel-5.10.13-200.fc31.x86_64", "kernel-5.10.14-200.fc31.x86_64"},
+		Repos:     []string{"repo1"},
+		Sources:   []string{ExplainSourceYum, ExplainSourceVmaas},
+	}}, explain.Packages)
+}
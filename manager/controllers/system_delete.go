@@ -42,11 +42,9 @@ func SystemDeleteHandler(c *gin.Context) {
 
 	defer tx.Rollback()
 
-	err := tx.Set("gorm:query_option", "FOR UPDATE OF system_platform").
-		Table("system_platform").
-		Where("rh_account_id = ?", account).
-		Where("inventory_id = ?::uuid", inventoryID).
-		Pluck("inventory_id", &systemInventoryID).Error
+	err := database.Systems(tx.Set("gorm:query_option", "FOR UPDATE OF sp"), account, middlewares.GetSystemScope(c)).
+		Where("sp.inventory_id = ?::uuid", inventoryID).
+		Pluck("sp.inventory_id", &systemInventoryID).Error
 
 	if err != nil {
 		LogAndRespError(c, err, "could not query database for system")
@@ -22,6 +22,7 @@ type SystemDetailResponse struct {
 // @Accept   json
 // @Produce  json
 // @Param    inventory_id    path    string   true "Inventory ID"
+// @Param    fields[systems] query   string   false "Comma separated list of returned attributes"
 // @Success 200 {object} SystemDetailResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -45,14 +46,21 @@ func SystemDetailHandler(c *gin.Context) {
 		return
 	}
 
+	fieldset, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
 	var systemItemAttributes SystemItemAttributes
-	query := database.Systems(database.Db, account).
-		Select(database.MustGetSelect(&systemItemAttributes)).
-		Joins("JOIN inventory.hosts ih ON ih.id = inventory_id").
-		Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id").
-		Where("sp.inventory_id = ?::uuid", inventoryID)
+	query := database.Systems(database.Db, account, middlewares.GetSystemScope(c)).
+		Select(fieldset.Select(&systemItemAttributes, nil, "display_name")).
+		Joins("JOIN inventory.hosts ih ON ih.id = inventory_id")
+	if fieldset.Has("baseline_name") {
+		query = query.Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id")
+	}
+	query = query.Where("sp.inventory_id = ?::uuid", inventoryID)
 
-	err := query.Take(&systemItemAttributes).Error
+	err = query.Take(&systemItemAttributes).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		LogAndRespNotFound(c, err, "inventory not found")
 		return
@@ -69,5 +77,5 @@ func SystemDetailHandler(c *gin.Context) {
 			ID:         inventoryID,
 			Type:       "system",
 		}}
-	c.JSON(http.StatusOK, &resp)
+	JSONWithFieldset(c, http.StatusOK, &resp, fieldset)
 }
@@ -33,6 +33,21 @@ func TestSystemDetailDefault1(t *testing.T) {
 	assert.Equal(t, "RHEL 8.10", output.Data.Attributes.OS)
 	assert.Equal(t, "baseline_1-1", output.Data.Attributes.BaselineName)
 	assert.Equal(t, true, *output.Data.Attributes.BaselineUpToDate)
+	assert.False(t, output.Data.Attributes.RebootPending)
+	assert.Equal(t, "template_1-1", output.Data.Attributes.TemplateName)
+	assert.Equal(t, 1, output.Data.Attributes.TemplateInstallable)
+	assert.Equal(t, 7, output.Data.Attributes.TemplateApplicable)
+}
+
+func TestSystemDetailRebootPending(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/00000000-0000-0000-0000-000000000002", nil, "",
+		SystemDetailHandler, "/:inventory_id")
+
+	var output SystemDetailResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.True(t, output.Data.Attributes.RebootPending)
+	assert.Equal(t, "4.18.0-305.el8.x86_64", output.Data.Attributes.RunningKernel)
 }
 
 func TestSystemDetailDefault2(t *testing.T) {
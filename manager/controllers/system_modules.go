@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var SystemModulesFields = database.MustGetQueryAttrs(&SystemModuleItem{})
+var SystemModulesSelect = database.MustGetSelect(&SystemModuleItem{})
+var SystemModulesOpts = ListOpts{
+	Fields:         SystemModulesFields,
+	DefaultFilters: nil,
+	DefaultSort:    "name",
+	StableSort:     "m.id",
+	SearchFields:   []string{"m.name"},
+	TotalFunc:      CountRows,
+}
+
+type SystemModuleItem struct {
+	Name   string `json:"name" csv:"name" query:"m.name" gorm:"column:name"`
+	Stream string `json:"stream" csv:"stream" query:"m.stream" gorm:"column:stream"`
+}
+
+type SystemModulesResponse struct {
+	Data  []SystemModuleItem `json:"data"`
+	Links Links              `json:"links"`
+	Meta  ListMeta           `json:"meta"`
+}
+
+func systemModulesQuery(acc int, scope *database.SystemScope, inventoryID string) *gorm.DB {
+	return database.Systems(database.Db, acc, scope).
+		Select(SystemModulesSelect).
+		Joins("JOIN system_module sm ON sm.system_id = sp.id AND sm.rh_account_id = sp.rh_account_id").
+		Joins("JOIN module m ON m.id = sm.module_id").
+		Where("sp.inventory_id = ?::uuid", inventoryID)
+}
+
+// @Summary Show me dnf module streams enabled on a system by given inventory id
+// @Description Show me dnf module streams enabled on a system by given inventory id
+// @ID systemModules
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    inventory_id    path    string   true "Inventory ID"
+// @Param    limit          query   int     false   "Limit for paging, set -1 to return all"
+// @Param    offset         query   int     false   "Offset for paging"
+// @Param    sort           query   string  false   "Sort field" Enums(name,stream)
+// @Param    search          query   string  false   "Find matching text"
+// @Param    filter[name]            query   string  false "Filter"
+// @Param    filter[stream]          query   string  false "Filter"
+// @Success 200 {object} SystemModulesResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /systems/{inventory_id}/modules [get]
+func SystemModulesHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	inventoryID := c.Param("inventory_id")
+	if inventoryID == "" {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{Error: "inventory_id param not found"})
+		return
+	}
+
+	if !utils.IsValidUUID(inventoryID) {
+		LogAndRespBadRequest(c, errors.New("bad request"), "incorrect inventory_id format")
+		return
+	}
+
+	var exists int64
+	err := database.Systems(database.Db, account, middlewares.GetSystemScope(c)).
+		Where("sp.inventory_id = ?::uuid", inventoryID).
+		Count(&exists).Error
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+	if exists == 0 {
+		LogAndRespNotFound(c, errors.New("no rows returned"), "inventory not found")
+		return
+	}
+
+	query := systemModulesQuery(account, middlewares.GetSystemScope(c), inventoryID)
+	query, meta, links, err := ListCommon(query, c, nil, SystemModulesOpts)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	var modules = make([]SystemModuleItem, 0)
+	err = query.Scan(&modules).Error
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return
+	}
+
+	c.JSON(http.StatusOK, SystemModulesResponse{
+		Data:  modules,
+		Links: *links,
+		Meta:  *meta,
+	})
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemModules(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/00000000-0000-0000-0000-000000000002/modules",
+		nil, "", SystemModulesHandler, 1, "GET", "/:inventory_id/modules")
+
+	var output SystemModulesResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, []SystemModuleItem{{Name: "nodejs", Stream: "12"}, {Name: "postgresql", Stream: "12"}},
+		output.Data)
+}
+
+func TestSystemModulesFilter(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/00000000-0000-0000-0000-000000000002/modules?filter[name]=nodejs",
+		nil, "", SystemModulesHandler, 1, "GET", "/:inventory_id/modules")
+
+	var output SystemModulesResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, []SystemModuleItem{{Name: "nodejs", Stream: "12"}}, output.Data)
+}
+
+func TestSystemModulesNotFound(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", "/00000000-0000-0000-0000-000000000012/modules",
+		nil, "", SystemModulesHandler, 1, "GET", "/:inventory_id/modules")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusNotFound, &errResp)
+	assert.Equal(t, "inventory not found", errResp.Error)
+}
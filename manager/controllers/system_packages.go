@@ -21,6 +21,9 @@ type SystemPackagesAttrs struct {
 	Summary     string `json:"summary" csv:"summary" query:"sum.value" gorm:"column:summary"`
 	Description string `json:"description" csv:"description" query:"descr.value" gorm:"column:description"`
 	Updatable   bool   `json:"updatable" csv:"updatable" query:"(COALESCE(json_array_length(spkg.update_data::json),0) > 0)" gorm:"column:updatable"`
+	// Dnf module stream the package build belongs to according to advisories, null for other packages
+	ModuleName   *string `json:"module_name" csv:"module_name" query:"pm.name" gorm:"column:module_name"`
+	ModuleStream *string `json:"module_stream" csv:"module_stream" query:"pm.stream" gorm:"column:module_stream"`
 }
 
 type SystemPackageData struct {
@@ -49,11 +52,19 @@ type SystemPackageDBLoad struct {
 	Updates []byte `json:"updates" query:"spkg.update_data" gorm:"column:updates"`
 }
 
-func systemPackageQuery(account int, inventoryID string) *gorm.DB {
-	query := database.SystemPackages(database.Db, account).
-		Joins("LEFT JOIN strings AS descr ON p.description_hash = descr.id").
-		Joins("LEFT JOIN strings AS sum ON p.summary_hash = sum.id").
-		Select(SystemPackagesSelect).
+// Package description and module are joined only if they are requested, filtered or sorted by
+func systemPackageQuery(c *gin.Context, account int, inventoryID string, fieldset Fieldset,
+	helpers map[string]string, required ...string) *gorm.DB {
+	query := database.SystemPackages(database.Db, account, middlewares.GetSystemScope(c))
+	if isAttrUsed(c, fieldset, "description", "description") {
+		query = query.Joins("LEFT JOIN strings AS descr ON p.description_hash = descr.id")
+	}
+	if isAttrUsed(c, fieldset, "module_name", "module_name") ||
+		isAttrUsed(c, fieldset, "module_stream", "module_stream") {
+		query = query.Joins(packageModuleJoin)
+	}
+	query = query.Joins("LEFT JOIN strings AS sum ON p.summary_hash = sum.id").
+		Select(fieldset.Select(&SystemPackageDBLoad{}, helpers, required...)).
 		Where("sp.inventory_id = ?::uuid", inventoryID)
 
 	return query
@@ -74,6 +85,9 @@ func systemPackageQuery(account int, inventoryID string) *gorm.DB {
 // @Param    filter[evra]            query   string  false "Filter"
 // @Param    filter[summary]         query   string  false "Filter"
 // @Param    filter[updatable]       query   bool    false "Filter"
+// @Param    filter[module_name]     query   string  false "Filter packages by dnf module name"
+// @Param    filter[module_stream]   query   string  false "Filter packages by dnf module stream"
+// @Param    fields[packages] query  string  false   "Comma separated list of returned attributes"
 // @Success 200 {object} SystemPackageResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -93,8 +107,13 @@ func SystemPackagesHandler(c *gin.Context) {
 		return
 	}
 
+	fieldset, err := ParseFieldset(c, "packages", &SystemPackageData{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
 	var loaded []SystemPackageDBLoad
-	q := systemPackageQuery(account, inventoryID)
+	q := systemPackageQuery(c, account, inventoryID, fieldset, nil, "name")
 	q, meta, links, err := ListCommon(q, c, nil, SystemPackagesOpts)
 	if err != nil {
 		return
@@ -126,5 +145,5 @@ func SystemPackagesHandler(c *gin.Context) {
 		}
 	}
 
-	c.JSON(200, response)
+	JSONWithFieldset(c, http.StatusOK, response, fieldset)
 }
@@ -6,9 +6,7 @@ import (
 	"app/manager/middlewares"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -32,6 +30,9 @@ type SystemPackageInline struct {
 // @Param    filter[evra]            query   string  false "Filter"
 // @Param    filter[summary]         query   string  false "Filter"
 // @Param    filter[updatable]       query   bool    false "Filter"
+// @Param    filter[module_name]     query   string  false "Filter packages by dnf module name"
+// @Param    filter[module_stream]   query   string  false "Filter packages by dnf module stream"
+// @Param    fields[packages] query  string  false   "Comma separated list of returned attributes"
 // @Success 200 {array} SystemPackageInline
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -52,9 +53,15 @@ func SystemPackagesExportHandler(c *gin.Context) {
 		return
 	}
 
+	fieldset, err := ParseFieldset(c, "packages", &SystemPackageInline{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
 	var loaded []SystemPackageDBLoad
-	q := systemPackageQuery(account, inventoryID)
-	q, err := ExportListCommon(q, c, SystemPackagesOpts)
+	// latest_evra is computed from package updates, or installed evra if there is no update
+	q := systemPackageQuery(c, account, inventoryID, fieldset, map[string]string{"latest_evra": "updates"}, "name", "evra")
+	q, err = ExportListCommon(q, c, SystemPackagesOpts)
 	if err != nil {
 		// Error handling and setting of result code & content is done in ListCommon
 		return
@@ -72,15 +79,7 @@ func SystemPackagesExportHandler(c *gin.Context) {
 	}
 
 	data := convertToOutputArray(&loaded)
-	accept := c.GetHeader("Accept")
-	if strings.Contains(accept, "application/json") { // nolint: gocritic
-		c.JSON(http.StatusOK, data)
-	} else if strings.Contains(accept, "text/csv") {
-		Csv(c, 200, data)
-	} else {
-		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
-	}
+	ExportWithFieldset(c, data, fieldset)
 }
 
 func convertToOutputArray(inArr *[]SystemPackageDBLoad) *[]SystemPackageInline {
@@ -33,12 +33,12 @@ func TestSystemPackagesExportHandlerCSV(t *testing.T) {
 	lines := strings.Split(body, "\n")
 
 	assert.Equal(t, 6, len(lines))
-	assert.Equal(t, "name,evra,summary,description,updatable,latest_evra", lines[0])
+	assert.Equal(t, "name,evra,summary,description,updatable,module_name,module_stream,latest_evra", lines[0])
 
-	assert.Equal(t, "kernel,5.6.13-200.fc31.x86_64,The Linux kernel,The kernel meta package,false,"+
+	assert.Equal(t, "kernel,5.6.13-200.fc31.x86_64,The Linux kernel,The kernel meta package,false,,,"+
 		"5.6.13-200.fc31.x86_64", lines[1])
 	assert.Equal(t, "firefox,76.0.1-1.fc31.x86_64,Mozilla Firefox Web browser,Mozilla Firefox is an "+
-		"open-source web browser...,true,76.0.1-1.fc31.x86_64", lines[2])
+		"open-source web browser...,true,,,76.0.1-1.fc31.x86_64", lines[2])
 }
 
 func TestSystemPackagesExportUnknown(t *testing.T) {
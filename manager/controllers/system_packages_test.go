@@ -82,3 +82,17 @@ func TestSystemPackagesUnknown(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func TestSystemPackagesFilterModule(t *testing.T) {
+	core.SetupTest(t)
+	defer createModularPackage(t)()
+	w := CreateRequestRouterWithParams("GET", "/00000000-0000-0000-0000-000000000012/packages?filter[module_name]=firefox",
+		nil, "", SystemPackagesHandler, 3, "GET", "/:inventory_id/packages")
+
+	var output SystemPackageResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Len(t, output.Data, 1)
+	assert.Equal(t, "77.0.1-1.module+el8.4.0+1+abcdef.x86_64", output.Data[0].EVRA)
+	assert.Equal(t, "firefox", *output.Data[0].ModuleName)
+	assert.Equal(t, "stable", *output.Data[0].ModuleStream)
+}
@@ -13,9 +13,10 @@ import (
 	"gorm.io/gorm"
 )
 
-var SystemsFields = database.MustGetQueryAttrs(&SystemDBLookup{})
-var SystemsSelect = database.MustGetSelect(&SystemDBLookup{})
+var SystemsFields = systemsFields()
+var SystemsSelect = database.MustGetSelectColumns(&SystemDBLookup{}, systemsDefaultColumns())
 var SystemsSumFields = database.MustGetSelect(&SystemSums{})
+var SystemsFieldsetHelpers = map[string]string{"tags": "tags_str", "repos": "repos_json"}
 var SystemOpts = ListOpts{
 	Fields: SystemsFields,
 	// By default, we show only fresh systems. If all systems are required, you must pass in:true,false filter into the api
@@ -40,10 +41,45 @@ type SystemDBLookup struct {
 
 	// Just helper field to get tags from db in plain string, then parsed to "Tags" attr., excluded from output data.
 	TagsStr string `json:"-" csv:"-" query:"ih.tags" gorm:"column:tags_str"`
+	// Just helper field to get repos from db as json list, then parsed to "Repos" attr., excluded from output data.
+	ReposJSON []byte `json:"-" csv:"-" query:"array_to_json(ARRAY(SELECT r.name FROM system_repo sr JOIN repo r ON r.id = sr.repo_id WHERE sr.rh_account_id = sp.rh_account_id AND sr.system_id = sp.id ORDER BY r.name))" gorm:"column:repos_json"` // nolint: lll
 
 	SystemItemAttributes
 }
 
+// Names of repositories enabled on the system, used for `repos` filter and sort
+const systemReposQuery = "ARRAY(SELECT r.name FROM system_repo sr JOIN repo r ON r.id = sr.repo_id " +
+	"WHERE sr.rh_account_id = sp.rh_account_id AND sr.system_id = sp.id ORDER BY r.name)"
+
+func systemsFields() database.AttrMap {
+	fields := database.MustGetQueryAttrs(&SystemDBLookup{})
+	repos := fields["display_name"] // reuse string parser
+	repos.DataQuery = systemReposQuery
+	repos.OrderQuery = systemReposQuery
+	fields["repos"] = repos
+	return fields
+}
+
+// Repos are loaded by a correlated subquery for each system, so they are selected only when requested in fieldset
+func systemsDefaultColumns() map[string]bool {
+	_, names, err := database.GetQueryAttrs(&SystemDBLookup{})
+	if err != nil {
+		panic(err)
+	}
+	columns := make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[name] = name != "repos_json"
+	}
+	return columns
+}
+
+func systemsSelect(fieldset Fieldset) string {
+	if fieldset == nil {
+		return SystemsSelect
+	}
+	return fieldset.Select(&SystemDBLookup{}, SystemsFieldsetHelpers, "id")
+}
+
 type SystemInlineItem SystemDBLookup
 
 // nolint: lll
@@ -68,15 +104,31 @@ type SystemItemAttributes struct {
 	OS      string `json:"os" csv:"os" query:"ih.system_profile->'operating_system'->>'name' || ' ' || coalesce(ih.system_profile->'operating_system'->>'major' || '.' || (ih.system_profile->'operating_system'->>'minor'), '')" order_query:"ih.system_profile->'operating_system'->>'name',cast(substring(ih.system_profile->'operating_system'->>'major','^\\d+') as int),cast(substring(ih.system_profile->'operating_system'->>'minor','^\\d+') as int)" gorm:"column:os"`
 	Rhsm    string `json:"rhsm" csv:"rhsm" query:"ih.system_profile->'rhsm'->>'version'" gorm:"column:rhsm"`
 
+	RunningKernel      string     `json:"running_kernel" csv:"running_kernel" query:"sp.running_kernel" gorm:"column:running_kernel"`
+	InstalledKernel    string     `json:"installed_kernel" csv:"installed_kernel" query:"sp.installed_kernel" gorm:"column:installed_kernel"`
+	LastBoot           *time.Time `json:"last_boot" csv:"last_boot" query:"sp.last_boot" gorm:"column:last_boot"`
+	Releasever         string     `json:"releasever" csv:"releasever" query:"sp.releasever" gorm:"column:releasever"`
+	InfrastructureType string     `json:"infrastructure_type" csv:"infrastructure_type" query:"sp.infrastructure_type" gorm:"column:infrastructure_type"`
+	CloudProvider      string     `json:"cloud_provider" csv:"cloud_provider" query:"sp.cloud_provider" gorm:"column:cloud_provider"`
+	SapSystem          bool       `json:"sap_system" csv:"sap_system" query:"sp.sap_system" gorm:"column:sap_system"`
+	Ansible            bool       `json:"ansible" csv:"ansible" query:"sp.ansible" gorm:"column:ansible"`
+	RebootPending      bool       `json:"reboot_pending" csv:"reboot_pending" query:"sp.reboot_pending" gorm:"column:reboot_pending"` // Kernels are compared only if running one is in `uname -r` format
+
 	StaleTimestamp        *time.Time `json:"stale_timestamp" csv:"stale_timestamp" query:"ih.stale_timestamp" gorm:"column:stale_timestamp"`
 	StaleWarningTimestamp *time.Time `json:"stale_warning_timestamp" csv:"stale_warning_timestamp" query:"ih.stale_warning_timestamp" gorm:"column:stale_warning_timestamp"`
 	CulledTimestamp       *time.Time `json:"culled_timestamp" csv:"culled_timestamp" query:"ih.culled_timestamp" gorm:"column:culled_timestamp"`
 	Created               *time.Time `json:"created" csv:"created" query:"ih.created" gorm:"column:created"`
 
 	Tags SystemTagsList `json:"tags" csv:"tags" gorm:"-"`
+	// Enabled repositories, loaded only when requested in `fields[systems]`
+	Repos SystemReposList `json:"repos" csv:"repos" query:"null" gorm:"-"`
 
 	BaselineName     string `json:"baseline_name" csv:"baseline_name" query:"bl.name" gorm:"column:baseline_name"`
 	BaselineUpToDate *bool  `json:"baseline_uptodate" csv:"baseline_uptodate" query:"sp.baseline_uptodate" gorm:"column:baseline_uptodate"`
+
+	TemplateName        string `json:"template_name" csv:"template_name" query:"(SELECT t.name FROM template t WHERE t.rh_account_id = sp.rh_account_id AND t.id = sp.template_id)" gorm:"column:template_name"`
+	TemplateInstallable int    `json:"template_installable" csv:"template_installable" query:"sp.template_installable" gorm:"column:template_installable"`
+	TemplateApplicable  int    `json:"template_applicable" csv:"template_applicable" query:"sp.template_applicable" gorm:"column:template_applicable"`
 }
 
 type SystemTagsList []SystemTag
@@ -90,6 +142,12 @@ func (v SystemTagsList) String() string {
 	return replacedQuotes
 }
 
+type SystemReposList []string
+
+func (v SystemReposList) String() string {
+	return strings.Join(v, ",")
+}
+
 // nolint: lll
 type SystemSums struct {
 	Total     int64 `query:"count(*)" gorm:"column:total"`
@@ -130,10 +188,10 @@ func systemSubtotals(tx *gorm.DB) (total int, subTotals map[string]int, err erro
 	return total, subTotals, err
 }
 
-func systemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
+func systemsCommon(c *gin.Context, fieldset Fieldset) (*gorm.DB, *ListMeta, *Links, error) {
 	var err error
 	account := c.GetInt(middlewares.KeyAccount)
-	query := querySystems(account)
+	query := querySystems(c, account, fieldset)
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return nil, nil, nil, err
@@ -153,7 +211,7 @@ func systemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 // @Produce  json
 // @Param    limit      query   int     false   "Limit for paging, set -1 to return all"
 // @Param    offset     query   int     false   "Offset for paging"
-// @Param    sort       query   string  false   "Sort field" Enums(id,display_name,last_evaluation,last_upload,rhsa_count,rhba_count,rhea_count,other_count,stale, packages_installed, packages_updatable)
+// @Param    sort       query   string  false   "Sort field" Enums(id,display_name,last_evaluation,last_upload,rhsa_count,rhba_count,rhea_count,other_count,stale, packages_installed, packages_updatable, running_kernel, installed_kernel, last_boot, releasever, infrastructure_type, cloud_provider, sap_system, ansible, reboot_pending, template_installable, template_applicable)
 // @Param    search     query   string  false   "Find matching text"
 // @Param    filter[insights_id]            query   string  false   "Filter"
 // @Param    filter[id]                     query   string  false   "Filter"
@@ -175,7 +233,18 @@ func systemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 // @Param    filter[osminor]                query   string  false   "Filter"
 // @Param    filter[osmajor]                query   string  false   "Filter"
 // @Param    filter[baseline_name]          query   string  false   "Filter"
+// @Param    filter[template_name]          query   string  false   "Filter"
+// @Param    filter[repos]                  query   string  false   "Filter systems by enabled repository"
 // @Param    filter[os]                     query   string  false   "Filter OS version"
+// @Param    filter[running_kernel]         query   string  false   "Filter"
+// @Param    filter[installed_kernel]       query   string  false   "Filter"
+// @Param    filter[last_boot]              query   string  false   "Filter"
+// @Param    filter[releasever]             query   string  false   "Filter"
+// @Param    filter[infrastructure_type]    query   string  false   "Filter"
+// @Param    filter[cloud_provider]         query   string  false   "Filter"
+// @Param    filter[sap_system]             query   string  false   "Filter"
+// @Param    filter[ansible]                query   string  false   "Filter"
+// @Param    filter[reboot_pending]         query   string  false   "Filter"
 // @Param    tags                           query   []string false  "Tag filter"
 // @Param    filter[system_profile][sap_system]                     query   string  false   "Filter only SAP systems"
 // @Param    filter[system_profile][sap_sids][in]                   query   []string false  "Filter systems by their SAP SIDs"
@@ -183,12 +252,18 @@ func systemsCommon(c *gin.Context) (*gorm.DB, *ListMeta, *Links, error) {
 // @Param    filter[system_profile][ansible][controller_version]    query   string  false   "Filter systems by ansible version"
 // @Param    filter[system_profile][mssql]                          query   string  false   "Filter systems by mssql version"
 // @Param    filter[system_profile][mssql][version]                 query   string  false   "Filter systems by mssql version"
+// @Param    fields[systems]                query   string  false   "Comma separated list of returned attributes"
 // @Success 200 {object} SystemsResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /systems [get]
 func SystemsListHandler(c *gin.Context) {
-	query, meta, links, err := systemsCommon(c)
+	fieldset, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query, meta, links, err := systemsCommon(c, fieldset)
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -206,7 +281,7 @@ func SystemsListHandler(c *gin.Context) {
 		Links: *links,
 		Meta:  *meta,
 	}
-	c.JSON(http.StatusOK, &resp)
+	JSONWithFieldset(c, http.StatusOK, &resp, fieldset)
 }
 
 // nolint: lll
@@ -218,7 +293,7 @@ func SystemsListHandler(c *gin.Context) {
 // @Produce  json
 // @Param    limit      query   int     false   "Limit for paging, set -1 to return all"
 // @Param    offset     query   int     false   "Offset for paging"
-// @Param    sort       query   string  false   "Sort field" Enums(id,display_name,last_evaluation,last_upload,rhsa_count,rhba_count,rhea_count,other_count,stale, packages_installed, packages_updatable)
+// @Param    sort       query   string  false   "Sort field" Enums(id,display_name,last_evaluation,last_upload,rhsa_count,rhba_count,rhea_count,other_count,stale, packages_installed, packages_updatable, running_kernel, installed_kernel, last_boot, releasever, infrastructure_type, cloud_provider, sap_system, ansible, reboot_pending, template_installable, template_applicable)
 // @Param    search     query   string  false   "Find matching text"
 // @Param    filter[insights_id]            query   string  false   "Filter"
 // @Param    filter[id]                     query   string  false   "Filter"
@@ -240,7 +315,18 @@ func SystemsListHandler(c *gin.Context) {
 // @Param    filter[osminor]                query   string  false   "Filter"
 // @Param    filter[osmajor]                query   string  false   "Filter"
 // @Param    filter[baseline_name]          query   string  false   "Filter"
+// @Param    filter[template_name]          query   string  false   "Filter"
+// @Param    filter[repos]                  query   string  false   "Filter systems by enabled repository"
 // @Param    filter[os]                     query   string  false   "Filter OS version"
+// @Param    filter[running_kernel]         query   string  false   "Filter"
+// @Param    filter[installed_kernel]       query   string  false   "Filter"
+// @Param    filter[last_boot]              query   string  false   "Filter"
+// @Param    filter[releasever]             query   string  false   "Filter"
+// @Param    filter[infrastructure_type]    query   string  false   "Filter"
+// @Param    filter[cloud_provider]         query   string  false   "Filter"
+// @Param    filter[sap_system]             query   string  false   "Filter"
+// @Param    filter[ansible]                query   string  false   "Filter"
+// @Param    filter[reboot_pending]         query   string  false   "Filter"
 // @Param    tags                           query   []string false  "Tag filter"
 // @Param    filter[system_profile][sap_system]                     query   string  false   "Filter only SAP systems"
 // @Param    filter[system_profile][sap_sids][in]                   query   []string false  "Filter systems by their SAP SIDs"
@@ -253,7 +339,8 @@ func SystemsListHandler(c *gin.Context) {
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /ids/systems [get]
 func SystemsListIDsHandler(c *gin.Context) {
-	query, _, _, err := systemsCommon(c)
+	// empty fieldset - only system IDs are loaded
+	query, _, _, err := systemsCommon(c, Fieldset{})
 	if err != nil {
 		return
 	} // Error handled in method itself
@@ -270,11 +357,15 @@ func SystemsListIDsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, &resp)
 }
 
-func querySystems(account int) *gorm.DB {
-	return database.Systems(database.Db, account).
-		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
-		Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id").
-		Select(SystemsSelect)
+// Inventory hosts are joined always as they limit systems to the ones known to inventory,
+// baseline is joined only if its name is requested, filtered or sorted by.
+func querySystems(c *gin.Context, account int, fieldset Fieldset) *gorm.DB {
+	query := database.Systems(database.Db, account, middlewares.GetSystemScope(c)).
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id")
+	if isAttrUsed(c, fieldset, "baseline_name", "baseline_name") {
+		query = query.Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id")
+	}
+	return query.Select(systemsSelect(fieldset))
 }
 
 func parseSystemTags(jsonStr string) ([]SystemTag, error) {
@@ -33,8 +33,9 @@ type systemsAdvisoriesDBLoad struct {
 
 var systemsAdvisoriesSelect = database.MustGetSelect(&systemsAdvisoriesDBLoad{})
 
-func systemsAdvisoriesQuery(acc int, systems []SystemID, advisories []AdvisoryName) *gorm.DB {
-	query := database.SystemAdvisories(database.Db, acc).
+func systemsAdvisoriesQuery(acc int, scope *database.SystemScope, systems []SystemID, advisories []AdvisoryName,
+) *gorm.DB {
+	query := database.SystemAdvisories(database.Db, acc, scope).
 		Select(systemsAdvisoriesSelect).
 		Joins("join advisory_metadata am on am.id = sa.advisory_id").
 		Order("sp.inventory_id, am.id")
@@ -54,7 +55,7 @@ func systemsAdvisories(c *gin.Context) ([]systemsAdvisoriesDBLoad, error) {
 		return nil, err
 	}
 	acc := c.GetInt(middlewares.KeyAccount)
-	q := systemsAdvisoriesQuery(acc, req.Systems, req.Advisories)
+	q := systemsAdvisoriesQuery(acc, middlewares.GetSystemScope(c), req.Systems, req.Advisories)
 	q, err := Paginate(q, req.Limit, req.Offset)
 	if err != nil {
 		LogAndRespBadRequest(c, err, err.Error())
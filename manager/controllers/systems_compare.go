@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const SystemsCompareMaxIDs = 10
+const InvalidCompareIDsMsg = "Invalid ids parameter, use between 2 and %d comma separated inventory ids"
+
+type SystemsCompareSystem struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+type SystemsCompareItem struct {
+	Name      string   `json:"name"`
+	Values    []string `json:"values"` // Value for each compared system in order of `systems`, empty when missing
+	Different bool     `json:"different"`
+}
+
+type SystemsCompareData struct {
+	Systems    []SystemsCompareSystem `json:"systems"`
+	Baseline   SystemsCompareItem     `json:"baseline"`
+	Packages   []SystemsCompareItem   `json:"packages"`
+	Advisories []SystemsCompareItem   `json:"advisories"`
+	Repos      []SystemsCompareItem   `json:"repos"`
+	Modules    []SystemsCompareItem   `json:"modules"`
+}
+
+type SystemsCompareResponse struct {
+	Data SystemsCompareData `json:"data"`
+}
+
+type SystemsCompareInlineItem struct {
+	Section string `json:"section"` // One of baseline, packages, advisories, repos, modules
+	SystemsCompareItem
+}
+
+type systemsCompareDBLoad struct {
+	ID           int64   `query:"sp.id" gorm:"column:id"`
+	InventoryID  string  `query:"sp.inventory_id" gorm:"column:inventory_id"`
+	DisplayName  string  `query:"sp.display_name" gorm:"column:display_name"`
+	BaselineName *string `query:"bl.name" gorm:"column:baseline_name"`
+}
+
+type systemsCompareValue struct {
+	SystemID int64  `gorm:"column:system_id"`
+	Name     string `gorm:"column:name"`
+	Value    string `gorm:"column:value"`
+}
+
+var systemsCompareSelect = database.MustGetSelect(&systemsCompareDBLoad{})
+
+// @Summary Compare packages, advisories, repos, modules and baseline of given systems
+// @Description Compare packages, advisories, repos, modules and baseline of given systems
+// @ID compareSystems
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    ids             query   string  true    "Comma separated list of compared inventory IDs"
+// @Param    only_different  query   bool    false   "Return only items which differ between systems"
+// @Success 200 {object} SystemsCompareResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /systems/compare [get]
+func SystemsCompareHandler(c *gin.Context) {
+	data, err := systemsCompareCommon(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	c.JSON(http.StatusOK, SystemsCompareResponse{Data: *data})
+}
+
+// @Summary Export comparison of packages, advisories, repos, modules and baseline of given systems
+// @Description Export comparison of packages, advisories, repos, modules and baseline of given systems
+// @ID exportCompareSystems
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json,text/csv
+// @Param    ids             query   string  true    "Comma separated list of compared inventory IDs"
+// @Param    only_different  query   bool    false   "Return only items which differ between systems"
+// @Success 200 {array} SystemsCompareInlineItem
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 415 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /export/systems/compare [get]
+func SystemsCompareExportHandler(c *gin.Context) {
+	data, err := systemsCompareCommon(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	items := systemsCompareInlineItems(data)
+	accept := c.GetHeader("Accept")
+	if strings.Contains(accept, "application/json") { // nolint: gocritic
+		c.JSON(http.StatusOK, items)
+	} else if strings.Contains(accept, "text/csv") {
+		systemsCompareCsv(c, data.Systems, items)
+	} else {
+		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
+	}
+}
+
+func parseSystemsCompareIDs(c *gin.Context) ([]string, error) {
+	ids := strings.Split(c.Query("ids"), ",")
+	if len(ids) < 2 || len(ids) > SystemsCompareMaxIDs {
+		msg := fmt.Sprintf(InvalidCompareIDsMsg, SystemsCompareMaxIDs)
+		err := errors.New(msg)
+		LogAndRespBadRequest(c, err, msg)
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if !utils.IsValidUUID(id) {
+			err := errors.Errorf("incorrect inventory_id format: %s", id)
+			LogAndRespBadRequest(c, err, err.Error())
+			return nil, err
+		}
+		if seen[id] {
+			err := errors.Errorf("duplicate inventory_id: %s", id)
+			LogAndRespBadRequest(c, err, err.Error())
+			return nil, err
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func systemsCompareCommon(c *gin.Context) (*SystemsCompareData, error) {
+	account := c.GetInt(middlewares.KeyAccount)
+	onlyDifferent := c.Query("only_different") == "true"
+
+	ids, err := parseSystemsCompareIDs(c)
+	if err != nil {
+		return nil, err
+	} // Error handled in method itself
+
+	systems, err := loadSystemsCompare(account, middlewares.GetSystemScope(c), ids)
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return nil, err
+	}
+	if len(systems) != len(ids) {
+		err = errors.New("inventory not found")
+		LogAndRespNotFound(c, err, "inventory not found")
+		return nil, err
+	}
+
+	data, err := buildSystemsCompareData(account, systems, onlyDifferent)
+	if err != nil {
+		LogAndRespError(c, err, "database error")
+		return nil, err
+	}
+	return data, nil
+}
+
+// Load compared systems in the same order as requested ids
+func loadSystemsCompare(account int, scope *database.SystemScope, ids []string) ([]systemsCompareDBLoad, error) {
+	var loaded []systemsCompareDBLoad
+	err := database.Systems(database.Db, account, scope).
+		Select(systemsCompareSelect).
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
+		Joins("LEFT JOIN baseline bl ON sp.baseline_id = bl.id AND sp.rh_account_id = bl.rh_account_id").
+		Where("sp.inventory_id::text IN (?)", ids).
+		Find(&loaded).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byInventoryID := make(map[string]systemsCompareDBLoad, len(loaded))
+	for _, s := range loaded {
+		byInventoryID[s.InventoryID] = s
+	}
+	systems := make([]systemsCompareDBLoad, 0, len(ids))
+	for _, id := range ids {
+		if s, ok := byInventoryID[id]; ok {
+			systems = append(systems, s)
+		}
+	}
+	return systems, nil
+}
+
+func buildSystemsCompareData(account int, systems []systemsCompareDBLoad, onlyDifferent bool) (
+	*SystemsCompareData, error) {
+	systemIDs := make([]int64, len(systems))
+	data := SystemsCompareData{
+		Systems:  make([]SystemsCompareSystem, len(systems)),
+		Baseline: SystemsCompareItem{Name: "baseline", Values: make([]string, len(systems))},
+	}
+	for i, s := range systems {
+		systemIDs[i] = s.ID
+		data.Systems[i] = SystemsCompareSystem{ID: s.InventoryID, DisplayName: s.DisplayName}
+		if s.BaselineName != nil {
+			data.Baseline.Values[i] = *s.BaselineName
+		}
+	}
+	data.Baseline.Different = hasDifferentValues(data.Baseline.Values)
+
+	var packages, advisories, repos, modules []systemsCompareValue
+	err := database.SystemPackages(database.Db, account, nil).
+		Select("sp.id AS system_id, pn.name AS name, p.evra AS value").
+		Where("sp.id IN (?)", systemIDs).
+		Scan(&packages).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load system packages")
+	}
+	data.Packages = buildSystemsCompareItems(systemIDs, packages, onlyDifferent)
+
+	err = database.SystemAdvisories(database.Db, account, nil).
+		Select("sp.id AS system_id, am.name AS name, 'applicable' AS value").
+		Joins("JOIN advisory_metadata am ON am.id = sa.advisory_id").
+		Where("sp.id IN (?)", systemIDs).
+		Scan(&advisories).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load system advisories")
+	}
+	data.Advisories = buildSystemsCompareItems(systemIDs, advisories, onlyDifferent)
+
+	err = database.Db.Table("system_repo sr").
+		Select("sr.system_id AS system_id, r.name AS name, 'enabled' AS value").
+		Joins("JOIN repo r ON r.id = sr.repo_id").
+		Where("sr.rh_account_id = ? AND sr.system_id IN (?)", account, systemIDs).
+		Scan(&repos).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load system repos")
+	}
+	data.Repos = buildSystemsCompareItems(systemIDs, repos, onlyDifferent)
+
+	err = database.Db.Table("system_module sm").
+		Select("sm.system_id AS system_id, m.name AS name, m.stream AS value").
+		Joins("JOIN module m ON m.id = sm.module_id").
+		Where("sm.rh_account_id = ? AND sm.system_id IN (?)", account, systemIDs).
+		Scan(&modules).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load system modules")
+	}
+	data.Modules = buildSystemsCompareItems(systemIDs, modules, onlyDifferent)
+	return &data, nil
+}
+
+// Pivot loaded values to items with value for each system, multiple values of the same name are joined
+func buildSystemsCompareItems(systemIDs []int64, values []systemsCompareValue, onlyDifferent bool) []SystemsCompareItem {
+	systemIndex := make(map[int64]int, len(systemIDs))
+	for i, id := range systemIDs {
+		systemIndex[id] = i
+	}
+
+	grouped := map[string][][]string{}
+	for _, v := range values {
+		if _, ok := grouped[v.Name]; !ok {
+			grouped[v.Name] = make([][]string, len(systemIDs))
+		}
+		i := systemIndex[v.SystemID]
+		grouped[v.Name][i] = append(grouped[v.Name][i], v.Value)
+	}
+
+	items := make([]SystemsCompareItem, 0, len(grouped))
+	for name, systemValues := range grouped {
+		item := SystemsCompareItem{Name: name, Values: make([]string, len(systemIDs))}
+		for i, vals := range systemValues {
+			sort.Strings(vals)
+			item.Values[i] = strings.Join(vals, ",")
+		}
+		item.Different = hasDifferentValues(item.Values)
+		if onlyDifferent && !item.Different {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+func hasDifferentValues(values []string) bool {
+	for _, v := range values {
+		if v != values[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func systemsCompareInlineItems(data *SystemsCompareData) []SystemsCompareInlineItem {
+	items := []SystemsCompareInlineItem{{Section: "baseline", SystemsCompareItem: data.Baseline}}
+	sections := []struct {
+		name  string
+		items []SystemsCompareItem
+	}{
+		{"packages", data.Packages},
+		{"advisories", data.Advisories},
+		{"repos", data.Repos},
+		{"modules", data.Modules},
+	}
+	for _, section := range sections {
+		for _, item := range section.items {
+			items = append(items, SystemsCompareInlineItem{Section: section.name, SystemsCompareItem: item})
+		}
+	}
+	return items
+}
+
+// Columns of compared systems are dynamic, so csv is written directly instead of gocsv struct marshalling
+func systemsCompareCsv(c *gin.Context, systems []SystemsCompareSystem, items []SystemsCompareInlineItem) {
+	header := []string{"section", "name", "different"}
+	for _, s := range systems {
+		header = append(header, s.ID)
+	}
+	rows := [][]string{header}
+	for _, item := range items {
+		row := append([]string{item.Section, item.Name, fmt.Sprint(item.Different)}, item.Values...)
+		rows = append(rows, row)
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/csv")
+	if err := csv.NewWriter(c.Writer).WriteAll(rows); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const compareSys2 = "00000000-0000-0000-0000-000000000002"
+const compareSys3 = "00000000-0000-0000-0000-000000000003"
+
+func doTestSystemsCompare(t *testing.T, q string, account int) SystemsCompareResponse {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("GET", q, nil, "", SystemsCompareHandler, account, "GET", "/")
+
+	var output SystemsCompareResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+func TestSystemsCompare(t *testing.T) {
+	output := doTestSystemsCompare(t, fmt.Sprintf("/?ids=%s,%s", compareSys2, compareSys3), 1)
+	assert.Equal(t, 2, len(output.Data.Systems))
+	assert.Equal(t, compareSys2, output.Data.Systems[0].ID)
+	assert.Equal(t, compareSys3, output.Data.Systems[1].ID)
+
+	assert.Equal(t, []string{"baseline_1-1", "baseline_1-2"}, output.Data.Baseline.Values)
+	assert.True(t, output.Data.Baseline.Different)
+
+	assert.Equal(t, 2, len(output.Data.Repos))
+	assert.Equal(t, SystemsCompareItem{"repo1", []string{"enabled", "enabled"}, false}, output.Data.Repos[0])
+	assert.Equal(t, SystemsCompareItem{"repo2", []string{"enabled", ""}, true}, output.Data.Repos[1])
+
+	assert.Equal(t, 1, len(output.Data.Advisories))
+	assert.Equal(t, "RH-1", output.Data.Advisories[0].Name)
+	assert.False(t, output.Data.Advisories[0].Different)
+
+	assert.Equal(t, []SystemsCompareItem{
+		{"nodejs", []string{"12", "12"}, false},
+		{"postgresql", []string{"12", ""}, true},
+	}, output.Data.Modules)
+}
+
+func TestSystemsCompareOnlyDifferent(t *testing.T) {
+	output := doTestSystemsCompare(t, fmt.Sprintf("/?ids=%s,%s&only_different=true", compareSys2, compareSys3), 1)
+	assert.Equal(t, 1, len(output.Data.Repos))
+	assert.Equal(t, "repo2", output.Data.Repos[0].Name)
+	assert.Equal(t, 0, len(output.Data.Advisories))
+}
+
+func TestSystemsComparePackages(t *testing.T) {
+	output := doTestSystemsCompare(t,
+		"/?ids=00000000-0000-0000-0000-000000000012,00000000-0000-0000-0000-000000000013&only_different=true", 3)
+	assert.Equal(t, 2, len(output.Data.Packages))
+	for _, pkg := range output.Data.Packages {
+		assert.Equal(t, "", pkg.Values[0])
+		assert.NotEqual(t, "", pkg.Values[1])
+	}
+}
+
+func TestSystemsCompareNotFound(t *testing.T) {
+	core.SetupTest(t)
+	q := fmt.Sprintf("/?ids=%s,00000000-0000-0000-0000-000000000009", compareSys2)
+	w := CreateRequestRouterWithParams("GET", q, nil, "", SystemsCompareHandler, 1, "GET", "/")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSystemsCompareInvalidIDs(t *testing.T) {
+	core.SetupTest(t)
+	for _, q := range []string{"/?ids=" + compareSys2, "/?ids=" + compareSys2 + ",invalid",
+		fmt.Sprintf("/?ids=%s,%s", compareSys2, compareSys2)} {
+		w := CreateRequestRouterWithParams("GET", q, nil, "", SystemsCompareHandler, 1, "GET", "/")
+		var errResp utils.ErrorResponse
+		CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	}
+}
+
+func TestSystemsCompareExportCSV(t *testing.T) {
+	core.SetupTest(t)
+	q := fmt.Sprintf("/?ids=%s,%s", compareSys2, compareSys3)
+	w := CreateRequestRouterWithParams("GET", q, nil, "text/csv", SystemsCompareExportHandler, 1, "GET", "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	lines := strings.Split(w.Body.String(), "\n")
+	assert.Equal(t, fmt.Sprintf("section,name,different,%s,%s", compareSys2, compareSys3), lines[0])
+	assert.Equal(t, "baseline,baseline,true,baseline_1-1,baseline_1-2", lines[1])
+	assert.Contains(t, lines, "repos,repo2,true,enabled,")
+}
+
+func TestBuildSystemsCompareItems(t *testing.T) {
+	values := []systemsCompareValue{
+		{1, "kernel", "5.10"}, {2, "kernel", "5.10"},
+		{1, "firefox", "76"}, {1, "firefox", "77"}, {2, "firefox", "77"},
+		{2, "curl", "7.0"},
+	}
+	items := buildSystemsCompareItems([]int64{1, 2}, values, false)
+	assert.Equal(t, []SystemsCompareItem{
+		{"curl", []string{"", "7.0"}, true},
+		{"firefox", []string{"76,77", "77"}, true},
+		{"kernel", []string{"5.10", "5.10"}, false},
+	}, items)
+
+	items = buildSystemsCompareItems([]int64{1, 2}, values, true)
+	assert.Equal(t, 2, len(items))
+}
@@ -2,9 +2,6 @@ package controllers
 
 import (
 	"app/manager/middlewares"
-	"fmt"
-	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,15 +34,32 @@ import (
 // @Param    filter[osminor]         query   string false "Filter"
 // @Param    filter[osmajor]         query   string false "Filter"
 // @Param    filter[baseline_name]   query   string false "Filter"
+// @Param    filter[template_name]   query   string false "Filter"
+// @Param    filter[repos]           query   string false "Filter systems by enabled repository"
 // @Param    filter[os]              query   string    false "Filter OS version"
+// @Param    filter[running_kernel]  query   string    false "Filter"
+// @Param    filter[installed_kernel] query   string    false "Filter"
+// @Param    filter[last_boot]       query   string    false "Filter"
+// @Param    filter[releasever]      query   string    false "Filter"
+// @Param    filter[infrastructure_type] query   string    false "Filter"
+// @Param    filter[cloud_provider]  query   string    false "Filter"
+// @Param    filter[sap_system]      query   string    false "Filter"
+// @Param    filter[ansible]         query   string    false "Filter"
+// @Param    filter[reboot_pending]  query   string    false "Filter"
 // @Param    tags                    query   []string  false "Tag filter"
+// @Param    fields[systems]         query   string    false "Comma separated list of returned attributes"
 // @Success 200 {array} SystemInlineItem
 // @Failure 415 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /export/systems [get]
 func SystemsExportHandler(c *gin.Context) {
 	account := c.GetInt(middlewares.KeyAccount)
-	query := querySystems(account)
+	fieldset, err := ParseFieldset(c, "systems", &SystemItemAttributes{})
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := querySystems(c, account, fieldset)
 	filters, err := ParseTagsFilters(c)
 	if err != nil {
 		return
@@ -67,13 +81,5 @@ func SystemsExportHandler(c *gin.Context) {
 	}
 
 	parseAndFillTags(&systems)
-	accept := c.GetHeader("Accept")
-	if strings.Contains(accept, "application/json") { // nolint: gocritic
-		c.JSON(http.StatusOK, systems)
-	} else if strings.Contains(accept, "text/csv") {
-		Csv(c, 200, systems)
-	} else {
-		LogWarnAndResp(c, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("Invalid content type '%s', use 'application/json' or 'text/csv'", accept))
-	}
+	ExportWithFieldset(c, systems, fieldset)
 }
@@ -52,14 +52,17 @@ func TestSystemsExportCSV(t *testing.T) {
 	assert.Equal(t,
 		"id,display_name,last_evaluation,last_upload,rhsa_count,rhba_count,rhea_count,other_count,stale,"+
 			"third_party,insights_id,packages_installed,packages_updatable,os_name,os_major,os_minor,os,"+
-			"rhsm,stale_timestamp,stale_warning_timestamp,culled_timestamp,created,tags,baseline_name,baseline_uptodate",
+			"rhsm,running_kernel,installed_kernel,last_boot,releasever,infrastructure_type,cloud_provider,sap_system,"+
+			"ansible,reboot_pending,stale_timestamp,stale_warning_timestamp,culled_timestamp,created,tags,repos,"+
+			"baseline_name,baseline_uptodate,template_name,template_installable,template_applicable",
 		lines[0])
 
 	assert.Equal(t, "00000000-0000-0000-0000-000000000001,00000000-0000-0000-0000-000000000001,"+
 		"2018-09-22T16:00:00Z,2020-09-22T16:00:00Z,2,3,3,0,false,true,00000000-0000-0000-0001-000000000001,0,0,RHEL,8,10,"+
-		"RHEL 8.10,8.10,2018-08-26T16:00:00Z,2018-09-02T16:00:00Z,2018-09-09T16:00:00Z,2018-08-26T16:00:00Z,"+
+		"RHEL 8.10,8.10,,,,,,,false,false,false,2018-08-26T16:00:00Z,2018-09-02T16:00:00Z,2018-09-09T16:00:00Z,"+
+		"2018-08-26T16:00:00Z,"+
 		"\"[{'key':'k1','namespace':'ns1','value':'val1'},{'key':'k2','namespace':'ns1','value':'val2'}]\","+
-		"baseline_1-1,true", lines[1])
+		",baseline_1-1,true,template_1-1,1,7", lines[1])
 }
 
 func TestSystemsExportWrongFormat(t *testing.T) {
@@ -82,7 +85,9 @@ func TestSystemsExportCSVFilter(t *testing.T) {
 	assert.Equal(t,
 		"id,display_name,last_evaluation,last_upload,rhsa_count,rhba_count,rhea_count,other_count,stale,"+
 			"third_party,insights_id,packages_installed,packages_updatable,os_name,os_major,os_minor,os,rhsm,"+
-			"stale_timestamp,stale_warning_timestamp,culled_timestamp,created,tags,baseline_name,baseline_uptodate",
+			"running_kernel,installed_kernel,last_boot,releasever,infrastructure_type,cloud_provider,sap_system,"+
+			"ansible,reboot_pending,stale_timestamp,stale_warning_timestamp,culled_timestamp,created,tags,repos,"+
+			"baseline_name,baseline_uptodate,template_name,template_installable,template_applicable",
 		lines[0])
 	assert.Equal(t, "", lines[1])
 }
@@ -127,3 +132,13 @@ func TestSystemsExportBaselineFilter(t *testing.T) {
 	assert.Equal(t, "00000000-0000-0000-0000-000000000001", output[0].ID)
 	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output[1].ID)
 }
+
+func TestSystemsExportFieldsetCSV(t *testing.T) {
+	w := makeRequest(t, "/?fields[systems]=display_name,rhsa_count", "text/csv")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(w.Body.String(), "\n")
+	assert.Equal(t, 10, len(lines))
+	assert.Equal(t, "id,display_name,rhsa_count", lines[0])
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001,00000000-0000-0000-0000-000000000001,2", lines[1])
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"app/base/core"
+	"app/base/database"
 	"app/base/utils"
 	"fmt"
 	"net/http"
@@ -178,6 +179,18 @@ func TestSystemsFilterBaseline(t *testing.T) {
 	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[1].ID)
 }
 
+func TestSystemsFilterRepos(t *testing.T) {
+	output := testSystems(t, "/?filter[repos]=repo2&fields[systems]=repos", 1)
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[0].ID)
+	assert.Equal(t, SystemReposList{"repo1", "repo2"}, output.Data[0].Attributes.Repos)
+}
+
+func TestSystemsFilterReposIn(t *testing.T) {
+	output := testSystems(t, "/?filter[repos]=in:repo1,repo2", 1)
+	assert.Equal(t, 2, len(output.Data))
+}
+
 func TestSystemsFilterNotExisting(t *testing.T) {
 	statusCode, errResp := testSystemsError(t, "/?filter[not-existing]=1")
 	assert.Equal(t, http.StatusBadRequest, statusCode)
@@ -201,6 +214,31 @@ func TestSystemsFilterOS(t *testing.T) {
 	assert.Equal(t, "RHEL 8.1", output.Data[2].Attributes.OS)
 }
 
+func TestSystemsFilterProfileFacts(t *testing.T) {
+	output := testSystems(t, `/?filter[releasever]=8.4&filter[infrastructure_type]=virtual&filter[sap_system]=true`, 1)
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[0].ID)
+	assert.Equal(t, "4.18.0-305.el8.x86_64", output.Data[0].Attributes.RunningKernel)
+	assert.Equal(t, "4.18.0-348.el8.x86_64", output.Data[0].Attributes.InstalledKernel)
+	assert.Equal(t, "aws", output.Data[0].Attributes.CloudProvider)
+	assert.True(t, output.Data[0].Attributes.Ansible)
+	assert.NotNil(t, output.Data[0].Attributes.LastBoot)
+}
+
+func TestSystemsFilterRebootPending(t *testing.T) {
+	output := testSystems(t, `/?filter[reboot_pending]=true`, 1)
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[0].ID)
+	assert.True(t, output.Data[0].Attributes.RebootPending)
+}
+
+func TestSystemsOrderLastBoot(t *testing.T) {
+	output := testSystems(t, `/?filter[last_boot]=gt:2018-09-01T00:00:00Z&sort=-last_boot`, 1)
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000003", output.Data[0].ID)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[1].ID)
+}
+
 func TestSystemsFilterInvalidSyntax(t *testing.T) {
 	statusCode, errResp := testSystemsError(t, "/?filter[os][in]=RHEL 8.1,RHEL 7.3")
 	assert.Equal(t, http.StatusBadRequest, statusCode)
@@ -354,3 +392,73 @@ func TestMSSQLSystemMeta3(t *testing.T) {
 	assert.Equal(t, ID, output.Data[0].ID)
 	assert.Equal(t, totalItems, output.Meta.TotalItems)
 }
+
+func TestSystemsFieldset(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithAccount("GET", "/?fields[systems]=display_name,rhsa_count&sort=id", nil, "",
+		SystemsListHandler, "/", 1)
+
+	var output struct {
+		Data []struct {
+			ID         string                 `json:"id"`
+			Type       string                 `json:"type"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"data"`
+	}
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, 8, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", output.Data[0].ID)
+	assert.Equal(t, "system", output.Data[0].Type)
+	assert.Equal(t, map[string]interface{}{
+		"display_name": "00000000-0000-0000-0000-000000000001",
+		"rhsa_count":   2.0,
+	}, output.Data[0].Attributes)
+}
+
+func TestSystemsFieldsetFilterBaseline(t *testing.T) {
+	output := testSystems(t, "/?fields[systems]=display_name&filter[baseline_name]=baseline_1-1", 1)
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, "", output.Data[0].Attributes.BaselineName)
+}
+
+func TestSystemsFieldsetInvalid(t *testing.T) {
+	statusCode, errResp := testSystemsError(t, "/?fields[systems]=display_name,not-existing")
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+	assert.Equal(t, "Invalid field in fields[systems]: not-existing", errResp.Error)
+}
+
+func testSystemsScope(t *testing.T, scope *database.SystemScope) SystemsResponse {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/", nil, "", withSystemScope(scope, SystemsListHandler), "/")
+
+	var output SystemsResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+func TestSystemsScopeGroups(t *testing.T) {
+	output := testSystemsScope(t, &database.SystemScope{Groups: []string{"inventory-group-1"}})
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", output.Data[0].ID)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[1].ID)
+
+	output = testSystemsScope(t, &database.SystemScope{Groups: []string{"inventory-group-2"}, Ungrouped: true})
+	assert.Equal(t, 6, len(output.Data))
+	for _, system := range output.Data {
+		assert.NotEqual(t, "00000000-0000-0000-0000-000000000001", system.ID)
+		assert.NotEqual(t, "00000000-0000-0000-0000-000000000002", system.ID)
+	}
+}
+
+func TestSystemsScopeTags(t *testing.T) {
+	ns := "ns1"
+	output := testSystemsScope(t, &database.SystemScope{Tags: []database.ScopeTag{{Namespace: &ns, Key: "k2"}}})
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", output.Data[0].ID)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", output.Data[1].ID)
+}
+
+func TestSystemsScopeEmpty(t *testing.T) {
+	output := testSystemsScope(t, &database.SystemScope{})
+	assert.Equal(t, 0, len(output.Data))
+}
@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/kafka"
+	"app/manager/middlewares"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const TemplateMissingNameErr = "missing required parameter 'name'"
+const DuplicateTemplateNameErr = "template name already exists"
+const TemplateSnapshotErr = "snapshot requires exactly one of 'inventory_id' or 'to_time'"
+
+type TemplateSnapshot struct {
+	// Reference system, its applicable advisories and package updates are approved
+	InventoryID *string `json:"inventory_id" example:"00000000-0000-0000-0000-000000000001"`
+	// Point in time, advisories published until then and applicable to my systems are approved
+	ToTime *time.Time `json:"to_time" example:"2022-01-01T00:00:00Z"`
+}
+
+type CreateTemplateRequest struct {
+	// Template name
+	Name string `json:"name"`
+	// Description of the template (optional)
+	Description *string `json:"description"`
+	// Names of approved advisories (optional)
+	Advisories []string `json:"advisories" example:"RHSA-2021:3801"`
+	// NEVRAs of approved package updates (optional)
+	Packages []string `json:"packages" example:"firefox-77.0.1-1.fc31.x86_64"`
+	// Snapshot of applicable advisories and package updates to approve in addition to the listed ones (optional)
+	Snapshot *TemplateSnapshot `json:"snapshot"`
+	// Inventory IDs list of systems to assign the template to (optional)
+	InventoryIDs []string `json:"inventory_ids"`
+}
+
+type CreateTemplateResponse struct {
+	TemplateID int `json:"template_id" example:"1"` // Created template unique ID, it can not be changed
+}
+
+// Approved advisories and package updates of a template
+type templateContent struct {
+	AdvisoryIDs []int
+	Packages    []models.TemplatePackage
+}
+
+// @Summary Create a patch template for my set of systems
+// @Description Create a named set of approved advisories and package updates and assign it to my systems
+// @ID createTemplate
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    body    body    CreateTemplateRequest true "Request body"
+// @Success 200 {object} CreateTemplateResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /templates [put]
+func CreateTemplateHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	var request CreateTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		LogAndRespBadRequest(c, err, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if request.Name == "" {
+		LogAndRespBadRequest(c, errors.New(TemplateMissingNameErr), TemplateMissingNameErr)
+		return
+	}
+	request.Description = utils.EmptyToNil(request.Description)
+
+	if !checkTemplateInventoryIDs(c, account, request.InventoryIDs) {
+		return
+	} // Error handled in method itself
+
+	content, err := loadTemplateContent(c, account, request.Advisories, request.Packages, request.Snapshot)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	templateID, err := buildCreateTemplateQuery(request, content, account)
+	if err != nil {
+		if database.IsPgErrorCode(err, database.PgErrorDuplicateKey) {
+			LogAndRespBadRequest(c, err, DuplicateTemplateNameErr)
+			return
+		}
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	inventoryIDs := kafka.GetTemplateInventoryIDsToEvaluate(templateID, account, false, request.InventoryIDs)
+	kafka.EvaluateBaselineSystems(inventoryIDs)
+
+	c.JSON(http.StatusOK, &CreateTemplateResponse{TemplateID: templateID})
+}
+
+func buildCreateTemplateQuery(request CreateTemplateRequest, content *templateContent, account int) (int, error) {
+	tx := database.Db.WithContext(base.Context).Begin()
+	defer tx.Rollback()
+
+	template := models.Template{
+		RhAccountID: account,
+		Name:        request.Name,
+		Description: request.Description,
+		Version:     1,
+	}
+	if err := tx.Omit("created", "updated").Create(&template).Error; err != nil {
+		return template.ID, err
+	}
+
+	if err := storeTemplateContent(tx, account, template.ID, content); err != nil {
+		return template.ID, err
+	}
+
+	if len(request.InventoryIDs) > 0 {
+		if err := updateSystemsTemplateID(tx, account, request.InventoryIDs, &template.ID); err != nil {
+			return template.ID, err
+		}
+	}
+
+	err := tx.Commit().Error
+	return template.ID, err
+}
+
+// Responds with 404 when some of the systems are not found
+func checkTemplateInventoryIDs(c *gin.Context, account int, inventoryIDs []string) bool {
+	missingIDs, err := checkInventoryIDs(account, middlewares.GetSystemScope(c), inventoryIDs)
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return false
+	}
+
+	if len(missingIDs) > 0 {
+		msg := fmt.Sprintf("Missing inventory_ids: %v", missingIDs)
+		LogAndRespNotFound(c, errors.New(msg), msg)
+		return false
+	}
+	return true
+}
+
+// Resolves listed advisories and packages and adds the snapshot ones, responds with error status on failure
+func loadTemplateContent(c *gin.Context, account int, advisories, packages []string,
+	snapshot *TemplateSnapshot) (*templateContent, error) {
+	advisoryIDs, err := templateAdvisoryIDs(c, advisories)
+	if err != nil {
+		return nil, err
+	}
+
+	packagesSet := map[models.TemplatePackage]bool{}
+	for _, nevraStr := range packages {
+		nevra, err := utils.ParseNevra(nevraStr)
+		if err != nil {
+			msg := "Invalid package: " + nevraStr
+			LogAndRespBadRequest(c, err, msg)
+			return nil, err
+		}
+		packagesSet[models.TemplatePackage{Name: nevra.Name, EVRA: nevra.EVRAString()}] = true
+	}
+
+	if snapshot != nil {
+		if err = loadTemplateSnapshot(c, account, snapshot, advisoryIDs, packagesSet); err != nil {
+			return nil, err
+		}
+	}
+
+	content := templateContent{
+		AdvisoryIDs: make([]int, 0, len(advisoryIDs)),
+		Packages:    make([]models.TemplatePackage, 0, len(packagesSet)),
+	}
+	for id := range advisoryIDs {
+		content.AdvisoryIDs = append(content.AdvisoryIDs, id)
+	}
+	sort.Ints(content.AdvisoryIDs)
+	for pkg := range packagesSet {
+		content.Packages = append(content.Packages, pkg)
+	}
+	sort.Slice(content.Packages, func(i, j int) bool {
+		a, b := content.Packages[i], content.Packages[j]
+		return a.Name < b.Name || a.Name == b.Name && a.EVRA < b.EVRA
+	})
+	return &content, nil
+}
+
+// Returns IDs of the advisories, responds with 404 when some of them are not found
+func templateAdvisoryIDs(c *gin.Context, names []string) (map[int]bool, error) {
+	ids := map[int]bool{}
+	if len(names) == 0 {
+		return ids, nil
+	}
+
+	var found []models.AdvisoryMetadata
+	err := database.Db.Select("id, name").Where("name IN (?)", names).Find(&found).Error
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return nil, err
+	}
+
+	foundNames := map[string]bool{}
+	for _, a := range found {
+		ids[a.ID] = true
+		foundNames[a.Name] = true
+	}
+	var missing []string
+	for _, name := range names {
+		if !foundNames[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		msg := fmt.Sprintf("Missing advisories: %v", missing)
+		err = errors.New(msg)
+		LogAndRespNotFound(c, err, msg)
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Adds advisories and package updates applicable to the reference system
+// or advisories published until the given time and applicable to any of my systems
+func loadTemplateSnapshot(c *gin.Context, account int, snapshot *TemplateSnapshot, advisoryIDs map[int]bool,
+	packages map[models.TemplatePackage]bool) error {
+	if (snapshot.InventoryID == nil) == (snapshot.ToTime == nil) {
+		err := errors.New(TemplateSnapshotErr)
+		LogAndRespBadRequest(c, err, TemplateSnapshotErr)
+		return err
+	}
+
+	scope := middlewares.GetSystemScope(c)
+	var snapshotIDs []int
+	var snapshotPackages []models.TemplatePackage
+	var err error
+	if snapshot.InventoryID != nil {
+		if !checkTemplateInventoryIDs(c, account, []string{*snapshot.InventoryID}) {
+			return errors.New("reference system not found")
+		}
+		err = database.SystemAdvisoriesByInventoryID(database.Db, account, scope, *snapshot.InventoryID).
+			Pluck("sa.advisory_id", &snapshotIDs).Error
+		if err == nil {
+			err = database.Systems(database.Db, account, scope).
+				Select("DISTINCT pn.name, upd->>'evra' AS evra").
+				Joins("JOIN system_package spkg ON spkg.system_id = sp.id AND spkg.rh_account_id = sp.rh_account_id").
+				Joins("JOIN package_name pn ON pn.id = spkg.name_id").
+				Joins("CROSS JOIN LATERAL jsonb_array_elements(spkg.update_data) upd").
+				Where("sp.inventory_id = ?::uuid", *snapshot.InventoryID).
+				Scan(&snapshotPackages).Error
+		}
+	} else {
+		err = database.SystemAdvisories(database.Db, account, scope).
+			Joins("JOIN advisory_metadata am ON am.id = sa.advisory_id").
+			Where("sp.stale = false AND am.public_date <= ?", snapshot.ToTime).
+			Group("sa.advisory_id").
+			Pluck("sa.advisory_id", &snapshotIDs).Error
+	}
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return err
+	}
+
+	for _, id := range snapshotIDs {
+		advisoryIDs[id] = true
+	}
+	for _, pkg := range snapshotPackages {
+		packages[models.TemplatePackage{Name: pkg.Name, EVRA: pkg.EVRA}] = true
+	}
+	return nil
+}
+
+// Replaces approved advisories and packages of the template
+func storeTemplateContent(tx *gorm.DB, account, templateID int, content *templateContent) error {
+	err := tx.Where("rh_account_id = ? AND template_id = ?", account, templateID).
+		Delete(&models.TemplateAdvisory{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("rh_account_id = ? AND template_id = ?", account, templateID).
+		Delete(&models.TemplatePackage{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(content.AdvisoryIDs) > 0 {
+		advisories := make([]models.TemplateAdvisory, len(content.AdvisoryIDs))
+		for i, id := range content.AdvisoryIDs {
+			advisories[i] = models.TemplateAdvisory{RhAccountID: account, TemplateID: templateID, AdvisoryID: id}
+		}
+		if err = database.BulkInsert(tx, advisories); err != nil {
+			return err
+		}
+	}
+
+	if len(content.Packages) > 0 {
+		packages := make([]models.TemplatePackage, len(content.Packages))
+		for i, pkg := range content.Packages {
+			packages[i] = models.TemplatePackage{RhAccountID: account, TemplateID: templateID, Name: pkg.Name,
+				EVRA: pkg.EVRA}
+		}
+		if err = database.BulkInsert(tx, packages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Assigns the template to the systems, removes their template when templateID is nil
+func updateSystemsTemplateID(tx *gorm.DB, account int, inventoryIDs []string, templateID *int) error {
+	updateFields := map[string]interface{}{"template_id": templateID, "unchanged_since": time.Now()}
+	if templateID == nil {
+		updateFields["template_installable"] = 0
+		updateFields["template_applicable"] = 0
+	}
+
+	var systemIDs []int
+	err := tx.Model(models.SystemPlatform{}).
+		Where("rh_account_id = ? AND inventory_id::text IN (?)", account, inventoryIDs).
+		Pluck("id", &systemIDs).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(models.SystemPlatform{}).
+		Where("rh_account_id = ? AND id IN (?)", account, systemIDs).
+		Updates(updateFields).Error
+	if err != nil || templateID != nil {
+		return err
+	}
+
+	// systems without template have no installable or applicable advisories
+	return tx.Model(models.SystemAdvisories{}).
+		Where("rh_account_id = ? AND system_id IN (?) AND in_template IS NOT NULL", account, systemIDs).
+		Update("in_template", nil).Error
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testCreateTemplate(t *testing.T, data string, expectedStatus int, output interface{}) {
+	testCreateTemplateAccount(t, 1, data, expectedStatus, output)
+}
+
+func testCreateTemplateAccount(t *testing.T, account int, data string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("PUT", "/", bytes.NewBufferString(data), "", CreateTemplateHandler, account,
+		"PUT", "/")
+	CheckResponse(t, w, expectedStatus, output)
+}
+
+func TestCreateTemplate(t *testing.T) {
+	data := `{
+		"name": "my_template",
+		"description": "desc",
+		"advisories": ["RH-1", "RH-2"],
+		"packages": ["firefox-0:77.0.1-1.fc31.x86_64", "kernel-5.10.13-200.fc31.x86_64"],
+		"inventory_ids": ["00000000-0000-0000-0000-000000000005"]
+	}`
+	var resp CreateTemplateResponse
+	testCreateTemplate(t, data, http.StatusOK, &resp)
+
+	template, err := getTemplate(1, resp.TemplateID)
+	assert.Nil(t, err)
+	assert.Equal(t, "my_template", template.Attributes.Name)
+	assert.Equal(t, "desc", *template.Attributes.Description)
+	assert.Equal(t, 1, template.Attributes.Version)
+	assert.Equal(t, []string{"RH-1", "RH-2"}, template.Attributes.Advisories)
+	assert.Equal(t, []string{"firefox-77.0.1-1.fc31.x86_64", "kernel-5.10.13-200.fc31.x86_64"},
+		template.Attributes.Packages)
+
+	var system models.SystemPlatform
+	assert.Nil(t, database.Db.Where("id = 5").Find(&system).Error)
+	assert.Equal(t, resp.TemplateID, *system.TemplateID)
+	database.DeleteTemplate(t, resp.TemplateID)
+}
+
+func TestCreateTemplateSnapshotSystem(t *testing.T) {
+	data := `{"name": "my_template", "snapshot": {"inventory_id": "00000000-0000-0000-0000-000000000013"}}`
+	var resp CreateTemplateResponse
+	testCreateTemplateAccount(t, 3, data, http.StatusOK, &resp)
+
+	template, err := getTemplate(3, resp.TemplateID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"firefox-76.0.1-1.fc31.x86_64", "firefox-77.0.1-1.fc31.x86_64"},
+		template.Attributes.Packages)
+	database.DeleteTemplate(t, resp.TemplateID)
+}
+
+func TestCreateTemplateSnapshotTime(t *testing.T) {
+	data := `{"name": "my_template", "advisories": ["RH-2"], "snapshot": {"to_time": "2017-01-01T00:00:00Z"}}`
+	var resp CreateTemplateResponse
+	testCreateTemplate(t, data, http.StatusOK, &resp)
+
+	template, err := getTemplate(1, resp.TemplateID)
+	assert.Nil(t, err)
+	assert.Contains(t, template.Attributes.Advisories, "RH-1")
+	assert.Contains(t, template.Attributes.Advisories, "RH-2")
+	database.DeleteTemplate(t, resp.TemplateID)
+}
+
+func TestCreateTemplateSnapshotInvalid(t *testing.T) {
+	data := `{"name": "my_template", "snapshot": {}}`
+	var errResp utils.ErrorResponse
+	testCreateTemplate(t, data, http.StatusBadRequest, &errResp)
+	assert.Equal(t, TemplateSnapshotErr, errResp.Error)
+}
+
+func TestCreateTemplateMissingName(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testCreateTemplate(t, `{"advisories": ["RH-1"]}`, http.StatusBadRequest, &errResp)
+	assert.Equal(t, TemplateMissingNameErr, errResp.Error)
+}
+
+func TestCreateTemplateMissingAdvisory(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testCreateTemplate(t, `{"name": "my_template", "advisories": ["RH-1", "RH-X"]}`, http.StatusNotFound, &errResp)
+	assert.Equal(t, "Missing advisories: [RH-X]", errResp.Error)
+}
+
+func TestCreateTemplateInvalidPackage(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testCreateTemplate(t, `{"name": "my_template", "packages": ["firefox"]}`, http.StatusBadRequest, &errResp)
+	assert.Equal(t, "Invalid package: firefox", errResp.Error)
+}
+
+func TestCreateTemplateDuplicatedName(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testCreateTemplate(t, `{"name": "template_1-1"}`, http.StatusBadRequest, &errResp)
+	assert.Equal(t, DuplicateTemplateNameErr, errResp.Error)
+}
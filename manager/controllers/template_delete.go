@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/kafka"
+	"app/manager/middlewares"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type DeleteTemplateResponse struct {
+	TemplateID int `json:"template_id" example:"1"` // Deleted template unique ID
+}
+
+// @Summary Delete a patch template
+// @Description Delete a patch template, its systems are left without template
+// @ID templateDelete
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param template_id path int true "Template ID"
+// @Success 200 {object} DeleteTemplateResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /templates/{template_id} [delete]
+func TemplateDeleteHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	templateIDstr := c.Param("template_id")
+	templateID, err := strconv.Atoi(templateIDstr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{Error: "Invalid template_id: " + templateIDstr})
+		return
+	}
+
+	tx := database.Db.WithContext(base.Context).Begin()
+	defer tx.Rollback()
+
+	var inventoryIDs []string
+	err = tx.Model(models.SystemPlatform{}).
+		Where("rh_account_id = ? AND template_id = ?", account, templateID).
+		Pluck("inventory_id", &inventoryIDs).Error
+	if err == nil && len(inventoryIDs) > 0 {
+		err = updateSystemsTemplateID(tx, account, inventoryIDs, nil)
+	}
+	if err != nil {
+		LogAndRespError(c, err, "Could not remove template from systems")
+		return
+	}
+
+	deleteQuery := tx.Where("rh_account_id = ? AND id = ?", account, templateID).
+		Delete(&models.Template{})
+	if err = deleteQuery.Error; err != nil {
+		LogAndRespError(c, err, "Could not delete template")
+		return
+	}
+
+	if deleteQuery.RowsAffected == 0 {
+		LogAndRespNotFound(c, errors.New("no rows returned"), "template not found")
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		LogAndRespError(c, errors.Wrap(err, "Could not commit template delete"), err.Error())
+		return
+	}
+
+	inventoryAIDs := kafka.GetTemplateInventoryIDsToEvaluate(templateID, account, false, inventoryIDs)
+	kafka.EvaluateBaselineSystems(inventoryAIDs)
+
+	c.JSON(http.StatusOK, &DeleteTemplateResponse{TemplateID: templateID})
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateDelete(t *testing.T) {
+	var created CreateTemplateResponse
+	testCreateTemplate(t, `{"name": "my_template", "advisories": ["RH-1"],
+		"inventory_ids": ["00000000-0000-0000-0000-000000000005"]}`, http.StatusOK, &created)
+
+	w := CreateRequestRouterWithPath("GET", fmt.Sprintf("/%d", created.TemplateID), nil, "",
+		TemplateDeleteHandler, "/:template_id")
+
+	var resp DeleteTemplateResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+	assert.Equal(t, created.TemplateID, resp.TemplateID)
+
+	var cnt int64
+	assert.Nil(t, database.Db.Model(&models.Template{}).Where("id = ?", created.TemplateID).Count(&cnt).Error)
+	assert.Equal(t, int64(0), cnt)
+	assert.Nil(t, database.Db.Model(&models.SystemPlatform{}).Where("template_id = ?", created.TemplateID).
+		Count(&cnt).Error)
+	assert.Equal(t, int64(0), cnt)
+}
+
+func TestTemplateDeleteNonExisting(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/88888", nil, "", TemplateDeleteHandler, "/:template_id")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusNotFound, &errResp)
+	assert.Equal(t, "template not found", errResp.Error)
+}
+
+func TestTemplateDeleteInvalid(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/invalidTemplate", nil, "", TemplateDeleteHandler,
+		"/:template_id")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, "Invalid template_id: invalidTemplate", errResp.Error)
+}
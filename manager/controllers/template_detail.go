@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/manager/middlewares"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TemplateDetailResponse struct {
+	Data TemplateDetailItem `json:"data"`
+}
+
+type TemplateDetailItem struct {
+	Attributes TemplateDetailAttributes `json:"attributes"`              // Additional template attributes
+	ID         int                      `json:"id" example:"1"`          // Template ID
+	Type       string                   `json:"type" example:"template"` // Document type name
+}
+
+type TemplateDetailAttributes struct {
+	Name        string    `json:"name" example:"my-template"`                      // Template name
+	Description *string   `json:"description" example:"approved for production"`   // Template description
+	Version     int       `json:"version" example:"1"`                             // Template version
+	Created     time.Time `json:"created" example:"2022-01-01T00:00:00Z"`          // Creation time
+	Updated     time.Time `json:"updated" example:"2022-01-01T00:00:00Z"`          // Last update time
+	Advisories  []string  `json:"advisories" example:"RHSA-2021:3801"`             // Names of approved advisories
+	Packages    []string  `json:"packages" example:"firefox-77.0.1-1.fc31.x86_64"` // NEVRAs of approved packages
+}
+
+// @Summary Show patch template detail by given template ID
+// @Description Show patch template detail with approved advisories and package updates
+// @ID detailTemplate
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    template_id    path    int   true "Template ID"
+// @Success 200 {object} TemplateDetailResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /templates/{template_id} [get]
+func TemplateDetailHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	templateIDstr := c.Param("template_id")
+	templateID, err := strconv.Atoi(templateIDstr)
+	if err != nil {
+		LogAndRespBadRequest(c, err, "Invalid template_id: "+templateIDstr)
+		return
+	}
+
+	item, err := getTemplate(account, templateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			LogAndRespNotFound(c, err, "template not found")
+		} else {
+			LogAndRespError(c, err, "template detail error")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, &TemplateDetailResponse{Data: *item})
+}
+
+func getTemplate(account, templateID int) (*TemplateDetailItem, error) {
+	var template models.Template
+	err := database.Db.Where("rh_account_id = ? AND id = ?", account, templateID).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := []string{}
+	err = database.Db.Table("template_advisory ta").
+		Joins("JOIN advisory_metadata am ON am.id = ta.advisory_id").
+		Where("ta.rh_account_id = ? AND ta.template_id = ?", account, templateID).
+		Order("am.name").
+		Pluck("am.name", &advisories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	packages := []string{}
+	err = database.Db.Model(&models.TemplatePackage{}).
+		Where("rh_account_id = ? AND template_id = ?", account, templateID).
+		Order("name, evra").
+		Pluck("name || '-' || evra", &packages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateDetailItem{
+		Attributes: TemplateDetailAttributes{
+			Name:        template.Name,
+			Description: template.Description,
+			Version:     template.Version,
+			Created:     template.Created,
+			Updated:     template.Updated,
+			Advisories:  advisories,
+			Packages:    packages,
+		},
+		ID:   template.ID,
+		Type: "template",
+	}, nil
+}
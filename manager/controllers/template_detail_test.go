@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/utils"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTemplateDetail(t *testing.T, url string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", url, nil, "", TemplateDetailHandler, "/:template_id")
+	CheckResponse(t, w, expectedStatus, &output)
+}
+
+func TestTemplateDetailDefault(t *testing.T) {
+	var output TemplateDetailResponse
+	testTemplateDetail(t, "/1", http.StatusOK, &output)
+	assert.Equal(t, 1, output.Data.ID)
+	assert.Equal(t, "template", output.Data.Type)
+	assert.Equal(t, "template_1-1", output.Data.Attributes.Name)
+	assert.Equal(t, "desc", *output.Data.Attributes.Description)
+	assert.Equal(t, 2, output.Data.Attributes.Version)
+	assert.Equal(t, []string{"RH-1"}, output.Data.Attributes.Advisories)
+	assert.Equal(t, []string{"firefox-77.0.1-1.fc31.x86_64"}, output.Data.Attributes.Packages)
+}
+
+func TestTemplateDetailNotFound(t *testing.T) {
+	var output utils.ErrorResponse
+	testTemplateDetail(t, "/10000", http.StatusNotFound, &output)
+	assert.Equal(t, "template not found", output.Error)
+}
+
+func TestTemplateDetailInvalid(t *testing.T) {
+	var output utils.ErrorResponse
+	testTemplateDetail(t, "/invalidID", http.StatusBadRequest, &output)
+	assert.Equal(t, "Invalid template_id: invalidID", output.Error)
+}
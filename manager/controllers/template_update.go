@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/kafka"
+	"app/manager/middlewares"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type UpdateTemplateRequest struct {
+	// Updated template name (optional)
+	Name *string `json:"name" example:"my-changed-template-name"`
+	// Description of the template (optional)
+	Description *string `json:"description"`
+	// Names of approved advisories, replaces the current ones (optional)
+	Advisories *[]string `json:"advisories"`
+	// NEVRAs of approved package updates, replaces the current ones (optional)
+	Packages *[]string `json:"packages"`
+	// Snapshot of applicable advisories and package updates to approve in addition to the current ones (optional)
+	Snapshot *TemplateSnapshot `json:"snapshot"`
+	// Map of inventories to assign the template to (true) or remove it from (false) (optional)
+	InventoryIDs map[string]bool `json:"inventory_ids"`
+}
+
+type UpdateTemplateResponse struct {
+	TemplateID int `json:"template_id" example:"1"` // Updated template unique ID, it can not be changed
+	Version    int `json:"version" example:"2"`     // Template version, incremented on each content change
+}
+
+// nolint: funlen
+// @Summary Update a patch template
+// @Description Update a patch template, each change of approved advisories and packages increments its version
+// @ID updateTemplate
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    template_id path    int                   true "Template ID"
+// @Param    body        body    UpdateTemplateRequest true "Request body"
+// @Success 200 {object} UpdateTemplateResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /templates/{template_id} [put]
+func TemplateUpdateHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	var req UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		LogAndRespBadRequest(c, err, "Invalid request body: "+err.Error())
+		return
+	}
+
+	templateIDstr := c.Param("template_id")
+	templateID, err := strconv.Atoi(templateIDstr)
+	if err != nil {
+		LogAndRespBadRequest(c, err, "Invalid template_id: "+templateIDstr)
+		return
+	}
+
+	var template models.Template
+	err = database.Db.Where("rh_account_id = ? AND id = ?", account, templateID).First(&template).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			LogAndRespNotFound(c, err, "Template not found")
+			return
+		}
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	inventoryIDsList := map2list(req.InventoryIDs)
+	if !checkTemplateInventoryIDs(c, account, inventoryIDsList) {
+		return
+	} // Error handled in method itself
+
+	contentUpdated := req.Advisories != nil || req.Packages != nil || req.Snapshot != nil
+	var content *templateContent
+	if contentUpdated {
+		content, err = loadUpdatedTemplateContent(c, &template, req)
+		if err != nil {
+			return
+		} // Error handled in method itself
+		template.Version++
+	}
+
+	newIDs, obsoleteIDs := sortInventoryIDs(req.InventoryIDs)
+	err = buildUpdateTemplateQuery(&template, req, content, newIDs, obsoleteIDs)
+	if err != nil {
+		if database.IsPgErrorCode(err, database.PgErrorDuplicateKey) {
+			LogAndRespBadRequest(c, err, DuplicateTemplateNameErr)
+			return
+		}
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	inventoryAIDs := kafka.GetTemplateInventoryIDsToEvaluate(templateID, account, contentUpdated, inventoryIDsList)
+	kafka.EvaluateBaselineSystems(inventoryAIDs)
+
+	c.JSON(http.StatusOK, &UpdateTemplateResponse{TemplateID: templateID, Version: template.Version})
+}
+
+// Loads new content of the template, current advisories and packages are kept unless replaced
+func loadUpdatedTemplateContent(c *gin.Context, template *models.Template, req UpdateTemplateRequest) (
+	*templateContent, error) {
+	var advisories, packages []string
+	if req.Advisories != nil {
+		advisories = *req.Advisories
+	} else {
+		err := database.Db.Table("template_advisory ta").
+			Joins("JOIN advisory_metadata am ON am.id = ta.advisory_id").
+			Where("ta.rh_account_id = ? AND ta.template_id = ?", template.RhAccountID, template.ID).
+			Pluck("am.name", &advisories).Error
+		if err != nil {
+			LogAndRespError(c, err, "Database error")
+			return nil, err
+		}
+	}
+
+	if req.Packages != nil {
+		packages = *req.Packages
+	} else {
+		var current []models.TemplatePackage
+		err := database.Db.Where("rh_account_id = ? AND template_id = ?", template.RhAccountID, template.ID).
+			Find(&current).Error
+		if err != nil {
+			LogAndRespError(c, err, "Database error")
+			return nil, err
+		}
+		for _, pkg := range current {
+			packages = append(packages, pkg.Name+"-"+pkg.EVRA)
+		}
+	}
+	return loadTemplateContent(c, template.RhAccountID, advisories, packages, req.Snapshot)
+}
+
+func buildUpdateTemplateQuery(template *models.Template, req UpdateTemplateRequest, content *templateContent,
+	newIDs, obsoleteIDs []string) error {
+	data := map[string]interface{}{}
+	if req.Name != nil {
+		data["name"] = req.Name
+	}
+	if req.Description != nil {
+		data["description"] = utils.EmptyToNil(req.Description)
+	}
+	if content != nil {
+		data["version"] = template.Version
+	}
+
+	tx := database.Db.WithContext(base.Context).Begin()
+	defer tx.Rollback()
+
+	if len(data) > 0 {
+		data["updated"] = gorm.Expr("CURRENT_TIMESTAMP")
+		err := tx.Model(models.Template{}).
+			Where("rh_account_id = ? AND id = ?", template.RhAccountID, template.ID).
+			Updates(data).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if content != nil {
+		if err := storeTemplateContent(tx, template.RhAccountID, template.ID, content); err != nil {
+			return err
+		}
+	}
+
+	if len(newIDs) > 0 {
+		if err := updateSystemsTemplateID(tx, template.RhAccountID, newIDs, &template.ID); err != nil {
+			return err
+		}
+	}
+
+	if len(obsoleteIDs) > 0 {
+		// remove the template only from its systems
+		var templateSystems []string
+		err := tx.Model(models.SystemPlatform{}).
+			Where("rh_account_id = ? AND template_id = ? AND inventory_id::text IN (?)",
+				template.RhAccountID, template.ID, obsoleteIDs).
+			Pluck("inventory_id", &templateSystems).Error
+		if err != nil {
+			return err
+		}
+		if len(templateSystems) > 0 {
+			if err = updateSystemsTemplateID(tx, template.RhAccountID, templateSystems, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testUpdateTemplate(t *testing.T, url, data string, expectedStatus int, output interface{}) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("PUT", url, bytes.NewBufferString(data), "", TemplateUpdateHandler, 1,
+		"PUT", "/:template_id")
+	CheckResponse(t, w, expectedStatus, output)
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	var created CreateTemplateResponse
+	testCreateTemplate(t, `{"name": "my_template", "advisories": ["RH-1"], "packages": ["firefox-1-1.x86_64"]}`,
+		http.StatusOK, &created)
+	url := fmt.Sprintf("/%d", created.TemplateID)
+
+	// name change doesn't change the version
+	var resp UpdateTemplateResponse
+	testUpdateTemplate(t, url, `{"name": "my_renamed_template"}`, http.StatusOK, &resp)
+	assert.Equal(t, created.TemplateID, resp.TemplateID)
+	assert.Equal(t, 1, resp.Version)
+
+	// advisories are replaced, packages are kept
+	testUpdateTemplate(t, url, `{"advisories": ["RH-2", "RH-3"]}`, http.StatusOK, &resp)
+	assert.Equal(t, 2, resp.Version)
+
+	template, err := getTemplate(1, created.TemplateID)
+	assert.Nil(t, err)
+	assert.Equal(t, "my_renamed_template", template.Attributes.Name)
+	assert.Equal(t, []string{"RH-2", "RH-3"}, template.Attributes.Advisories)
+	assert.Equal(t, []string{"firefox-1-1.x86_64"}, template.Attributes.Packages)
+	database.DeleteTemplate(t, created.TemplateID)
+}
+
+func TestUpdateTemplateSystems(t *testing.T) {
+	var created CreateTemplateResponse
+	testCreateTemplate(t, `{"name": "my_template", "inventory_ids": ["00000000-0000-0000-0000-000000000005"]}`,
+		http.StatusOK, &created)
+	url := fmt.Sprintf("/%d", created.TemplateID)
+
+	var resp UpdateTemplateResponse
+	data := `{"inventory_ids": {
+		"00000000-0000-0000-0000-000000000005": false,
+		"00000000-0000-0000-0000-000000000006": true
+	}}`
+	testUpdateTemplate(t, url, data, http.StatusOK, &resp)
+	assert.Equal(t, 1, resp.Version)
+
+	var templateSystems []int
+	assert.Nil(t, database.Db.Model(&models.SystemPlatform{}).Where("template_id = ?", created.TemplateID).
+		Pluck("id", &templateSystems).Error)
+	assert.Equal(t, []int{6}, templateSystems)
+	database.DeleteTemplate(t, created.TemplateID)
+}
+
+func TestUpdateTemplateNotFound(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testUpdateTemplate(t, "/10000", `{"name": "my_template"}`, http.StatusNotFound, &errResp)
+	assert.Equal(t, "Template not found", errResp.Error)
+}
+
+func TestUpdateTemplateInvalidID(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testUpdateTemplate(t, "/invalidID", `{"name": "my_template"}`, http.StatusBadRequest, &errResp)
+	assert.Equal(t, "Invalid template_id: invalidID", errResp.Error)
+}
+
+func TestUpdateTemplateDuplicatedName(t *testing.T) {
+	var errResp utils.ErrorResponse
+	testUpdateTemplate(t, "/2", `{"name": "template_1-1"}`, http.StatusBadRequest, &errResp)
+	assert.Equal(t, DuplicateTemplateNameErr, errResp.Error)
+}
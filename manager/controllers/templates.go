@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/manager/middlewares"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var TemplateFields = database.MustGetQueryAttrs(&TemplatesDBLookup{})
+var TemplateSelect = database.MustGetSelect(&TemplatesDBLookup{})
+var TemplateOpts = ListOpts{
+	Fields:         TemplateFields,
+	DefaultFilters: nil,
+	DefaultSort:    "name",
+	StableSort:     "id",
+	SearchFields:   []string{"t.name"},
+	TotalFunc:      CountRows,
+}
+
+type TemplatesDBLookup struct {
+	ID int `query:"t.id" gorm:"column:id"`
+	TemplateItemAttributes
+}
+
+// nolint: lll
+type TemplateItemAttributes struct {
+	// Template name
+	Name string `json:"name" csv:"name" query:"t.name" gorm:"column:name" example:"my-template"`
+	// Template version, incremented on each change of approved advisories and packages
+	Version int `json:"version" csv:"version" query:"t.version" gorm:"column:version" example:"1"`
+	// Last update of the template
+	Updated time.Time `json:"updated" csv:"updated" query:"t.updated" gorm:"column:updated" example:"2022-01-01T00:00:00Z"`
+	// Count of the approved advisories
+	Advisories int `json:"advisories" csv:"advisories" query:"(SELECT count(*) FROM template_advisory ta WHERE ta.rh_account_id = t.rh_account_id AND ta.template_id = t.id)" gorm:"column:advisories" example:"12"`
+	// Count of the approved package updates
+	Packages int `json:"packages" csv:"packages" query:"(SELECT count(*) FROM template_package tp WHERE tp.rh_account_id = t.rh_account_id AND tp.template_id = t.id)" gorm:"column:packages" example:"3"`
+	// Count of the systems assigned to the template
+	Systems int `json:"systems" csv:"systems" query:"COALESCE(sp.systems, 0)" gorm:"column:systems" example:"22"`
+}
+
+type TemplateItem struct {
+	Attributes TemplateItemAttributes `json:"attributes"`              // Additional template attributes
+	ID         int                    `json:"id" example:"10"`         // Unique template id
+	Type       string                 `json:"type" example:"template"` // Document type name
+}
+
+type TemplatesResponse struct {
+	Data  []TemplateItem `json:"data"`  // Template items
+	Links Links          `json:"links"` // Pagination links
+	Meta  ListMeta       `json:"meta"`  // Generic response fields (pagination params, filters etc.)
+}
+
+// @Summary Show me all patch templates of my systems
+// @Description Show me all patch templates of my systems
+// @ID listTemplates
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    limit          query   int     false   "Limit for paging, set -1 to return all"
+// @Param    offset         query   int     false   "Offset for paging"
+// @Param    sort           query   string  false   "Sort field"    Enums(id,name,version,updated,advisories,packages,systems)
+// @Param    search         query   string  false   "Find matching text"
+// @Param    filter[id]           query   string  false "Filter"
+// @Param    filter[name]         query   string  false "Filter"
+// @Param    filter[version]      query   string  false "Filter"
+// @Param    filter[systems]      query   string  false "Filter"
+// @Param    tags           query   []string  false "Tag filter"
+// @Success 200 {object} TemplatesResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /templates [get]
+func TemplatesListHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+	filters, err := ParseTagsFilters(c)
+	if err != nil {
+		return
+	} // Error handled in method itself
+
+	query := buildQueryTemplates(filters, account, middlewares.GetSystemScope(c))
+	query, meta, links, err := ListCommon(query, c, filters, TemplateOpts)
+	if err != nil {
+		// Error handling and setting of result code & content is done in ListCommon
+		return
+	}
+
+	var templates []TemplatesDBLookup
+	if err = query.Find(&templates).Error; err != nil {
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	data := make([]TemplateItem, len(templates))
+	for i, t := range templates {
+		data[i] = TemplateItem{Attributes: t.TemplateItemAttributes, ID: t.ID, Type: "template"}
+	}
+	c.JSON(http.StatusOK, &TemplatesResponse{Data: data, Links: *links, Meta: *meta})
+}
+
+func buildQueryTemplates(filters map[string]FilterData, account int, scope *database.SystemScope) *gorm.DB {
+	subq := database.Systems(database.Db, account, scope).
+		Select("sp.template_id, count(sp.inventory_id) as systems").
+		Joins("JOIN inventory.hosts ih ON ih.id = sp.inventory_id").
+		Where("sp.stale = false").
+		Group("sp.template_id")
+	subq, _ = ApplyTagsFilter(filters, subq, "sp.inventory_id")
+
+	return database.Db.Table("template t").
+		Select(TemplateSelect).
+		Joins("LEFT JOIN (?) sp ON sp.template_id = t.id", subq).
+		Where("t.rh_account_id = ?", account)
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"app/base/core"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTemplates(t *testing.T, url string) TemplatesResponse {
+	core.SetupTest(t)
+	w := CreateRequest("GET", url, nil, "", TemplatesListHandler)
+
+	var output TemplatesResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	return output
+}
+
+func TestTemplatesDefault(t *testing.T) {
+	output := testTemplates(t, "/")
+
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, 1, output.Data[0].ID)
+	assert.Equal(t, "template", output.Data[0].Type)
+	assert.Equal(t, "template_1-1", output.Data[0].Attributes.Name)
+	assert.Equal(t, 2, output.Data[0].Attributes.Version)
+	assert.Equal(t, 1, output.Data[0].Attributes.Advisories)
+	assert.Equal(t, 1, output.Data[0].Attributes.Packages)
+	assert.Equal(t, 1, output.Data[0].Attributes.Systems)
+	assert.Equal(t, "template_1-2", output.Data[1].Attributes.Name)
+	assert.Equal(t, 2, output.Data[1].Attributes.Advisories)
+	assert.Equal(t, 0, output.Data[1].Attributes.Packages)
+	assert.Equal(t, 0, output.Data[1].Attributes.Systems)
+	assert.Equal(t, 2, output.Meta.TotalItems)
+}
+
+func TestTemplatesFilterSystems(t *testing.T) {
+	output := testTemplates(t, "/?filter[systems]=gt:0")
+
+	assert.Equal(t, 1, len(output.Data))
+	assert.Equal(t, "template_1-1", output.Data[0].Attributes.Name)
+}
+
+func TestTemplatesTags(t *testing.T) {
+	output := testTemplates(t, "/?tags=ns1/k3=val4")
+
+	assert.Equal(t, 2, len(output.Data))
+	assert.Equal(t, 0, output.Data[0].Attributes.Systems)
+}
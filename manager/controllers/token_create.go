@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/manager/middlewares"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const TokenMissingNameErr = "missing required parameter 'name'"
+const TokenMissingScopesErr = "missing required parameter 'scopes'"
+const DuplicateTokenNameErr = "token name already exists"
+const TokenExpiresErr = "expires has to be in the future and at most 365 days from now"
+const TokenScopesNotGrantedErr = "you can't grant permissions you don't have"
+
+const defaultTokenLifetime = 90 * 24 * time.Hour
+const maxTokenLifetime = 365 * 24 * time.Hour
+
+type CreateTokenRequest struct {
+	// Token name, unique within the organization
+	Name string `json:"name"`
+	// Token scopes: read-only, baseline-write, status-write
+	Scopes []string `json:"scopes"`
+	// Expiration time, at most 365 days from now (optional, default 90 days)
+	Expires *time.Time `json:"expires"`
+}
+
+type CreateTokenResponse struct {
+	ID      int64     `json:"id" example:"1"`                         // Unique token ID
+	Name    string    `json:"name" example:"ci-pipeline"`             // Token name
+	Token   string    `json:"token" example:"patch_abc"`              // Token value, it is not shown again
+	Expires time.Time `json:"expires" example:"2022-04-01T00:00:00Z"` // Expiration time
+}
+
+// @Summary Create an API token for automation
+// @Description Create a named API token with the given scopes, the token is sent in `Authorization: Bearer` header
+// @ID createToken
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    body    body    CreateTokenRequest true "Request body"
+// @Success 200 {object} CreateTokenResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /tokens [post]
+func CreateTokenHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	var request CreateTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		LogAndRespBadRequest(c, err, "Invalid request body: "+err.Error())
+		return
+	}
+	if request.Name == "" {
+		LogAndRespBadRequest(c, errors.New(TokenMissingNameErr), TokenMissingNameErr)
+		return
+	}
+	if len(request.Scopes) == 0 {
+		LogAndRespBadRequest(c, errors.New(TokenMissingScopesErr), TokenMissingScopesErr)
+		return
+	}
+	for _, scope := range request.Scopes {
+		if _, ok := middlewares.APITokenScopes[scope]; !ok {
+			msg := "Invalid scope: " + scope
+			LogAndRespBadRequest(c, errors.New(msg), msg)
+			return
+		}
+	}
+
+	now := time.Now()
+	expires := now.Add(defaultTokenLifetime)
+	if request.Expires != nil {
+		if !request.Expires.After(now) || request.Expires.After(now.Add(maxTokenLifetime)) {
+			LogAndRespBadRequest(c, errors.New(TokenExpiresErr), TokenExpiresErr)
+			return
+		}
+		expires = *request.Expires
+	}
+
+	for _, scope := range request.Scopes {
+		if !middlewares.CanGrant(c, middlewares.APITokenScopes[scope]) {
+			LogAndRespStatusError(c, http.StatusForbidden, errors.New(TokenScopesNotGrantedErr),
+				TokenScopesNotGrantedErr)
+			return
+		}
+	}
+
+	token, err := middlewares.GenerateAPIToken()
+	if err != nil {
+		LogAndRespError(c, err, "Unable to generate token")
+		return
+	}
+	apiToken, err := buildCreateTokenQuery(request, account, middlewares.GetPrincipal(c), token, expires)
+	if err != nil {
+		if database.IsPgErrorCode(err, database.PgErrorDuplicateKey) {
+			LogAndRespBadRequest(c, err, DuplicateTokenNameErr)
+			return
+		}
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	resp := CreateTokenResponse{ID: apiToken.ID, Name: apiToken.Name, Token: token, Expires: expires}
+	c.JSON(http.StatusOK, &resp)
+}
+
+func buildCreateTokenQuery(request CreateTokenRequest, account int, principal *middlewares.Principal,
+	token string, expires time.Time) (*models.APIToken, error) {
+	scopes, err := json.Marshal(request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	apiToken := models.APIToken{
+		RhAccountID: account,
+		Name:        request.Name,
+		TokenHash:   middlewares.HashToken(token),
+		Scopes:      scopes,
+		Expires:     &expires,
+	}
+	if principal != nil {
+		apiToken.CreatedBy = &principal.Name
+	}
+	err = database.Db.WithContext(base.Context).
+		Omit("created", "last_used", "revoked").
+		Create(&apiToken).Error
+	return &apiToken, err
+}
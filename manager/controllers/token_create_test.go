@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateToken(t *testing.T) {
+	core.SetupTest(t)
+	data := `{"name": "ci", "scopes": ["read-only", "baseline-write"]}`
+	w := CreateRequestRouterWithParams("POST", "/", bytes.NewBufferString(data), "", CreateTokenHandler, 1, "POST", "/")
+
+	var resp CreateTokenResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+	assert.Equal(t, "ci", resp.Name)
+	assert.True(t, strings.HasPrefix(resp.Token, middlewares.APITokenPrefix))
+	assert.True(t, resp.Expires.After(time.Now().Add(89*24*time.Hour)))
+
+	var token models.APIToken
+	assert.NoError(t, database.Db.Where("id = ?", resp.ID).Find(&token).Error)
+	assert.Equal(t, 1, token.RhAccountID)
+	assert.Equal(t, middlewares.HashToken(resp.Token), token.TokenHash)
+	assert.Equal(t, `["read-only", "baseline-write"]`, string(token.Scopes))
+	assert.Equal(t, "mock", *token.CreatedBy)
+	assert.Nil(t, token.Revoked)
+	database.DeleteAPIToken(t, resp.ID)
+}
+
+func TestCreateTokenExpires(t *testing.T) {
+	core.SetupTest(t)
+	expires := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	data := fmt.Sprintf(`{"name": "ci", "scopes": ["status-write"], "expires": "%s"}`, expires.Format(time.RFC3339))
+	w := CreateRequestRouterWithParams("POST", "/", bytes.NewBufferString(data), "", CreateTokenHandler, 1, "POST", "/")
+
+	var resp CreateTokenResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+	assert.True(t, expires.Equal(resp.Expires))
+	database.DeleteAPIToken(t, resp.ID)
+}
+
+func TestCreateTokenRevokedName(t *testing.T) {
+	core.SetupTest(t)
+	// token_1-3 is revoked so its name can be reused
+	data := `{"name": "token_1-3", "scopes": ["read-only"]}`
+	w := CreateRequestRouterWithParams("POST", "/", bytes.NewBufferString(data), "", CreateTokenHandler, 1, "POST", "/")
+
+	var resp CreateTokenResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+	assert.Equal(t, "token_1-3", resp.Name)
+	database.DeleteAPIToken(t, resp.ID)
+}
+
+func TestCreateTokenInvalid(t *testing.T) {
+	core.SetupTest(t)
+	expired := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	tooLong := time.Now().Add(400 * 24 * time.Hour).Format(time.RFC3339)
+	for data, msg := range map[string]string{
+		`{"scopes": ["read-only"]}`:                                             TokenMissingNameErr,
+		`{"name": "ci"}`:                                                        TokenMissingScopesErr,
+		`{"name": "ci", "scopes": ["admin"]}`:                                   "Invalid scope: admin",
+		`{"name": "token_1-1", "scopes": ["read-only"]}`:                        DuplicateTokenNameErr,
+		`{"name": "ci", "scopes": ["read-only"], "expires": "` + expired + `"}`: TokenExpiresErr,
+		`{"name": "ci", "scopes": ["read-only"], "expires": "` + tooLong + `"}`: TokenExpiresErr,
+	} {
+		w := CreateRequestRouterWithParams("POST", "/", bytes.NewBufferString(data), "", CreateTokenHandler, 1,
+			"POST", "/")
+
+		var errResp utils.ErrorResponse
+		CheckResponse(t, w, http.StatusBadRequest, &errResp)
+		assert.Equal(t, msg, errResp.Error, data)
+	}
+}
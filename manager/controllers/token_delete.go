@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/manager/middlewares"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type DeleteTokenResponse struct {
+	ID int64 `json:"id" example:"1"` // Revoked token ID
+}
+
+// @Summary Revoke an API token
+// @Description Revoke an API token, revoked tokens are kept for auditing
+// @ID tokenDelete
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param token_id path int true "Token ID"
+// @Success 200 {object} DeleteTokenResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /tokens/{token_id} [delete]
+func TokenDeleteHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	tokenIDstr := c.Param("token_id")
+	tokenID, err := strconv.ParseInt(tokenIDstr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{Error: "Invalid token_id: " + tokenIDstr})
+		return
+	}
+
+	query := database.Db.WithContext(base.Context).Model(models.APIToken{}).
+		Where("rh_account_id = ? AND id = ? AND revoked IS NULL", account, tokenID).
+		Update("revoked", time.Now())
+	if query.Error != nil {
+		LogAndRespError(c, query.Error, "Could not revoke token")
+		return
+	}
+	if query.RowsAffected == 0 {
+		LogAndRespNotFound(c, errors.New("no rows returned"), "token not found")
+		return
+	}
+
+	utils.Log("token_id", tokenID, "account_id", account).Info("API token revoked")
+	c.JSON(http.StatusOK, &DeleteTokenResponse{ID: tokenID})
+}
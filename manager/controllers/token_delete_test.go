@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenDelete(t *testing.T) {
+	core.SetupTest(t)
+	token := models.APIToken{RhAccountID: 1, Name: "to-revoke", TokenHash: "hash", Scopes: []byte(`["read-only"]`)}
+	assert.NoError(t, database.Db.Omit("created").Create(&token).Error)
+
+	w := CreateRequestRouterWithParams("DELETE", fmt.Sprintf("/%d", token.ID), nil, "", TokenDeleteHandler, 1,
+		"DELETE", "/:token_id")
+
+	var resp DeleteTokenResponse
+	CheckResponse(t, w, http.StatusOK, &resp)
+	assert.Equal(t, token.ID, resp.ID)
+	assert.NoError(t, database.Db.Where("id = ?", token.ID).Find(&token).Error)
+	assert.NotNil(t, token.Revoked)
+	database.DeleteAPIToken(t, token.ID)
+}
+
+func TestTokenDeleteNotFound(t *testing.T) {
+	core.SetupTest(t)
+	// revoked token, token of other account and non-existing token
+	for _, path := range []string{"/3", "/4", "/88888"} {
+		w := CreateRequestRouterWithParams("DELETE", path, nil, "", TokenDeleteHandler, 1, "DELETE", "/:token_id")
+
+		var errResp utils.ErrorResponse
+		CheckResponse(t, w, http.StatusNotFound, &errResp)
+		assert.Equal(t, "token not found", errResp.Error)
+	}
+}
+
+func TestTokenDeleteInvalid(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithParams("DELETE", "/invalid", nil, "", TokenDeleteHandler, 1, "DELETE", "/:token_id")
+
+	var errResp utils.ErrorResponse
+	CheckResponse(t, w, http.StatusBadRequest, &errResp)
+	assert.Equal(t, "Invalid token_id: invalid", errResp.Error)
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/manager/middlewares"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TokenItem struct {
+	ID        int64      `json:"id" example:"1"`                           // Unique token ID
+	Name      string     `json:"name" example:"ci-pipeline"`               // Token name
+	Scopes    []string   `json:"scopes" example:"read-only"`               // Granted scopes
+	Created   time.Time  `json:"created" example:"2022-01-01T00:00:00Z"`   // Creation time
+	CreatedBy *string    `json:"created_by" example:"jdoe"`                // User who created the token
+	Expires   *time.Time `json:"expires" example:"2022-04-01T00:00:00Z"`   // Expiration time
+	LastUsed  *time.Time `json:"last_used" example:"2022-02-01T00:00:00Z"` // Last use of the token
+	Revoked   *time.Time `json:"revoked" example:"2022-03-01T00:00:00Z"`   // Revocation time
+}
+
+type TokensResponse struct {
+	Data []TokenItem `json:"data"`
+}
+
+// @Summary Show me API tokens of my organization
+// @Description Show me API tokens of my organization, token values are never returned
+// @ID listTokens
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Success 200 {object} TokensResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /tokens [get]
+func TokensListHandler(c *gin.Context) {
+	account := c.GetInt(middlewares.KeyAccount)
+
+	var tokens []models.APIToken
+	err := database.Db.Where("rh_account_id = ?", account).Order("id").Find(&tokens).Error
+	if err != nil {
+		LogAndRespError(c, err, "Database error")
+		return
+	}
+
+	data := make([]TokenItem, len(tokens))
+	for i, t := range tokens {
+		data[i] = TokenItem{ID: t.ID, Name: t.Name, Created: t.Created, CreatedBy: t.CreatedBy,
+			Expires: t.Expires, LastUsed: t.LastUsed, Revoked: t.Revoked}
+		if err = json.Unmarshal(t.Scopes, &data[i].Scopes); err != nil {
+			LogAndRespError(c, err, "Invalid token scopes")
+			return
+		}
+	}
+	c.JSON(http.StatusOK, TokensResponse{Data: data})
+}
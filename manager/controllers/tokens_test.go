@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"app/base/core"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokensList(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithPath("GET", "/", nil, "", TokensListHandler, "/")
+
+	var output TokensResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, 3, len(output.Data))
+	assert.Equal(t, int64(1), output.Data[0].ID)
+	assert.Equal(t, "token_1-1", output.Data[0].Name)
+	assert.Equal(t, []string{"read-only"}, output.Data[0].Scopes)
+	assert.Equal(t, "user-1", *output.Data[0].CreatedBy)
+	assert.Nil(t, output.Data[0].Revoked)
+	assert.Equal(t, []string{"read-only", "baseline-write"}, output.Data[1].Scopes)
+	assert.NotNil(t, output.Data[1].LastUsed)
+	assert.NotNil(t, output.Data[2].Revoked)
+}
+
+func TestTokensListOtherAccount(t *testing.T) {
+	core.SetupTest(t)
+	w := CreateRequestRouterWithAccount("GET", "/", nil, "", TokensListHandler, "/", 3)
+
+	var output TokensResponse
+	CheckResponse(t, w, http.StatusOK, &output)
+	assert.Equal(t, 0, len(output.Data))
+}
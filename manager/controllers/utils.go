@@ -58,10 +58,8 @@ func ApplySort(c *gin.Context, tx *gorm.DB, fieldExprs database.AttrMap,
 	query := c.DefaultQuery("sort", defaultSort)
 	fields := strings.Split(query, ",")
 	var appliedFields []string
-	allowedFieldSet := map[string]bool{
-		"id": true,
-	}
-
+	// Only attributes with a sort expression are allowed, `id` included
+	allowedFieldSet := map[string]bool{}
 	for f := range fieldExprs {
 		allowedFieldSet[f] = true
 	}
@@ -619,6 +617,7 @@ func systemDBLookups2SystemItems(systems []SystemDBLookup) []SystemItem {
 		if err != nil {
 			utils.Log("err", err.Error(), "inventory_id", system.ID).Debug("system tags parsing failed")
 		}
+		system.Repos = parseSystemRepos(system.ReposJSON, system.ID)
 		data[i] = SystemItem{
 			Attributes: system.SystemItemAttributes,
 			ID:         system.ID,
@@ -650,7 +649,7 @@ func systemsIDs(systems []SystemsID) []string {
 	return ids
 }
 
-// Parse tags from TagsStr string attribute to Tags SystemTag array attribute.
+// Parse tags from TagsStr string attribute to Tags SystemTag array attribute, and repos from ReposJSON.
 // It's used in /*systems endpoints as we can not map this attribute directly from database query result.
 func parseAndFillTags(systems *[]SystemDBLookup) {
 	var err error
@@ -659,6 +658,7 @@ func parseAndFillTags(systems *[]SystemDBLookup) {
 		if err != nil {
 			utils.Log("err", err.Error(), "inventory_id", system.ID).Debug("system tags to export parsing failed")
 		}
+		(*systems)[i].Repos = parseSystemRepos(system.ReposJSON, system.ID)
 	}
 }
 
@@ -672,6 +672,14 @@ func systemAdvisoryItemAttributeParse(advisory SystemAdvisoryItemAttributes) Sys
 	return advisory
 }
 
+func parseSystemRepos(jsonb []byte, inventoryID string) SystemReposList {
+	repos, err := parseJSONList(jsonb)
+	if err != nil {
+		utils.Log("err", err.Error(), "inventory_id", inventoryID).Debug("system repos parsing failed")
+	}
+	return repos
+}
+
 func parseJSONList(jsonb []byte) ([]string, error) {
 	if jsonb == nil {
 		return []string{}, nil
@@ -49,7 +49,7 @@ func GetInventoryIDsToEvaluate(baselineID *int, accountID int,
 	if !configUpdated { // we just need to evaluate updated inventory IDs
 		inventoryAIDs = inventoryIDs2InventoryAIDs(accountID, updatedInventoryIDs)
 	} else { // config updated - we need to update all baseline inventory IDs and the added ones too
-		inventoryAIDs = getInventoryIDs(baselineID, accountID, updatedInventoryIDs)
+		inventoryAIDs = getInventoryIDs("baseline_id", baselineID, accountID, updatedInventoryIDs)
 	}
 
 	utils.Log("nInventoryIDs", len(inventoryAIDs), "accountID", accountID).
@@ -57,6 +57,26 @@ func GetInventoryIDsToEvaluate(baselineID *int, accountID int,
 	return inventoryAIDs
 }
 
+// Returns all systems of the template and the updated ones when the template content changes,
+// only the updated systems otherwise
+func GetTemplateInventoryIDsToEvaluate(templateID int, accountID int,
+	contentUpdated bool, updatedInventoryIDs []string) []mqueue.EvalData {
+	if !enableBaselineChangeEval {
+		return nil
+	}
+
+	var inventoryAIDs []mqueue.EvalData
+	if !contentUpdated {
+		inventoryAIDs = inventoryIDs2InventoryAIDs(accountID, updatedInventoryIDs)
+	} else {
+		inventoryAIDs = getInventoryIDs("template_id", &templateID, accountID, updatedInventoryIDs)
+	}
+
+	utils.Log("nInventoryIDs", len(inventoryAIDs), "accountID", accountID, "templateID", templateID).
+		Debug("Loaded template inventory IDs to evaluate")
+	return inventoryAIDs
+}
+
 func inventoryIDs2InventoryAIDs(accountID int, inventoryIDs []string) []mqueue.EvalData {
 	inventoryAIDs := make([]mqueue.EvalData, 0, len(inventoryIDs))
 	for _, v := range inventoryIDs {
@@ -65,11 +85,12 @@ func inventoryIDs2InventoryAIDs(accountID int, inventoryIDs []string) []mqueue.E
 	return inventoryAIDs
 }
 
-func getInventoryIDs(baselineID *int, accountID int, inventoryIDs []string) []mqueue.EvalData {
+// Returns inventory IDs of systems with the given baseline_id or template_id column value and the given ones
+func getInventoryIDs(column string, id *int, accountID int, inventoryIDs []string) []mqueue.EvalData {
 	var inventoryAIDs []mqueue.EvalData
 	query := database.Db.Model(&models.SystemPlatform{}).
 		Select("inventory_id, rh_account_id").
-		Where(map[string]interface{}{"rh_account_id": accountID, "baseline_id": baselineID})
+		Where(map[string]interface{}{"rh_account_id": accountID, column: id})
 
 	if len(inventoryIDs) > 0 {
 		query = query.Or("inventory_id IN (?) AND rh_account_id = ?", inventoryIDs, accountID)
@@ -79,7 +100,7 @@ func getInventoryIDs(baselineID *int, accountID int, inventoryIDs []string) []mq
 		Scan(&inventoryAIDs).Error
 	if err != nil {
 		utils.Log("err", err.Error()).
-			Error("Unable to load inventory IDs for baseline")
+			Error("Unable to load inventory IDs for " + column)
 	}
 	return inventoryAIDs
 }
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"app/base/database"
+	"app/base/utils"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// APITokenPrefix distinguishes API tokens created by org admins from other bearer tokens
+const APITokenPrefix = "patch_"
+const apiTokenLastUsedInterval = time.Minute
+
+var enableAPITokens = utils.GetBoolEnvOrDefault("ENABLE_API_TOKENS", true)
+
+var errInvalidAPIToken = errors.New("Invalid API token")
+
+// APITokenScopes maps API token scopes to granted permissions
+var APITokenScopes = map[string][]string{
+	"read-only":      {"patch:*:read"},
+	"baseline-write": {"patch:*:read", "patch:baseline:write"},
+	"status-write":   {"patch:*:read", "patch:status:write"},
+}
+
+// GenerateAPIToken returns new random API token, only its hash is stored
+func GenerateAPIToken() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return APITokenPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Returns permissions granted by the token scopes, unknown scopes grant nothing
+func apiTokenPermissions(scopes []string) []string {
+	permissions := []string{}
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		for _, p := range APITokenScopes[scope] {
+			if !seen[p] {
+				seen[p] = true
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	return permissions
+}
+
+type apiTokenRow struct {
+	ID       int64
+	Name     string
+	OrgID    string
+	Scopes   []byte
+	Expires  *time.Time
+	LastUsed *time.Time
+	Revoked  *time.Time
+}
+
+// Authenticates requests with API tokens stored in api_token table
+type apiTokenAuthenticator struct{}
+
+func (apiTokenAuthenticator) Authenticate(c *gin.Context) (*Principal, error) {
+	token := bearerToken(c)
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, nil
+	}
+
+	var rows []apiTokenRow
+	err := database.Db.Table("api_token t").
+		Select("t.id, t.name, ra.org_id, t.scopes, t.expires, t.last_used, t.revoked").
+		Joins("JOIN rh_account ra ON ra.id = t.rh_account_id").
+		Where("t.token_hash = ?", HashToken(token)).
+		Scan(&rows).Error
+	if err != nil {
+		utils.Log("err", err.Error()).Error("Unable to load API token")
+		return nil, errInvalidAPIToken
+	}
+	now := time.Now()
+	if len(rows) == 0 || rows[0].Revoked != nil || (rows[0].Expires != nil && now.After(*rows[0].Expires)) {
+		return nil, errInvalidAPIToken
+	}
+	row := rows[0]
+
+	var scopes []string
+	if err = json.Unmarshal(row.Scopes, &scopes); err != nil {
+		utils.Log("token_id", row.ID, "err", err.Error()).Error("Invalid API token scopes")
+		return nil, errInvalidAPIToken
+	}
+
+	if row.LastUsed == nil || now.Sub(*row.LastUsed) > apiTokenLastUsedInterval {
+		err = database.Db.Table("api_token").Where("id = ?", row.ID).Update("last_used", now).Error
+		if err != nil {
+			utils.Log("token_id", row.ID, "err", err.Error()).Warn("Unable to update API token last use")
+		}
+	}
+	utils.Log("token_name", row.Name, "token_id", row.ID, "org_id", row.OrgID, "method", c.Request.Method,
+		"path", c.Request.URL.Path).Info("API token used")
+	return &Principal{OrgID: row.OrgID, Name: row.Name, Permissions: permissionsAccess(apiTokenPermissions(scopes))}, nil
+}
@@ -0,0 +1,110 @@
+package middlewares
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/rbac"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokenPermissions(t *testing.T) {
+	perms := newRbacPerms(permissionsAccess(apiTokenPermissions([]string{"read-only"})))
+	assert.True(t, perms.allowed(resourceSystem, verbRead))
+	assert.True(t, perms.allowed(resourceBaseline, verbRead))
+	assert.False(t, perms.allowed(resourceBaseline, verbWrite))
+
+	permissions := apiTokenPermissions([]string{"read-only", "baseline-write", "unknown"})
+	assert.Equal(t, []string{"patch:*:read", "patch:baseline:write"}, permissions)
+	perms = newRbacPerms(permissionsAccess(permissions))
+	assert.True(t, perms.allowed(resourceBaseline, verbWrite))
+	assert.False(t, perms.allowed(resourceSystem, verbWrite))
+
+	perms = newRbacPerms(permissionsAccess(apiTokenPermissions([]string{"status-write"})))
+	assert.True(t, perms.allowed(resourceStatus, verbWrite))
+	assert.False(t, perms.allowed(resourceSystem, verbWrite))
+	assert.False(t, perms.allowed(resourceBaseline, verbWrite))
+}
+
+func TestGenerateAPIToken(t *testing.T) {
+	token1, err := GenerateAPIToken()
+	assert.NoError(t, err)
+	token2, err := GenerateAPIToken()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token1, APITokenPrefix))
+	assert.NotEqual(t, token1, token2)
+}
+
+func TestAPITokenAuthenticatorOtherToken(t *testing.T) {
+	for _, header := range []string{"", "Bearer secret-token", "Bearer a.b.c", "Basic patch_token"} {
+		principal, err := apiTokenAuthenticator{}.Authenticate(testAuthContext("Authorization", header))
+		assert.NoError(t, err, header)
+		assert.Nil(t, principal, header)
+	}
+}
+
+func TestAPITokenAuthenticator(t *testing.T) {
+	testSetup(t)
+	principal, err := apiTokenAuthenticator{}.Authenticate(testAuthContext("Authorization", "Bearer patch_read-token"))
+	assert.NoError(t, err)
+	assert.Equal(t, "org_1", principal.OrgID)
+	assert.Equal(t, "token_1-1", principal.Name)
+	assert.False(t, principal.OrgAdmin)
+	assert.Equal(t, []rbac.Access{{Permission: "patch:*:read"}}, principal.Permissions)
+
+	var token models.APIToken
+	assert.NoError(t, database.Db.Where("id = 1").Find(&token).Error)
+	assert.NotNil(t, token.LastUsed)
+	assert.True(t, time.Since(*token.LastUsed) < time.Minute)
+	assert.NoError(t, database.Db.Model(&token).Update("last_used", nil).Error)
+}
+
+func TestAPITokenAuthenticatorInvalid(t *testing.T) {
+	testSetup(t)
+	for _, token := range []string{"patch_expired-token", "patch_revoked-token", "patch_unknown-token"} {
+		principal, err := apiTokenAuthenticator{}.Authenticate(testAuthContext("Authorization", "Bearer "+token))
+		assert.Equal(t, errInvalidAPIToken, err, token)
+		assert.Nil(t, principal, token)
+	}
+}
+
+func TestOrgAdmin(t *testing.T) {
+	for principal, status := range map[*Principal]int{
+		nil:                             http.StatusForbidden,
+		{Name: "user"}:                  http.StatusForbidden,
+		{Name: "admin", OrgAdmin: true}: http.StatusOK,
+	} {
+		principal := principal
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if principal != nil {
+				c.Set(KeyPrincipal, principal)
+			}
+		})
+		router.Use(OrgAdmin())
+		router.GET("/", okHandler)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code)
+	}
+}
+
+func TestCanGrant(t *testing.T) {
+	c := testAuthContext("", "")
+	assert.True(t, CanGrant(c, APITokenScopes["status-write"]))
+
+	c.Set(keyRbacPerms, newRbacPerms([]rbac.Access{{Permission: "patch:*:read"}, {Permission: "patch:baseline:*"}}))
+	assert.True(t, CanGrant(c, APITokenScopes["read-only"]))
+	assert.True(t, CanGrant(c, APITokenScopes["baseline-write"]))
+	assert.False(t, CanGrant(c, APITokenScopes["status-write"]))
+
+	c.Set(keyRbacPerms, rbacPerms{granted: map[string]bool{"*:*": true}, scope: &database.SystemScope{}})
+	assert.False(t, CanGrant(c, APITokenScopes["read-only"]))
+}
@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"app/base"
+	"app/base/utils"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	jose "gopkg.in/go-jose/go-jose.v2"
+	"gopkg.in/go-jose/go-jose.v2/jwt"
+)
+
+const jwtLeeway = 30 * time.Second
+const jwksReloadInterval = time.Minute
+
+var errInvalidJWT = errors.New("Invalid bearer token")
+
+// JWKS is fetched while verifying token with unknown key id so the request must not hang on unresponsive provider
+var jwksTimeout = time.Duration(utils.GetIntEnvOrDefault("OIDC_JWKS_TIMEOUT_SEC", 10)) * time.Second
+var jwksClient = &http.Client{Timeout: jwksTimeout}
+
+// Asymmetric JWS algorithms accepted for token signatures
+var jwtAlgorithms = map[string]bool{
+	string(jose.RS256): true, string(jose.RS384): true, string(jose.RS512): true,
+	string(jose.PS256): true, string(jose.PS384): true, string(jose.PS512): true,
+	string(jose.ES256): true, string(jose.ES384): true, string(jose.ES512): true,
+}
+
+// Parses JSON web key set, invalid and other than public keys are skipped
+func parseJWKS(data []byte) (map[string]jose.JSONWebKey, error) {
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]jose.JSONWebKey, len(set.Keys))
+	for _, k := range set.Keys {
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(k); err != nil {
+			utils.Log("err", err.Error()).Warn("Skipping JWKS key")
+			continue
+		}
+		if !key.IsPublic() {
+			utils.Log("kid", key.KeyID).Warn("Skipping JWKS key which is not public")
+			continue
+		}
+		keys[key.KeyID] = key
+	}
+	return keys, nil
+}
+
+// Keys used to sign tokens, loaded from file:// or http(s):// JWKS URL.
+// Failed load keeps the previously loaded keys.
+type jwksKeys struct {
+	url    string
+	lock   sync.RWMutex
+	keys   map[string]jose.JSONWebKey
+	loaded time.Time
+}
+
+func (j *jwksKeys) fetch() (map[string]jose.JSONWebKey, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(j.url, "file://") {
+		data, err = ioutil.ReadFile(strings.TrimPrefix(j.url, "file://"))
+	} else {
+		var req *http.Request
+		req, err = http.NewRequestWithContext(base.Context, http.MethodGet, j.url, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res *http.Response
+		res, err = jwksClient.Do(req)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to fetch JWKS")
+		}
+		defer res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return nil, errors.Errorf("unable to fetch JWKS: %s", res.Status)
+		}
+		data, err = ioutil.ReadAll(res.Body)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read JWKS")
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse JWKS")
+	}
+	if len(keys) == 0 {
+		// e.g. error page of the provider, keep verifying tokens with the current keys
+		return nil, errors.New("JWKS contains no usable key")
+	}
+	return keys, nil
+}
+
+func (j *jwksKeys) load() error {
+	keys, err := j.fetch()
+	if err != nil {
+		return err
+	}
+	j.lock.Lock()
+	j.keys = keys
+	j.loaded = time.Now()
+	j.lock.Unlock()
+	return nil
+}
+
+// Claims reload of the keys, at most one reload is started per reload interval
+func (j *jwksKeys) startReload() bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if time.Since(j.loaded) <= jwksReloadInterval {
+		return false
+	}
+	j.loaded = time.Now()
+	return true
+}
+
+func (j *jwksKeys) lookup(kid string) (jose.JSONWebKey, bool) {
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// Returns signing key, keys are reloaded when unknown key id is requested (e.g. after key rotation).
+// Keys are fetched without holding the lock so other requests are verified with the current keys meanwhile.
+func (j *jwksKeys) get(kid string) (*jose.JSONWebKey, error) {
+	key, ok := j.lookup(kid)
+	if !ok && j.startReload() {
+		if err := j.load(); err != nil {
+			return nil, err
+		}
+		key, ok = j.lookup(kid)
+	}
+	if !ok {
+		return nil, errors.New("unknown signing key " + kid)
+	}
+	return &key, nil
+}
+
+type oidcAuthenticator struct {
+	issuer           string
+	audience         string
+	orgIDClaim       string
+	permissionsClaim string
+	keys             *jwksKeys
+}
+
+func newOIDCAuthenticator() *oidcAuthenticator {
+	a := oidcAuthenticator{
+		issuer:           utils.FailIfEmpty(utils.Getenv("OIDC_ISSUER", ""), "OIDC_ISSUER"),
+		audience:         utils.Getenv("OIDC_AUDIENCE", ""),
+		orgIDClaim:       utils.Getenv("OIDC_ORG_ID_CLAIM", "org_id"),
+		permissionsClaim: utils.Getenv("OIDC_PERMISSIONS_CLAIM", "permissions"),
+		keys:             &jwksKeys{url: utils.FailIfEmpty(utils.Getenv("OIDC_JWKS_URL", ""), "OIDC_JWKS_URL")},
+	}
+	if err := a.keys.load(); err != nil {
+		panic(err)
+	}
+	return &a
+}
+
+// Validates token signature and standard claims, returns all token claims
+func (a *oidcAuthenticator) verify(token string, now time.Time) (*jwt.Claims, map[string]json.RawMessage, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil || len(parsed.Headers) != 1 {
+		return nil, nil, errInvalidJWT
+	}
+	header := parsed.Headers[0]
+	if !jwtAlgorithms[header.Algorithm] {
+		return nil, nil, errors.New("unsupported algorithm " + header.Algorithm)
+	}
+	key, err := a.keys.get(header.KeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var claims jwt.Claims
+	var raw map[string]json.RawMessage
+	if err = parsed.Claims(key, &claims, &raw); err != nil {
+		return nil, nil, errors.Wrap(err, "invalid token")
+	}
+
+	if claims.Expiry == nil {
+		return nil, nil, errors.New("token expiration is missing")
+	}
+	expected := jwt.Expected{Issuer: a.issuer, Time: now}
+	if a.audience != "" {
+		expected.Audience = jwt.Audience{a.audience}
+	}
+	if err = claims.ValidateWithLeeway(expected, jwtLeeway); err != nil {
+		return nil, nil, err
+	}
+	return &claims, raw, nil
+}
+
+// Bearer tokens which are not JWTs are left to other authenticators
+func (a *oidcAuthenticator) Authenticate(c *gin.Context) (*Principal, error) {
+	token := bearerToken(c)
+	if strings.Count(token, ".") != 2 {
+		return nil, nil
+	}
+	claims, raw, err := a.verify(token, time.Now())
+	if err != nil {
+		utils.Log("err", err.Error()).Debug("Invalid bearer token")
+		return nil, errInvalidJWT
+	}
+
+	var orgID string
+	if json.Unmarshal(raw[a.orgIDClaim], &orgID) != nil || orgID == "" {
+		return nil, errors.New("Missing " + a.orgIDClaim + " claim")
+	}
+	permissions := []string{}
+	if p, ok := raw[a.permissionsClaim]; ok {
+		if err = json.Unmarshal(p, &permissions); err != nil {
+			return nil, errors.New("Invalid " + a.permissionsClaim + " claim")
+		}
+	}
+	return &Principal{OrgID: orgID, Name: claims.Subject, Permissions: permissionsAccess(permissions)}, nil
+}
@@ -0,0 +1,206 @@
+package middlewares
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	jose "gopkg.in/go-jose/go-jose.v2"
+	"gopkg.in/go-jose/go-jose.v2/jwt"
+)
+
+const testIssuer = "https://sso.example.com/realms/patch"
+
+var testRSAKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+var testECKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+var testHMACKey = []byte("0123456789abcdef0123456789abcdef")
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Symmetric and invalid keys are skipped when JWKS is loaded
+func testJWKS() string {
+	jwks, _ := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &testRSAKey.PublicKey, KeyID: "rsa-1"},
+		{Key: &testECKey.PublicKey, KeyID: "ec-1"},
+		{Key: testHMACKey, KeyID: "hmac-1"},
+	}})
+	return strings.Replace(string(jwks), `"keys":[`, `"keys":[{"kty":"RSA","kid":"broken"},`, 1)
+}
+
+func signTestJWT(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	keys := map[string]interface{}{"RS256": testRSAKey, "ES256": testECKey, "HS256": testHMACKey}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.SignatureAlgorithm(alg), Key: keys[alg]},
+		(&jose.SignerOptions{}).WithHeader("kid", kid))
+	assert.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	assert.NoError(t, err)
+	return token
+}
+
+func testOIDCAuthenticator(t *testing.T) *oidcAuthenticator {
+	dir, path := writeTempFile(t, "jwks.json", testJWKS())
+	defer os.RemoveAll(dir)
+	a := oidcAuthenticator{issuer: testIssuer, audience: "patch", orgIDClaim: "org_id",
+		permissionsClaim: "permissions", keys: &jwksKeys{url: "file://" + path}}
+	assert.NoError(t, a.keys.load())
+	return &a
+}
+
+func testClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":         testIssuer,
+		"sub":         "user-1",
+		"aud":         []string{"account", "patch"},
+		"exp":         time.Now().Add(time.Hour).Unix(),
+		"org_id":      "org_1",
+		"permissions": []string{"patch:system:read", "patch:baseline:write"},
+	}
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	a := testOIDCAuthenticator(t)
+	for alg, kid := range map[string]string{"RS256": "rsa-1", "ES256": "ec-1"} {
+		token := signTestJWT(t, alg, kid, testClaims())
+		principal, err := a.Authenticate(testAuthContext("Authorization", "Bearer "+token))
+		assert.NoError(t, err, alg)
+		assert.Equal(t, "org_1", principal.OrgID)
+		assert.Equal(t, "user-1", principal.Name)
+		assert.Equal(t, 2, len(principal.Permissions))
+
+		perms := newRbacPerms(principal.Permissions)
+		assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+		assert.True(t, perms.allowed(resourceBaseline, verbWrite))
+		assert.False(t, perms.allowed(resourceSystem, verbWrite))
+	}
+}
+
+func TestOIDCAuthenticatorNoToken(t *testing.T) {
+	a := testOIDCAuthenticator(t)
+	principal, err := a.Authenticate(testAuthContext("Authorization", "Bearer api-token"))
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+	principal, err = a.Authenticate(testAuthContext("", ""))
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+}
+
+func TestOIDCAuthenticatorInvalid(t *testing.T) {
+	a := testOIDCAuthenticator(t)
+	invalid := map[string]func(claims map[string]interface{}){
+		"issuer":   func(claims map[string]interface{}) { claims["iss"] = "https://other.example.com" },
+		"audience": func(claims map[string]interface{}) { claims["aud"] = "account" },
+		"expired":  func(claims map[string]interface{}) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no exp":   func(claims map[string]interface{}) { delete(claims, "exp") },
+		"nbf":      func(claims map[string]interface{}) { claims["nbf"] = time.Now().Add(time.Hour).Unix() },
+	}
+	for name, modify := range invalid {
+		claims := testClaims()
+		modify(claims)
+		_, _, err := a.verify(signTestJWT(t, "RS256", "rsa-1", claims), time.Now())
+		assert.Error(t, err, name)
+	}
+
+	// signed by other key than declared in header
+	_, _, err := a.verify(signTestJWT(t, "ES256", "rsa-1", testClaims()), time.Now())
+	assert.Error(t, err)
+	// symmetric keys are not trusted
+	_, _, err = a.verify(signTestJWT(t, "HS256", "hmac-1", testClaims()), time.Now())
+	assert.Error(t, err)
+	// tampered payload
+	parts := strings.Split(signTestJWT(t, "RS256", "rsa-1", testClaims()), ".")
+	claims := testClaims()
+	claims["org_id"] = "org_2"
+	payload, _ := json.Marshal(claims)
+	_, _, err = a.verify(parts[0]+"."+encodeSegment(payload)+"."+parts[2], time.Now())
+	assert.Error(t, err)
+
+	claims = testClaims()
+	delete(claims, "org_id")
+	_, err = a.Authenticate(testAuthContext("Authorization", "Bearer "+signTestJWT(t, "RS256", "rsa-1", claims)))
+	assert.Error(t, err)
+}
+
+func TestOIDCAuthenticatorUnknownKey(t *testing.T) {
+	a := testOIDCAuthenticator(t)
+	_, _, err := a.verify(signTestJWT(t, "RS256", "rsa-2", testClaims()), time.Now())
+	assert.Error(t, err)
+	assert.Equal(t, 2, len(a.keys.keys))
+}
+
+func TestJWKSKeysReload(t *testing.T) {
+	jwks, _ := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &testECKey.PublicKey, KeyID: "ec-1"}}})
+	dir, path := writeTempFile(t, "jwks.json", string(jwks))
+	defer os.RemoveAll(dir)
+	keys := jwksKeys{url: "file://" + path}
+	assert.NoError(t, keys.load())
+
+	// rotated keys are not reloaded sooner than after reload interval
+	assert.NoError(t, ioutil.WriteFile(path, []byte(testJWKS()), 0600))
+	_, err := keys.get("rsa-1")
+	assert.Error(t, err)
+
+	keys.loaded = time.Now().Add(-2 * jwksReloadInterval)
+	key, err := keys.get("rsa-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "rsa-1", key.KeyID)
+	_, err = keys.get("rsa-2")
+	assert.Error(t, err)
+}
+
+func TestJWKSKeysFetchFailed(t *testing.T) {
+	status, body := http.StatusOK, testJWKS()
+	router := gin.New()
+	router.GET("/jwks", func(c *gin.Context) {
+		c.String(status, body)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	keys := jwksKeys{url: server.URL + "/jwks"}
+	assert.NoError(t, keys.load())
+	assert.Equal(t, 2, len(keys.keys))
+
+	// previous keys are kept when provider fails or returns no usable key
+	status, body = http.StatusServiceUnavailable, `{"keys":[{"kty":"RSA","kid":"rsa-1"}]}`
+	assert.Error(t, keys.load())
+	status, body = http.StatusOK, `{"keys":[]}`
+	assert.Error(t, keys.load())
+	assert.Equal(t, 2, len(keys.keys))
+	_, ok := keys.lookup("rsa-1")
+	assert.True(t, ok)
+}
+
+func TestJWKSKeysFetchTimeout(t *testing.T) {
+	done := make(chan struct{})
+	router := gin.New()
+	router.GET("/jwks", func(c *gin.Context) {
+		<-done
+		c.String(http.StatusOK, testJWKS())
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+	defer close(done)
+
+	client := jwksClient
+	jwksClient = &http.Client{Timeout: 100 * time.Millisecond}
+	defer func() { jwksClient = client }()
+
+	keys := jwksKeys{url: server.URL + "/jwks"}
+	tStart := time.Now()
+	assert.Error(t, keys.load())
+	assert.Less(t, int64(time.Since(tStart)), int64(time.Second))
+}
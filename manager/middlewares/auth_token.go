@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"app/base/rbac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bearerPrefix = "Bearer "
+
+// Static API token loaded from AUTH_TOKENS_FILE, only SHA-256 hash of the token is stored
+type staticToken struct {
+	Name        string   `json:"name"`
+	OrgID       string   `json:"org_id"`
+	TokenHash   string   `json:"token_sha256"`
+	Permissions []string `json:"permissions"`
+}
+
+type staticTokenAuthenticator struct {
+	tokens []staticToken
+}
+
+func newStaticTokenAuthenticator(path string) *staticTokenAuthenticator {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	var tokens []staticToken
+	if err = json.Unmarshal(data, &tokens); err != nil {
+		panic(err)
+	}
+	for _, t := range tokens {
+		if t.OrgID == "" || len(t.TokenHash) != sha256.Size*2 {
+			panic("invalid static token " + t.Name)
+		}
+	}
+	return &staticTokenAuthenticator{tokens: tokens}
+}
+
+// Returns token from `Authorization: Bearer <token>` header
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):])
+}
+
+// HashToken returns SHA-256 hash of the token in hex format
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func permissionsAccess(permissions []string) []rbac.Access {
+	access := make([]rbac.Access, len(permissions))
+	for i, p := range permissions {
+		access[i] = rbac.Access{Permission: p}
+	}
+	return access
+}
+
+// Unknown bearer tokens are left to other authenticators, e.g. JWTs are validated by OIDC authenticator
+func (a *staticTokenAuthenticator) Authenticate(c *gin.Context) (*Principal, error) {
+	token := bearerToken(c)
+	if token == "" {
+		return nil, nil
+	}
+	hash := []byte(HashToken(token))
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(strings.ToLower(t.TokenHash))) == 1 {
+			return &Principal{OrgID: t.OrgID, Name: t.Name, Permissions: permissionsAccess(t.Permissions)}, nil
+		}
+	}
+	return nil, nil
+}
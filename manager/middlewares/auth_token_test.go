@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAuthContext(header, value string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	if header != "" {
+		c.Request.Header.Set(header, value)
+	}
+	return c
+}
+
+// Write file to a temporary directory, returned directory has to be removed by caller
+func writeTempFile(t *testing.T, name, content string) (dir, path string) {
+	dir, err := ioutil.TempDir("", "auth")
+	assert.NoError(t, err)
+	path = filepath.Join(dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return dir, path
+}
+
+func testStaticTokenAuthenticator(t *testing.T) *staticTokenAuthenticator {
+	dir, path := writeTempFile(t, "tokens.json", `[{"name": "ci", "org_id": "org_1", "token_sha256": "`+
+		HashToken("secret-token")+`", "permissions": ["patch:*:read"]}]`)
+	defer os.RemoveAll(dir)
+	return newStaticTokenAuthenticator(path)
+}
+
+func TestStaticTokenAuthenticator(t *testing.T) {
+	a := testStaticTokenAuthenticator(t)
+
+	principal, err := a.Authenticate(testAuthContext("Authorization", "Bearer secret-token"))
+	assert.NoError(t, err)
+	assert.Equal(t, "org_1", principal.OrgID)
+	assert.Equal(t, "ci", principal.Name)
+	assert.Equal(t, "patch:*:read", principal.Permissions[0].Permission)
+}
+
+func TestStaticTokenAuthenticatorUnknown(t *testing.T) {
+	a := testStaticTokenAuthenticator(t)
+
+	principal, err := a.Authenticate(testAuthContext("Authorization", "Bearer other-token"))
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+	principal, err = a.Authenticate(testAuthContext("Authorization", "Basic secret-token"))
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+}
+
+func TestStaticTokenAuthenticatorInvalidFile(t *testing.T) {
+	dir, path := writeTempFile(t, "tokens.json", `[{"name": "ci", "token_sha256": "secret"}]`)
+	defer os.RemoveAll(dir)
+	assert.Panics(t, func() { newStaticTokenAuthenticator(path) })
+}
+
+func TestChainAuthenticator(t *testing.T) {
+	a := testStaticTokenAuthenticator(t)
+	router := gin.New()
+	router.Use(chainAuthenticator("Missing or invalid credentials", identityAuthenticator{}, a))
+	router.GET("/", okHandler)
+
+	for header, status := range map[string]int{
+		"":                       http.StatusUnauthorized,
+		"Bearer other-token":     http.StatusUnauthorized,
+		"Bearer not.a.jwt-token": http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", header)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, header)
+		assert.Contains(t, w.Body.String(), "Missing or invalid credentials")
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("x-rh-identity", "invalid")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid x-rh-identity header")
+}
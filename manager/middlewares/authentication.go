@@ -3,6 +3,7 @@ package middlewares
 import (
 	"app/base/database"
 	"app/base/models"
+	"app/base/rbac"
 	"app/base/utils"
 	"net/http"
 	"strconv"
@@ -15,10 +16,19 @@ import (
 )
 
 const KeyAccount = "account"
+const KeyPermissions = "permissions"
+const KeyPrincipal = "principal"
 const UIReferer = "console.redhat.com"
 const APISource = "API"
 const UISource = "UI"
 
+// Names of authenticators configured by AUTHENTICATORS variable
+const (
+	identityAuthName = "identity"
+	tokenAuthName    = "token"
+	oidcAuthName     = "oidc"
+)
+
 var AccountIDCache = struct {
 	Values map[string]int
 	Lock   sync.Mutex
@@ -69,35 +79,122 @@ func findAccount(c *gin.Context, orgID string) bool {
 	return true
 }
 
+// Principal is the authenticated caller of the API
+type Principal struct {
+	OrgID    string
+	Name     string
+	OrgAdmin bool
+	// Permissions granted by the authenticator, nil when permissions are resolved by RBAC service
+	Permissions []rbac.Access
+}
+
+// Authenticator verifies credentials of the request,
+// nil principal is returned when the request doesn't contain credentials handled by the authenticator
+type Authenticator interface {
+	Authenticate(c *gin.Context) (*Principal, error)
+}
+
 func PublicAuthenticator() gin.HandlerFunc {
 	devModeEnabled := utils.GetBoolEnvOrDefault("ENABLE_DEV_MODE", false)
 	if devModeEnabled {
 		accountID := utils.GetIntEnvOrDefault("DEV_ACCOUNT_ID", 1)
 		return MockAuthenticator(accountID)
 	}
-	return headerAuthenticator()
+
+	names := strings.Split(utils.Getenv("AUTHENTICATORS", identityAuthName), ",")
+	if len(names) == 1 && names[0] == identityAuthName {
+		return headerAuthenticator()
+	}
+	authenticators := make([]Authenticator, 0, len(names))
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case identityAuthName:
+			authenticators = append(authenticators, identityAuthenticators()...)
+		case tokenAuthName:
+			authenticators = append(authenticators, newStaticTokenAuthenticator(
+				utils.FailIfEmpty(utils.Getenv("AUTH_TOKENS_FILE", ""), "AUTH_TOKENS_FILE")))
+		case oidcAuthName:
+			authenticators = append(authenticators, newOIDCAuthenticator())
+		default:
+			panic("unknown authenticator: " + name)
+		}
+	}
+	return chainAuthenticator("Missing or invalid credentials", authenticators...)
 }
 
-func headerAuthenticator() gin.HandlerFunc {
+// Authenticates request with the first authenticator recognizing its credentials
+func chainAuthenticator(missingMsg string, authenticators ...Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		identStr := c.GetHeader("x-rh-identity")
-		if identStr == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.ErrorResponse{Error: "Missing x-rh-identity header"})
+		for _, a := range authenticators {
+			principal, err := a.Authenticate(c)
+			if err != nil {
+				utils.Log("err", err.Error()).Debug("Authentication failed")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, utils.ErrorResponse{Error: err.Error()})
+				return
+			}
+			if principal == nil {
+				continue
+			}
+			utils.Log("principal", principal.Name, "org_id", principal.OrgID).Trace("Principal authenticated")
+			c.Set(KeyPrincipal, principal)
+			if principal.Permissions != nil {
+				c.Set(KeyPermissions, principal.Permissions)
+			}
+			if findAccount(c, principal.OrgID) {
+				c.Next()
+			}
 			return
 		}
-		utils.Log("ident", identStr).Trace("Identity retrieved")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, utils.ErrorResponse{Error: missingMsg})
+	}
+}
 
-		ident, err := utils.ParseIdentity(identStr)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.ErrorResponse{Error: "Invalid x-rh-identity header"})
-			return
-		}
-		if findAccount(c, ident.OrgID) {
-			c.Next()
+// Identity header authenticator, API tokens created by org admins are accepted alongside the header
+func identityAuthenticators() []Authenticator {
+	if enableAPITokens {
+		return []Authenticator{identityAuthenticator{}, apiTokenAuthenticator{}}
+	}
+	return []Authenticator{identityAuthenticator{}}
+}
+
+func headerAuthenticator() gin.HandlerFunc {
+	return chainAuthenticator("Missing x-rh-identity header", identityAuthenticators()...)
+}
+
+// GetPrincipal returns authenticated caller, nil when authentication is mocked
+func GetPrincipal(c *gin.Context) *Principal {
+	if principal, ok := c.Get(KeyPrincipal); ok {
+		return principal.(*Principal)
+	}
+	return nil
+}
+
+// OrgAdmin allows only organization administrators authenticated with x-rh-identity header
+func OrgAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := GetPrincipal(c)
+		if principal == nil || !principal.OrgAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, utils.ErrorResponse{Error: "Organization administrator required"})
 		}
 	}
 }
 
+type identityAuthenticator struct{}
+
+func (identityAuthenticator) Authenticate(c *gin.Context) (*Principal, error) {
+	identStr := c.GetHeader("x-rh-identity")
+	if identStr == "" {
+		return nil, nil
+	}
+	utils.Log("ident", identStr).Trace("Identity retrieved")
+
+	ident, err := utils.ParseIdentity(identStr)
+	if err != nil {
+		return nil, errors.New("Invalid x-rh-identity header")
+	}
+	return &Principal{OrgID: ident.OrgID, Name: ident.User.Username, OrgAdmin: ident.User.OrgAdmin}, nil
+}
+
 // Check referer type and identify caller source
 func CheckReferer() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -133,6 +230,7 @@ func MockAuthenticator(account int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		utils.Log("account_id", account).Warn("using mocking account id")
 		c.Set(KeyAccount, account)
+		c.Set(KeyPrincipal, &Principal{Name: "mock", OrgAdmin: true})
 		c.Next()
 	}
 }
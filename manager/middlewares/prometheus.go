@@ -29,9 +29,32 @@ var callerSourceCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
 	Name:      "caller_source",
 }, []string{"source", "account"})
 
+var rbacCacheCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Help:      "RBAC permissions cache lookups by result (hit, stale, miss)",
+	Namespace: "patchman_engine",
+	Subsystem: "manager",
+	Name:      "rbac_cache",
+}, []string{"result"})
+
+var rbacRequestDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Help:      "RBAC request durations",
+	Namespace: "patchman_engine",
+	Subsystem: "manager",
+	Name:      "rbac_request_duration_seconds",
+	Buckets:   prometheus.DefBuckets,
+})
+
+var rateLimitedCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Help:      "Requests rejected by rate limiter by route class",
+	Namespace: "patchman_engine",
+	Subsystem: "manager",
+	Name:      "rate_limited",
+}, []string{"class"})
+
 // Create and configure Prometheus middleware to expose metrics
 func Prometheus() *ginprometheus.Prometheus {
-	prometheus.MustRegister(serviceErrorCnt, requestDurations, callerSourceCnt)
+	prometheus.MustRegister(serviceErrorCnt, requestDurations, callerSourceCnt, rbacCacheCnt, rbacRequestDuration,
+		rateLimitedCnt)
 
 	p := ginprometheus.NewPrometheus("patchman_engine")
 	p.MetricsPath = utils.Cfg.MetricsPath
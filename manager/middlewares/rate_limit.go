@@ -0,0 +1,226 @@
+package middlewares
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Route classes with separate rate limit budgets
+const (
+	routeClassList   = "list"
+	routeClassExport = "export"
+	routeClassWrite  = "write"
+)
+
+var rateLimitDefaults = map[string]rateLimitConfig{
+	routeClassList: {
+		RequestsPerMinute: utils.GetIntEnvOrDefault("RATE_LIMIT_LIST_PER_MIN", 600),
+		Burst:             utils.GetIntEnvOrDefault("RATE_LIMIT_LIST_BURST", 100),
+	},
+	routeClassExport: {
+		RequestsPerMinute: utils.GetIntEnvOrDefault("RATE_LIMIT_EXPORT_PER_MIN", 30),
+		Burst:             utils.GetIntEnvOrDefault("RATE_LIMIT_EXPORT_BURST", 5),
+	},
+	routeClassWrite: {
+		RequestsPerMinute: utils.GetIntEnvOrDefault("RATE_LIMIT_WRITE_PER_MIN", 120),
+		Burst:             utils.GetIntEnvOrDefault("RATE_LIMIT_WRITE_BURST", 20),
+	},
+}
+
+type rateLimitConfig struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// Per-account overrides of the default configs by route class
+type rateLimitOverrides map[int]map[string]rateLimitConfig
+
+type tokenBucket struct {
+	lock   sync.Mutex
+	config rateLimitConfig
+	tokens float64
+	last   time.Time
+}
+
+// Result of taking a token from the bucket
+type rateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // time until next request is allowed
+	Reset      time.Duration // time until the bucket is full
+}
+
+// Refills the bucket and takes one token if available
+func (b *tokenBucket) take(config rateLimitConfig, now time.Time) rateLimitResult {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	rate := float64(config.RequestsPerMinute) / 60 // tokens per second
+	if b.last.IsZero() || b.config != config {
+		if b.last.IsZero() || b.tokens > float64(config.Burst) {
+			b.tokens = float64(config.Burst)
+		}
+		b.config = config
+	} else {
+		b.tokens = math.Min(float64(config.Burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+
+	res := rateLimitResult{Limit: config.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((float64(config.Burst) - b.tokens) / rate * float64(time.Second))
+	return res
+}
+
+type rateLimiter struct {
+	defaults        map[string]rateLimitConfig
+	buckets         *lru.Cache
+	lock            sync.Mutex
+	overrides       rateLimitOverrides
+	overridesLoaded time.Time
+	overridesTTL    time.Duration
+	loadOverrides   func() (rateLimitOverrides, error)
+}
+
+func newRateLimiter(defaults map[string]rateLimitConfig, size int, overridesTTL time.Duration,
+	loadOverrides func() (rateLimitOverrides, error)) *rateLimiter {
+	buckets, err := lru.New(size)
+	if err != nil {
+		panic(err)
+	}
+	return &rateLimiter{defaults: defaults, buckets: buckets, overridesTTL: overridesTTL,
+		loadOverrides: loadOverrides}
+}
+
+// Loads overrides configured in rate_limit table
+func loadRateLimitOverrides() (rateLimitOverrides, error) {
+	var rows []models.RateLimit
+	if err := database.Db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	overrides := rateLimitOverrides{}
+	for _, r := range rows {
+		if overrides[r.RhAccountID] == nil {
+			overrides[r.RhAccountID] = map[string]rateLimitConfig{}
+		}
+		overrides[r.RhAccountID][r.RouteClass] = rateLimitConfig{
+			RequestsPerMinute: r.RequestsPerMinute,
+			Burst:             r.Burst,
+		}
+	}
+	return overrides, nil
+}
+
+// Claims reload of the overrides when they are older than TTL, at most one reload runs at a time
+func (l *rateLimiter) startOverridesReload(now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if now.Sub(l.overridesLoaded) <= l.overridesTTL {
+		return false
+	}
+	l.overridesLoaded = now
+	return true
+}
+
+// Returns account config of the route class, overrides are reloaded periodically and kept when reload fails.
+// Overrides are loaded without holding the lock, meanwhile other requests use the previous overrides.
+func (l *rateLimiter) config(account int, class string, now time.Time) rateLimitConfig {
+	if l.startOverridesReload(now) {
+		overrides, err := l.loadOverrides()
+		if err != nil {
+			utils.Log("err", err.Error()).Error("Unable to load rate limit overrides")
+		} else {
+			l.lock.Lock()
+			l.overrides = overrides
+			l.lock.Unlock()
+		}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if config, ok := l.overrides[account][class]; ok {
+		return config
+	}
+	return l.defaults[class]
+}
+
+func (l *rateLimiter) take(account int, user, class string, now time.Time) rateLimitResult {
+	config := l.config(account, class, now)
+	key := strconv.Itoa(account) + ":" + user + ":" + class
+
+	l.lock.Lock()
+	bucket := &tokenBucket{}
+	if val, ok := l.buckets.Get(key); ok {
+		bucket = val.(*tokenBucket)
+	} else {
+		l.buckets.Add(key, bucket)
+	}
+	l.lock.Unlock()
+	return bucket.take(config, now)
+}
+
+// Returns route class of the request, POST requests reading data use list budget
+func routeClass(fullPath, handlerName, method string) string {
+	if _, verb := requiredPermission(fullPath, handlerName, method); verb == verbWrite {
+		return routeClassWrite
+	}
+	if strings.Contains(fullPath, "/export/") || strings.HasSuffix(fullPath, "/export") {
+		return routeClassExport
+	}
+	return routeClassList
+}
+
+func durationSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// RateLimit limits requests of each user of the account by route class with token bucket.
+// Buckets are kept in memory of each manager pod, so N replicas allow up to N times the configured budget.
+func RateLimit() gin.HandlerFunc {
+	if !utils.GetBoolEnvOrDefault("ENABLE_RATE_LIMIT", false) {
+		return func(c *gin.Context) {}
+	}
+	limiter := newRateLimiter(rateLimitDefaults, utils.GetIntEnvOrDefault("RATE_LIMIT_CACHE_SIZE", 10000),
+		time.Duration(utils.GetIntEnvOrDefault("RATE_LIMIT_OVERRIDES_TTL_SEC", 60))*time.Second,
+		loadRateLimitOverrides)
+	return rateLimit(limiter)
+}
+
+func rateLimit(limiter *rateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		account := c.GetInt(KeyAccount)
+		user := ""
+		if principal := GetPrincipal(c); principal != nil {
+			user = principal.Name
+		}
+		class := routeClass(c.FullPath(), c.HandlerName(), c.Request.Method)
+
+		res := limiter.take(account, user, class, time.Now())
+		c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("X-RateLimit-Reset", durationSeconds(res.Reset))
+		if !res.Allowed {
+			rateLimitedCnt.WithLabelValues(class).Inc()
+			utils.Log("account_id", account, "user", user, "class", class).Warn("Rate limit exceeded")
+			c.Header("Retry-After", durationSeconds(res.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, utils.ErrorResponse{Error: "Rate limit exceeded"})
+		}
+	}
+}
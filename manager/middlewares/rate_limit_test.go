@@ -0,0 +1,159 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRateLimitDefaults = map[string]rateLimitConfig{
+	routeClassList:   {RequestsPerMinute: 60, Burst: 2},
+	routeClassExport: {RequestsPerMinute: 6, Burst: 1},
+	routeClassWrite:  {RequestsPerMinute: 60, Burst: 1},
+}
+
+func noRateLimitOverrides() (rateLimitOverrides, error) {
+	return rateLimitOverrides{}, nil
+}
+
+func TestTokenBucket(t *testing.T) {
+	config := rateLimitConfig{RequestsPerMinute: 60, Burst: 2}
+	now := time.Now()
+	b := tokenBucket{}
+
+	res := b.take(config, now)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Limit)
+	assert.Equal(t, 1, res.Remaining)
+	assert.True(t, b.take(config, now).Allowed)
+
+	res = b.take(config, now)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 2*time.Second, res.Reset)
+
+	// one token per second is refilled
+	assert.True(t, b.take(config, now.Add(time.Second)).Allowed)
+	assert.False(t, b.take(config, now.Add(time.Second)).Allowed)
+	// bucket doesn't overflow burst
+	res = b.take(config, now.Add(time.Hour))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+}
+
+func TestTokenBucketConfigChange(t *testing.T) {
+	now := time.Now()
+	b := tokenBucket{}
+	assert.True(t, b.take(rateLimitConfig{RequestsPerMinute: 60, Burst: 10}, now).Allowed)
+	// tokens are capped by new burst
+	res := b.take(rateLimitConfig{RequestsPerMinute: 60, Burst: 2}, now)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	now := time.Now()
+	l := newRateLimiter(testRateLimitDefaults, 100, time.Minute, noRateLimitOverrides)
+	assert.True(t, l.take(1, "user1", routeClassExport, now).Allowed)
+	assert.False(t, l.take(1, "user1", routeClassExport, now).Allowed)
+	// other users, accounts and route classes have own budgets
+	assert.True(t, l.take(1, "user2", routeClassExport, now).Allowed)
+	assert.True(t, l.take(2, "user1", routeClassExport, now).Allowed)
+	assert.True(t, l.take(1, "user1", routeClassList, now).Allowed)
+}
+
+func TestRateLimiterOverrides(t *testing.T) {
+	now := time.Now()
+	loads := 0
+	l := newRateLimiter(testRateLimitDefaults, 100, time.Minute, func() (rateLimitOverrides, error) {
+		loads++
+		if loads > 1 {
+			return nil, errors.New("db error")
+		}
+		return rateLimitOverrides{2: {routeClassExport: {RequestsPerMinute: 60, Burst: 3}}}, nil
+	})
+	assert.Equal(t, 3, l.take(2, "user1", routeClassExport, now).Limit)
+	assert.Equal(t, 1, l.take(1, "user1", routeClassExport, now).Limit)
+	assert.Equal(t, 2, l.take(2, "user1", routeClassList, now).Limit)
+	assert.Equal(t, 1, loads)
+
+	// overrides are kept when reload fails
+	assert.Equal(t, 3, l.take(2, "user1", routeClassExport, now.Add(2*time.Minute)).Limit)
+	assert.Equal(t, 2, loads)
+}
+
+func TestRateLimiterOverridesReload(t *testing.T) {
+	now := time.Now()
+	loading, release := make(chan bool), make(chan bool)
+	l := newRateLimiter(testRateLimitDefaults, 100, time.Minute, noRateLimitOverrides)
+	assert.Equal(t, 1, l.take(2, "user1", routeClassExport, now).Limit)
+	l.loadOverrides = func() (rateLimitOverrides, error) {
+		loading <- true
+		<-release
+		return rateLimitOverrides{2: {routeClassExport: {RequestsPerMinute: 60, Burst: 3}}}, nil
+	}
+
+	done := make(chan bool)
+	go func() {
+		l.take(2, "user1", routeClassExport, now.Add(2*time.Minute))
+		done <- true
+	}()
+	<-loading
+	// requests are not blocked by the reload, they use previous overrides
+	assert.Equal(t, 1, l.take(2, "user2", routeClassExport, now.Add(2*time.Minute)).Limit)
+	release <- true
+	<-done
+	assert.Equal(t, 3, l.take(2, "user2", routeClassExport, now.Add(2*time.Minute)).Limit)
+}
+
+func TestRouteClass(t *testing.T) {
+	assert.Equal(t, routeClassList, routeClass("/api/patch/v2/systems", "controllers.SystemsListHandler", "GET"))
+	assert.Equal(t, routeClassList, routeClass("/api/patch/v2/views/systems/advisories",
+		"controllers.PostSystemsAdvisories", "POST"))
+	assert.Equal(t, routeClassExport, routeClass("/api/patch/v2/export/systems",
+		"controllers.SystemsExportHandler", "GET"))
+	assert.Equal(t, routeClassExport, routeClass("/api/patch/v2/baselines/:baseline_id/export",
+		"controllers.BaselineExportHandler", "GET"))
+	assert.Equal(t, routeClassWrite, routeClass("/api/patch/v2/systems/:inventory_id",
+		"controllers.SystemDeleteHandler", "DELETE"))
+	assert.Equal(t, routeClassWrite, routeClass("/api/patch/v2/baselines", "controllers.CreateBaselineHandler", "PUT"))
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	l := newRateLimiter(testRateLimitDefaults, 100, time.Minute, noRateLimitOverrides)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(KeyAccount, 1)
+		c.Set(KeyPrincipal, &Principal{Name: "user1"})
+	})
+	router.Use(rateLimit(l))
+	router.GET("/api/patch/v2/export/systems", okHandler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/patch/v2/export/systems", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "10", w.Header().Get("X-RateLimit-Reset"))
+	assert.Equal(t, "", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "Rate limit exceeded")
+}
+
+func TestLoadRateLimitOverrides(t *testing.T) {
+	testSetup(t)
+	overrides, err := loadRateLimitOverrides()
+	assert.NoError(t, err)
+	assert.Equal(t, rateLimitOverrides{2: {routeClassExport: {RequestsPerMinute: 120, Burst: 20}}}, overrides)
+}
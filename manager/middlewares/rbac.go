@@ -3,13 +3,18 @@ package middlewares
 import (
 	"app/base"
 	"app/base/api"
+	"app/base/database"
 	"app/base/rbac"
 	"app/base/utils"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -33,45 +38,269 @@ func makeClient(identity string) *api.Client {
 	return &client
 }
 
+// Permission verbs and resources of the patch application
+const (
+	rbacApplication  = "patch"
+	rbacAll          = "*"
+	verbRead         = "read"
+	verbWrite        = "write"
+	resourceAdvisory = "advisory"
+	resourcePackage  = "package"
+	resourceSystem   = "system"
+	resourceBaseline = "baseline"
+	resourceTemplate = "template"
+	resourceStatus   = "status"
+	resourceToken    = "token" // API tokens are not RBAC resource, see rbacPerms.allowed
+)
+
+// RBAC attribute filter keys restricting accessible systems
+const (
+	groupFilterKey = "group.id"
+	tagsFilterKey  = "tags"
+)
+
+const KeySystemScope = "system_scope"
+const keyRbacPerms = "rbac_perms"
+
+// Resources of API path segments, /export, /ids and /views paths are resolved by the following segment
+var pathResources = map[string]string{
+	"advisories": resourceAdvisory,
+	"packages":   resourcePackage,
+	"systems":    resourceSystem,
+	"repos":      resourceSystem,
+	"modules":    resourceSystem,
+	"dashboard":  resourceSystem,
+	"baselines":  resourceBaseline,
+	"templates":  resourceTemplate,
+	"tokens":     resourceToken,
+}
+
+// Handlers of patch status of systems, i.e. compliance with baselines and association of systems with them
+var handlerResources = map[string]string{
+	"BaselinesComplianceHandler":   resourceStatus,
+	"BaselineReportHandler":        resourceStatus,
+	"BaselineReportExportHandler":  resourceStatus,
+	"BaselineSystemsRemoveHandler": resourceStatus,
+}
+
+// Handlers listing systems outside of systems path segment, they require system read permission as well
+var systemsHandlers = map[string]bool{
+	"BaselineReportHandler":       true,
+	"BaselineReportExportHandler": true,
+	"BaselineExportHandler":       true,
+}
+
+// Handlers changing data with POST method, other POST handlers only read data
+var writePostHandlers = map[string]bool{
+	"BaselineSystemsRemoveHandler": true,
+	"BaselineImportHandler":        true,
+	"BaselineCloneHandler":         true,
+	"CreateTokenHandler":           true,
+}
+
+var scopeTagRegex = regexp.MustCompile(`^([^/=]+)/([^/=]+)(=([^/=]+))?$`)
+
 type rbacPerms struct {
-	Read  bool
-	Write bool
+	granted map[string]bool // "resource:verb" permissions
+	scope   *database.SystemScope
 }
 
-func isAccessGranted(c *gin.Context) rbacPerms {
-	client := makeClient(c.GetHeader("x-rh-identity"))
+func (p *rbacPerms) has(resource, verb string) bool {
+	for _, r := range []string{resource, rbacAll} {
+		for _, v := range []string{verb, rbacAll} {
+			if p.granted[r+":"+v] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Advisories, packages and status are computed from systems so system read permission grants read on them as well.
+// API tokens require no permission, token routes are restricted to org admins and created tokens are limited
+// to permissions of the caller by CanGrant.
+func (p *rbacPerms) allowed(resource, verb string) bool {
+	if resource == resourceToken {
+		return true
+	}
+	if p.has(resource, verb) {
+		return true
+	}
+	return verb == verbRead && (resource == resourceAdvisory || resource == resourcePackage ||
+		resource == resourceStatus) && p.has(resourceSystem, verbRead)
+}
+
+func parseScopeTag(tag string) *database.ScopeTag {
+	matches := scopeTagRegex.FindStringSubmatch(tag)
+	if matches == nil {
+		return nil
+	}
+	res := database.ScopeTag{Key: matches[2]}
+	if strings.ToLower(matches[1]) != "null" {
+		res.Namespace = &matches[1]
+	}
+	if matches[4] != "" {
+		res.Value = &matches[4]
+	}
+	return &res
+}
+
+// Adds groups and tags of the resource definitions to the scope, unknown filters don't grant any system
+func addScopeDefinitions(scope *database.SystemScope, definitions []rbac.ResourceDefinition) {
+	for _, d := range definitions {
+		values, err := d.AttributeFilter.Values()
+		if err != nil {
+			utils.Log("err", err.Error(), "key", d.AttributeFilter.Key).Warn("Invalid RBAC attribute filter")
+			continue
+		}
+		for _, v := range values {
+			switch {
+			case d.AttributeFilter.Key == groupFilterKey && v == nil:
+				scope.Ungrouped = true
+			case d.AttributeFilter.Key == groupFilterKey:
+				scope.Groups = append(scope.Groups, *v)
+			case d.AttributeFilter.Key == tagsFilterKey && v != nil:
+				if tag := parseScopeTag(*v); tag != nil {
+					scope.Tags = append(scope.Tags, *tag)
+				}
+			default:
+				utils.Log("key", d.AttributeFilter.Key).Warn("Unsupported RBAC attribute filter")
+			}
+		}
+	}
+}
+
+// Collects granted permissions, systems are restricted to the scope only when all permissions
+// granting read access to systems have resource definitions
+func newRbacPerms(access []rbac.Access) rbacPerms {
+	perms := rbacPerms{granted: map[string]bool{}}
+	scope := database.SystemScope{}
+	restricted, unrestricted := false, false
+	for _, a := range access {
+		parts := strings.Split(a.Permission, ":")
+		if len(parts) != 3 || parts[0] != rbacApplication {
+			continue
+		}
+		resource, verb := parts[1], parts[2]
+		perms.granted[resource+":"+verb] = true
+
+		grantsSystems := (resource == resourceSystem || resource == rbacAll) && (verb == verbRead || verb == rbacAll)
+		switch {
+		case !grantsSystems:
+		case len(a.ResourceDefinitions) == 0:
+			unrestricted = true
+		default:
+			restricted = true
+			addScopeDefinitions(&scope, a.ResourceDefinitions)
+		}
+	}
+	if restricted && !unrestricted {
+		perms.scope = &scope
+	}
+	return perms
+}
+
+// Requests permissions of the identity from RBAC
+func fetchRbacPerms(identity string) (rbacPerms, error) {
+	client := makeClient(identity)
 	access := rbac.AccessPagination{}
+	tStart := time.Now()
 	res, err := client.Request(&base.Context, http.MethodGet, rbacURL, nil, &access)
+	rbacRequestDuration.Observe(time.Since(tStart).Seconds())
 	if res != nil && res.Body != nil {
 		defer res.Body.Close()
 	}
 
 	if err != nil {
-		utils.Log("err", err.Error()).Error("Call to RBAC svc failed")
 		status := http.StatusInternalServerError
 		if res != nil {
 			status = res.StatusCode
 		}
 		serviceErrorCnt.WithLabelValues("rbac", strconv.Itoa(status)).Inc()
-		return rbacPerms{Read: false, Write: false}
-	}
-
-	perms := rbacPerms{Read: false, Write: false}
-	for _, a := range access.Data {
-		switch a.Permission {
-		case "patch:*:*", "patch:system:*":
-			perms.Read = true
-			perms.Write = true
-		case "patch:*:read", "patch:system:read":
-			perms.Read = true
-		case "patch:*:write", "patch:system:write":
-			perms.Write = true
-		default:
-		}
+		return rbacPerms{}, errors.Wrap(err, "call to RBAC svc failed")
+	}
+	return newRbacPerms(access.Data), nil
+}
+
+// Permissions granted by standalone authenticators are used instead of RBAC service
+func isAccessGranted(c *gin.Context) rbacPerms {
+	if access, ok := c.Get(KeyPermissions); ok {
+		return newRbacPerms(access.([]rbac.Access))
+	}
+	perms, err := permsCache.Get(c.GetHeader(xRHIdentity))
+	if err != nil {
+		return rbacPerms{granted: map[string]bool{}}
 	}
 	return perms
 }
 
+// Returns resource and verb of the permission required by the request
+func requiredPermission(fullPath, handlerName, method string) (resource, verb string) {
+	resource = resourceSystem
+	for _, segment := range strings.Split(fullPath, "/") {
+		if r, ok := pathResources[segment]; ok {
+			resource = r
+			break
+		}
+	}
+
+	handler := handlerName[strings.LastIndex(handlerName, ".")+1:]
+	if r, ok := handlerResources[handler]; ok {
+		resource = r
+	}
+	switch {
+	case method == http.MethodGet:
+		verb = verbRead
+	case method == http.MethodPost && !writePostHandlers[handler]:
+		verb = verbRead
+	default:
+		verb = verbWrite
+	}
+	return resource, verb
+}
+
+// Reports whether the request returns systems of other resource than system, e.g. /advisories/:advisory_id/systems
+func returnsSystems(fullPath, handlerName string) bool {
+	handler := handlerName[strings.LastIndex(handlerName, ".")+1:]
+	if systemsHandlers[handler] {
+		return true
+	}
+	for _, segment := range strings.Split(fullPath, "/") {
+		if segment == "systems" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSystemScope returns systems accessible by the user, nil when access to systems is not restricted
+func GetSystemScope(c *gin.Context) *database.SystemScope {
+	if scope, ok := c.Get(KeySystemScope); ok {
+		return scope.(*database.SystemScope)
+	}
+	return nil
+}
+
+// CanGrant reports whether the caller holds all the permissions with access to all systems,
+// permissions are not checked when RBAC is disabled
+func CanGrant(c *gin.Context, permissions []string) bool {
+	val, ok := c.Get(keyRbacPerms)
+	if !ok {
+		return true
+	}
+	perms := val.(rbacPerms)
+	if perms.scope != nil {
+		return false
+	}
+	for _, p := range permissions {
+		parts := strings.Split(p, ":")
+		if len(parts) != 3 || parts[0] != rbacApplication || !perms.has(parts[1], parts[2]) {
+			return false
+		}
+	}
+	return true
+}
+
 func RBAC() gin.HandlerFunc {
 	enableRBACCHeck := utils.GetBoolEnvOrDefault("ENABLE_RBAC", true)
 	if !enableRBACCHeck {
@@ -80,16 +309,14 @@ func RBAC() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		grantedPerms := isAccessGranted(c)
-
-		switch c.Request.Method {
-		case "GET", "POST":
-			if grantedPerms.Read {
-				return
-			}
-		case "DELETE", "PUT":
-			if grantedPerms.Write {
-				return
+		resource, verb := requiredPermission(c.FullPath(), c.HandlerName(), c.Request.Method)
+		if grantedPerms.allowed(resource, verb) &&
+			(!returnsSystems(c.FullPath(), c.HandlerName()) || grantedPerms.allowed(resourceSystem, verbRead)) {
+			if grantedPerms.scope != nil {
+				c.Set(KeySystemScope, grantedPerms.scope)
 			}
+			c.Set(keyRbacPerms, grantedPerms)
+			return
 		}
 		c.AbortWithStatusJSON(http.StatusUnauthorized,
 			utils.ErrorResponse{Error: "You don't have access to this application"})
@@ -0,0 +1,136 @@
+package middlewares
+
+import (
+	"app/base/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+var enableRbacCache = utils.GetBoolEnvOrDefault("ENABLE_RBAC_CACHE", true)
+var rbacCacheSize = utils.GetIntEnvOrDefault("RBAC_CACHE_SIZE", 1000)
+var rbacCacheTTL = time.Duration(utils.GetIntEnvOrDefault("RBAC_CACHE_TTL_SEC", 60)) * time.Second
+var rbacCacheStale = time.Duration(utils.GetIntEnvOrDefault("RBAC_CACHE_STALE_SEC", 300)) * time.Second
+var permsCache = newRbacCache(enableRbacCache, rbacCacheSize, rbacCacheTTL, rbacCacheStale, fetchRbacPerms)
+
+// Cache results reported in metrics
+const (
+	rbacCacheHit      = "hit"
+	rbacCacheStaleHit = "stale"
+	rbacCacheMiss     = "miss"
+)
+
+type rbacCacheItem struct {
+	Perms   rbacPerms
+	Fetched time.Time
+}
+
+// In-flight RBAC request shared by concurrent lookups of the same identity
+type rbacCall struct {
+	wg    sync.WaitGroup
+	perms rbacPerms
+	err   error
+}
+
+// Cache of permissions resolved by RBAC. Items older than ttl are refreshed on access, the expired item
+// is served for the stale period only when the refresh fails, so RBAC outage shorter than the stale period
+// doesn't deny access.
+type rbacCache struct {
+	items    *lru.Cache
+	ttl      time.Duration
+	stale    time.Duration
+	fetch    func(identity string) (rbacPerms, error)
+	lock     sync.Mutex
+	inflight map[string]*rbacCall
+}
+
+func newRbacCache(enabled bool, size int, ttl, stale time.Duration,
+	fetch func(identity string) (rbacPerms, error)) *rbacCache {
+	cache := rbacCache{ttl: ttl, stale: stale, fetch: fetch, inflight: map[string]*rbacCall{}}
+	if !enabled || ttl <= 0 {
+		return &cache
+	}
+
+	items, err := lru.New(size)
+	if err != nil {
+		panic(err)
+	}
+	cache.items = items
+	return &cache
+}
+
+// Identity header is not stored in memory, only its hash
+func identityKey(identity string) string {
+	hash := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(hash[:])
+}
+
+// Get returns permissions of the identity, permissions can't be resolved when RBAC fails and no usable
+// cached item exists
+func (c *rbacCache) Get(identity string) (rbacPerms, error) {
+	key := identityKey(identity)
+	if c.items == nil {
+		rbacCacheCnt.WithLabelValues(rbacCacheMiss).Inc()
+		return c.load(key, identity)
+	}
+
+	val, ok := c.items.Get(key)
+	if !ok {
+		rbacCacheCnt.WithLabelValues(rbacCacheMiss).Inc()
+		return c.load(key, identity)
+	}
+	item := val.(rbacCacheItem)
+	age := time.Since(item.Fetched)
+	if age < c.ttl {
+		rbacCacheCnt.WithLabelValues(rbacCacheHit).Inc()
+		return item.Perms, nil
+	}
+
+	perms, err := c.load(key, identity)
+	if err == nil {
+		rbacCacheCnt.WithLabelValues(rbacCacheMiss).Inc()
+		return perms, nil
+	}
+	if age < c.ttl+c.stale {
+		rbacCacheCnt.WithLabelValues(rbacCacheStaleHit).Inc()
+		return item.Perms, nil
+	}
+	c.items.Remove(key)
+	rbacCacheCnt.WithLabelValues(rbacCacheMiss).Inc()
+	return perms, err
+}
+
+// Fetches permissions from RBAC, concurrent requests for the same identity wait for the first one
+func (c *rbacCache) load(key, identity string) (rbacPerms, error) {
+	c.lock.Lock()
+	if call, ok := c.inflight[key]; ok {
+		c.lock.Unlock()
+		call.wg.Wait()
+		return call.perms, call.err
+	}
+	// waiting requests get the error even if fetch panics
+	call := &rbacCall{err: errors.New("unable to resolve RBAC permissions")}
+	call.wg.Add(1)
+	c.inflight[key] = call
+	c.lock.Unlock()
+	defer c.finish(key, call)
+
+	call.perms, call.err = c.fetch(identity)
+	if call.err != nil {
+		utils.Log("err", call.err.Error()).Error("Unable to resolve RBAC permissions")
+	} else if c.items != nil {
+		c.items.Add(key, rbacCacheItem{Perms: call.perms, Fetched: time.Now()})
+	}
+	return call.perms, call.err
+}
+
+func (c *rbacCache) finish(key string, call *rbacCall) {
+	c.lock.Lock()
+	delete(c.inflight, key)
+	c.lock.Unlock()
+	call.wg.Done()
+}
@@ -0,0 +1,228 @@
+package middlewares
+
+import (
+	"app/base/rbac"
+	"app/base/utils"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFetcher struct {
+	calls         int32
+	fail          bool
+	wait          chan struct{}
+	identityPerms map[string]string // permission returned for the identity instead of default one
+}
+
+func (f *testFetcher) fetch(identity string) (rbacPerms, error) {
+	atomic.AddInt32(&f.calls, 1)
+	if f.wait != nil {
+		<-f.wait
+	}
+	if f.fail {
+		return rbacPerms{}, errors.New("rbac unavailable")
+	}
+	if permission, ok := f.identityPerms[identity]; ok {
+		return newRbacPerms([]rbac.Access{{Permission: permission}}), nil
+	}
+	return newRbacPerms([]rbac.Access{{Permission: "patch:" + identity + ":read"}}), nil
+}
+
+func (f *testFetcher) callCount() int {
+	return int(atomic.LoadInt32(&f.calls))
+}
+
+// Make cached item of the identity older by given duration
+func ageRbacCacheItem(cache *rbacCache, identity string, age time.Duration) {
+	key := identityKey(identity)
+	val, _ := cache.items.Get(key)
+	item := val.(rbacCacheItem)
+	item.Fetched = item.Fetched.Add(-age)
+	cache.items.Add(key, item)
+}
+
+func TestRbacCacheHit(t *testing.T) {
+	fetcher := testFetcher{}
+	cache := newRbacCache(true, 10, time.Minute, time.Minute, fetcher.fetch)
+
+	perms, err := cache.Get("system")
+	assert.NoError(t, err)
+	assert.True(t, perms.allowed(resourceSystem, verbRead))
+	perms, err = cache.Get("system")
+	assert.NoError(t, err)
+	assert.True(t, perms.allowed(resourceSystem, verbRead))
+	assert.Equal(t, 1, fetcher.callCount())
+
+	perms, err = cache.Get("baseline")
+	assert.NoError(t, err)
+	assert.False(t, perms.allowed(resourceSystem, verbRead))
+	assert.True(t, perms.allowed(resourceBaseline, verbRead))
+	assert.Equal(t, 2, fetcher.callCount())
+}
+
+func TestRbacCacheLRU(t *testing.T) {
+	fetcher := testFetcher{}
+	cache := newRbacCache(true, 1, time.Minute, time.Minute, fetcher.fetch)
+
+	_, _ = cache.Get("system")
+	_, _ = cache.Get("baseline")
+	_, _ = cache.Get("system")
+	assert.Equal(t, 3, fetcher.callCount())
+}
+
+func TestRbacCacheStale(t *testing.T) {
+	fetcher := testFetcher{}
+	cache := newRbacCache(true, 10, time.Minute, time.Minute, fetcher.fetch)
+	_, err := cache.Get("system")
+	assert.NoError(t, err)
+
+	// expired item is served during RBAC outage
+	fetcher.fail = true
+	ageRbacCacheItem(cache, "system", 90*time.Second)
+	perms, err := cache.Get("system")
+	assert.NoError(t, err)
+	assert.True(t, perms.allowed(resourceSystem, verbRead))
+	assert.Equal(t, 2, fetcher.callCount())
+
+	// item older than stale period is not used
+	ageRbacCacheItem(cache, "system", time.Minute)
+	_, err = cache.Get("system")
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.items.Len())
+}
+
+func TestRbacCacheRefresh(t *testing.T) {
+	fetcher := testFetcher{}
+	cache := newRbacCache(true, 10, time.Minute, time.Minute, fetcher.fetch)
+	_, _ = cache.Get("system")
+	ageRbacCacheItem(cache, "system", 90*time.Second)
+
+	// expired item is refreshed before it's returned, permission changes apply right after ttl
+	fetcher.identityPerms = map[string]string{"system": "patch:baseline:read"}
+	perms, err := cache.Get("system")
+	assert.NoError(t, err)
+	assert.False(t, perms.allowed(resourceSystem, verbRead))
+	assert.True(t, perms.allowed(resourceBaseline, verbRead))
+	val, _ := cache.items.Get(identityKey("system"))
+	assert.True(t, time.Since(val.(rbacCacheItem).Fetched) < time.Minute)
+
+	_, _ = cache.Get("system")
+	assert.Equal(t, 2, fetcher.callCount())
+}
+
+func TestRbacCacheError(t *testing.T) {
+	fetcher := testFetcher{fail: true}
+	cache := newRbacCache(true, 10, time.Minute, time.Minute, fetcher.fetch)
+	_, err := cache.Get("system")
+	assert.Error(t, err)
+	_, err = cache.Get("system")
+	assert.Error(t, err)
+	// errors are not cached
+	assert.Equal(t, 2, fetcher.callCount())
+}
+
+func TestRbacCacheSingleflight(t *testing.T) {
+	fetcher := testFetcher{wait: make(chan struct{})}
+	cache := newRbacCache(true, 10, time.Minute, time.Minute, fetcher.fetch)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			perms, err := cache.Get("system")
+			assert.NoError(t, err)
+			assert.True(t, perms.allowed(resourceSystem, verbRead))
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		cache.lock.Lock()
+		defer cache.lock.Unlock()
+		return len(cache.inflight) == 1
+	}, time.Second, 10*time.Millisecond)
+	close(fetcher.wait)
+	wg.Wait()
+	assert.Equal(t, 1, fetcher.callCount())
+}
+
+func TestRbacCacheDisabled(t *testing.T) {
+	fetcher := testFetcher{}
+	cache := newRbacCache(false, 10, time.Minute, time.Minute, fetcher.fetch)
+	_, _ = cache.Get("system")
+	_, _ = cache.Get("system")
+	assert.Equal(t, 2, fetcher.callCount())
+}
+
+func TestFetchRbacPerms(t *testing.T) {
+	router := gin.New()
+	router.GET("/api/rbac/v1/access/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, rbac.AccessPagination{Data: []rbac.Access{{Permission: "patch:*:read"}}})
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := rbacURL
+	rbacURL = server.URL + "/api/rbac/v1/access/?application=patch"
+	defer func() { rbacURL = url }()
+
+	perms, err := fetchRbacPerms("identity")
+	assert.NoError(t, err)
+	assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+	assert.False(t, perms.allowed(resourceBaseline, verbWrite))
+
+	server.Close()
+	_, err = fetchRbacPerms("identity")
+	assert.Error(t, err)
+}
+
+func TestRbacCachePlatform(t *testing.T) {
+	utils.SkipWithoutPlatform(t)
+	url := rbacURL
+	defer func() { rbacURL = url }()
+	rbacURL = ""
+	makeClient("")
+
+	cache := newRbacCache(true, 10, time.Minute, time.Minute, fetchRbacPerms)
+	perms, err := cache.Get("identity")
+	assert.NoError(t, err)
+	assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+
+	// RBAC is unavailable, expired item is served for the stale period
+	platformURL := rbacURL
+	rbacURL = "http://localhost:1/api/rbac/v1/access/?application=patch"
+	ageRbacCacheItem(cache, "identity", 90*time.Second)
+	perms, err = cache.Get("identity")
+	assert.NoError(t, err)
+	assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+
+	// RBAC is available again, expired item is refreshed
+	rbacURL = platformURL
+	perms, err = cache.Get("identity")
+	assert.NoError(t, err)
+	assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+	val, _ := cache.items.Get(identityKey("identity"))
+	assert.True(t, time.Since(val.(rbacCacheItem).Fetched) < time.Minute)
+
+	rbacURL = "http://localhost:1/api/rbac/v1/access/?application=patch"
+	ageRbacCacheItem(cache, "identity", 3*time.Minute)
+	_, err = cache.Get("identity")
+	assert.Error(t, err)
+}
+
+func TestRbacCachePanic(t *testing.T) {
+	cache := newRbacCache(true, 10, time.Minute, time.Minute, func(identity string) (rbacPerms, error) {
+		panic("rbac address not set")
+	})
+	assert.Panics(t, func() { _, _ = cache.Get("system") })
+	// failed lookup doesn't block following ones
+	assert.Panics(t, func() { _, _ = cache.Get("system") })
+	assert.Equal(t, 0, len(cache.inflight))
+}
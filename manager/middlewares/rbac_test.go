@@ -1,10 +1,14 @@
 package middlewares
 
 import (
+	"app/base/database"
+	"app/base/rbac"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -37,3 +41,187 @@ func TestRBACDelete(t *testing.T) {
 func TestRBACPut(t *testing.T) {
 	testRBAC(t, "PUT", http.StatusUnauthorized)
 }
+
+func testAccess(permission string, key, operation, value string) rbac.Access {
+	access := rbac.Access{Permission: permission}
+	if key != "" {
+		access.ResourceDefinitions = []rbac.ResourceDefinition{{AttributeFilter: rbac.AttributeFilter{
+			Key: key, Operation: operation, Value: json.RawMessage(value),
+		}}}
+	}
+	return access
+}
+
+func TestRbacPermsWildcard(t *testing.T) {
+	perms := newRbacPerms([]rbac.Access{testAccess("patch:*:*", "", "", "")})
+	assert.True(t, perms.allowed(resourceBaseline, verbWrite))
+	assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+	assert.Nil(t, perms.scope)
+
+	perms = newRbacPerms([]rbac.Access{testAccess("inventory:*:*", "", "", "")})
+	assert.False(t, perms.allowed(resourceSystem, verbRead))
+}
+
+func TestRbacPermsResources(t *testing.T) {
+	perms := newRbacPerms([]rbac.Access{
+		testAccess("patch:system:read", "", "", ""),
+		testAccess("patch:baseline:*", "", "", ""),
+	})
+	assert.True(t, perms.allowed(resourceSystem, verbRead))
+	assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+	assert.True(t, perms.allowed(resourcePackage, verbRead))
+	assert.True(t, perms.allowed(resourceBaseline, verbWrite))
+	assert.False(t, perms.allowed(resourceSystem, verbWrite))
+	assert.False(t, perms.allowed(resourceAdvisory, verbWrite))
+
+	perms = newRbacPerms([]rbac.Access{testAccess("patch:advisory:read", "", "", "")})
+	assert.True(t, perms.allowed(resourceAdvisory, verbRead))
+	assert.False(t, perms.allowed(resourcePackage, verbRead))
+	assert.False(t, perms.allowed(resourceSystem, verbRead))
+	assert.False(t, perms.allowed(resourceStatus, verbRead))
+
+	perms = newRbacPerms([]rbac.Access{
+		testAccess("patch:system:read", "", "", ""),
+		testAccess("patch:template:*", "", "", ""),
+		testAccess("patch:status:write", "", "", ""),
+	})
+	assert.True(t, perms.allowed(resourceTemplate, verbWrite))
+	assert.True(t, perms.allowed(resourceStatus, verbRead))
+	assert.True(t, perms.allowed(resourceStatus, verbWrite))
+	assert.False(t, perms.allowed(resourceBaseline, verbWrite))
+}
+
+func TestReturnsSystems(t *testing.T) {
+	assert.True(t, returnsSystems("/api/patch/v1/advisories/:advisory_id/systems",
+		"controllers.AdvisorySystemsListHandler"))
+	assert.True(t, returnsSystems("/api/patch/v1/ids/advisories/:advisory_id/systems",
+		"controllers.AdvisorySystemsListIDsHandler"))
+	assert.True(t, returnsSystems("/api/patch/v1/views/advisories/systems", "controllers.PostAdvisoriesSystems"))
+	assert.True(t, returnsSystems("/api/patch/v1/baselines/:baseline_id/report", "controllers.BaselineReportHandler"))
+	assert.False(t, returnsSystems("/api/patch/v1/advisories/:advisory_id", "controllers.AdvisoryDetailHandlerV2"))
+	assert.False(t, returnsSystems("/api/patch/v1/baselines/compliance", "controllers.BaselinesComplianceHandler"))
+}
+
+func TestRBACSystemsOfResource(t *testing.T) {
+	for path, status := range map[string]int{"/advisories/:advisory_id": http.StatusOK,
+		"/advisories/:advisory_id/systems": http.StatusUnauthorized} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/advisories/RH-1"+strings.TrimPrefix(path, "/advisories/:advisory_id"), nil)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(KeyPermissions, []rbac.Access{{Permission: "patch:advisory:read"}})
+		})
+		router.Use(RBAC())
+		router.Handle("GET", path, okHandler)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
+
+func TestRbacPermsScope(t *testing.T) {
+	perms := newRbacPerms([]rbac.Access{
+		testAccess("patch:system:read", groupFilterKey, "in", `["group-1", null]`),
+		testAccess("patch:*:read", tagsFilterKey, "equal", `"ns1/k1=val1"`),
+		testAccess("patch:system:write", groupFilterKey, "in", `["group-2"]`),
+	})
+	assert.NotNil(t, perms.scope)
+	assert.Equal(t, []string{"group-1"}, perms.scope.Groups)
+	assert.True(t, perms.scope.Ungrouped)
+	assert.Equal(t, 1, len(perms.scope.Tags))
+	assert.Equal(t, "ns1", *perms.scope.Tags[0].Namespace)
+	assert.Equal(t, "k1", perms.scope.Tags[0].Key)
+	assert.Equal(t, "val1", *perms.scope.Tags[0].Value)
+}
+
+func TestRbacPermsScopeCommaSeparated(t *testing.T) {
+	perms := newRbacPerms([]rbac.Access{testAccess("patch:system:read", groupFilterKey, "in", `"group-1, group-2"`)})
+	assert.Equal(t, []string{"group-1", "group-2"}, perms.scope.Groups)
+}
+
+func TestRbacPermsScopeUnknownKey(t *testing.T) {
+	perms := newRbacPerms([]rbac.Access{testAccess("patch:system:read", "host.id", "equal", `"abc"`)})
+	assert.NotNil(t, perms.scope)
+	assert.Equal(t, database.SystemScope{}, *perms.scope)
+}
+
+func TestRbacPermsScopeUnrestricted(t *testing.T) {
+	perms := newRbacPerms([]rbac.Access{
+		testAccess("patch:system:read", groupFilterKey, "in", `["group-1"]`),
+		testAccess("patch:*:*", "", "", ""),
+	})
+	assert.Nil(t, perms.scope)
+}
+
+func TestParseScopeTag(t *testing.T) {
+	tag := parseScopeTag("null/k1")
+	assert.Nil(t, tag.Namespace)
+	assert.Equal(t, "k1", tag.Key)
+	assert.Nil(t, tag.Value)
+	assert.Nil(t, parseScopeTag("k1=val1"))
+}
+
+func TestRequiredPermission(t *testing.T) {
+	check := func(fullPath, handler, method, resource, verb string) {
+		r, v := requiredPermission(fullPath, handler, method)
+		assert.Equal(t, resource, r, fullPath)
+		assert.Equal(t, verb, v, fullPath)
+	}
+	check("/api/patch/v1/advisories/:advisory_id", "controllers.AdvisoryDetailHandler", "GET",
+		resourceAdvisory, verbRead)
+	check("/api/patch/v1/export/packages", "controllers.PackagesExportHandler", "GET", resourcePackage, verbRead)
+	check("/api/patch/v1/ids/systems", "controllers.SystemsListIDsHandler", "GET", resourceSystem, verbRead)
+	check("/api/patch/v1/views/systems/advisories", "controllers.PostSystemsAdvisories", "POST",
+		resourceSystem, verbRead)
+	check("/api/patch/v1/baselines/systems/remove", "controllers.BaselineSystemsRemoveHandler", "POST",
+		resourceStatus, verbWrite)
+	check("/api/patch/v1/baselines/:baseline_id/report", "controllers.BaselineReportHandler", "GET",
+		resourceStatus, verbRead)
+	check("/api/patch/v1/baselines/:baseline_id", "controllers.BaselineDeleteHandler", "DELETE",
+		resourceBaseline, verbWrite)
+	check("/api/patch/v1/baselines/import", "controllers.BaselineImportHandler", "POST", resourceBaseline, verbWrite)
+	check("/api/patch/v1/baselines/:baseline_id/export", "controllers.BaselineExportHandler", "GET",
+		resourceBaseline, verbRead)
+	check("/api/patch/v1/templates/:template_id", "controllers.TemplateUpdateHandler", "PUT",
+		resourceTemplate, verbWrite)
+	check("/api/patch/v1/templates", "controllers.TemplatesListHandler", "GET", resourceTemplate, verbRead)
+	check("/api/patch/v1/systems/:inventory_id", "controllers.SystemDeleteHandler", "DELETE",
+		resourceSystem, verbWrite)
+	check("/api/patch/v3/tokens", "controllers.CreateTokenHandler", "POST", resourceToken, verbWrite)
+	check("/api/patch/v3/tokens/:token_id", "controllers.TokenDeleteHandler", "DELETE", resourceToken, verbWrite)
+	check("/", "middlewares.okHandler", "PUT", resourceSystem, verbWrite)
+}
+
+func TestRBACTokens(t *testing.T) {
+	for _, method := range []string{"GET", "POST", "DELETE"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/tokens", nil)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(KeyPermissions, []rbac.Access{{Permission: "patch:*:read"}})
+		})
+		router.Use(RBAC())
+		router.Handle(method, "/tokens", func(c *gin.Context) {
+			// token scopes are limited by permissions of the caller
+			assert.True(t, CanGrant(c, APITokenScopes["read-only"]))
+			assert.False(t, CanGrant(c, APITokenScopes["baseline-write"]))
+			okHandler(c)
+		})
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, method)
+	}
+}
+
+func TestRBACAuthenticatorPermissions(t *testing.T) {
+	for method, status := range map[string]int{"GET": http.StatusOK, "DELETE": http.StatusUnauthorized} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/", nil)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(KeyPermissions, []rbac.Access{{Permission: "patch:system:read"}})
+		})
+		router.Use(RBAC())
+		router.Handle(method, "/", okHandler)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, method)
+	}
+}
@@ -12,8 +12,9 @@ import (
 )
 
 func InitAPI(api *gin.RouterGroup, config docs.EndpointsConfig) { // nolint: funlen
-	api.Use(middlewares.RBAC())
 	api.Use(middlewares.PublicAuthenticator())
+	api.Use(middlewares.RateLimit())
+	api.Use(middlewares.RBAC())
 	api.Use(middlewares.CheckReferer())
 	basePath := api.BasePath()
 
@@ -27,23 +28,42 @@ func InitAPI(api *gin.RouterGroup, config docs.EndpointsConfig) { // nolint: fun
 		advisories.GET("/:advisory_id", controllers.AdvisoryDetailHandlerV2)
 	}
 	advisories.GET("/:advisory_id/systems", controllers.AdvisorySystemsListHandler)
+	advisories.GET("/:advisory_id/packages", controllers.AdvisoryPackagesHandler)
+	advisories.GET("/:advisory_id/packages/systems", controllers.AdvisoryPackageSystemsHandler)
 
 	if config.EnableBaselines {
 		baselines := api.Group("/baselines")
 		baselines.GET("/", controllers.BaselinesListHandler)
 		baselines.GET("/:baseline_id", controllers.BaselineDetailHandler)
 		baselines.GET("/:baseline_id/systems", controllers.BaselineSystemsListHandler)
+		baselines.GET("/:baseline_id/export", controllers.BaselineExportHandler)
+		baselines.GET("/:baseline_id/report", controllers.BaselineReportHandler)
+		baselines.GET("/compliance", controllers.BaselinesComplianceHandler)
 		baselines.PUT("/", controllers.CreateBaselineHandler)
 		baselines.PUT("/:baseline_id", controllers.BaselineUpdateHandler)
 		baselines.DELETE("/:baseline_id", controllers.BaselineDeleteHandler)
 		baselines.POST("/systems/remove", controllers.BaselineSystemsRemoveHandler)
+		baselines.POST("/import", controllers.BaselineImportHandler)
+		baselines.POST("/:baseline_id/clone", controllers.BaselineCloneHandler)
+	}
+
+	if config.EnableTemplates {
+		templates := api.Group("/templates")
+		templates.GET("/", controllers.TemplatesListHandler)
+		templates.GET("/:template_id", controllers.TemplateDetailHandler)
+		templates.PUT("/", controllers.CreateTemplateHandler)
+		templates.PUT("/:template_id", controllers.TemplateUpdateHandler)
+		templates.DELETE("/:template_id", controllers.TemplateDeleteHandler)
 	}
 
 	systems := api.Group("/systems")
 	systems.GET("/", controllers.SystemsListHandler)
+	systems.GET("/compare", controllers.SystemsCompareHandler)
 	systems.GET("/:inventory_id", controllers.SystemDetailHandler)
 	systems.GET("/:inventory_id/advisories", controllers.SystemAdvisoriesHandler)
+	systems.GET("/:inventory_id/advisories/:advisory_id/explain", controllers.SystemAdvisoryExplainHandler)
 	systems.GET("/:inventory_id/packages", controllers.SystemPackagesHandler)
+	systems.GET("/:inventory_id/modules", controllers.SystemModulesHandler)
 	systems.DELETE("/:inventory_id", controllers.SystemDeleteHandler)
 
 	packages := api.Group("/packages")
@@ -52,17 +72,29 @@ func InitAPI(api *gin.RouterGroup, config docs.EndpointsConfig) { // nolint: fun
 	packages.GET("/:package_name/versions", controllers.PackageVersionsListHandler)
 	packages.GET("/:package_name", controllers.PackageDetailHandler)
 
+	repos := api.Group("/repos")
+	repos.GET("/", controllers.ReposListHandler)
+	repos.GET("/:repo_name/systems", controllers.RepoSystemsListHandler)
+
+	modules := api.Group("/modules")
+	modules.GET("/", controllers.ModulesListHandler)
+
 	export := api.Group("export")
 	export.GET("/advisories", controllers.AdvisoriesExportHandler)
 	export.GET("/advisories/:advisory_id/systems", controllers.AdvisorySystemsExportHandler)
 
 	export.GET("/systems", controllers.SystemsExportHandler)
+	export.GET("/systems/compare", controllers.SystemsCompareExportHandler)
 	export.GET("/systems/:inventory_id/advisories", controllers.SystemAdvisoriesExportHandler)
 	export.GET("/systems/:inventory_id/packages", controllers.SystemPackagesExportHandler)
 
 	export.GET("/packages", controllers.PackagesExportHandler)
 	export.GET("/packages/:package_name/systems", controllers.PackageSystemsExportHandler)
 
+	if config.EnableBaselines {
+		export.GET("/baselines/:baseline_id/report", controllers.BaselineReportExportHandler)
+	}
+
 	views := api.Group("/views")
 	views.POST("/systems/advisories", controllers.PostSystemsAdvisories)
 	views.POST("/advisories/systems", controllers.PostAdvisoriesSystems)
@@ -74,6 +106,14 @@ func InitAPI(api *gin.RouterGroup, config docs.EndpointsConfig) { // nolint: fun
 	ids.GET("/systems", controllers.SystemsListIDsHandler)
 	ids.GET("/systems/:inventory_id/advisories", controllers.SystemAdvisoriesIDsHandler)
 
+	tokens := api.Group("/tokens")
+	tokens.Use(middlewares.OrgAdmin())
+	tokens.GET("/", controllers.TokensListHandler)
+	tokens.POST("/", controllers.CreateTokenHandler)
+	tokens.DELETE("/:token_id", controllers.TokenDeleteHandler)
+
+	api.GET("/dashboard", controllers.DashboardHandler)
+
 	api.GET("/status", controllers.Status)
 }
 
@@ -86,6 +126,12 @@ func InitAdmin(app *gin.Engine) {
 	}
 
 	api.GET("/sync", admin.Syncapi)
+	api.GET("/sync/status", admin.SyncStatus)
+	api.GET("/sync/history", admin.SyncHistory)
 	api.GET("/re-calc", admin.Recalc)
+	api.POST("/re-calc", admin.RecalcScoped)
+	api.GET("/re-calc/:job_id", admin.RecalcJobStatus)
 	api.GET("/check-caches", admin.CheckCaches)
+	api.POST("/check-caches/repair", admin.RepairCaches)
+	api.GET("/shadow-eval/diffs", admin.ShadowEvalDiffs)
 }
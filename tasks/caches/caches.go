@@ -8,11 +8,13 @@ import (
 
 var (
 	enableRefreshAdvisoryCaches bool
+	cacheRepairBatchSize        int
 )
 
 func configure() {
 	core.ConfigureApp()
 	enableRefreshAdvisoryCaches = utils.GetBoolEnvOrDefault("ENABLE_REFRESH_ADVISORY_CACHES", false)
+	cacheRepairBatchSize = utils.GetIntEnvOrDefault("CACHE_REPAIR_BATCH_SIZE", CacheRepairDefaultBatchSize)
 }
 
 func RunAdvisoryRefresh() {
@@ -0,0 +1,303 @@
+package caches
+
+import (
+	"app/base/database"
+	"app/base/utils"
+	"app/tasks"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CacheReportDefaultLimit     = 100
+	CacheRepairDefaultBatchSize = 1000
+)
+
+// Cached vs expected advisory counts of a system in system_platform
+type SystemCacheMismatch struct {
+	RhAccountID int    `json:"rh_account_id" gorm:"column:rh_account_id"`
+	SystemID    int64  `json:"system_id" gorm:"column:system_id"`
+	InventoryID string `json:"inventory_id" gorm:"column:inventory_id"`
+	CachedAll   int    `json:"cached_all" gorm:"column:cached_all"`
+	ExpectedAll int    `json:"expected_all" gorm:"column:expected_all"`
+	CachedEnh   int    `json:"cached_enh" gorm:"column:cached_enh"`
+	ExpectedEnh int    `json:"expected_enh" gorm:"column:expected_enh"`
+	CachedBug   int    `json:"cached_bug" gorm:"column:cached_bug"`
+	ExpectedBug int    `json:"expected_bug" gorm:"column:expected_bug"`
+	CachedSec   int    `json:"cached_sec" gorm:"column:cached_sec"`
+	ExpectedSec int    `json:"expected_sec" gorm:"column:expected_sec"`
+}
+
+// Cached vs expected affected systems count of an advisory in advisory_account_data
+type AdvisoryCacheMismatch struct {
+	RhAccountID  int    `json:"rh_account_id" gorm:"column:rh_account_id"`
+	AdvisoryID   int64  `json:"advisory_id" gorm:"column:advisory_id"`
+	AdvisoryName string `json:"advisory_name" gorm:"column:advisory_name"`
+	Cached       int    `json:"cached" gorm:"column:cached"`
+	Expected     int    `json:"expected" gorm:"column:expected"`
+}
+
+type CacheMismatchReport struct {
+	Valid      bool                    `json:"valid"`
+	Systems    []SystemCacheMismatch   `json:"systems"`
+	Advisories []AdvisoryCacheMismatch `json:"advisories"`
+	// Set when there are more mismatches than the report limit
+	Truncated bool `json:"truncated"`
+}
+
+type CacheRepairResult struct {
+	SystemsRepaired    int `json:"systems_repaired"`
+	AdvisoriesRepaired int `json:"advisories_repaired"`
+}
+
+// Accounts whose advisory caches are checked at once, aggregation of their systems is bounded by the range
+const cacheCheckAccountWindow = 10
+
+// Key of the last checked system, systems are checked in windows of consecutive keys
+type systemKey struct {
+	RhAccountID int   `gorm:"column:rh_account_id"`
+	SystemID    int64 `gorm:"column:system_id"`
+}
+
+// Mismatching caches of systems with keys in (after, last] range, the aggregation is limited to the range too
+func systemCacheMismatchesQuery(after, last systemKey) *gorm.DB {
+	counts := database.Db.Table("system_advisories sa").
+		Select(`sa.rh_account_id, sa.system_id, count(*) AS cnt,
+			count(*) FILTER (WHERE am.advisory_type_id = 1) AS enh,
+			count(*) FILTER (WHERE am.advisory_type_id = 2) AS bug,
+			count(*) FILTER (WHERE am.advisory_type_id = 3) AS sec`).
+		Joins("JOIN advisory_metadata am ON am.id = sa.advisory_id").
+		Where("sa.when_patched IS NULL").
+		Where("sa.rh_account_id BETWEEN ? AND ?", after.RhAccountID, last.RhAccountID).
+		Where("(sa.rh_account_id, sa.system_id) > (?, ?) AND (sa.rh_account_id, sa.system_id) <= (?, ?)",
+			after.RhAccountID, after.SystemID, last.RhAccountID, last.SystemID).
+		Group("sa.rh_account_id, sa.system_id")
+
+	return database.Db.Table("system_platform sp").
+		Select(`sp.rh_account_id, sp.id AS system_id, sp.inventory_id,
+			sp.advisory_count_cache AS cached_all, COALESCE(c.cnt, 0) AS expected_all,
+			sp.advisory_enh_count_cache AS cached_enh, COALESCE(c.enh, 0) AS expected_enh,
+			sp.advisory_bug_count_cache AS cached_bug, COALESCE(c.bug, 0) AS expected_bug,
+			sp.advisory_sec_count_cache AS cached_sec, COALESCE(c.sec, 0) AS expected_sec`).
+		Joins("LEFT JOIN (?) c ON c.rh_account_id = sp.rh_account_id AND c.system_id = sp.id", counts).
+		Where("(sp.rh_account_id, sp.id) > (?, ?) AND (sp.rh_account_id, sp.id) <= (?, ?)",
+			after.RhAccountID, after.SystemID, last.RhAccountID, last.SystemID).
+		Where(`sp.advisory_count_cache != COALESCE(c.cnt, 0)
+			OR sp.advisory_enh_count_cache != COALESCE(c.enh, 0)
+			OR sp.advisory_bug_count_cache != COALESCE(c.bug, 0)
+			OR sp.advisory_sec_count_cache != COALESCE(c.sec, 0)`).
+		Order("sp.rh_account_id, sp.id")
+}
+
+// Mismatching advisory caches of accounts with ids in (after, last] range
+func advisoryCacheMismatchesQuery(after, last int) *gorm.DB {
+	// same conditions as in refresh_advisory_caches_multi()
+	counts := database.Db.Table("system_advisories sa").
+		Select("sa.advisory_id, sp.rh_account_id, count(sa.system_id) AS systems_affected").
+		Joins("JOIN system_platform sp ON sa.rh_account_id = sp.rh_account_id AND sa.system_id = sp.id").
+		Where("sp.last_evaluation IS NOT NULL AND sp.stale = false AND sa.when_patched IS NULL").
+		Where("sa.rh_account_id > ? AND sa.rh_account_id <= ?", after, last).
+		Group("sa.advisory_id, sp.rh_account_id")
+	cached := database.Db.Table("advisory_account_data").
+		Where("rh_account_id > ? AND rh_account_id <= ?", after, last)
+
+	return database.Db.Table("(?) aad", cached).
+		Select(`COALESCE(aad.rh_account_id, c.rh_account_id) AS rh_account_id,
+			COALESCE(aad.advisory_id, c.advisory_id) AS advisory_id, am.name AS advisory_name,
+			COALESCE(aad.systems_affected, 0) AS cached, COALESCE(c.systems_affected, 0) AS expected`).
+		Joins(`FULL OUTER JOIN (?) c ON c.rh_account_id = aad.rh_account_id AND c.advisory_id = aad.advisory_id`,
+			counts).
+		Joins("JOIN advisory_metadata am ON am.id = COALESCE(aad.advisory_id, c.advisory_id)").
+		Where("COALESCE(aad.systems_affected, 0) != COALESCE(c.systems_affected, 0)").
+		Order("1, 2")
+}
+
+// Calls fn with mismatches of every window of `window` systems until fn returns false or systems run out
+func forEachSystemCacheWindow(window int, fn func([]SystemCacheMismatch) (bool, error)) error {
+	after := systemKey{}
+	for {
+		var keys []systemKey
+		err := database.Db.Table("system_platform").
+			Select("rh_account_id, id AS system_id").
+			Where("(rh_account_id, id) > (?, ?)", after.RhAccountID, after.SystemID).
+			Order("rh_account_id, id").
+			Limit(window).
+			Scan(&keys).Error
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+
+		last := keys[len(keys)-1]
+		var mismatches []SystemCacheMismatch
+		if err = systemCacheMismatchesQuery(after, last).Scan(&mismatches).Error; err != nil {
+			return err
+		}
+		if next, err := fn(mismatches); err != nil || !next {
+			return err
+		}
+		after = last
+	}
+}
+
+// Calls fn with mismatches of every window of `window` accounts until fn returns false or accounts run out
+func forEachAdvisoryCacheWindow(window int, fn func([]AdvisoryCacheMismatch) (bool, error)) error {
+	after := 0
+	for {
+		var accounts []int
+		err := database.Db.Table("rh_account").
+			Where("id > ?", after).
+			Order("id").
+			Limit(window).
+			Pluck("id", &accounts).Error
+		if err != nil || len(accounts) == 0 {
+			return err
+		}
+
+		last := accounts[len(accounts)-1]
+		var mismatches []AdvisoryCacheMismatch
+		if err = advisoryCacheMismatchesQuery(after, last).Scan(&mismatches).Error; err != nil {
+			return err
+		}
+		if next, err := fn(mismatches); err != nil || !next {
+			return err
+		}
+		after = last
+	}
+}
+
+// GetCacheMismatchReport lists up to `limit` mismatching system and advisory caches
+func GetCacheMismatchReport(limit int) (*CacheMismatchReport, error) {
+	report := CacheMismatchReport{
+		Systems:    []SystemCacheMismatch{},
+		Advisories: []AdvisoryCacheMismatch{},
+	}
+	// load one more item to find out whether the report is complete
+	err := forEachSystemCacheWindow(CacheRepairDefaultBatchSize, func(mismatches []SystemCacheMismatch) (bool, error) {
+		report.Systems = append(report.Systems, mismatches...)
+		return len(report.Systems) <= limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = forEachAdvisoryCacheWindow(cacheCheckAccountWindow, func(mismatches []AdvisoryCacheMismatch) (bool, error) {
+		report.Advisories = append(report.Advisories, mismatches...)
+		return len(report.Advisories) <= limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(report.Systems) > limit {
+		report.Systems = report.Systems[:limit]
+		report.Truncated = true
+	}
+	if len(report.Advisories) > limit {
+		report.Advisories = report.Advisories[:limit]
+		report.Truncated = true
+	}
+	report.Valid = len(report.Systems) == 0 && len(report.Advisories) == 0
+	return &report, nil
+}
+
+// RepairCaches recomputes only divergent caches, in batches, every batch in its own transaction
+// locking just the repaired rows
+func RepairCaches(batchSize int) (*CacheRepairResult, error) {
+	var result CacheRepairResult
+	var err error
+
+	result.SystemsRepaired, err = repairSystemCaches(batchSize)
+	if err != nil {
+		return &result, err
+	}
+	result.AdvisoriesRepaired, err = repairAdvisoryCaches(batchSize)
+	return &result, err
+}
+
+func repairSystemCaches(batchSize int) (int, error) {
+	repaired := 0
+	err := forEachSystemCacheWindow(batchSize, func(batch []SystemCacheMismatch) (bool, error) {
+		if len(batch) == 0 {
+			return true, nil
+		}
+
+		ids := make([][]interface{}, len(batch))
+		for i, s := range batch {
+			ids[i] = []interface{}{s.RhAccountID, s.SystemID}
+		}
+		err := tasks.WithTx(func(tx *gorm.DB) error {
+			return tx.Exec(`UPDATE system_platform sp
+				SET advisory_count_cache = system_advisories_count(sp.id, NULL),
+				    advisory_enh_count_cache = system_advisories_count(sp.id, 1),
+				    advisory_bug_count_cache = system_advisories_count(sp.id, 2),
+				    advisory_sec_count_cache = system_advisories_count(sp.id, 3)
+				WHERE (sp.rh_account_id, sp.id) IN ?`, ids).Error
+		})
+		if err != nil {
+			return false, err
+		}
+		repaired += len(batch)
+		utils.Log("repaired", repaired).Info("Repaired system caches batch")
+		return true, nil
+	})
+	return repaired, err
+}
+
+func repairAdvisoryCaches(batchSize int) (int, error) {
+	repaired := 0
+	err := forEachAdvisoryCacheWindow(cacheCheckAccountWindow, func(mismatches []AdvisoryCacheMismatch) (bool, error) {
+		for len(mismatches) > 0 {
+			n := batchSize
+			if n > len(mismatches) {
+				n = len(mismatches)
+			}
+			if err := repairAdvisoryCachesBatch(mismatches[:n]); err != nil {
+				return false, err
+			}
+			mismatches = mismatches[n:]
+			repaired += n
+			utils.Log("repaired", repaired).Info("Repaired advisory caches batch")
+		}
+		return true, nil
+	})
+	return repaired, err
+}
+
+func repairAdvisoryCachesBatch(batch []AdvisoryCacheMismatch) error {
+	// refresh function works per account
+	accountAdvisories := map[int][]int64{}
+	for _, a := range batch {
+		accountAdvisories[a.RhAccountID] = append(accountAdvisories[a.RhAccountID], a.AdvisoryID)
+	}
+	return tasks.WithTx(func(tx *gorm.DB) error {
+		for acc, advisoryIDs := range accountAdvisories {
+			// every array item needs its own placeholder, slice var would be rendered as a row
+			args := make([]interface{}, 0, len(advisoryIDs)+1)
+			for _, id := range advisoryIDs {
+				args = append(args, id)
+			}
+			args = append(args, acc)
+			placeholders := strings.TrimSuffix(strings.Repeat("?,", len(advisoryIDs)), ",")
+			err := tx.Exec(fmt.Sprintf("SELECT refresh_advisory_caches_multi(ARRAY[%s]::int[], ?)", placeholders),
+				args...).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func RunCacheRepair() {
+	tasks.HandleContextCancel(tasks.WaitAndExit)
+	configure()
+	utils.Log().Info("Repairing caches")
+
+	result, err := RepairCaches(cacheRepairBatchSize)
+	if err != nil {
+		utils.Log("err", err.Error()).Error("Cache repair failed")
+		return
+	}
+	utils.Log("systems", result.SystemsRepaired, "advisories", result.AdvisoriesRepaired).Info("Caches repaired")
+}
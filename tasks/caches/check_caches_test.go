@@ -0,0 +1,56 @@
+package caches
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheMismatchReportValid(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	report, err := GetCacheMismatchReport(CacheReportDefaultLimit)
+	assert.Nil(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 0, len(report.Systems))
+	assert.Equal(t, 0, len(report.Advisories))
+}
+
+func TestCacheMismatchReportAndRepair(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	// set wrong numbers of caches
+	assert.Nil(t, database.Db.Model(&models.SystemPlatform{}).
+		Where("rh_account_id = 1 AND id IN (1, 2)").Update("advisory_count_cache", 100).Error)
+	assert.Nil(t, database.Db.Model(&models.AdvisoryAccountData{}).
+		Where("advisory_id = 1 AND rh_account_id = 2").Update("systems_affected", 5).Error)
+
+	report, err := GetCacheMismatchReport(1)
+	assert.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.True(t, report.Truncated)
+	assert.Equal(t, 1, len(report.Systems))
+	assert.Equal(t, int64(1), report.Systems[0].SystemID)
+	assert.Equal(t, 100, report.Systems[0].CachedAll)
+	assert.Equal(t, 1, len(report.Advisories))
+	assert.Equal(t, AdvisoryCacheMismatch{RhAccountID: 2, AdvisoryID: 1, AdvisoryName: "RH-1", Cached: 5, Expected: 2},
+		report.Advisories[0])
+
+	result, err := RepairCaches(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.SystemsRepaired)
+	assert.Equal(t, 1, result.AdvisoriesRepaired)
+
+	report, err = GetCacheMismatchReport(CacheReportDefaultLimit)
+	assert.Nil(t, err)
+	assert.True(t, report.Valid)
+	database.CheckCachesValid(t)
+}
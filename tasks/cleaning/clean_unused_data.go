@@ -5,16 +5,19 @@ import (
 	"app/base/database"
 	"app/base/models"
 	"app/base/utils"
+	"time"
 )
 
 var (
-	enableUnusedDataDelete bool
-	deleteUnusedDataLimit  int
+	enableUnusedDataDelete      bool
+	deleteUnusedDataLimit       int
+	shadowEvalDiffRetentionDays int
 )
 
 func init() {
 	deleteUnusedDataLimit = utils.GetIntEnvOrDefault("DELETE_UNUSED_DATA_LIMIT", 1000)
 	enableUnusedDataDelete = utils.GetBoolEnvOrDefault("ENABLE_UNUSED_DATA_DELETE", true)
+	shadowEvalDiffRetentionDays = utils.GetIntEnvOrDefault("SHADOW_EVAL_DIFF_RETENTION_DAYS", 30)
 }
 
 func RunDeleteUnusedData() {
@@ -23,6 +26,7 @@ func RunDeleteUnusedData() {
 
 	deleteUnusedPackages()
 	deleteUnusedAdvisories()
+	deleteOldShadowEvalDiffs()
 }
 
 func deleteUnusedPackages() {
@@ -77,3 +81,26 @@ func deleteUnusedAdvisories() {
 	tx.Commit()
 	utils.Log().Info("DeleteUnusedAdvisories tasks performed successfully")
 }
+
+func deleteOldShadowEvalDiffs() {
+	if !enableUnusedDataDelete {
+		return
+	}
+	tx := database.Db.WithContext(base.Context).Begin()
+	defer tx.Rollback()
+
+	// shadow evaluation differences are needed only for recent comparison of evaluation sources
+	subq := tx.Select("id").Table("shadow_eval_diff").
+		Where("created < ?", time.Now().AddDate(0, 0, -shadowEvalDiffRetentionDays)).
+		Limit(deleteUnusedDataLimit)
+
+	err := tx.Delete(&models.ShadowEvalDiff{}, "id IN (?)", subq).Error
+
+	if err != nil {
+		utils.Log("err", err.Error()).Error("DeleteOldShadowEvalDiffs")
+		return
+	}
+
+	tx.Commit()
+	utils.Log().Info("DeleteOldShadowEvalDiffs tasks performed successfully")
+}
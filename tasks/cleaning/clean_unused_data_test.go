@@ -6,6 +6,7 @@ import (
 	"app/base/models"
 	"app/base/utils"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -99,3 +100,27 @@ func TestCleanUnusedAdvisories(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, beforeAdvCount, afterAdvCount)
 }
+
+func TestDeleteOldShadowEvalDiffs(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+
+	inventoryID := "00000000-0000-0000-0000-000000000001"
+	diffs := []models.ShadowEvalDiff{
+		{RhAccountID: 1, InventoryID: inventoryID, Source: "old",
+			Created: time.Now().AddDate(0, 0, -shadowEvalDiffRetentionDays-1)},
+		{RhAccountID: 1, InventoryID: inventoryID, Source: "recent", Created: time.Now()},
+	}
+	assert.Nil(t, database.Db.Create(&diffs).Error)
+
+	currentDeleteStatus := enableUnusedDataDelete
+	enableUnusedDataDelete = true
+	deleteOldShadowEvalDiffs()
+	enableUnusedDataDelete = currentDeleteStatus
+
+	var sources []string
+	assert.Nil(t, database.Db.Model(&models.ShadowEvalDiff{}).Where("inventory_id = ?", inventoryID).
+		Pluck("source", &sources).Error)
+	assert.Equal(t, []string{"recent"}, sources)
+	assert.Nil(t, database.Db.Where("inventory_id = ?", inventoryID).Delete(&models.ShadowEvalDiff{}).Error)
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
 )
 
 const SyncBatchSize = 1000 // Should be < 5000
@@ -252,7 +253,9 @@ func storeAdvisories(data map[string]vmaas.ErrataResponseErrataList) error {
 	for _, u := range toUpdate {
 		if err := database.Db.Table("advisory_metadata").Select(updateCols).Updates(u).Error; err != nil {
 			utils.Log("err", err).Error("couldn't update advisory_metadata")
+			continue
 		}
+		syncCounts.AdvisoriesUpdated++
 	}
 
 	tx = database.OnConflictUpdate(database.Db, "name", updateCols...)
@@ -260,11 +263,80 @@ func storeAdvisories(data map[string]vmaas.ErrataResponseErrataList) error {
 	if err != nil {
 		return errors.WithMessage(err, "Storing advisories")
 	}
+	syncCounts.AdvisoriesInserted += len(toStore)
+
+	if err = storeAdvisoryModules(data); err != nil {
+		return errors.WithMessage(err, "Storing advisory modules")
+	}
 
 	storeAdvisoriesCnt.WithLabelValues("success").Add(float64(len(data)))
 	return nil
 }
 
+type moduleKey struct {
+	name   string
+	stream string
+}
+
+// Stores module streams of the advisories together with their modular package builds,
+// it's the only source telling which module stream an installed package belongs to
+func storeAdvisoryModules(data map[string]vmaas.ErrataResponseErrataList) error {
+	modulePackages := map[moduleKey]map[utils.Nevra]bool{}
+	names := []string{}
+	for errataName, erratum := range data {
+		for _, m := range erratum.ModulesList {
+			key := moduleKey{m.ModuleName, m.ModuleStream}
+			if strings.TrimSpace(key.name) == "" || strings.TrimSpace(key.stream) == "" {
+				continue
+			}
+			if _, has := modulePackages[key]; !has {
+				modulePackages[key] = map[utils.Nevra]bool{}
+				names = append(names, key.name)
+			}
+			for _, p := range m.PackageList {
+				nevra, err := utils.ParseNevra(p)
+				if err != nil {
+					utils.Log("err", err.Error(), "erratum", errataName).Warn("Invalid module package nevra")
+					continue
+				}
+				modulePackages[key][*nevra] = true
+			}
+		}
+	}
+	if len(modulePackages) == 0 {
+		return nil
+	}
+
+	modules := make(models.ModuleSlice, 0, len(modulePackages))
+	for key := range modulePackages {
+		modules = append(modules, models.Module{Name: key.name, Stream: key.stream})
+	}
+	tx := database.Db.Clauses(clause.OnConflict{DoNothing: true})
+	if err := tx.CreateInBatches(&modules, SyncBatchSize).Error; err != nil {
+		return errors.Wrap(err, "Storing modules")
+	}
+
+	// IDs of already existing modules are not returned on conflict
+	var existingModules models.ModuleSlice
+	if err := database.Db.Where("name IN (?)", names).Find(&existingModules).Error; err != nil {
+		return errors.Wrap(err, "Loading modules")
+	}
+	toStore := models.ModulePackageSlice{}
+	for _, m := range existingModules {
+		for nevra := range modulePackages[moduleKey{m.Name, m.Stream}] {
+			toStore = append(toStore, models.ModulePackage{ModuleID: m.ID, Name: nevra.Name, EVRA: nevra.EVRAString()})
+		}
+	}
+	if len(toStore) == 0 {
+		return nil
+	}
+	tx = database.Db.Clauses(clause.OnConflict{DoNothing: true})
+	if err := tx.CreateInBatches(&toStore, SyncBatchSize).Error; err != nil {
+		return errors.Wrap(err, "Storing module packages")
+	}
+	return nil
+}
+
 func downloadAndProcessErratasPage(iPage int, modifiedSince *string) (*vmaas.ErrataResponse, error) {
 	errataResponse, err := vmaasErrataRequest(iPage, modifiedSince, advisoryPageSize)
 	if err != nil {
@@ -286,6 +358,9 @@ func vmaasErrataRequest(iPage int, modifiedSince *string, pageSize int) (*vmaas.
 		ThirdParty:    utils.PtrBool(true),
 		ModifiedSince: modifiedSince,
 	}
+	if vmaasDump != nil {
+		return vmaasDump.ErrataPage(&errataRequest)
+	}
 
 	vmaasCallFunc := func() (interface{}, *http.Response, error) {
 		vmaasData := vmaas.ErrataResponse{}
@@ -164,6 +164,58 @@ func TestSaveAdvisories(t *testing.T) {
 	assert.Nil(t, database.Db.Unscoped().Where("url = ?", "TEST").Delete(&models.AdvisoryMetadata{}).Error)
 }
 
+func TestStoreAdvisoryModules(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+
+	data := map[string]vmaas.ErrataResponseErrataList{
+		"ER1": {ModulesList: []vmaas.ErrataResponseModule{
+			{ModuleName: "firefox", ModuleStream: "stable",
+				PackageList: []string{"firefox-78.0.1-1.module+el8.4.0+2+abcdef.x86_64", "invalid"}},
+			{ModuleName: "nodejs", ModuleStream: "14", PackageList: []string{"nodejs-1:14.1-1.module+el8.x86_64"}},
+			{ModuleName: "", ModuleStream: "1", PackageList: []string{"unnamed-1-1.module+el8.x86_64"}},
+		}},
+	}
+	modules := []string{"firefox:stable", "nodejs:14", ":1"}
+	nevras := []string{"firefox-78.0.1-1.module+el8.4.0+2+abcdef.x86_64", "invalid",
+		"nodejs-1:14.1-1.module+el8.x86_64", "unnamed-1-1.module+el8.x86_64"}
+	defer func() {
+		assert.Nil(t, database.Db.Exec(`DELETE FROM module_package mp USING module m
+			WHERE m.id = mp.module_id AND m.name || ':' || m.stream IN (?) AND mp.name || '-' || mp.evra IN (?)`,
+			modules, nevras).Error)
+		// firefox:stable module is stored by fixtures
+		assert.Nil(t, database.Db.Exec("DELETE FROM module WHERE name || ':' || stream IN (?)",
+			[]string{"nodejs:14", ":1"}).Error)
+	}()
+
+	// storing is idempotent
+	assert.Nil(t, storeAdvisoryModules(data))
+	assert.Nil(t, storeAdvisoryModules(data))
+
+	type modulePackage struct {
+		Module  string
+		Stream  string
+		Package string
+		Evra    string
+	}
+	var modulePackages []modulePackage
+	assert.Nil(t, database.Db.Table("module_package mp").Joins("JOIN module m ON m.id = mp.module_id").
+		Select("m.name AS module, m.stream, mp.name AS package, mp.evra").
+		Where("m.name || ':' || m.stream IN (?)", modules).
+		Where("mp.name || '-' || mp.evra IN (?)", nevras).
+		Order("m.name, mp.evra").
+		Scan(&modulePackages).Error)
+	// unnamed module and invalid nevra are skipped
+	assert.Equal(t, []modulePackage{
+		{Module: "firefox", Stream: "stable", Package: "firefox", Evra: "78.0.1-1.module+el8.4.0+2+abcdef.x86_64"},
+		{Module: "nodejs", Stream: "14", Package: "nodejs", Evra: "1:14.1-1.module+el8.x86_64"},
+	}, modulePackages)
+
+	var unnamed int64
+	assert.Nil(t, database.Db.Table("module").Where("stream = ? AND name = ''", "1").Count(&unnamed).Error)
+	assert.Equal(t, int64(0), unnamed)
+}
+
 func TestSyncAdvisories(t *testing.T) {
 	utils.SkipWithoutDB(t)
 	core.SetupTestEnvironment()
@@ -3,47 +3,152 @@ package vmaas_sync //nolint:revive,stylecheck
 import (
 	"app/base"
 	"app/base/database"
-	"app/base/types"
 	"app/base/utils"
 	"app/base/vmaas"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
-func isSyncNeeded() bool {
+// Keys of the last synced VMaaS change timestamps of the entity types
+const (
+	LastSyncErrata   = "last_sync_errata"
+	LastSyncPackages = "last_sync_packages"
+	LastSyncRepos    = "last_sync_repos"
+	LastSyncCves     = "last_sync_cves"
+)
+
+type entitySync struct {
+	key     string     // timestamp_kv key storing the last synced change
+	changed *time.Time // last change of the entity type in VMaaS, nil when unknown
+	needed  bool
+}
+
+// Entity types which need to be synced from VMaaS
+type syncPlan struct {
+	errata   entitySync
+	packages entitySync
+	repos    entitySync
+	cves     entitySync
+}
+
+func newSyncPlan(dbchange *vmaas.DBChangeResponse) *syncPlan {
+	errataChanged := dbchange.GetErrataChanges()
+	reposChanged := dbchange.GetRepositoryChanges()
+	// package list is built from errata and repositories content
+	var packagesChanged *time.Time
+	if errataChanged != nil && reposChanged != nil {
+		packagesChanged = errataChanged
+		if reposChanged.After(*errataChanged) {
+			packagesChanged = reposChanged
+		}
+	}
+
+	return &syncPlan{
+		errata:   entitySync{key: LastSyncErrata, changed: errataChanged},
+		packages: entitySync{key: LastSyncPackages, changed: packagesChanged},
+		repos:    entitySync{key: LastSyncRepos, changed: reposChanged},
+		cves:     entitySync{key: LastSyncCves, changed: dbchange.GetCVEChanges()},
+	}
+}
+
+func (p *syncPlan) entities() []*entitySync {
+	return []*entitySync{&p.errata, &p.packages, &p.repos, &p.cves}
+}
+
+func (p *syncPlan) setAll() *syncPlan {
+	for _, e := range p.entities() {
+		e.needed = true
+	}
+	return p
+}
+
+func (p *syncPlan) any() bool {
+	for _, e := range p.entities() {
+		if e.needed {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *syncPlan) all() bool {
+	for _, e := range p.entities() {
+		if !e.needed {
+			return false
+		}
+	}
+	return true
+}
+
+// advisories contain CVE lists so changed CVEs need advisories sync as well
+func (p *syncPlan) advisoriesNeeded() bool {
+	return p.errata.needed || p.cves.needed
+}
+
+func (e *entitySync) isNeeded() bool {
+	if e.changed == nil {
+		return true
+	}
+	synced := getLastSync(e.key)
+	return synced == nil || synced.Time().Before(*e.changed)
+}
+
+// Store the synced change timestamp, with sub-second precision used by VMaaS
+func (e *entitySync) markSynced() {
+	if e.changed == nil {
+		return
+	}
+	err := database.UpdateTimestampKVValueStr(e.changed.Format(time.RFC3339Nano), e.key)
+	if err != nil {
+		utils.Log("err", err.Error(), "key", e.key).Error("Unable to update last sync timestamp")
+	}
+}
+
+// Sync plan with all entity types, change timestamps are loaded from VMaaS if available
+func fullSyncPlan() *syncPlan {
+	dbchange, err := getDBChange()
+	if err != nil {
+		utils.Log("err", err.Error()).Warn("Unable to load vmaas dbchange")
+	}
+	return newSyncPlan(dbchange).setAll()
+}
+
+// Compare VMaaS change timestamps of entity types with the last synced ones
+func getSyncPlan() *syncPlan {
 	if vmaasClient == nil {
 		panic("VMaaS client is nil")
 	}
 
+	dbchange, err := vmaasDBChangeRequest()
+	if err != nil {
+		utils.Log("err", err).Error("Could'n query vmaas dbchange")
+		return newSyncPlan(nil).setAll()
+	}
+
+	plan := newSyncPlan(dbchange)
 	ts, err := database.GetTimestampKVValue(LastSync)
 	if err != nil || ts == nil {
 		utils.Log("ts", ts, "err", err).Info("Last sync disabled - sync needed")
-		return true
+		return plan.setAll()
 	}
 
-	var emptyTS types.Rfc3339TimestampNoT
-	dbchange, err := vmaasDBChangeRequest()
-	if err != nil {
-		utils.Log("err", err).Error("Could'n query vmaas dbchange")
-		return true
+	for _, e := range plan.entities() {
+		e.needed = e.isNeeded()
+		utils.Log("entity", e.key, "changed", e.changed, "needed", e.needed).Info()
 	}
-	// check only `exported` timestamp
-	// we can possibly check each timestamp to reduce syncing of CVEs, errata, and repos
-	exported := dbchange.GetExported()
-	utils.Log("last sync", ts, "dbchange.exported", exported).Info()
-	switch {
-	case exported == emptyTS:
-		return true
-	case ts.Time().Before(*exported.Time()):
-		return true
-	default:
+	if !plan.any() {
 		utils.Log().Info("No need to sync vmaas")
-		return false
 	}
+	return plan
 }
 
 func vmaasDBChangeRequest() (*vmaas.DBChangeResponse, error) {
+	if vmaasDump != nil {
+		return vmaasDump.GetDBChange(), nil
+	}
+
 	vmaasCallFunc := func() (interface{}, *http.Response, error) {
 		response := vmaas.DBChangeResponse{}
 		resp, err := vmaasClient.Request(&base.Context, http.MethodGet, vmaasDBChangeURL, nil, &response)
@@ -0,0 +1,82 @@
+package vmaas_sync //nolint:revive,stylecheck
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"app/base/vmaas"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDBChange(t *testing.T) *vmaas.DBChangeResponse {
+	var dbchange vmaas.DBChangeResponse
+	assert.Nil(t, json.Unmarshal([]byte(`{"dbchange": {
+		"errata_changes": "2222-04-16 20:07:58.500192+00",
+		"cve_changes": "2222-04-16 20:06:47.214266+00",
+		"repository_changes": "2222-04-16 20:07:55.214266+00",
+		"exported": "2222-04-16 20:07:59.235962+00"}}`), &dbchange))
+	return &dbchange
+}
+
+func deleteLastSyncTimestamps(t *testing.T) {
+	assert.Nil(t, database.Db.Where("name IN (?)",
+		[]string{LastSync, LastSyncErrata, LastSyncPackages, LastSyncRepos, LastSyncCves}).
+		Delete(&models.TimestampKV{}).Error)
+}
+
+func TestNewSyncPlan(t *testing.T) {
+	plan := newSyncPlan(testDBChange(t))
+	assert.Equal(t, time.Date(2222, 4, 16, 20, 7, 58, 500192000, time.UTC), plan.errata.changed.UTC())
+	assert.Equal(t, time.Date(2222, 4, 16, 20, 6, 47, 214266000, time.UTC), plan.cves.changed.UTC())
+	assert.Equal(t, time.Date(2222, 4, 16, 20, 7, 55, 214266000, time.UTC), plan.repos.changed.UTC())
+	// later of errata and repos changes
+	assert.Equal(t, *plan.errata.changed, *plan.packages.changed)
+	assert.False(t, plan.any())
+
+	plan = newSyncPlan(nil)
+	for _, e := range plan.entities() {
+		assert.Nil(t, e.changed)
+	}
+	assert.True(t, plan.setAll().all())
+}
+
+func TestSyncPlanAdvisories(t *testing.T) {
+	plan := newSyncPlan(nil)
+	assert.False(t, plan.advisoriesNeeded())
+	plan.cves.needed = true
+	assert.True(t, plan.advisoriesNeeded())
+}
+
+func TestGetSyncPlan(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+	defer deleteLastSyncTimestamps(t)
+
+	// no last sync
+	deleteLastSyncTimestamps(t)
+	assert.True(t, getSyncPlan().all())
+
+	database.UpdateTimestampKVValue(time.Now(), LastSync)
+	assert.True(t, getSyncPlan().all())
+
+	// store synced changes with sub-second precision
+	synced := newSyncPlan(testDBChange(t))
+	synced.errata.markSynced()
+	synced.repos.markSynced()
+	plan := getSyncPlan()
+	assert.False(t, plan.errata.needed)
+	assert.False(t, plan.repos.needed)
+	assert.True(t, plan.packages.needed)
+	assert.True(t, plan.cves.needed)
+	assert.True(t, plan.advisoriesNeeded())
+
+	synced.packages.markSynced()
+	synced.cves.markSynced()
+	assert.False(t, getSyncPlan().any())
+}
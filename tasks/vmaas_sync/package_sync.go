@@ -91,6 +91,9 @@ func vmaasPkgListRequest(iPage int, modifiedSince *string) (*vmaas.PkgListRespon
 		PageSize:      packagesPageSize,
 		ModifiedSince: modifiedSince,
 	}
+	if vmaasDump != nil {
+		return vmaasDump.PkgListPage(&request), nil
+	}
 
 	vmaasCallFunc := func() (interface{}, *http.Response, error) {
 		vmaasData := vmaas.PkgListResponse{}
@@ -279,10 +282,12 @@ func storeOrUpdate(tx *gorm.DB, pkgs models.PackageSlice) error {
 		storePackagesCnt.WithLabelValues("error").Add(float64(len(toStore)))
 		return errors.Wrap(err, "Packages bulk insert failed")
 	}
+	syncCounts.PackagesInserted += len(toStore)
 	if updErr != nil {
 		storePackagesCnt.WithLabelValues("success").Add(float64(len(toStore)))
 	} else {
 		storePackagesCnt.WithLabelValues("success").Add(float64(len(pkgs)))
+		syncCounts.PackagesUpdated += len(toUpdate)
 	}
 	utils.Log().Info("Packages stored")
 	return updErr
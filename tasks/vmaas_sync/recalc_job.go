@@ -0,0 +1,122 @@
+package vmaas_sync //nolint:revive,stylecheck
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/mqueue"
+	"app/base/utils"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var ErrRecalcDisabled = errors.New("recalc messages sending disabled")
+
+// RecalcScope selects systems to re-evaluate, all the set conditions have to match.
+// Empty scope selects all systems.
+type RecalcScope struct {
+	OrgIDs       []string `json:"org_ids,omitempty"`
+	InventoryIDs []string `json:"inventory_ids,omitempty"`
+	BaselineIDs  []int64  `json:"baseline_ids,omitempty"`
+	RepoNames    []string `json:"repo_names,omitempty"`
+	// Systems evaluated before the timestamp or never evaluated
+	LastEvaluationBefore *time.Time `json:"last_evaluation_before,omitempty"`
+	// Systems with any of the advisories applicable
+	Advisories []string `json:"advisories,omitempty"`
+}
+
+func (s *RecalcScope) Validate() error {
+	for _, id := range s.InventoryIDs {
+		if !utils.IsValidUUID(id) {
+			return errors.Errorf("invalid inventory id: %s", id)
+		}
+	}
+	return nil
+}
+
+func scopedInventoryIDsQuery(scope *RecalcScope) *gorm.DB {
+	query := database.Db.Table("system_platform sp").
+		Select("sp.inventory_id, sp.rh_account_id, ra.org_id").
+		Joins("JOIN rh_account ra on ra.id = sp.rh_account_id")
+
+	if len(scope.OrgIDs) > 0 {
+		query = query.Where("ra.org_id IN (?)", scope.OrgIDs)
+	}
+	if len(scope.InventoryIDs) > 0 {
+		query = query.Where("sp.inventory_id IN (?)", scope.InventoryIDs)
+	}
+	if len(scope.BaselineIDs) > 0 {
+		query = query.Where("sp.baseline_id IN (?)", scope.BaselineIDs)
+	}
+	if len(scope.RepoNames) > 0 {
+		query = query.Where(`EXISTS (SELECT 1 FROM system_repo sr JOIN repo r ON r.id = sr.repo_id
+			WHERE sr.rh_account_id = sp.rh_account_id AND sr.system_id = sp.id AND r.name IN (?))`, scope.RepoNames)
+	}
+	if scope.LastEvaluationBefore != nil {
+		query = query.Where("(sp.last_evaluation IS NULL OR sp.last_evaluation < ?)", *scope.LastEvaluationBefore)
+	}
+	if len(scope.Advisories) > 0 {
+		query = query.Where(`EXISTS (SELECT 1 FROM system_advisories sa JOIN advisory_metadata am ON am.id = sa.advisory_id
+			WHERE sa.rh_account_id = sp.rh_account_id AND sa.system_id = sp.id AND sa.when_patched IS NULL
+			AND am.name IN (?))`, scope.Advisories)
+	}
+	return query.Order("ra.id, sp.id")
+}
+
+func getScopedInventoryIDs(scope *RecalcScope) (mqueue.EvalDataSlice, error) {
+	var inventoryAIDs mqueue.EvalDataSlice
+	err := scopedInventoryIDsQuery(scope).Scan(&inventoryAIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return inventoryAIDs, nil
+}
+
+// SendScopedReevaluationMessages creates a re-calc job and sends systems matching the scope to re-evaluation.
+// Evaluator acknowledges evaluated systems in the job so the progress can be tracked.
+func SendScopedReevaluationMessages(scope *RecalcScope) (*models.RecalcJob, error) {
+	if !enableRecalcMessagesSend {
+		return nil, ErrRecalcDisabled
+	}
+
+	inventoryAIDs, err := getScopedInventoryIDs(scope)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading systems in scope failed")
+	}
+
+	scopeJSON, err := json.Marshal(scope)
+	if err != nil {
+		return nil, errors.Wrap(err, "serializing scope failed")
+	}
+
+	now := time.Now()
+	job := models.RecalcJob{Scope: scopeJSON, Created: now, SystemsTotal: len(inventoryAIDs)}
+	if len(inventoryAIDs) == 0 {
+		job.Finished = &now
+	}
+	if err = database.Db.Create(&job).Error; err != nil {
+		return nil, errors.Wrap(err, "creating re-calc job failed")
+	}
+
+	for i := range inventoryAIDs {
+		inventoryAIDs[i].RecalcJobID = &job.ID
+	}
+
+	tStart := time.Now()
+	defer utils.ObserveSecondsSince(tStart, messageSendDuration)
+	err = mqueue.SendMessages(base.Context, evalWriter, &inventoryAIDs)
+	if err != nil {
+		return &job, errors.Wrap(err, "sending to re-evaluate failed")
+	}
+
+	job.MessagesQueued = inventoryAIDs.CountMessages()
+	err = database.Db.Model(&job).Update("messages_queued", job.MessagesQueued).Error
+	if err != nil {
+		return &job, errors.Wrap(err, "updating re-calc job failed")
+	}
+	utils.Log("job", job.ID, "count", len(inventoryAIDs)).Info("systems in scope sent to re-calc")
+	return &job, nil
+}
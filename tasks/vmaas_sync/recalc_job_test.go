@@ -0,0 +1,71 @@
+package vmaas_sync //nolint:revive,stylecheck
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scopedIDs(t *testing.T, scope RecalcScope) []string {
+	inventoryAIDs, err := getScopedInventoryIDs(&scope)
+	assert.Nil(t, err)
+	ids := make([]string, len(inventoryAIDs))
+	for i, inv := range inventoryAIDs {
+		ids[i] = inv.InventoryID
+	}
+	return ids
+}
+
+func TestGetScopedInventoryIDs(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	assert.Equal(t, len(database.GetAllSystems(t)), len(scopedIDs(t, RecalcScope{})))
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000002"},
+		scopedIDs(t, RecalcScope{RepoNames: []string{"repo2"}}))
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"},
+		scopedIDs(t, RecalcScope{BaselineIDs: []int64{1}}))
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000003"},
+		scopedIDs(t, RecalcScope{OrgIDs: []string{"org_1"}, InventoryIDs: []string{
+			"00000000-0000-0000-0000-000000000003", "00000000-0000-0000-0000-000000000012"}}))
+	assert.Equal(t, 0, len(scopedIDs(t, RecalcScope{OrgIDs: []string{"org_1"}, Advisories: []string{"RH-X"}})))
+	before := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range scopedIDs(t, RecalcScope{LastEvaluationBefore: &before}) {
+		var system models.SystemPlatform
+		assert.Nil(t, database.Db.Where("inventory_id = ?", id).Take(&system).Error)
+		assert.True(t, system.LastEvaluation == nil || system.LastEvaluation.Before(before))
+	}
+}
+
+func TestSendScopedReevaluationMessages(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+	evalWriter = &mockKafkaWriter{}
+	msgs = nil
+
+	job, err := SendScopedReevaluationMessages(&RecalcScope{RepoNames: []string{"repo1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, job.SystemsTotal)
+	assert.Equal(t, 1, job.MessagesQueued)
+	assert.Equal(t, 1, len(msgs))
+	assert.Contains(t, string(msgs[0].Value), `"recalc_job_id":`)
+
+	var stored models.RecalcJob
+	assert.Nil(t, database.Db.Where("id = ?", job.ID).Take(&stored).Error)
+	assert.Equal(t, 1, stored.MessagesQueued)
+	assert.Nil(t, stored.Finished)
+	assert.Equal(t, `{"repo_names": ["repo1"]}`, string(stored.Scope))
+	assert.Nil(t, database.Db.Delete(&stored).Error)
+}
+
+func TestRecalcScopeValidate(t *testing.T) {
+	assert.Nil(t, (&RecalcScope{InventoryIDs: []string{"00000000-0000-0000-0000-000000000001"}}).Validate())
+	assert.NotNil(t, (&RecalcScope{InventoryIDs: []string{"invalid"}}).Validate())
+}
@@ -73,18 +73,10 @@ func getUpdatedRepos(syncStart time.Time, modifiedSince *string) ([]string, []st
 			ModifiedSince:  modifiedSince,
 		}
 
-		vmaasCallFunc := func() (interface{}, *http.Response, error) {
-			vmaasData := vmaas.ReposResponse{}
-			resp, err := vmaasClient.Request(&base.Context, http.MethodPost, vmaasReposURL, &reposReq, &vmaasData)
-			return &vmaasData, resp, err
-		}
-
-		vmaasDataPtr, err := utils.HTTPCallRetry(base.Context, vmaasCallFunc, vmaasCallExpRetry, vmaasCallMaxRetries)
+		repos, err := vmaasReposRequest(&reposReq)
 		if err != nil {
 			return nil, nil, err
 		}
-		vmaasCallCnt.WithLabelValues("success").Inc()
-		repos := vmaasDataPtr.(*vmaas.ReposResponse)
 		if repos.Pages < 1 {
 			utils.Log().Info("No repos returned from VMaaS")
 			break
@@ -119,3 +111,22 @@ func getUpdatedRepos(syncStart time.Time, modifiedSince *string) ([]string, []st
 	utils.Log("redhat", len(reposRedHat), "thirdparty", len(reposThirdParty)).Info("Repos downloading complete")
 	return reposRedHat, reposThirdParty, nil
 }
+
+func vmaasReposRequest(reposReq *vmaas.ReposRequest) (*vmaas.ReposResponse, error) {
+	if vmaasDump != nil {
+		return vmaasDump.ReposPage(reposReq)
+	}
+
+	vmaasCallFunc := func() (interface{}, *http.Response, error) {
+		vmaasData := vmaas.ReposResponse{}
+		resp, err := vmaasClient.Request(&base.Context, http.MethodPost, vmaasReposURL, reposReq, &vmaasData)
+		return &vmaasData, resp, err
+	}
+
+	vmaasDataPtr, err := utils.HTTPCallRetry(base.Context, vmaasCallFunc, vmaasCallExpRetry, vmaasCallMaxRetries)
+	if err != nil {
+		return nil, err
+	}
+	vmaasCallCnt.WithLabelValues("success").Inc()
+	return vmaasDataPtr.(*vmaas.ReposResponse), nil
+}
@@ -18,14 +18,18 @@ func syncRepos(syncStart time.Time) error {
 		return nil
 	}
 
-	err = database.Db.Exec("UPDATE repo SET third_party = false WHERE name in (?)", redhatRepos).Error
-	if err != nil {
-		return errors.WithMessage(err, "Updating repo third_party flag for redhat content")
+	// update only repos with changed flag to count them
+	res := database.Db.Exec("UPDATE repo SET third_party = false WHERE name in (?) AND third_party = true", redhatRepos)
+	if res.Error != nil {
+		return errors.WithMessage(res.Error, "Updating repo third_party flag for redhat content")
 	}
+	syncCounts.ReposUpdated += int(res.RowsAffected)
 
-	err = database.Db.Exec("UPDATE repo SET third_party = true WHERE name NOT IN (?)", redhatRepos).Error
-	if err != nil {
-		return errors.WithMessage(err, "Updating repo third_party flag for third party content")
+	res = database.Db.Exec("UPDATE repo SET third_party = true WHERE name NOT IN (?) AND third_party = false",
+		redhatRepos)
+	if res.Error != nil {
+		return errors.WithMessage(res.Error, "Updating repo third_party flag for third party content")
 	}
+	syncCounts.ReposUpdated += int(res.RowsAffected)
 	return nil
 }
@@ -0,0 +1,136 @@
+package vmaas_sync //nolint:revive,stylecheck
+
+import (
+	"app/base"
+	"app/base/database"
+	"app/base/models"
+	"app/base/types"
+	"app/base/utils"
+	"app/base/vmaas"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrSyncRunning = errors.New("sync already running")
+
+// Counts of synced data in the current sync run
+type SyncCounts struct {
+	AdvisoriesInserted int
+	AdvisoriesUpdated  int
+	PackagesInserted   int
+	PackagesUpdated    int
+	ReposUpdated       int
+}
+
+var syncCounts SyncCounts
+
+// Record sync as running, unique index on unfinished runs allows only one sync across all instances at a time
+func startSyncRun(lastSyncTS *string) (*models.SyncRun, error) {
+	if err := expireSyncRuns(); err != nil {
+		return nil, errors.Wrap(err, "Expiring sync runs")
+	}
+
+	run := models.SyncRun{Started: time.Now()}
+	run.ModifiedSince = parseModifiedSince(lastSyncTS)
+	if err := database.Db.Create(&run).Error; err != nil {
+		if database.IsPgErrorCode(err, database.PgErrorDuplicateKey) {
+			return nil, ErrSyncRunning
+		}
+		return nil, errors.Wrap(err, "Recording sync run")
+	}
+	syncCounts = SyncCounts{}
+	return &run, nil
+}
+
+// Mark stale unfinished runs as finished so they don't block new syncs
+func expireSyncRuns() error {
+	tx := database.Db.Model(&models.SyncRun{}).
+		Where("finished IS NULL AND started < ?", time.Now().Add(-syncRunTimeout)).
+		Updates(map[string]interface{}{"finished": time.Now(), "error": "sync run expired"})
+	if tx.RowsAffected > 0 {
+		utils.Log("count", tx.RowsAffected).Warn("Expired unfinished sync runs")
+	}
+	return tx.Error
+}
+
+// Store sync results and mark sync as finished
+func finishSyncRun(run *models.SyncRun, modifiedSince *string, syncErr error) {
+	now := time.Now()
+	run.Finished = &now
+	run.ModifiedSince = parseModifiedSince(modifiedSince)
+	run.AdvisoriesInserted = syncCounts.AdvisoriesInserted
+	run.AdvisoriesUpdated = syncCounts.AdvisoriesUpdated
+	run.PackagesInserted = syncCounts.PackagesInserted
+	run.PackagesUpdated = syncCounts.PackagesUpdated
+	run.ReposUpdated = syncCounts.ReposUpdated
+	run.DBChange = getDBChangeJSON()
+	if syncErr != nil {
+		errStr := syncErr.Error()
+		run.Error = &errStr
+	}
+
+	err := database.Db.Select("finished", "modified_since", "advisories_inserted", "advisories_updated",
+		"packages_inserted", "packages_updated", "repos_updated", "dbchange", "error").
+		Updates(run).Error
+	if err != nil {
+		utils.Log("err", err.Error(), "id", run.ID).Error("Unable to record sync run results")
+	}
+}
+
+func parseModifiedSince(modifiedSince *string) *time.Time {
+	if modifiedSince == nil {
+		return nil
+	}
+	ts, err := time.Parse(time.RFC3339, *modifiedSince)
+	if err != nil {
+		utils.Log("modified_since", *modifiedSince).Warn("Unable to parse modified since timestamp")
+		return nil
+	}
+	return &ts
+}
+
+// Single dbchange call without retrying so the sync can't hang on unavailable VMaaS
+func getDBChange() (*vmaas.DBChangeResponse, error) {
+	if vmaasDump != nil {
+		return vmaasDump.GetDBChange(), nil
+	}
+	dbchange := vmaas.DBChangeResponse{}
+	_, err := vmaasClient.Request(&base.Context, http.MethodGet, vmaasDBChangeURL, nil, &dbchange)
+	if err != nil {
+		return nil, err
+	}
+	return &dbchange, nil
+}
+
+func getDBChangeJSON() []byte {
+	dbchange, err := getDBChange()
+	if err != nil {
+		utils.Log("err", err.Error()).Warn("Unable to record vmaas dbchange")
+		return nil
+	}
+	data, err := json.Marshal(dbchange.DBChange)
+	if err != nil {
+		utils.Log("err", err.Error()).Warn("Unable to serialize vmaas dbchange")
+		return nil
+	}
+	return data
+}
+
+// SyncDataAsync records and starts data sync in background
+func SyncDataAsync(lastSyncTS *string, lastFullSyncTS *types.Rfc3339TimestampWithZ) (*models.SyncRun, error) {
+	run, err := startSyncRun(lastSyncTS)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer utils.LogPanics(false)
+		if err := syncData(run, nil, lastSyncTS, lastFullSyncTS); err != nil {
+			utils.Log("err", err.Error(), "id", run.ID).Error("Async data sync failed")
+		}
+	}()
+	return run, nil
+}
@@ -0,0 +1,95 @@
+package vmaas_sync //nolint:revive,stylecheck
+
+import (
+	"app/base/core"
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncDataRecordsRun(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	assert.Nil(t, SyncData(nil, nil))
+
+	var run models.SyncRun
+	assert.Nil(t, database.Db.Order("started DESC").Take(&run).Error)
+	assert.NotNil(t, run.Finished)
+	assert.Nil(t, run.ModifiedSince)
+	assert.Nil(t, run.Error)
+	assert.True(t, run.AdvisoriesInserted+run.AdvisoriesUpdated > 0)
+	assert.NotNil(t, run.DBChange)
+	assert.Nil(t, database.Db.Delete(&run).Error)
+	deleteLastSyncTimestamps(t)
+}
+
+func TestSyncDataAlreadyRunning(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	running := models.SyncRun{Started: time.Now()}
+	assert.Nil(t, database.Db.Create(&running).Error)
+	defer database.Db.Delete(&running)
+
+	assert.Equal(t, ErrSyncRunning, SyncData(nil, nil))
+	_, err := SyncDataAsync(nil, nil)
+	assert.Equal(t, ErrSyncRunning, err)
+}
+
+func TestRunSyncAlreadyRunning(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	running := models.SyncRun{Started: time.Now()}
+	assert.Nil(t, database.Db.Create(&running).Error)
+	defer database.Db.Delete(&running)
+
+	var nRuns, nRunsAfter int64
+	assert.Nil(t, database.Db.Model(&models.SyncRun{}).Count(&nRuns).Error)
+	evalWriter = &mockKafkaWriter{}
+	nMsgs := len(msgs)
+
+	// job run exits without failing and without touching the running sync
+	runSync()
+
+	assert.Nil(t, database.Db.Model(&models.SyncRun{}).Count(&nRunsAfter).Error)
+	assert.Equal(t, nRuns, nRunsAfter)
+	assert.Nil(t, database.Db.Take(&running, running.ID).Error)
+	assert.Nil(t, running.Finished)
+	assert.Equal(t, nMsgs, len(msgs))
+}
+
+func TestStartSyncRunExpired(t *testing.T) {
+	utils.SkipWithoutDB(t)
+	core.SetupTestEnvironment()
+	configure()
+
+	stale := models.SyncRun{Started: time.Now().Add(-syncRunTimeout - time.Hour)}
+	assert.Nil(t, database.Db.Create(&stale).Error)
+	defer database.Db.Delete(&stale)
+
+	run, err := startSyncRun(nil)
+	assert.Nil(t, err)
+	defer database.Db.Delete(run)
+	finishSyncRun(run, nil, nil)
+
+	assert.Nil(t, database.Db.Take(&stale, stale.ID).Error)
+	assert.NotNil(t, stale.Finished)
+	assert.Equal(t, "sync run expired", *stale.Error)
+}
+
+func TestParseModifiedSince(t *testing.T) {
+	assert.Nil(t, parseModifiedSince(nil))
+	assert.Nil(t, parseModifiedSince(utils.PtrString("invalid")))
+
+	ts := parseModifiedSince(utils.PtrString("2021-01-02T03:04:05Z"))
+	assert.Equal(t, time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), ts.UTC())
+}
@@ -0,0 +1,51 @@
+package vmaas_sync //nolint:revive,stylecheck
+
+import (
+	"app/base/vmaasdump"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVmaasDumpRequests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vmaasdump")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "errata.json"),
+		[]byte(`{"errata_list": {"RH-1": {"type": "security"}}}`), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "pkglist.json"),
+		[]byte(`{"package_list": [{"nevra": "kernel-5.14.0-2.el9.x86_64"}]}`), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "repos.json"),
+		[]byte(`{"repository_list": {"rhel-9": [{"label": "rhel-9"}]}}`), 0600))
+
+	vmaasDumpPath = dir
+	defer func() {
+		vmaasDumpPath = ""
+		vmaasDump = nil
+	}()
+	assert.Nil(t, loadVmaasDump())
+	assert.IsType(t, &vmaasdump.Dump{}, vmaasDump)
+
+	errata, err := vmaasErrataRequest(0, nil, advisoryPageSize)
+	assert.Nil(t, err)
+	assert.Contains(t, errata.ErrataList, "RH-1")
+
+	pkgs, err := vmaasPkgListRequest(0, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pkgs.PackageList))
+
+	redhatRepos, thirdPartyRepos, err := getUpdatedRepos(time.Now(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"rhel-9"}, redhatRepos)
+	assert.Equal(t, 0, len(thirdPartyRepos))
+
+	// dump without dbchange syncs everything
+	assert.True(t, fullSyncPlan().all())
+	dbchange, err := vmaasDBChangeRequest()
+	assert.Nil(t, err)
+	assert.Nil(t, newSyncPlan(dbchange).errata.changed)
+}
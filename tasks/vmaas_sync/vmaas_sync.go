@@ -5,9 +5,11 @@ import (
 	"app/base/api"
 	"app/base/core"
 	"app/base/database"
+	"app/base/models"
 	"app/base/mqueue"
 	"app/base/types"
 	"app/base/utils"
+	"app/base/vmaasdump"
 	"app/tasks"
 	"app/tasks/caches"
 	"net/http"
@@ -36,6 +38,9 @@ var (
 	vmaasCallExpRetry        bool
 	vmaasCallMaxRetries      int
 	fullSyncCadence          int
+	vmaasDumpPath            string
+	vmaasDump                *vmaasdump.Dump
+	syncRunTimeout           time.Duration
 )
 
 func configure() {
@@ -45,7 +50,12 @@ func configure() {
 		HTTPClient: &http.Client{},
 		Debug:      useTraceLevel,
 	}
-	vmaasAddress := utils.FailIfEmpty(utils.Cfg.VmaasAddress, "VMAAS_ADDRESS")
+	// sync from VMaaS data files instead of VMaaS API, used in disconnected deployments
+	vmaasDumpPath = utils.Getenv("VMAAS_DUMP_PATH", "")
+	vmaasAddress := utils.Cfg.VmaasAddress
+	if vmaasDumpPath == "" {
+		vmaasAddress = utils.FailIfEmpty(vmaasAddress, "VMAAS_ADDRESS")
+	}
 	vmaasErratasURL = vmaasAddress + base.VMaaSAPIPrefix + "/errata"
 	vmaasPkgListURL = vmaasAddress + base.VMaaSAPIPrefix + "/pkglist"
 	vmaasReposURL = vmaasAddress + base.VMaaSAPIPrefix + "/repos"
@@ -67,6 +77,13 @@ func configure() {
 	vmaasCallExpRetry = utils.GetBoolEnvOrDefault("VMAAS_CALL_EXP_RETRY", false) // false - retry periodically
 
 	fullSyncCadence = utils.GetIntEnvOrDefault("FULL_SYNC_CADENCE", 24*7) // run full sync once in 7 days by default
+	// unfinished runs older than the timeout are considered interrupted, e.g. by crash of the instance running them
+	syncRunTimeout = time.Duration(utils.GetIntEnvOrDefault("SYNC_RUN_TIMEOUT_HOURS", 6)) * time.Hour
+}
+
+// Configure sync for the components calling it outside of the vmaas_sync job, e.g. admin API
+func Configure() {
+	configure()
 }
 
 func runSync() {
@@ -74,17 +91,36 @@ func runSync() {
 	lastSyncTS := getLastSyncIfNeeded()
 	lastFullSyncTS := getLastSync(LastFullSync)
 
-	if isSyncNeeded() {
-		err := SyncData(lastSyncTS, lastFullSyncTS) // respect ENABLE_MODIFIED_SINCE_SYNC
-		if err != nil {
-			// This probably means programming error, better to exit with nonzero error code, so the error is noticed
-			utils.Log("err", err.Error()).Fatal("vmaas data sync failed")
-		}
+	if err := loadVmaasDump(); err != nil {
+		utils.Log("err", err.Error()).Fatal("vmaas dump loading failed")
+	}
+	plan := getSyncPlan()
+	if !plan.any() {
+		return
+	}
 
-		err = SendReevaluationMessages()
-		if err != nil {
-			utils.Log("err", err.Error()).Error("re-evaluation sending routine failed")
-		}
+	run, err := startSyncRun(lastSyncTS)
+	if errors.Is(err, ErrSyncRunning) {
+		// sync started e.g. from admin API is still running, next job run picks up the changes
+		utils.Log().Warn("Another vmaas data sync is running, skipping sync")
+		return
+	}
+	if err == nil {
+		err = syncData(run, plan, lastSyncTS, lastFullSyncTS) // respect ENABLE_MODIFIED_SINCE_SYNC
+	}
+	if err != nil {
+		// This probably means programming error, better to exit with nonzero error code, so the error is noticed
+		utils.Log("err", err.Error()).Fatal("vmaas data sync failed")
+	}
+
+	// repo based re-evaluation picks systems with updated repos only
+	if enabledRepoBasedReeval && !plan.repos.needed {
+		utils.Log().Info("Repos not changed, skipping re-evaluation")
+		return
+	}
+	err = SendReevaluationMessages()
+	if err != nil {
+		utils.Log("err", err.Error()).Error("re-evaluation sending routine failed")
 	}
 }
 
@@ -105,41 +141,70 @@ func getLastSync(key string) *types.Rfc3339TimestampWithZ {
 	return ts
 }
 
+// SyncData records and runs data sync, fails when another sync is running
 func SyncData(lastSyncTS *string, lastFullSyncTS *types.Rfc3339TimestampWithZ) error {
-	utils.Log().Info("Data sync started")
-	syncStart := time.Now()
+	run, err := startSyncRun(lastSyncTS)
+	if err != nil {
+		return err
+	}
+	return syncData(run, nil, lastSyncTS, lastFullSyncTS)
+}
+
+// Sync entity types needed by the plan, all of them when the plan is nil
+func syncData(run *models.SyncRun, plan *syncPlan, lastSyncTS *string,
+	lastFullSyncTS *types.Rfc3339TimestampWithZ) (err error) {
+	utils.Log("id", run.ID).Info("Data sync started")
+	syncStart := run.Started
 	defer utils.ObserveSecondsSince(syncStart, syncDuration)
+	defer func() {
+		if obj := recover(); obj != nil {
+			finishSyncRun(run, lastSyncTS, errors.Errorf("sync panicked: %v", obj))
+			panic(obj)
+		}
+		finishSyncRun(run, lastSyncTS, err)
+	}()
 
+	if plan == nil {
+		if err = loadVmaasDump(); err != nil {
+			return errors.Wrap(err, "Failed to load vmaas dump")
+		}
+		plan = fullSyncPlan()
+	}
 	if lastFullSyncTS != nil {
 		nextFullSync := lastFullSyncTS.Time().Add(time.Duration(fullSyncCadence) * time.Hour)
 		if syncStart.After(nextFullSync) {
 			lastSyncTS = nil // set last sync to `nil` to do a full vmaas sync
+			plan.setAll()
 		}
 	}
 
-	if enableAdvisoriesSync {
-		if err := syncAdvisories(syncStart, lastSyncTS); err != nil {
+	if enableAdvisoriesSync && plan.advisoriesNeeded() {
+		if err = syncAdvisories(syncStart, lastSyncTS); err != nil {
 			return errors.Wrap(err, "Failed to sync advisories")
 		}
+		plan.errata.markSynced()
+		plan.cves.markSynced()
 	}
 
-	if enablePackagesSync {
-		if err := syncPackages(syncStart, lastSyncTS); err != nil {
+	if enablePackagesSync && plan.packages.needed {
+		if err = syncPackages(syncStart, lastSyncTS); err != nil {
 			return errors.Wrap(err, "Failed to sync packages")
 		}
+		plan.packages.markSynced()
 	}
 
-	if enableReposSync {
-		if err := syncRepos(syncStart); err != nil {
+	if enableReposSync && plan.repos.needed {
+		if err = syncRepos(syncStart); err != nil {
 			return errors.Wrap(err, "Failed to sync repos")
 		}
+		plan.repos.markSynced()
 	}
 
 	// refresh caches
 	caches.RefreshAdvisoryCaches()
 
 	database.UpdateTimestampKVValue(syncStart, LastSync)
-	if lastSyncTS == nil {
+	if lastSyncTS == nil && plan.all() {
 		database.UpdateTimestampKVValue(syncStart, LastFullSync)
 	}
 	utils.Log().Info("Data sync finished successfully")
@@ -155,3 +220,16 @@ func RunVmaasSync() {
 		utils.Log("err", err).Info("Could not push to pushgateway")
 	}
 }
+
+// Load current content of the dump, data files can be replaced between syncs
+func loadVmaasDump() error {
+	if vmaasDumpPath == "" {
+		return nil
+	}
+	dump, err := vmaasdump.Load(vmaasDumpPath)
+	if err != nil {
+		return err
+	}
+	vmaasDump = dump
+	return nil
+}
@@ -60,6 +60,7 @@ func TestSync(t *testing.T) {
 	resetLastEvalTimestamp(t)
 	database.DeleteNewlyAddedPackages(t)
 	database.DeleteNewlyAddedAdvisories(t)
+	deleteLastSyncTimestamps(t)
 }
 
 func TestHandleContextCancel(t *testing.T) {
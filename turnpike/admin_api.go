@@ -6,6 +6,7 @@ import (
 	"app/base/utils"
 	"app/manager/middlewares"
 	"app/manager/routes"
+	"app/tasks/vmaas_sync"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,6 +28,7 @@ import (
 // @BasePath /api/patch/admin
 func RunAdminAPI() {
 	core.ConfigureApp()
+	vmaas_sync.Configure()
 
 	utils.Log("port", utils.Cfg.PublicPort).Info("Manager-admin starting")
 	app := gin.New()
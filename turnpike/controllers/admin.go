@@ -2,32 +2,57 @@ package controllers
 
 import (
 	"app/base/database"
+	"app/base/models"
 	"app/base/utils"
+	"app/tasks/caches"
 	sync "app/tasks/vmaas_sync"
+	"encoding/json"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
+type RecalcJob struct {
+	ID               int64            `json:"id"`
+	Scope            sync.RecalcScope `json:"scope"`
+	Created          time.Time        `json:"created"`
+	Finished         *time.Time       `json:"finished"`
+	SystemsTotal     int              `json:"systems_total"`
+	MessagesQueued   int              `json:"messages_queued"`
+	SystemsEvaluated int              `json:"systems_evaluated"`
+	Progress         float64          `json:"progress"` // Percentage of evaluated systems
+}
+
 // @Summary Sync data from VMaaS
-// @Description Sync data from VMaaS
+// @Description Start data sync from VMaaS in background, use sync status to check its progress
 // @ID sync
 // @Security RhIdentity
 // @Accept   json
 // @Produce  json
-// @Success 200 {object} string
+// @Success 202 {object} SyncRun
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /sync [get]
 func Syncapi(c *gin.Context) {
 	utils.Log().Info("manual syncing called...")
-	err := sync.SyncData(nil, nil)
+	run, err := sync.SyncDataAsync(nil, nil)
+	if errors.Is(err, sync.ErrSyncRunning) {
+		c.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	}
 	if err != nil {
 		utils.Log("err", err.Error()).Error("manual called syncing failed")
 		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
 		return
 	}
-	utils.Log().Info("manual syncing finished successfully")
-	c.JSON(http.StatusOK, "OK")
+	utils.Log("id", run.ID).Info("manual syncing started")
+	c.JSON(http.StatusAccepted, syncRunResponse(run))
 }
 
 // @Summary Re-evaluate systems
@@ -51,29 +76,200 @@ func Recalc(c *gin.Context) {
 	c.JSON(http.StatusOK, "OK")
 }
 
+// @Summary Re-evaluate systems in scope
+// @Description Send systems matching all the set scope conditions to re-evaluation, empty scope selects all systems.
+// @Description Returns job which tracks the re-evaluation progress.
+// @ID recalcScoped
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    body    body    sync.RecalcScope false "Re-evaluation scope"
+// @Success 202 {object} RecalcJob
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /re-calc [post]
+func RecalcScoped(c *gin.Context) {
+	var scope sync.RecalcScope
+	if err := c.ShouldBindJSON(&scope); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"err": "invalid scope: " + err.Error()})
+		return
+	}
+	if err := scope.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	utils.Log("scope", scope).Info("manual scoped re-calc messages sending called...")
+	job, err := sync.SendScopedReevaluationMessages(&scope)
+	if errors.Is(err, sync.ErrRecalcDisabled) {
+		c.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		utils.Log("err", err.Error()).Error("manual scoped re-calc msgs sending failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+	utils.Log("job", job.ID, "count", job.SystemsTotal).Info("manual scoped re-calc messages sent successfully")
+
+	resp, err := recalcJobResponse(job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// @Summary Show re-evaluation job progress
+// @Description Show re-evaluation job progress
+// @ID recalcJob
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    job_id    path    int     true    "Job ID"
+// @Success 200 {object} RecalcJob
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /re-calc/{job_id} [get]
+func RecalcJobStatus(c *gin.Context) {
+	jobID, err := strconv.ParseInt(c.Param("job_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"err": "invalid job_id"})
+		return
+	}
+
+	var job models.RecalcJob
+	err = database.Db.Where("id = ?", jobID).Take(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"err": "job not found"})
+		return
+	}
+	if err != nil {
+		utils.Log("err", err.Error()).Error("re-calc job loading failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+
+	resp, err := recalcJobResponse(&job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func recalcJobResponse(job *models.RecalcJob) (*RecalcJob, error) {
+	resp := RecalcJob{
+		ID:               job.ID,
+		Created:          job.Created,
+		Finished:         job.Finished,
+		SystemsTotal:     job.SystemsTotal,
+		MessagesQueued:   job.MessagesQueued,
+		SystemsEvaluated: job.SystemsEvaluated,
+		Progress:         100,
+	}
+	if job.SystemsTotal > 0 {
+		resp.Progress = math.Min(float64(job.SystemsEvaluated)*100/float64(job.SystemsTotal), 100)
+	}
+	if err := json.Unmarshal(job.Scope, &resp.Scope); err != nil {
+		return nil, errors.Wrap(err, "unable to parse job scope")
+	}
+	return &resp, nil
+}
+
 // @Summary Check cached counts
-// @Description Check cached counts
+// @Description Check cached advisory counts of systems and accounts, list mismatching caches
 // @ID checkCaches
 // @Security RhIdentity
 // @Accept   json
 // @Produce  json
-// @Success 200 {object} string
-// @Failure 409 {object} string
+// @Param    limit    query   int     false   "Max number of listed mismatches per cache, default 100"
+// @Success 200 {object} caches.CacheMismatchReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} caches.CacheMismatchReport
 // @Failure 500 {object} map[string]interface{}
 // @Router /check-caches [get]
 func CheckCaches(c *gin.Context) {
-	valid, err := database.CheckCachesValidRet()
+	limit, err := parsePositiveIntQuery(c, "limit", caches.CacheReportDefaultLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	report, err := caches.GetCacheMismatchReport(limit)
 	if err != nil {
 		utils.Log("error", err).Error("Could not check validity of caches")
 		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
 		return
 	}
 
-	if !valid {
-		utils.Log().Error("Cache mismatch found")
-		c.JSON(http.StatusConflict, "conflict")
+	if !report.Valid {
+		utils.Log("systems", len(report.Systems), "advisories", len(report.Advisories)).Error("Cache mismatch found")
+		c.JSON(http.StatusConflict, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+type CacheRepairResponse struct {
+	Repaired caches.CacheRepairResult   `json:"repaired"`
+	Report   caches.CacheMismatchReport `json:"report"` // Caches state after the repair
+}
+
+// @Summary Repair cached counts
+// @Description Recompute mismatching cached advisory counts of systems and accounts in batches
+// @ID repairCaches
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    batch_size    query   int     false   "Number of caches repaired in one transaction, default 1000"
+// @Param    limit         query   int     false   "Max number of listed mismatches per cache after repair, default 100"
+// @Success 200 {object} CacheRepairResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /check-caches/repair [post]
+func RepairCaches(c *gin.Context) {
+	batchSize, err := parsePositiveIntQuery(c, "batch_size", caches.CacheRepairDefaultBatchSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	limit, err := parsePositiveIntQuery(c, "limit", caches.CacheReportDefaultLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	utils.Log().Info("manual cache repair called...")
+	repaired, err := caches.RepairCaches(batchSize)
+	if err != nil {
+		utils.Log("err", err.Error()).Error("manual cache repair failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+	utils.Log("systems", repaired.SystemsRepaired, "advisories", repaired.AdvisoriesRepaired).
+		Info("manual cache repair finished")
+
+	report, err := caches.GetCacheMismatchReport(limit)
+	if err != nil {
+		utils.Log("error", err).Error("Could not check validity of caches")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, CacheRepairResponse{Repaired: *repaired, Report: *report})
+}
 
-	c.JSON(http.StatusOK, "caches counts OK")
+func parsePositiveIntQuery(c *gin.Context, name string, defaultValue int) (int, error) {
+	valueStr := c.Query(name)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 1 {
+		return 0, errors.Errorf("invalid %s, use positive number", name)
+	}
+	return value, nil
 }
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ShadowEvalDiffsDefaultLimit = 20
+	ShadowEvalDiffsMaxLimit     = 100
+)
+
+type ShadowEvalDiff struct {
+	ID                    int64     `json:"id"`
+	RhAccountID           int       `json:"rh_account_id"`
+	InventoryID           string    `json:"inventory_id"`
+	Created               time.Time `json:"created"`
+	Source                string    `json:"source"` // "local" engine or VMaaS address used by shadow evaluation
+	AdvisoriesOnlyPrimary []string  `json:"advisories_only_primary"`
+	AdvisoriesOnlyShadow  []string  `json:"advisories_only_shadow"`
+	PackagesOnlyPrimary   []string  `json:"packages_only_primary"`
+	PackagesOnlyShadow    []string  `json:"packages_only_shadow"`
+}
+
+type ShadowEvalDiffsResponse struct {
+	Data []ShadowEvalDiff `json:"data"`
+}
+
+func parseStringList(data []byte) []string {
+	list := []string{}
+	if data != nil {
+		if err := json.Unmarshal(data, &list); err != nil {
+			utils.Log("err", err.Error()).Warn("Unable to parse shadow evaluation diff list")
+		}
+	}
+	return list
+}
+
+func shadowEvalDiffResponse(diff *models.ShadowEvalDiff) ShadowEvalDiff {
+	return ShadowEvalDiff{
+		ID:                    diff.ID,
+		RhAccountID:           diff.RhAccountID,
+		InventoryID:           diff.InventoryID,
+		Created:               diff.Created,
+		Source:                diff.Source,
+		AdvisoriesOnlyPrimary: parseStringList(diff.AdvisoriesOnlyPrimary),
+		AdvisoriesOnlyShadow:  parseStringList(diff.AdvisoriesOnlyShadow),
+		PackagesOnlyPrimary:   parseStringList(diff.PackagesOnlyPrimary),
+		PackagesOnlyShadow:    parseStringList(diff.PackagesOnlyShadow),
+	}
+}
+
+// @Summary Show shadow evaluation discrepancies
+// @Description Show advisories and packages found only by primary or only by shadow evaluation, newest first
+// @ID shadowEvalDiffs
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    limit          query   int     false   "Number of returned diffs, max 100"
+// @Param    offset         query   int     false   "Offset of the first returned diff"
+// @Param    inventory_id   query   string  false   "Filter diffs of the system"
+// @Param    source         query   string  false   "Filter diffs of the shadow source"
+// @Success 200 {object} ShadowEvalDiffsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /shadow-eval/diffs [get]
+func ShadowEvalDiffs(c *gin.Context) {
+	limit := ShadowEvalDiffsDefaultLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > ShadowEvalDiffsMaxLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"err": "invalid limit, use number between 1 and 100"})
+			return
+		}
+	}
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"err": "invalid offset, use non-negative number"})
+			return
+		}
+	}
+
+	query := database.Db.Order("created DESC, id DESC").Limit(limit).Offset(offset)
+	if inventoryID := c.Query("inventory_id"); inventoryID != "" {
+		if !utils.IsValidUUID(inventoryID) {
+			c.JSON(http.StatusBadRequest, gin.H{"err": "invalid inventory_id"})
+			return
+		}
+		query = query.Where("inventory_id = ?", inventoryID)
+	}
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", source)
+	}
+
+	var diffs []models.ShadowEvalDiff
+	if err := query.Find(&diffs).Error; err != nil {
+		utils.Log("err", err.Error()).Error("shadow evaluation diffs loading failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+
+	data := make([]ShadowEvalDiff, len(diffs))
+	for i := range diffs {
+		data[i] = shadowEvalDiffResponse(&diffs[i])
+	}
+	c.JSON(http.StatusOK, ShadowEvalDiffsResponse{Data: data})
+}
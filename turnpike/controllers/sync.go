@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"app/base/database"
+	"app/base/models"
+	"app/base/utils"
+	sync "app/tasks/vmaas_sync"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SyncHistoryDefaultLimit = 20
+	SyncHistoryMaxLimit     = 100
+)
+
+type SyncRun struct {
+	ID                 int64           `json:"id"`
+	Started            time.Time       `json:"started"`
+	Finished           *time.Time      `json:"finished"`
+	ModifiedSince      *time.Time      `json:"modified_since"`
+	FullSync           bool            `json:"full_sync"`
+	AdvisoriesInserted int             `json:"advisories_inserted"`
+	AdvisoriesUpdated  int             `json:"advisories_updated"`
+	PackagesInserted   int             `json:"packages_inserted"`
+	PackagesUpdated    int             `json:"packages_updated"`
+	ReposUpdated       int             `json:"repos_updated"`
+	DBChange           json.RawMessage `json:"dbchange" swaggertype:"object"` // VMaaS dbchange timestamps
+	Error              *string         `json:"error"`
+}
+
+type SyncStatusResponse struct {
+	Running      bool       `json:"running"`
+	Current      *SyncRun   `json:"current"`       // Running sync
+	LastFinished *SyncRun   `json:"last_finished"` // Last finished sync, successful or failed
+	LastSync     *time.Time `json:"last_sync"`
+	LastFullSync *time.Time `json:"last_full_sync"`
+	// Last synced VMaaS changes of the entity types
+	LastSyncErrata   *time.Time `json:"last_sync_errata"`
+	LastSyncPackages *time.Time `json:"last_sync_packages"`
+	LastSyncRepos    *time.Time `json:"last_sync_repos"`
+	LastSyncCves     *time.Time `json:"last_sync_cves"`
+}
+
+type SyncHistoryResponse struct {
+	Data []SyncRun `json:"data"`
+}
+
+func syncRunResponse(run *models.SyncRun) *SyncRun {
+	if run == nil {
+		return nil
+	}
+	resp := SyncRun{
+		ID:                 run.ID,
+		Started:            run.Started,
+		Finished:           run.Finished,
+		ModifiedSince:      run.ModifiedSince,
+		FullSync:           run.ModifiedSince == nil,
+		AdvisoriesInserted: run.AdvisoriesInserted,
+		AdvisoriesUpdated:  run.AdvisoriesUpdated,
+		PackagesInserted:   run.PackagesInserted,
+		PackagesUpdated:    run.PackagesUpdated,
+		ReposUpdated:       run.ReposUpdated,
+		Error:              run.Error,
+	}
+	if len(run.DBChange) > 0 {
+		resp.DBChange = run.DBChange
+	}
+	return &resp
+}
+
+func lastSyncRun(finished bool) (*models.SyncRun, error) {
+	var runs []models.SyncRun
+	query := database.Db.Order("started DESC").Limit(1)
+	if finished {
+		query = query.Where("finished IS NOT NULL")
+	} else {
+		query = query.Where("finished IS NULL")
+	}
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
+
+// @Summary Show VMaaS sync status
+// @Description Show running and last finished VMaaS sync with last sync timestamps
+// @ID syncStatus
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Success 200 {object} SyncStatusResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /sync/status [get]
+func SyncStatus(c *gin.Context) {
+	current, err := lastSyncRun(false)
+	if err != nil {
+		utils.Log("err", err.Error()).Error("sync status loading failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+	last, err := lastSyncRun(true)
+	if err != nil {
+		utils.Log("err", err.Error()).Error("sync status loading failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+
+	resp := SyncStatusResponse{
+		Running:      current != nil,
+		Current:      syncRunResponse(current),
+		LastFinished: syncRunResponse(last),
+	}
+	resp.LastSync = getTimestampKV(sync.LastSync)
+	resp.LastFullSync = getTimestampKV(sync.LastFullSync)
+	resp.LastSyncErrata = getTimestampKV(sync.LastSyncErrata)
+	resp.LastSyncPackages = getTimestampKV(sync.LastSyncPackages)
+	resp.LastSyncRepos = getTimestampKV(sync.LastSyncRepos)
+	resp.LastSyncCves = getTimestampKV(sync.LastSyncCves)
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Show VMaaS sync history
+// @Description Show recent VMaaS syncs, newest first
+// @ID syncHistory
+// @Security RhIdentity
+// @Accept   json
+// @Produce  json
+// @Param    limit    query   int     false   "Number of returned syncs, max 100"
+// @Success 200 {object} SyncHistoryResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sync/history [get]
+func SyncHistory(c *gin.Context) {
+	limit := SyncHistoryDefaultLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > SyncHistoryMaxLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"err": "invalid limit, use number between 1 and 100"})
+			return
+		}
+	}
+
+	var runs []models.SyncRun
+	err := database.Db.Order("started DESC").Limit(limit).Find(&runs).Error
+	if err != nil {
+		utils.Log("err", err.Error()).Error("sync history loading failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		return
+	}
+
+	data := make([]SyncRun, len(runs))
+	for i := range runs {
+		data[i] = *syncRunResponse(&runs[i])
+	}
+	c.JSON(http.StatusOK, SyncHistoryResponse{Data: data})
+}
+
+func getTimestampKV(key string) *time.Time {
+	ts, err := database.GetTimestampKVValue(key)
+	if err != nil || ts == nil {
+		return nil
+	}
+	return ts.Time()
+}